@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/client"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+func actionItemsCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "action-items PR_URL|PR_NUMBER",
+		Short: "Summarize a PR's review comments into action items",
+		Long: `Collect the review comments on a PR, consolidate them into a checklist
+of action items and post it as a comment. Running it again edits the same
+comment. With action_items enabled in the repository config, the server
+does this whenever a review is submitted. Requires GGQUICK_ADMIN_TOKEN
+when the server sets one.`,
+		Example: `  ggquick action-items https://github.com/my-org/api/pull/142
+  ggquick action-items 142 --repo my-org/api`,
+		Args: cli.ExactArgs(1),
+	}
+	repo := cmd.Flags().String("repo", originRepo(), "repository as owner/name, when giving a PR number")
+	server := cmd.Flags().String("server", serverBaseURL(), "ggquick server URL")
+	cmd.Run = func(_ *cli.Command, args []string) error {
+		var req client.ActionItemsRequest
+		var err error
+		if req.Repo, req.PR, err = prArg(args[0], *repo); err != nil {
+			return configError(err)
+		}
+
+		logger := log.New(true)
+		logger.Loading("📋 Collecting action items on #%d in %s...", req.PR, req.Repo)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		result, err := adminClient(*server).ActionItems(ctx, req)
+		if err != nil {
+			return fmt.Errorf("action items failed: %w", err)
+		}
+		if jsonOutput {
+			return printJSON(result)
+		}
+		logger.Success("✅ Posted %d action item(s) from %d review comment(s) on #%d: %s", result.Items, result.Comments, result.Number, result.URL)
+		return nil
+	}
+	return cmd
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/client"
+	"github.com/saint0x/ggquick/pkg/config"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+func applyCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "apply REPO_URL",
+		Short: "Apply ggquick to a repository",
+		Long: `Register a repository with the server, which sets up its GitHub
+webhook. Without --server or GGQUICK_SERVER the hosted server is tried
+first, falling back to a local one. The repository's push secret, made by
+ggquick init, is sent along so the server only accepts signed pushes.
+Sending it the first time needs GGQUICK_ADMIN_TOKEN when the server sets
+one.`,
+		Example: `  ggquick apply https://github.com/user/repo`,
+		Args:    cli.ExactArgs(1),
+	}
+	server := cmd.Flags().String("server", configuredServer(), "ggquick server URL")
+	cmd.Run = func(_ *cli.Command, args []string) error { return handleApply(args[0], *server) }
+	return cmd
+}
+
+func handleApply(repoURL, server string) error {
+	logger := log.New(true)
+	logger.Loading("🚀 Initializing ggquick client...")
+	logger.Info("📝 Target repository: %s", repoURL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if server != "" {
+		return applyTo(ctx, logger, server, repoURL)
+	}
+
+	// Check remote server health first
+	logger.Loading("🔍 Checking remote server (ggquick.fly.dev)...")
+	err := applyTo(ctx, logger, client.DefaultBaseURL, repoURL)
+	if err == nil {
+		return nil
+	}
+	logger.Error("❌ Remote server failed: %v", err)
+
+	// If remote server failed, try local server
+	logger.Info("ℹ️ Remote server unavailable, falling back to local server...")
+	if err := applyTo(ctx, logger, localBaseURL(), repoURL); err != nil {
+		logger.Error("❌ Local server failed: %v", err)
+		return networkError(fmt.Errorf("both remote and local servers are unavailable"))
+	}
+	return nil
+}
+
+// applyTo registers repoURL with the server at baseURL
+func applyTo(ctx context.Context, logger *log.Logger, baseURL, repoURL string) error {
+	// Setting the first push secret needs the admin token
+	c := serverClient(baseURL).WithToken(os.Getenv("GGQUICK_ADMIN_TOKEN"))
+	if m := originPattern.FindStringSubmatch(repoURL); m != nil {
+		c.WithPushSecret(config.PushSecret(m[1]))
+	}
+	if err := c.Health(ctx); err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+	logger.Success("✅ Server is healthy")
+
+	logger.Loading("📤 Sending configuration to %s...", baseURL)
+	resp, err := c.Configure(ctx, repoURL)
+	if err != nil {
+		return fmt.Errorf("failed to send config to server: %w", err)
+	}
+
+	if jsonOutput {
+		return printJSON(map[string]string{"server": baseURL, "owner": resp.Owner, "name": resp.Name})
+	}
+
+	// Single, clear success sequence
+	logger.Success("✨ Configuration sent successfully to %s", baseURL)
+	logger.Success("✅ Server confirmed configuration is stored")
+	logger.Info("📦 Repository configured: %s/%s", resp.Owner, resp.Name)
+	logger.Success("🔄 Ready to process Git events")
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/client"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+func approveCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "approve JOB_ID",
+		Short: "Run a job held by the author cap",
+		Long: `Run a job held because its author was over the repository's daily
+cap. Requires GGQUICK_ADMIN_TOKEN when the server sets one.`,
+		Example: `  ggquick approve 3f9a1c2b7d4e5f60`,
+		Args:    cli.ExactArgs(1),
+	}
+	server := cmd.Flags().String("server", serverBaseURL(), "ggquick server URL")
+	cmd.Run = func(_ *cli.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		id := args[0]
+		job, err := adminClient(*server).ApproveJob(ctx, id)
+		if err != nil {
+			if client.IsNotFound(err) {
+				return fmt.Errorf("no job with ID %s", id)
+			}
+			return fmt.Errorf("failed to approve job: %w", err)
+		}
+		if jsonOutput {
+			return printJSON(job)
+		}
+		log.New(true).Success("✅ Approved job %s (%s/%s %s)", job.ID, job.Owner, job.Repo, job.Branch)
+		return nil
+	}
+	return cmd
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/client"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+// backfillPageSize is how many branches each backfill request asks for,
+// the server's maximum
+const backfillPageSize = 25
+
+func backfillCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:     "backfill OWNER/REPO",
+		Short:   "Generate PRs for recent unmerged branches",
+		Example: `  ggquick backfill my-org/api --since 2w --dry-run`,
+		Args:    cli.ExactArgs(1),
+	}
+	since := cmd.Flags().String("since", "30d", "only branches with commits in this window (e.g. 30d, 2w, 12h)")
+	dryRun := cmd.Flags().Bool("dry-run", false, "list branches without generating PRs")
+	server := cmd.Flags().String("server", serverBaseURL(), "ggquick server URL")
+	cmd.Run = func(_ *cli.Command, args []string) error {
+		return handleBackfill(*server, args[0], *since, *dryRun)
+	}
+	return cmd
+}
+
+func handleBackfill(server, repo, since string, dryRun bool) error {
+	if strings.Count(repo, "/") != 1 {
+		return configError(fmt.Errorf("repository must be owner/repo, got %q", repo))
+	}
+
+	logger := log.New(true)
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	c := adminClient(server)
+	logger.Loading("🔍 Finding unmerged branches in %s from the last %s...", repo, since)
+	var branches []client.BackfillBranch
+	var jobs []*client.Job
+	failed := 0
+	// Branches come a page at a time, and each page's jobs are waited for
+	// before asking for the next so the server doesn't evict them first
+	for after := ""; ; {
+		page, err := c.Backfill(ctx, client.BackfillRequest{
+			Repo:   repo,
+			Since:  since,
+			DryRun: dryRun,
+			After:  after,
+			Limit:  backfillPageSize,
+		})
+		if err != nil {
+			return fmt.Errorf("backfill failed: %w", err)
+		}
+		branches = append(branches, page...)
+
+		for _, b := range page {
+			logger.Branch("%s (%d ahead, %s, %s)", b.Name, b.AheadBy, b.Author, b.LastCommit.Format("2006-01-02"))
+		}
+		if !dryRun && len(page) > 0 {
+			logger.Loading("🤖 Generating %d PR(s)...", len(page))
+			for _, b := range page {
+				job, err := c.WaitJob(ctx, b.JobID, 2*time.Second)
+				if err != nil {
+					return fmt.Errorf("failed to wait for %s: %w", b.Name, err)
+				}
+				jobs = append(jobs, job)
+				if job.Status == "failed" {
+					failed++
+					logger.Error("❌ %s: %s", b.Name, job.Error)
+					continue
+				}
+				if job.Status == "cancelled" {
+					logger.Warning("%s: cancelled", b.Name)
+					continue
+				}
+				logger.PR("%s: %s", b.Name, job.PRURL)
+			}
+		}
+
+		if len(page) < backfillPageSize {
+			break
+		}
+		after = page[len(page)-1].Name
+	}
+
+	if jsonOutput && (dryRun || len(branches) == 0) {
+		return printJSON(branches)
+	}
+	if len(branches) == 0 {
+		logger.Success("✅ No unmerged branches without PRs")
+		return nil
+	}
+	if dryRun {
+		logger.Info("ℹ️ Dry run: %d branch(es) would get PRs", len(branches))
+		return nil
+	}
+
+	if jsonOutput {
+		if err := printJSON(jobs); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return generationError(fmt.Errorf("%d of %d backfill job(s) failed", failed, len(branches)))
+	}
+	logger.Success("✨ Backfill complete")
+	return nil
+}
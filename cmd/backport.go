@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/client"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+func backportCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "backport PR_NUMBER --to BRANCH",
+		Short: "Open a PR backporting a merged PR to another branch",
+		Long: `Cherry-pick a merged PR onto another branch on GitHub and open the
+backport PR with a generated description. When the changes don't apply
+cleanly, the original PR gets a comment explaining the conflicting files
+and how to backport by hand. Requires GGQUICK_ADMIN_TOKEN when the server
+sets one.`,
+		Example: `  ggquick backport 142 --to release/1.x
+  ggquick backport 142 --to release/2.0 --repo my-org/api`,
+		Args: cli.ExactArgs(1),
+	}
+	to := cmd.Flags().String("to", "", "branch to backport to")
+	repo := cmd.Flags().String("repo", originRepo(), "repository as owner/name")
+	server := cmd.Flags().String("server", serverBaseURL(), "ggquick server URL")
+	cmd.Run = func(_ *cli.Command, args []string) error {
+		number, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+		if err != nil || number <= 0 {
+			return configError(fmt.Errorf("invalid PR number %q", args[0]))
+		}
+		if *to == "" {
+			return configError(fmt.Errorf("--to is required"))
+		}
+		if strings.Count(*repo, "/") != 1 {
+			return configError(fmt.Errorf("repository must be owner/repo, got %q", *repo))
+		}
+
+		logger := log.New(true)
+		logger.Loading("🍒 Backporting #%d to %s in %s...", number, *to, *repo)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		result, err := adminClient(*server).Backport(ctx, client.BackportRequest{Repo: *repo, PR: number, To: *to})
+		if err != nil {
+			return fmt.Errorf("backport failed: %w", err)
+		}
+		if jsonOutput {
+			return printJSON(result)
+		}
+		logger.Success("✅ Opened backport PR #%d to %s: %s", result.Number, result.Target, result.URL)
+		return nil
+	}
+	return cmd
+}
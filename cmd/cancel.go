@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/client"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+func cancelCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "cancel JOB_ID",
+		Short: "Cancel a PR generation job",
+		Long: `Cancel a queued, waiting or running job. Requires GGQUICK_ADMIN_TOKEN
+when the server sets one, unless the job was started by your own push
+after ggquick login.`,
+		Example: `  ggquick cancel 3f9a1c2b7d4e5f60`,
+		Args:    cli.ExactArgs(1),
+	}
+	server := cmd.Flags().String("server", serverBaseURL(), "ggquick server URL")
+	cmd.Run = func(_ *cli.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		id := args[0]
+		job, err := adminClient(*server).WithUserKey(os.Getenv("GGQUICK_USER_KEY")).CancelJob(ctx, id)
+		if err != nil {
+			if client.IsNotFound(err) {
+				return fmt.Errorf("no job with ID %s", id)
+			}
+			return fmt.Errorf("failed to cancel job: %w", err)
+		}
+		if jsonOutput {
+			return printJSON(job)
+		}
+		log.New(true).Success("✅ Cancelled job %s (%s/%s %s)", job.ID, job.Owner, job.Repo, job.Branch)
+		return nil
+	}
+	return cmd
+}
@@ -1,21 +1,37 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"net/http"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/cli"
 )
 
-func handleCheck() error {
-	resp, err := http.Get("https://ggquick.fly.dev/health")
-	if err != nil {
-		return fmt.Errorf("server is not running: %w", err)
+func checkCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "check",
+		Short: "Check if the ggquick server is running",
+		Args:  cli.NoArgs,
 	}
-	defer resp.Body.Close()
+	server := cmd.Flags().String("server", serverBaseURL(), "ggquick server URL")
+	cmd.Run = func(*cli.Command, []string) error { return handleCheck(*server) }
+	return cmd
+}
+
+func handleCheck(server string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("server returned non-OK status: %d", resp.StatusCode)
+	if err := serverClient(server).Health(ctx); err != nil {
+		return fmt.Errorf("server is not running: %w", err)
 	}
 
-	fmt.Println("Server is running!")
+	if jsonOutput {
+		return printJSON(map[string]interface{}{"server": server, "running": true})
+	}
+	if !quietOutput {
+		fmt.Println("Server is running!")
+	}
 	return nil
 }
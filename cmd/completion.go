@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+)
+
+func completionCommand() *cli.Command {
+	return &cli.Command{
+		Use:   "completion bash|zsh|fish",
+		Short: "Generate a shell completion script",
+		Long: `Generate a completion script for bash, zsh or fish and print it to
+stdout.`,
+		Example: `  source <(ggquick completion bash)
+  ggquick completion zsh > "${fpath[1]}/_ggquick"
+  ggquick completion fish > ~/.config/fish/completions/ggquick.fish`,
+		Args: cli.ExactArgs(1),
+		Run: func(cmd *cli.Command, args []string) error {
+			root := cmd.Parent()
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletion(os.Stdout)
+			case "zsh":
+				return root.GenZshCompletion(os.Stdout)
+			case "fish":
+				return root.GenFishCompletion(os.Stdout)
+			default:
+				return fmt.Errorf("unsupported shell %q, expected bash, zsh or fish", args[0])
+			}
+		},
+	}
+}
+
+func manCommand() *cli.Command {
+	return &cli.Command{
+		Use:     "man [DIR]",
+		Short:   "Generate man pages",
+		Long:    "Write a man page for every command to DIR, ./man by default.",
+		Example: `  ggquick man /usr/local/share/man/man1`,
+		Args:    cli.MaximumNArgs(1),
+		Run: func(cmd *cli.Command, args []string) error {
+			dir := "man"
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			if err := cmd.Parent().GenManTree(dir); err != nil {
+				return err
+			}
+			if jsonOutput {
+				return printJSON(map[string]string{"dir": dir})
+			}
+			if !quietOutput {
+				fmt.Printf("Man pages written to %s\n", dir)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/saint0x/ggquick/pkg/client"
+)
+
+// configuredServer returns GGQUICK_SERVER, or "" when unset
+func configuredServer() string {
+	return os.Getenv("GGQUICK_SERVER")
+}
+
+// serverBaseURL returns the server the CLI talks to
+func serverBaseURL() string {
+	if url := configuredServer(); url != "" {
+		return url
+	}
+	return client.DefaultBaseURL
+}
+
+// localPort returns the port a local server listens on
+func localPort() string {
+	if port := os.Getenv("PORT"); port != "" {
+		return port
+	}
+	return "8080"
+}
+
+// localBaseURL returns the address of a server started with ggquick serve
+func localBaseURL() string {
+	return fmt.Sprintf("http://localhost:%s", localPort())
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/client"
+	"github.com/saint0x/ggquick/pkg/hooks"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+// doctorResult is the --json output of ggquick doctor
+type doctorResult struct {
+	Root    string              `json:"root"`
+	Repo    string              `json:"repo,omitempty"`
+	User    string              `json:"user,omitempty"`
+	Hooks   hooks.HookStatus    `json:"hooks"`
+	Server  string              `json:"server"`
+	Running bool                `json:"running"`
+	Reports []client.HookReport `json:"reports,omitempty"`
+	Fixed   bool                `json:"fixed"`
+	// OptOut says why the current branch isn't reported, if it isn't
+	OptOut string `json:"opt_out,omitempty"`
+}
+
+func doctorCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "doctor",
+		Short: "Check the git hooks in this clone and reinstall them when missing",
+		Long: `Check that the ggquick git hooks in this clone are installed and match
+this version, and ask the server whether it has stopped hearing from them.
+The server marks a user's hooks missing when GitHub reports a push of theirs
+that the hooks never sent, as happens after a fresh clone.
+
+With --fix, missing or outdated hooks are reinstalled. Outdated hooks are
+also refreshed by ggquick notify on the next commit.`,
+		Example: `  ggquick doctor
+  ggquick doctor --fix`,
+		Args: cli.NoArgs,
+	}
+	server := cmd.Flags().String("server", serverBaseURL(), "ggquick server URL")
+	fix := cmd.Flags().Bool("fix", false, "reinstall missing or outdated hooks")
+	cmd.Run = func(*cli.Command, []string) error { return handleDoctor(*server, *fix) }
+	return cmd
+}
+
+func handleDoctor(server string, fix bool) error {
+	logger := log.New(false)
+	root := gitOutput("rev-parse", "--show-toplevel")
+	if root == "" {
+		return configError(fmt.Errorf("not in a git repository"))
+	}
+	mgr := hooks.New(logger)
+	status, err := mgr.Status(root)
+	if err != nil {
+		return err
+	}
+	result := doctorResult{Root: root, Repo: originRepo(), User: githubUser(), Hooks: status, Server: server}
+	if branch := gitOutput("rev-parse", "--abbrev-ref", "HEAD"); branch != "" && branch != "HEAD" {
+		result.OptOut = hooks.OptOut(root, branch)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	c := serverClient(server)
+	if err := c.Health(ctx); err == nil {
+		result.Running = true
+		// Without a login the server can't say whose hooks went quiet
+		if result.User != "" {
+			reports, err := c.HookReports(ctx, result.Repo, result.User)
+			if err != nil {
+				logger.Warning("Failed to fetch hook reports: %v", err)
+			}
+			result.Reports = reports
+		}
+	}
+
+	missed := ""
+	for _, report := range result.Reports {
+		if report.Missing {
+			missed = report.MissedCommit
+		}
+	}
+	broken := status.State != hooks.HooksCurrent
+	if broken && fix {
+		if err := mgr.InstallHooks(root); err != nil {
+			return err
+		}
+		result.Fixed = true
+	}
+
+	if jsonOutput {
+		if err := printJSON(result); err != nil {
+			return err
+		}
+	} else {
+		printDoctor(logger, result, missed)
+	}
+	if broken && !fix {
+		return configError(fmt.Errorf("git hooks are %s, run ggquick doctor --fix to reinstall them", status.State))
+	}
+	return nil
+}
+
+// printDoctor reports the hook checks in human-readable form
+func printDoctor(logger *log.Logger, result doctorResult, missed string) {
+	switch result.Hooks.State {
+	case hooks.HooksCurrent:
+		logger.Success("✅ Git hooks installed and current in %s", result.Root)
+	case hooks.HooksMissing:
+		logger.Warning("Git hooks missing in %s: %v", result.Root, result.Hooks.Missing)
+		if !hooks.GlobalInstalled() {
+			logger.Info("ℹ️ ggquick hooks install --global adds them to every new clone")
+		}
+	case hooks.HooksOutdated:
+		logger.Warning("Git hooks outdated in %s: %v", result.Root, result.Hooks.Outdated)
+	}
+	if result.Fixed {
+		logger.Success("✅ Reinstalled git hooks in %s", result.Root)
+	}
+	if result.OptOut != "" {
+		logger.Info("ℹ️ The hooks don't report this branch: %s", result.OptOut)
+	}
+
+	switch {
+	case !result.Running:
+		logger.Warning("Server %s is not reachable, skipping its hook reports", result.Server)
+	case result.User == "":
+		logger.Info("ℹ️ Set GGQUICK_USER or git config github.user to compare with the server's hook reports")
+	case len(result.Reports) == 0:
+		logger.Info("ℹ️ The server has no hook reports from %s yet", result.User)
+	case missed != "" && result.Fixed:
+		logger.Info("ℹ️ The server saw %s pushed without a hook report, the next commit will clear it", shortCommit(missed))
+	case missed != "" && result.Hooks.State == hooks.HooksCurrent:
+		logger.Warning("The server saw %s pushed without a hook report. Another clone may be missing its hooks; run ggquick doctor --fix there.", shortCommit(missed))
+	case missed != "":
+		logger.Warning("The server saw %s pushed without a hook report, matching the hooks missing here", shortCommit(missed))
+	default:
+		logger.Success("✅ The server is receiving hook reports from %s", result.User)
+	}
+}
+
+// shortCommit abbreviates a commit SHA for display
+func shortCommit(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
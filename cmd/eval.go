@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/ai"
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/eval"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+// defaultEvalDir holds the recorded cases
+const defaultEvalDir = "testdata/eval"
+
+func evalCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "eval [dir]",
+		Short: "Check generated descriptions against golden outputs",
+		Long: `Generate a description for each recorded change in a directory and
+compare it to the expected one beside it. A case is <name>.json holding the
+branch, commit message and files of a GitHub compare; its golden is
+<name>.golden.md. Descriptions are compared by word overlap and their
+section headings, so small rewording passes but dropped or reordered
+sections are reported as drift.
+
+The mock provider is used by default, which catches prompt and pipeline
+changes without API calls. Pass --real to run against the configured
+providers when changing models or prompt wording.`,
+		Example: `  ggquick eval
+  ggquick eval testdata/eval --update
+  ggquick eval --real --threshold 0.7`,
+		Args: cli.MaximumNArgs(1),
+	}
+	useReal := cmd.Flags().Bool("real", false, "use the configured AI providers instead of the mock")
+	threshold := cmd.Flags().Float64("threshold", eval.DefaultThreshold, "similarity a description needs to its golden, 0 to 1")
+	update := cmd.Flags().Bool("update", false, "write the generated descriptions as the new goldens")
+	cmd.Run = func(_ *cli.Command, args []string) error {
+		dir := defaultEvalDir
+		if len(args) == 1 {
+			dir = args[0]
+		}
+		if *threshold <= 0 || *threshold > 1 {
+			return configError(fmt.Errorf("threshold must be above 0 and at most 1"))
+		}
+		cases, err := eval.Load(dir)
+		if err != nil {
+			return configError(err)
+		}
+		if len(cases) == 0 {
+			return configError(fmt.Errorf("no cases in %s", dir))
+		}
+
+		logger := log.New(false)
+		gen := ai.New(logger)
+		if *useReal {
+			key := os.Getenv("OPENAI_API_KEY")
+			if err := gen.Initialize(key); err != nil {
+				return configError(err)
+			}
+			providers, err := ai.ProvidersFromEnv(key)
+			if err != nil {
+				return configError(err)
+			}
+			gen.SetProviders(providers)
+		} else {
+			gen.SetProviders([]ai.Provider{ai.MockProvider{}})
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(len(cases))*2*time.Minute)
+		defer cancel()
+		results := eval.Run(ctx, gen, cases, eval.Options{Threshold: *threshold, Update: *update})
+		if jsonOutput {
+			if err := printJSON(results); err != nil {
+				return err
+			}
+		} else {
+			printEvalResults(logger, results)
+		}
+		if eval.Failed(results) {
+			return generationError(fmt.Errorf("description(s) drifted from their goldens, rerun with --update to accept them"))
+		}
+		return nil
+	}
+	return cmd
+}
+
+// printEvalResults reports each case, with a diff for drifted ones
+func printEvalResults(logger *log.Logger, results []eval.Result) {
+	passed := 0
+	for _, r := range results {
+		switch r.Status {
+		case eval.StatusPass:
+			passed++
+			logger.Success("✅ %s (%.2f)", r.Name, r.Similarity)
+		case eval.StatusUpdated:
+			passed++
+			logger.Success("✅ %s golden updated", r.Name)
+		case eval.StatusMissing:
+			logger.Warning("%s has no golden, run with --update to write one", r.Name)
+		case eval.StatusError:
+			logger.Error("❌ %s: %s", r.Name, r.Error)
+		case eval.StatusDrift:
+			reason := fmt.Sprintf("similarity %.2f", r.Similarity)
+			if !r.Headings {
+				reason += ", sections changed"
+			}
+			logger.Error("❌ %s drifted (%s)", r.Name, reason)
+			fmt.Println(r.Diff)
+		}
+	}
+	logger.Info("ℹ️ %d of %d case(s) passed", passed, len(results))
+}
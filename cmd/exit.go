@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/client"
+)
+
+// Exit codes, stable so CI steps can branch on them
+const (
+	exitOK         = 0
+	exitFailure    = 1 // anything not covered below
+	exitUsage      = 2 // unknown command, bad flags or arguments
+	exitConfig     = 3 // missing settings or a request the server rejected
+	exitNetwork    = 4 // server unreachable, timed out or erroring
+	exitGeneration = 5 // a PR generation job failed
+)
+
+// exitError tags an error with the exit code it should produce
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func (e *exitError) Unwrap() error { return e.err }
+
+// configError marks err as a settings problem
+func configError(err error) error {
+	return &exitError{code: exitConfig, err: err}
+}
+
+// networkError marks err as a connectivity problem
+func networkError(err error) error {
+	return &exitError{code: exitNetwork, err: err}
+}
+
+// generationError marks err as a failed PR generation
+func generationError(err error) error {
+	return &exitError{code: exitGeneration, err: err}
+}
+
+// exitCode maps a command error to its exit code
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	var usageErr *cli.UsageError
+	if errors.As(err, &usageErr) {
+		return exitUsage
+	}
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.StatusCode >= 500 {
+			return exitNetwork
+		}
+		return exitConfig
+	}
+	var urlErr *url.Error
+	var netErr net.Error
+	if errors.As(err, &urlErr) || errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return exitNetwork
+	}
+	return exitFailure
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/client"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+// serverClient returns a client for the server that presents the
+// locally provisioned client certificate, which servers requiring mTLS
+// check on every change
+func serverClient(server string) *client.Client {
+	c := client.New(server)
+	certFile, keyFile := clientCertFiles()
+	if certFile == "" {
+		return c
+	}
+	tlsConfig, err := client.TLSConfig(certFile, keyFile, os.Getenv("GGQUICK_SERVER_CA"))
+	if err != nil {
+		log.New(false).Warning("Not presenting client certificate: %v", err)
+		return c
+	}
+	return c.WithTLS(tlsConfig)
+}
+
+// adminClient returns a client for the server carrying the admin token
+func adminClient(server string) *client.Client {
+	return serverClient(server).WithToken(os.Getenv("GGQUICK_ADMIN_TOKEN"))
+}
+
+func exportCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "export",
+		Short: "Export server configuration",
+		Long: `Export all repository and org configuration as JSON. Secrets are
+encrypted with GGQUICK_EXPORT_KEY, or omitted when it is unset. Requires
+GGQUICK_ADMIN_TOKEN.`,
+		Example: `  ggquick export > ggquick-backup.json`,
+		Args:    cli.NoArgs,
+	}
+	server := cmd.Flags().String("server", serverBaseURL(), "ggquick server URL")
+	cmd.Run = func(*cli.Command, []string) error { return handleExport(*server) }
+	return cmd
+}
+
+func importCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "import [FILE]",
+		Short: "Import server configuration",
+		Long: `Import an export into a server, reading stdin when no file is given.
+Requires GGQUICK_ADMIN_TOKEN.`,
+		Example: `  ggquick import ggquick-backup.json --server https://new.example.com`,
+		Args:    cli.MaximumNArgs(1),
+	}
+	server := cmd.Flags().String("server", serverBaseURL(), "ggquick server URL")
+	cmd.Run = func(_ *cli.Command, args []string) error {
+		file := ""
+		if len(args) > 0 {
+			file = args[0]
+		}
+		return handleImport(*server, file)
+	}
+	return cmd
+}
+
+func handleExport(server string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	data, err := adminClient(server).Export(ctx)
+	if err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return fmt.Errorf("failed to format export: %w", err)
+	}
+	out.WriteByte('\n')
+	_, err = out.WriteTo(os.Stdout)
+	return err
+}
+
+func handleImport(server, file string) error {
+	// Read from the named file, or stdin when none is given
+	var in io.Reader = os.Stdin
+	if file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", file, err)
+		}
+		defer f.Close()
+		in = f
+	}
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return fmt.Errorf("failed to read export: %w", err)
+	}
+	if !json.Valid(data) {
+		return configError(fmt.Errorf("export is not valid JSON"))
+	}
+
+	logger := log.New(true)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	logger.Loading("📥 Importing configuration into %s...", server)
+	n, err := adminClient(server).Import(ctx, data)
+	if err != nil {
+		return fmt.Errorf("import failed: %w", err)
+	}
+	if jsonOutput {
+		return printJSON(map[string]int{"imported": n})
+	}
+	logger.Success("✅ Imported %d repository config(s)", n)
+	return nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/hooks"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+// hookOptionFlags adds the flags that configure generated hooks
+func hookOptionFlags(cmd *cli.Command) *hooks.Options {
+	opts := &hooks.Options{}
+	cmd.Flags().StringVar(&opts.ServerURL, "hook-server", "", "server the hooks report to, default GGQUICK_SERVER or the hosted server")
+	cmd.Flags().StringVar(&opts.DisableEnv, "hook-disable-env", "", "variable that skips the hooks when set, default GGQUICK_DISABLED")
+	cmd.Flags().BoolVar(&opts.Blocking, "hook-blocking", false, "make git wait for the report instead of sending it in the background")
+	cmd.Flags().DurationVar(&opts.Timeout, "hook-timeout", 0, "give up on the server after this long, default 10s")
+	cmd.Flags().StringVar(&opts.AuthHeader, "hook-auth-header", "", `header the curl fallback sends, like "X-Ggquick-User-Key: $GGQUICK_USER_KEY"`)
+	return opts
+}
+
+func hooksCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "hooks",
+		Short: "Install or remove the git hooks",
+		Long: `Install or remove the git hooks that report commits to the server, in
+the current repository or, with --global, in git's template directory so
+every repository cloned or initialized afterwards gets them.`,
+	}
+	cmd.AddCommand(hooksInstallCommand(), hooksUninstallCommand())
+	return cmd
+}
+
+func hooksInstallCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "install",
+		Short: "Install the git hooks",
+		Long: `Install the git hooks in the current repository. With --global, write
+them to ~/.ggquick/git-template and point git's init.templateDir at it, so
+new clones get them automatically. Run git init in an existing repository
+to add them there. An init.templateDir already set to another directory is
+left alone.`,
+		Example: `  ggquick hooks install
+  ggquick hooks install --global --hook-timeout 5s`,
+		Args: cli.NoArgs,
+	}
+	global := cmd.Flags().Bool("global", false, "install for every new clone through init.templateDir")
+	opts := hookOptionFlags(cmd)
+	cmd.Run = func(*cli.Command, []string) error {
+		logger := log.New(false)
+		mgr := hooks.New(logger)
+		if *global {
+			dir, err := mgr.InstallGlobal(*opts)
+			if err != nil {
+				return configError(err)
+			}
+			if jsonOutput {
+				return printJSON(map[string]interface{}{"global": true, "template_dir": dir})
+			}
+			logger.Success("✅ Installed git hooks in %s for new clones", dir)
+			logger.Info("ℹ️ Run git init in existing repositories to add them there")
+			return nil
+		}
+
+		root := gitOutput("rev-parse", "--show-toplevel")
+		if root == "" {
+			return configError(fmt.Errorf("not in a git repository, use --global to install for new clones"))
+		}
+		if err := mgr.InstallHooksWith(root, *opts); err != nil {
+			return configError(err)
+		}
+		if jsonOutput {
+			return printJSON(map[string]interface{}{"global": false, "root": root})
+		}
+		logger.Success("✅ Installed git hooks in %s", root)
+		return nil
+	}
+	return cmd
+}
+
+func hooksUninstallCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "uninstall",
+		Short: "Remove the git hooks",
+		Long: `Remove the git hooks from the current repository, restoring any hooks
+they replaced. With --global, unset init.templateDir and remove the
+template; repositories that already copied the hooks keep them.`,
+		Args: cli.NoArgs,
+	}
+	global := cmd.Flags().Bool("global", false, "stop installing the hooks in new clones")
+	cmd.Run = func(*cli.Command, []string) error {
+		logger := log.New(false)
+		mgr := hooks.New(logger)
+		if *global {
+			if err := mgr.UninstallGlobal(); err != nil {
+				return err
+			}
+			if jsonOutput {
+				return printJSON(map[string]interface{}{"global": true})
+			}
+			logger.Success("✅ New clones no longer get ggquick hooks")
+			return nil
+		}
+
+		root := gitOutput("rev-parse", "--show-toplevel")
+		if root == "" {
+			return configError(fmt.Errorf("not in a git repository, use --global to stop installing for new clones"))
+		}
+		if err := mgr.RemoveHooks(root); err != nil {
+			return err
+		}
+		if jsonOutput {
+			return printJSON(map[string]interface{}{"global": false, "root": root})
+		}
+		logger.Success("✅ Removed git hooks from %s", root)
+		return nil
+	}
+	return cmd
+}
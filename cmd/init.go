@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/config"
+	"github.com/saint0x/ggquick/pkg/hooks"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+// envTemplate is the env file written by ggquick init
+const envTemplate = `# ggquick settings, read by every ggquick command.
+# Variables already set in the environment take precedence.
+GITHUB_TOKEN=%s
+OPENAI_API_KEY=%s
+# GGQUICK_SERVER=https://ggquick.fly.dev
+# DEBUG=true
+`
+
+func initCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "init",
+		Short: "Set up ggquick settings and git hooks",
+		Long: `Write the settings file ~/.ggquick/env and, inside a git repository,
+install the hooks that report commits to the server and generate the
+repository's push secret, which signs what they send. An existing settings
+file is kept unless --force is given.`,
+		Args: cli.NoArgs,
+	}
+	githubToken := cmd.Flags().String("github-token", os.Getenv("GITHUB_TOKEN"), "GitHub token to store")
+	openaiKey := cmd.Flags().String("openai-key", os.Getenv("OPENAI_API_KEY"), "OpenAI API key to store")
+	force := cmd.Flags().Bool("force", false, "overwrite an existing settings file")
+	noHooks := cmd.Flags().Bool("no-hooks", false, "don't install git hooks")
+	opts := hookOptionFlags(cmd)
+	cmd.Run = func(*cli.Command, []string) error {
+		return handleInit(*githubToken, *openaiKey, *force, !*noHooks, *opts)
+	}
+	return cmd
+}
+
+func handleInit(githubToken, openaiKey string, force, installHooks bool, opts hooks.Options) error {
+	logger := log.New(true)
+
+	path, err := config.UserEnvFile()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil && !force {
+		logger.Info("ℹ️ Keeping existing settings in %s", path)
+	} else {
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+		}
+		data := fmt.Sprintf(envTemplate, githubToken, openaiKey)
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			return fmt.Errorf("failed to write settings: %w", err)
+		}
+		logger.Success("✅ Wrote settings to %s", path)
+		if githubToken == "" || openaiKey == "" {
+			logger.Warning("Add GITHUB_TOKEN and OPENAI_API_KEY to %s before running ggquick serve", path)
+		}
+	}
+
+	root, err := initHooks(logger, installHooks, opts)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		return printJSON(map[string]string{"settings": path, "hooks": root})
+	}
+	if root != "" {
+		logger.Info("ℹ️ Next: ggquick apply %s", originURL())
+	}
+	return nil
+}
+
+// initHooks installs the git hooks in the current repository with opts,
+// returning its root or "" when skipped
+func initHooks(logger *log.Logger, install bool, opts hooks.Options) (string, error) {
+	if !install {
+		return "", nil
+	}
+	root := gitOutput("rev-parse", "--show-toplevel")
+	if root == "" {
+		logger.Info("ℹ️ Not in a git repository, skipping hooks")
+		return "", nil
+	}
+	mgr := hooks.New(logger)
+	if err := mgr.ValidateGitRepo(root); err != nil {
+		return "", err
+	}
+	if err := mgr.InstallHooksWith(root, opts); err != nil {
+		return "", err
+	}
+	logger.Success("✅ Installed git hooks in %s", root)
+
+	if repo := originRepo(); repo != "" {
+		_, created, err := config.EnsurePushSecret(repo)
+		if err != nil {
+			return "", err
+		}
+		if created {
+			logger.Success("🔑 Generated a push secret for %s, ggquick apply registers it", repo)
+		}
+	}
+	return root, nil
+}
+
+// originURL returns the GitHub URL of the origin remote, or a placeholder
+func originURL() string {
+	if repo := originRepo(); repo != "" {
+		return "https://github.com/" + repo
+	}
+	return "REPO_URL"
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/config"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+func loginCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "login",
+		Short: "Register your GitHub token so PRs are opened as you",
+		Long: `Register a GitHub token with the server. Repositories with attribution
+on_behalf enabled then open the PRs for your pushes with that token. The
+returned key is saved as GGQUICK_USER_KEY in ~/.ggquick/env and sent with
+every push.`,
+		Args: cli.NoArgs,
+	}
+	server := cmd.Flags().String("server", serverBaseURL(), "ggquick server URL")
+	token := cmd.Flags().String("token", os.Getenv("GITHUB_TOKEN"), "GitHub token to register")
+	cmd.Run = func(*cli.Command, []string) error { return handleLogin(*server, *token) }
+	return cmd
+}
+
+func handleLogin(server, token string) error {
+	if token == "" {
+		return configError(fmt.Errorf("no GitHub token, set GITHUB_TOKEN or pass --token"))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	user, err := serverClient(server).RegisterUser(ctx, token)
+	if err != nil {
+		return fmt.Errorf("failed to register token: %w", err)
+	}
+	path, err := config.SetUserEnv("GGQUICK_USER_KEY", user.Key)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		return printJSON(map[string]string{"login": user.Login, "settings": path})
+	}
+	log.New(false).Success("✅ Logged in as @%s, key saved to %s", user.Login, path)
+	return nil
+}
+
+func logoutCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "logout",
+		Short: "Remove your registered GitHub token from the server",
+		Args:  cli.NoArgs,
+	}
+	server := cmd.Flags().String("server", serverBaseURL(), "ggquick server URL")
+	cmd.Run = func(*cli.Command, []string) error { return handleLogout(*server) }
+	return cmd
+}
+
+func handleLogout(server string) error {
+	key := os.Getenv("GGQUICK_USER_KEY")
+	if key == "" {
+		return configError(fmt.Errorf("not logged in"))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	user, err := serverClient(server).WithUserKey(key).RemoveUser(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to remove token: %w", err)
+	}
+	if _, err := config.SetUserEnv("GGQUICK_USER_KEY", ""); err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		return printJSON(user)
+	}
+	log.New(false).Success("✅ Logged out @%s", user.Login)
+	return nil
+}
@@ -3,51 +3,75 @@ package main
 import (
 	"fmt"
 	"os"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/config"
 )
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Usage:")
-		fmt.Println("  ggquick start              - Start the local ggquick server")
-		fmt.Println("  ggquick apply [repo-url]   - Apply ggquick to a repository")
-		fmt.Println("  ggquick check              - Check if ggquick server is running")
-		fmt.Println("  ggquick stop               - Stop the local ggquick server")
-		os.Exit(1)
+	// Env files are loaded first so flag defaults see their values
+	if _, err := config.LoadEnvFiles(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitConfig)
 	}
 
-	var err error
-	switch os.Args[1] {
-	case "start":
-		// Start local server
-		if err := handleServe(); err != nil {
-			fmt.Printf("Server error: %v\n", err)
-			os.Exit(1)
-		}
-
-	case "apply":
-		if len(os.Args) != 3 {
-			fmt.Println("Usage: ggquick apply [repository-url]")
-			os.Exit(1)
-		}
-		// Only send configuration to server
-		if err := handleStart(os.Args[2]); err != nil {
-			fmt.Printf("Error applying config: %v\n", err)
-			os.Exit(1)
-		}
-
-	case "check":
-		err = handleCheck()
-
-	case "stop":
-		err = handleStop()
-
-	default:
-		fmt.Printf("Unknown command: %s\n", os.Args[1])
-		os.Exit(1)
+	if err := rootCommand().Execute(os.Args[1:]); err != nil {
+		printError(err)
+		os.Exit(exitCode(err))
 	}
+}
 
-	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+// rootCommand builds the ggquick command tree
+func rootCommand() *cli.Command {
+	root := &cli.Command{
+		Use:              "ggquick",
+		Short:            "AI-generated pull requests for every pushed branch",
+		PersistentPreRun: setupOutput,
 	}
+	root.PersistentFlags().BoolVar(&jsonOutput, "json", false, "print machine-readable JSON instead of logs")
+	root.PersistentFlags().BoolVar(&quietOutput, "quiet", false, "only print warnings, errors and results")
+	root.AddCommand(
+		initCommand(),
+		serveCommand(),
+		applyCommand(),
+		checkCommand(),
+		statusCommand(),
+		stopCommand(),
+		cancelCommand(),
+		approveCommand(),
+		&cli.Command{
+			Use:   "watch [SERVER_URL]",
+			Short: "Stream live server events",
+			Args:  cli.MaximumNArgs(1),
+			Run: func(_ *cli.Command, args []string) error {
+				baseURL := serverBaseURL()
+				if len(args) > 0 {
+					baseURL = args[0]
+				}
+				return handleWatch(baseURL)
+			},
+		},
+		prsCommand(),
+		usageCommand(),
+		notifyCommand(),
+		doctorCommand(),
+		hooksCommand(),
+		loginCommand(),
+		logoutCommand(),
+		backfillCommand(),
+		revertCommand(),
+		backportCommand(),
+		translateCommand(),
+		actionItemsCommand(),
+		releaseCommand(),
+		promptCommand(),
+		evalCommand(),
+		replayCommand(),
+		rulesCommand(),
+		exportCommand(),
+		importCommand(),
+		completionCommand(),
+		manCommand(),
+	)
+	return root
 }
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/client"
+	"github.com/saint0x/ggquick/pkg/config"
+	"github.com/saint0x/ggquick/pkg/hooks"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+// originPattern extracts owner/name from an HTTPS or SSH GitHub remote
+var originPattern = regexp.MustCompile(`github\.com[:/]([^/]+/[^/]+?)(?:\.git)?/?$`)
+
+func notifyCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "notify",
+		Short: "Report the current commit (used by git hooks)",
+		Long: `Report the current commit to the server. The client certificate from
+GGQUICK_CLIENT_CERT/GGQUICK_CLIENT_KEY or ~/.ggquick/client.{crt,key} is
+presented when present, and the body is signed with the repository's push
+secret from GGQUICK_PUSH_SECRET or ~/.ggquick/push-secrets/OWNER/NAME. Git
+hooks call this after commits and pushes.
+
+With --async the commit is resolved, then reported by a detached process
+so the caller returns at once. The report still gives up after --timeout.
+
+Nothing is reported when git config ggquick.disable is true or the branch
+matches a pattern in .ggquick-ignore at the repository root.`,
+		Args: cli.NoArgs,
+	}
+	server := cmd.Flags().String("server", serverBaseURL(), "ggquick server URL")
+	ref := cmd.Flags().String("ref", "", "branch to report, default the current branch")
+	sha := cmd.Flags().String("sha", "", "commit to report, default HEAD")
+	timeout := cmd.Flags().Duration("timeout", 15*time.Second, "give up on the server after this long")
+	async := cmd.Flags().Bool("async", false, "report from a background process and return immediately")
+	cmd.Run = func(*cli.Command, []string) error { return handleNotify(*server, *ref, *sha, *timeout, *async) }
+	return cmd
+}
+
+// handleNotify reports the current commit to the server, presenting the
+// locally provisioned client certificate when the server requires mTLS
+func handleNotify(server, ref, sha string, timeout time.Duration, async bool) error {
+	if ref == "" {
+		ref = gitOutput("rev-parse", "--abbrev-ref", "HEAD")
+	}
+	if sha == "" {
+		sha = gitOutput("rev-parse", "HEAD")
+	}
+	if ref == "" || ref == "HEAD" {
+		return configError(fmt.Errorf("not on a branch"))
+	}
+	if root := gitOutput("rev-parse", "--show-toplevel"); root != "" {
+		if reason := hooks.OptOut(root, ref); reason != "" {
+			if jsonOutput {
+				return printJSON(map[string]interface{}{"ref": ref, "skipped": reason})
+			}
+			log.New(false).Info("ℹ️ Not reporting %s: %s", ref, reason)
+			return nil
+		}
+	}
+	if async {
+		return notifyInBackground(server, ref, sha, timeout)
+	}
+
+	repo := originRepo()
+	c := serverClient(server).WithUserKey(os.Getenv("GGQUICK_USER_KEY"))
+	if repo != "" {
+		c.WithPushSecret(config.PushSecret(repo))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	files, commits := localChanges(sha)
+	job, err := c.Push(ctx, client.PushRequest{
+		Ref:     ref,
+		SHA:     sha,
+		Repo:    repo,
+		Author:  githubUser(),
+		Hooks:   refreshHooks(),
+		Files:   files,
+		Commits: commits,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to notify server: %w", err)
+	}
+
+	if jsonOutput {
+		return printJSON(job)
+	}
+	logger := log.New(false)
+	if job.ID == "" {
+		logger.Info("ℹ️ Branch %s skipped by server rules", ref)
+		return nil
+	}
+	logger.Success("✅ Queued job %s for %s", job.ID, ref)
+	return nil
+}
+
+// notifyInBackground starts a detached ggquick notify for the resolved
+// commit, so a later checkout can't change what gets reported
+func notifyInBackground(server, ref, sha string, timeout time.Duration) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find ggquick executable: %w", err)
+	}
+	// Stdio is left unset so the child never holds the caller's pipes open
+	cmd := exec.Command(exe, "notify", "--server", server, "--ref", ref, "--sha", sha, "--timeout", timeout.String())
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start background notify: %w", err)
+	}
+	pid := cmd.Process.Pid
+	if err := cmd.Process.Release(); err != nil {
+		return fmt.Errorf("failed to detach background notify: %w", err)
+	}
+	if jsonOutput {
+		return printJSON(map[string]interface{}{"ref": ref, "sha": sha, "pid": pid, "async": true})
+	}
+	return nil
+}
+
+// refreshHooks reinstalls ggquick hooks written by an older version and
+// returns the fingerprint of the installed set, so the server can tell when
+// a clone stops reporting. Hooks the user replaced are left alone.
+func refreshHooks() string {
+	root := gitOutput("rev-parse", "--show-toplevel")
+	if root == "" {
+		return ""
+	}
+	mgr := hooks.New(log.New(false))
+	status, err := mgr.Status(root)
+	if err != nil {
+		return ""
+	}
+	if status.State != hooks.HooksOutdated {
+		return status.Fingerprint
+	}
+	if err := mgr.InstallHooks(root); err != nil {
+		return status.Fingerprint
+	}
+	fingerprint, _ := hooks.Fingerprint(root)
+	return fingerprint
+}
+
+// localChanges returns the files a branch changes and its commit
+// subjects, newest first, counted from its upstream or, when it isn't
+// ahead of one, from origin's default branch
+func localChanges(sha string) ([]string, []string) {
+	if sha == "" {
+		sha = "HEAD"
+	}
+	for _, base := range []string{"@{upstream}", "refs/remotes/origin/HEAD"} {
+		mergeBase := gitOutput("merge-base", base, sha)
+		if mergeBase == "" {
+			continue
+		}
+		commits := gitLines("log", "--format=%s", "--max-count="+strconv.Itoa(client.MaxPushCommits), mergeBase+".."+sha)
+		if len(commits) == 0 {
+			continue
+		}
+		files := gitLines("-c", "core.quotePath=false", "diff", "--name-only", mergeBase, sha)
+		if len(files) > client.MaxPushFiles {
+			files = files[:client.MaxPushFiles]
+		}
+		return files, commits
+	}
+	return nil, nil
+}
+
+// clientCertFiles returns the client certificate and key to present,
+// from GGQUICK_CLIENT_CERT/GGQUICK_CLIENT_KEY or ~/.ggquick/client.{crt,key}
+func clientCertFiles() (string, string) {
+	if cert := os.Getenv("GGQUICK_CLIENT_CERT"); cert != "" {
+		return cert, os.Getenv("GGQUICK_CLIENT_KEY")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", ""
+	}
+	cert := filepath.Join(home, ".ggquick", "client.crt")
+	key := filepath.Join(home, ".ggquick", "client.key")
+	if _, err := os.Stat(cert); err != nil {
+		return "", ""
+	}
+	return cert, key
+}
+
+// originRepo returns owner/name of the origin remote, or "" if unknown
+func originRepo() string {
+	m := originPattern.FindStringSubmatch(gitOutput("remote", "get-url", "origin"))
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// gitOutput runs git in the current directory and returns trimmed stdout
+func gitOutput(args ...string) string {
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// gitLines runs git in the current directory and returns the non-empty
+// lines of its output
+func gitLines(args ...string) []string {
+	var lines []string
+	for _, line := range strings.Split(gitOutput(args...), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// githubUser returns the pusher's GitHub login from GGQUICK_USER or the
+// github.user git setting
+func githubUser() string {
+	if user := os.Getenv("GGQUICK_USER"); user != "" {
+		return user
+	}
+	return gitOutput("config", "github.user")
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+var (
+	// jsonOutput is set by the global --json flag
+	jsonOutput bool
+	// quietOutput is set by the global --quiet flag
+	quietOutput bool
+)
+
+// setupOutput silences decorative logging for --json and --quiet
+func setupOutput(*cli.Command, []string) error {
+	if jsonOutput {
+		log.SetOutput(io.Discard)
+	}
+	log.SetQuiet(quietOutput)
+	return nil
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printError reports a failed command in the selected output format
+func printError(err error) {
+	if jsonOutput {
+		printJSON(map[string]string{"error": err.Error()})
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/client"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+func promptCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "prompt",
+		Short: "Print the prompt a push would send, with its token count and cost",
+		Long: `Build the exact prompt the server would send to the model for a pushed
+branch: templated instructions, the diff fitted to the budget, analysis
+notes and pipeline stages. It is printed with an estimated token count and
+cost for the first routed model. The model is not called, and
+pre-generation hooks and per-file summaries are skipped.`,
+		Example: `  ggquick prompt
+  ggquick prompt --branch feature/login --repo my-org/api
+  ggquick prompt --json | jq .prompt_tokens`,
+		Args: cli.NoArgs,
+	}
+	branch := cmd.Flags().String("branch", "", "branch to build the prompt for, default the current branch")
+	message := cmd.Flags().String("message", "", "commit message, default the branch's head commit")
+	repo := cmd.Flags().String("repo", originRepo(), "repository as owner/name")
+	server := cmd.Flags().String("server", serverBaseURL(), "ggquick server URL")
+	cmd.Run = func(_ *cli.Command, _ []string) error {
+		if strings.Count(*repo, "/") != 1 {
+			return configError(fmt.Errorf("repository must be owner/repo, got %q", *repo))
+		}
+		if *branch == "" {
+			*branch = gitOutput("rev-parse", "--abbrev-ref", "HEAD")
+		}
+		if *branch == "" || *branch == "HEAD" {
+			return configError(fmt.Errorf("not on a branch, pass --branch"))
+		}
+		if *message == "" {
+			*message = gitOutput("log", "-1", "--format=%B", *branch)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+		result, err := serverClient(*server).Prompt(ctx, client.PromptRequest{
+			Repo:    *repo,
+			Branch:  *branch,
+			Message: *message,
+			Author:  githubUser(),
+		})
+		if err != nil {
+			return fmt.Errorf("prompt dry run failed: %w", err)
+		}
+		if jsonOutput {
+			return printJSON(result)
+		}
+
+		for _, m := range result.Messages {
+			fmt.Printf("━━━ %s ━━━\n%s\n\n", m.Role, m.Content)
+		}
+		logger := log.New(false)
+		model := "the default model"
+		if len(result.Models) > 0 {
+			model = result.Models[0]
+		}
+		if result.Cost > 0 {
+			logger.Info("ℹ️ ~%d prompt tokens + ~%d completion tokens on %s, about $%.4f", result.PromptTokens, result.CompletionTokens, model, result.Cost)
+		} else {
+			logger.Info("ℹ️ ~%d prompt tokens + ~%d completion tokens on %s, no price known", result.PromptTokens, result.CompletionTokens, model)
+		}
+		if len(result.Omitted) > 0 {
+			logger.Info("ℹ️ %d file(s) left out of the diff for length", len(result.Omitted))
+		}
+		for _, note := range result.Notes {
+			logger.Warning("%s", note)
+		}
+		return nil
+	}
+	return cmd
+}
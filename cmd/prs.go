@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/client"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+func prsCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "prs",
+		Short: "List PRs opened by ggquick",
+		Args:  cli.NoArgs,
+	}
+	repo := cmd.Flags().String("repo", "", "only PRs for this repository (owner/name)")
+	limit := cmd.Flags().Int("limit", 20, "maximum number of PRs to list")
+	server := cmd.Flags().String("server", serverBaseURL(), "ggquick server URL")
+	cmd.Run = func(*cli.Command, []string) error { return handlePRs(*server, *repo, *limit) }
+	return cmd
+}
+
+func handlePRs(server, repo string, limit int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	jobs, err := serverClient(server).Jobs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	prs := []client.Job{}
+	for _, j := range jobs {
+		if j.PRURL == "" || (repo != "" && !strings.EqualFold(j.Owner+"/"+j.Repo, repo)) {
+			continue
+		}
+		prs = append(prs, j)
+	}
+	sort.Slice(prs, func(i, j int) bool { return prs[i].UpdatedAt.After(prs[j].UpdatedAt) })
+	if limit > 0 && len(prs) > limit {
+		prs = prs[:limit]
+	}
+
+	if jsonOutput {
+		return printJSON(prs)
+	}
+	if len(prs) == 0 {
+		log.New(true).Info("ℹ️ No PRs yet")
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "OPENED\tREPO\tBRANCH\tPR")
+	for _, j := range prs {
+		fmt.Fprintf(tw, "%s\t%s/%s\t%s\t%s\n",
+			j.UpdatedAt.Local().Format("2006-01-02 15:04"), j.Owner, j.Repo, j.Branch, j.PRURL)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/client"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+func releaseCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "release",
+		Short: "Open a release PR with generated release notes",
+		Long: `Open a "release vX.Y.Z" PR from the release config's from branch
+(develop by default) to its to branch (the default branch by default). The
+version is the latest semver tag bumped by --bump, by the included PRs'
+labels, or set with --version. The PR has release notes generated from
+the merged PRs it includes, links to each of them, and bumps the
+configured version files. Requires GGQUICK_ADMIN_TOKEN when the server
+sets one.`,
+		Example: `  ggquick release
+  ggquick release --bump major
+  ggquick release --version v2.0.0 --repo my-org/api`,
+		Args: cli.NoArgs,
+	}
+	version := cmd.Flags().String("version", "", "version to release, instead of bumping the latest tag")
+	bump := cmd.Flags().String("bump", "", "major, minor or patch (default from PR labels, then config)")
+	repo := cmd.Flags().String("repo", originRepo(), "repository as owner/name")
+	server := cmd.Flags().String("server", serverBaseURL(), "ggquick server URL")
+	cmd.Run = func(_ *cli.Command, _ []string) error {
+		if strings.Count(*repo, "/") != 1 {
+			return configError(fmt.Errorf("repository must be owner/repo, got %q", *repo))
+		}
+
+		logger := log.New(true)
+		logger.Loading("📦 Opening release PR in %s...", *repo)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		result, err := adminClient(*server).Release(ctx, client.ReleaseRequest{Repo: *repo, Version: *version, Bump: *bump})
+		if err != nil {
+			return fmt.Errorf("release failed: %w", err)
+		}
+		if jsonOutput {
+			return printJSON(result)
+		}
+		logger.Success("✅ Opened release PR #%d for %s with %d PR(s): %s", result.Number, result.Version, result.PRs, result.URL)
+		return nil
+	}
+	return cmd
+}
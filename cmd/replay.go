@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/client"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+// replayResult is the server's answer to one replayed request
+type replayResult struct {
+	Entry  int    `json:"entry"`
+	Event  string `json:"event"`
+	Status int    `json:"status"`
+	Body   string `json:"body,omitempty"`
+}
+
+func replayCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "replay FILE",
+		Short: "Send recorded /push and webhook requests to a server again",
+		Long: `Send the requests a server recorded with "ggquick serve --record" to a
+server again, in order, to reproduce a production issue locally. Point
+--server at a local server, ideally one running against test credentials;
+replaying against production opens real pull requests.
+
+Recordings leave out credentials, so replayed pushes are anonymous.`,
+		Example: `  ggquick replay pushes.jsonl --server http://localhost:8080
+  ggquick replay pushes.jsonl --entry 3
+  ggquick replay pushes.jsonl --event pull_request --delay 2s`,
+		Args: cli.ExactArgs(1),
+	}
+	server := cmd.Flags().String("server", serverBaseURL(), "ggquick server URL")
+	entry := cmd.Flags().Int("entry", 0, "replay only this request, counting from 1")
+	event := cmd.Flags().String("event", "", "replay only this event, like push or pull_request")
+	delay := cmd.Flags().Duration("delay", 0, "wait between requests")
+	cmd.Run = func(_ *cli.Command, args []string) error {
+		recordings, err := client.ReadRecordings(args[0])
+		if err != nil {
+			return configError(err)
+		}
+		if *entry < 0 || *entry > len(recordings) {
+			return configError(fmt.Errorf("entry %d out of range, the file has %d request(s)", *entry, len(recordings)))
+		}
+
+		logger := log.New(false)
+		c := adminClient(*server)
+		var results []replayResult
+		failed := 0
+		for i, rec := range recordings {
+			if (*entry != 0 && i+1 != *entry) || (*event != "" && rec.Event() != *event) {
+				continue
+			}
+			if len(results) > 0 && *delay > 0 {
+				time.Sleep(*delay)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+			status, body, err := c.Replay(ctx, rec)
+			cancel()
+			if err != nil {
+				return networkError(fmt.Errorf("failed to replay request %d: %w", i+1, err))
+			}
+			results = append(results, replayResult{Entry: i + 1, Event: rec.Event(), Status: status, Body: body})
+			if status >= 300 {
+				failed++
+				if !jsonOutput {
+					logger.Error("❌ %d %s from %s: %d %s", i+1, rec.Event(), rec.Time.Format(time.RFC3339), status, body)
+				}
+			} else if !jsonOutput {
+				logger.Success("✅ %d %s from %s: %d", i+1, rec.Event(), rec.Time.Format(time.RFC3339), status)
+			}
+		}
+
+		if jsonOutput {
+			if err := printJSON(results); err != nil {
+				return err
+			}
+		} else {
+			logger.Info("ℹ️ Replayed %d request(s), %d rejected", len(results), failed)
+		}
+		if len(results) == 0 {
+			return configError(fmt.Errorf("no recorded request matched"))
+		}
+		return nil
+	}
+	return cmd
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/client"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+func revertCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "revert PR_NUMBER|SHA",
+		Short: "Open a PR reverting a merged PR or commit",
+		Long: `Create a revert branch on GitHub and open a PR explaining what is
+reverted and why, using the original PR for context. The original PR gets
+a comment linking to the revert. Requires GGQUICK_ADMIN_TOKEN when the
+server sets one.`,
+		Example: `  ggquick revert 142 --reason "breaks login on Safari"
+  ggquick revert 9fceb02 --repo my-org/api`,
+		Args: cli.ExactArgs(1),
+	}
+	repo := cmd.Flags().String("repo", originRepo(), "repository as owner/name")
+	reason := cmd.Flags().String("reason", "", "why the change is reverted")
+	server := cmd.Flags().String("server", serverBaseURL(), "ggquick server URL")
+	cmd.Run = func(_ *cli.Command, args []string) error {
+		req := client.RevertRequest{Repo: *repo, Reason: *reason}
+		target := strings.TrimPrefix(args[0], "#")
+		// Commit SHAs are at least 7 characters, PR numbers shorter
+		if n, err := strconv.Atoi(target); err == nil && len(target) < 7 {
+			req.PR = n
+		} else {
+			req.SHA = target
+		}
+		if strings.Count(req.Repo, "/") != 1 {
+			return configError(fmt.Errorf("repository must be owner/repo, got %q", req.Repo))
+		}
+
+		logger := log.New(true)
+		logger.Loading("⏪ Reverting %s in %s...", args[0], req.Repo)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		result, err := adminClient(*server).Revert(ctx, req)
+		if err != nil {
+			return fmt.Errorf("revert failed: %w", err)
+		}
+		if jsonOutput {
+			return printJSON(result)
+		}
+		logger.Success("✅ Opened revert PR #%d: %s", result.Number, result.URL)
+		return nil
+	}
+	return cmd
+}
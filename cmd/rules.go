@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/client"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+const ruleKinds = `Kinds:
+  branch    only matching branches get PRs
+  ignore    matching branches never get PRs
+  base      PRs from matching branches target VALUE
+  label     PRs from matching branches get label VALUE
+  reviewer  VALUE (login or org/team) is asked to review matching PRs`
+
+func rulesCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "rules",
+		Short: "Manage branch, label and reviewer rules",
+		Long:  "Manage per-repository branch, label and reviewer rules.\n\n" + ruleKinds,
+	}
+	cmd.AddCommand(rulesListCommand(), rulesAddCommand(), rulesRemoveCommand())
+	return cmd
+}
+
+// ruleFlags adds the flags shared by the rules subcommands
+func ruleFlags(cmd *cli.Command) (repo, server *string) {
+	repo = cmd.Flags().String("repo", "", "repository as owner/name, optional with one configured repo")
+	server = cmd.Flags().String("server", serverBaseURL(), "ggquick server URL")
+	return repo, server
+}
+
+func rulesListCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "list",
+		Short: "List a repository's rules",
+		Args:  cli.NoArgs,
+	}
+	repo, server := ruleFlags(cmd)
+	cmd.Run = func(*cli.Command, []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		rules, err := serverClient(*server).Rules(ctx, *repo)
+		if err != nil {
+			return fmt.Errorf("failed to list rules: %w", err)
+		}
+		if jsonOutput {
+			return printJSON(rules)
+		}
+		if len(rules) == 0 {
+			log.New(true).Info("ℹ️ No rules configured")
+			return nil
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tKIND\tPATTERN\tVALUE")
+		for _, r := range rules {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.ID, r.Kind, r.Pattern, r.Value)
+		}
+		return tw.Flush()
+	}
+	return cmd
+}
+
+func rulesAddCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "add",
+		Short: "Add a rule",
+		Long:  "Add a rule to a repository.\n\n" + ruleKinds,
+		Example: `  ggquick rules add --repo owner/repo --kind ignore --pattern 'dependabot/*'
+  ggquick rules add --repo owner/repo --kind base --pattern 'hotfix/*' --value release`,
+		Args: cli.NoArgs,
+	}
+	repo, server := ruleFlags(cmd)
+	kind := cmd.Flags().String("kind", "", "rule kind")
+	pattern := cmd.Flags().String("pattern", "", "branch glob, e.g. feature/*")
+	value := cmd.Flags().String("value", "", "base branch, label or reviewer")
+	cmd.Run = func(*cli.Command, []string) error {
+		if *kind == "" || *pattern == "" {
+			return configError(fmt.Errorf("--kind and --pattern are required"))
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		rule, err := adminClient(*server).AddRule(ctx, *repo, client.Rule{Kind: *kind, Pattern: *pattern, Value: *value})
+		if err != nil {
+			return fmt.Errorf("failed to add rule: %w", err)
+		}
+		if jsonOutput {
+			return printJSON(rule)
+		}
+		log.New(true).Success("✅ Added %s rule %s", rule.Kind, rule.ID)
+		return nil
+	}
+	return cmd
+}
+
+func rulesRemoveCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "remove ID",
+		Short: "Remove a rule",
+		Args:  cli.ExactArgs(1),
+	}
+	repo, server := ruleFlags(cmd)
+	cmd.Run = func(_ *cli.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		id := args[0]
+		if err := adminClient(*server).RemoveRule(ctx, *repo, id); err != nil {
+			if client.IsNotFound(err) {
+				return fmt.Errorf("no rule with ID %s", id)
+			}
+			return fmt.Errorf("failed to remove rule: %w", err)
+		}
+		if jsonOutput {
+			return printJSON(map[string]string{"removed": id})
+		}
+		log.New(true).Success("✅ Removed rule %s", id)
+		return nil
+	}
+	return cmd
+}
@@ -2,22 +2,51 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 
-	"github.com/saint0x/ggquick/pkg/ai"
-	"github.com/saint0x/ggquick/pkg/github"
-	"github.com/saint0x/ggquick/pkg/hooks"
+	"github.com/saint0x/ggquick/pkg/app"
+	"github.com/saint0x/ggquick/pkg/cli"
 	"github.com/saint0x/ggquick/pkg/log"
-	"github.com/saint0x/ggquick/pkg/server"
 )
 
+func serveCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:     "serve",
+		Aliases: []string{"start"},
+		Short:   "Run the ggquick server",
+		Long: `Run the ggquick server in the foreground. GITHUB_TOKEN and
+OPENAI_API_KEY are required and are read from the environment or the
+env files (see "ggquick init").
+
+With --record every /push and /webhook request is appended to a file,
+to send again with "ggquick replay". With --sandbox GitHub and the AI
+provider are replaced by deterministic fakes and no credentials are
+needed; pull requests are listed at /sandbox.`,
+		Args: cli.NoArgs,
+	}
+	record := cmd.Flags().String("record", os.Getenv("GGQUICK_RECORD_FILE"), "append /push and /webhook requests to this file")
+	sandbox := cmd.Flags().Bool("sandbox", os.Getenv("GGQUICK_SANDBOX") == "true", "use fake GitHub and AI providers, for demos and tests")
+	cmd.Run = func(*cli.Command, []string) error {
+		if *sandbox {
+			os.Setenv("GGQUICK_SANDBOX", "true")
+		}
+		if *record != "" {
+			os.Setenv("GGQUICK_RECORD_FILE", *record)
+		}
+		return handleServe()
+	}
+	return cmd
+}
+
 func handleServe() error {
-	// Initialize logger
-	debug := os.Getenv("DEBUG") == "true"
-	logger := log.New(debug)
+	if jsonOutput {
+		return fmt.Errorf("--json is not supported by serve")
+	}
+	logger := log.New(os.Getenv("DEBUG") == "true")
 
 	// Create context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
@@ -47,30 +76,9 @@ func handleServe() error {
 		}
 	}()
 
-	// Initialize server components
-	aiGen := ai.New(logger)
-	ghClient := github.New(logger)
-	if ghClient == nil {
-		return fmt.Errorf("failed to initialize GitHub client")
+	err := app.Serve(ctx, logger)
+	if errors.Is(err, app.ErrEnvironment) {
+		return configError(err)
 	}
-
-	hooksMgr := hooks.New(logger)
-	if err := hooksMgr.InitGitHub(os.Getenv("GITHUB_TOKEN")); err != nil {
-		return fmt.Errorf("failed to initialize hooks manager: %w", err)
-	}
-
-	// Create and start server
-	srv, err := server.New(logger, aiGen, ghClient, hooksMgr)
-	if err != nil {
-		return fmt.Errorf("failed to create server: %w", err)
-	}
-
-	// Start server
-	if err := srv.Start(ctx); err != nil {
-		return fmt.Errorf("server error: %w", err)
-	}
-
-	// Wait for shutdown
-	<-ctx.Done()
-	return nil
+	return err
 }
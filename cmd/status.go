@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/client"
+	"github.com/saint0x/ggquick/pkg/config"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+// statusJobs is how many recent jobs ggquick status lists
+const statusJobs = 10
+
+// statusResult is the --json output of ggquick status
+type statusResult struct {
+	Server     string         `json:"server"`
+	Running    bool           `json:"running"`
+	EnvFiles   []string       `json:"env_files"`
+	MissingEnv []string       `json:"missing_env,omitempty"`
+	JobCounts  map[string]int `json:"job_counts"`
+	RecentJobs []client.Job   `json:"recent_jobs"`
+}
+
+func statusCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "status",
+		Short: "Show server health, settings and recent jobs",
+		Args:  cli.NoArgs,
+	}
+	server := cmd.Flags().String("server", serverBaseURL(), "ggquick server URL")
+	cmd.Run = func(*cli.Command, []string) error { return handleStatus(*server) }
+	return cmd
+}
+
+func handleStatus(server string) error {
+	logger := log.New(true)
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	result := statusResult{Server: server, EnvFiles: []string{}, JobCounts: map[string]int{}}
+	logger.Info("🌐 Server: %s", server)
+	for _, path := range config.EnvFiles() {
+		if _, err := os.Stat(path); err == nil {
+			result.EnvFiles = append(result.EnvFiles, path)
+			logger.Info("📄 Env file: %s", path)
+		}
+	}
+	for _, key := range []string{"GITHUB_TOKEN", "OPENAI_API_KEY"} {
+		if os.Getenv(key) == "" {
+			result.MissingEnv = append(result.MissingEnv, key)
+			logger.Warning("%s is not set, ggquick serve will not start", key)
+		}
+	}
+
+	c := serverClient(server)
+	if err := c.Health(ctx); err != nil {
+		return fmt.Errorf("server is not running: %w", err)
+	}
+	result.Running = true
+	logger.Success("✅ Server is running")
+
+	jobs, err := c.Jobs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list jobs: %w", err)
+	}
+	for _, j := range jobs {
+		result.JobCounts[j.Status]++
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].UpdatedAt.After(jobs[j].UpdatedAt) })
+	if len(jobs) > statusJobs {
+		jobs = jobs[:statusJobs]
+	}
+	result.RecentJobs = jobs
+
+	if jsonOutput {
+		return printJSON(result)
+	}
+	if len(jobs) == 0 {
+		logger.Info("ℹ️ No jobs yet")
+		return nil
+	}
+
+	statuses := make([]string, 0, len(result.JobCounts))
+	for s := range result.JobCounts {
+		statuses = append(statuses, s)
+	}
+	sort.Strings(statuses)
+	for _, s := range statuses {
+		logger.Info("📊 %s: %d", s, result.JobCounts[s])
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\nUPDATED\tREPO\tBRANCH\tSTATUS\tPR")
+	for _, j := range jobs {
+		fmt.Fprintf(tw, "%s\t%s/%s\t%s\t%s\t%s\n",
+			j.UpdatedAt.Local().Format("01-02 15:04"), j.Owner, j.Repo, j.Branch, j.Status, j.PRURL)
+	}
+	return tw.Flush()
+}
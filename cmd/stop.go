@@ -4,34 +4,45 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"os/exec"
 	"runtime"
 	"strings"
 	"time"
 
+	"github.com/saint0x/ggquick/pkg/cli"
 	"github.com/saint0x/ggquick/pkg/log"
 )
 
-func handleStop() error {
+func stopCommand() *cli.Command {
+	return &cli.Command{
+		Use:   "stop",
+		Short: "Stop the local ggquick server",
+		Args:  cli.NoArgs,
+		Run: func(*cli.Command, []string) error {
+			stopped, err := handleStop()
+			if err != nil || !jsonOutput {
+				return err
+			}
+			return printJSON(map[string]interface{}{"port": localPort(), "stopped": stopped})
+		},
+	}
+}
+
+// handleStop stops a server listening on the local port, reporting
+// whether one was running
+func handleStop() (bool, error) {
 	logger := log.New(true)
 	logger.Loading("🛑 Stopping ggquick server...")
 
-	// Get port from environment or use default
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
-
-	// Try to gracefully stop by sending a request
-	localBase := fmt.Sprintf("http://localhost:%s", port)
+	port := localPort()
+	localBase := localBaseURL()
 	logger.Loading("🔍 Checking local server on port %s...", port)
 
 	// First try a health check to see if server is running
 	resp, err := http.Get(localBase + "/health")
 	if err != nil || resp.StatusCode != http.StatusOK {
 		logger.Error("❌ No local server running on port %s", port)
-		return nil // Not an error if server isn't running
+		return false, nil // Not an error if server isn't running
 	}
 	if resp != nil {
 		resp.Body.Close()
@@ -62,7 +73,7 @@ func handleStop() error {
 		logger.Loading("🔄 Stopping local server process...")
 		if err := cmd.Run(); err != nil {
 			logger.Error("❌ Failed to stop server process: %v", err)
-			return fmt.Errorf("failed to stop server: %w", err)
+			return false, fmt.Errorf("failed to stop server: %w", err)
 		}
 	}
 
@@ -74,7 +85,7 @@ func handleStop() error {
 	if err != nil {
 		// Error means server is not responding, which is what we want
 		logger.Success("✅ Local server stopped successfully")
-		return nil
+		return true, nil
 	}
 	defer resp.Body.Close()
 
@@ -82,9 +93,9 @@ func handleStop() error {
 	if resp.StatusCode == http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		logger.Error("❌ Server is still running: %s", string(body))
-		return fmt.Errorf("server is still running on port %s", port)
+		return false, fmt.Errorf("server is still running on port %s", port)
 	}
 
 	logger.Success("✅ Local server stopped successfully")
-	return nil
+	return true, nil
 }
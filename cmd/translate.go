@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/client"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+func translateCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "translate PR_URL|PR_NUMBER LANG",
+		Short: "Add a translation of a PR description to the PR",
+		Long: `Translate an existing PR description into another language and append
+it to the description in a collapsible section. Code blocks and the
+Markdown structure are kept as they are. Translating into the same
+language again replaces the earlier translation. LANG is a language tag
+like ja or pt-BR. Requires GGQUICK_ADMIN_TOKEN when the server sets one.`,
+		Example: `  ggquick translate https://github.com/my-org/api/pull/142 ja
+  ggquick translate 142 pt-BR --repo my-org/api`,
+		Args: cli.ExactArgs(2),
+	}
+	repo := cmd.Flags().String("repo", originRepo(), "repository as owner/name, when giving a PR number")
+	server := cmd.Flags().String("server", serverBaseURL(), "ggquick server URL")
+	cmd.Run = func(_ *cli.Command, args []string) error {
+		req := client.TranslateRequest{Lang: args[1]}
+		var err error
+		if req.Repo, req.PR, err = prArg(args[0], *repo); err != nil {
+			return configError(err)
+		}
+
+		logger := log.New(true)
+		logger.Loading("🌐 Translating #%d in %s to %s...", req.PR, req.Repo, req.Lang)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		result, err := adminClient(*server).Translate(ctx, req)
+		if err != nil {
+			return fmt.Errorf("translation failed: %w", err)
+		}
+		if jsonOutput {
+			return printJSON(result)
+		}
+		logger.Success("✅ Added %s translation to #%d: %s", result.Lang, result.Number, result.URL)
+		return nil
+	}
+	return cmd
+}
+
+// prArg returns the repository and number of a PR given as a URL, or as
+// a number in repo
+func prArg(arg, repo string) (string, int, error) {
+	if strings.Contains(arg, "/pull/") {
+		return parsePRURL(arg)
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+	if err != nil || n <= 0 {
+		return "", 0, fmt.Errorf("invalid PR %q, want a PR URL or number", arg)
+	}
+	if strings.Count(repo, "/") != 1 {
+		return "", 0, fmt.Errorf("repository must be owner/repo, got %q", repo)
+	}
+	return repo, n, nil
+}
+
+// parsePRURL returns the repository and number of a PR URL like
+// https://github.com/owner/name/pull/142
+func parsePRURL(raw string) (string, int, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid PR URL %q: %w", raw, err)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 4 || parts[2] != "pull" {
+		return "", 0, fmt.Errorf("invalid PR URL %q, want https://github.com/owner/name/pull/N", raw)
+	}
+	number, err := strconv.Atoi(parts[3])
+	if err != nil || number <= 0 {
+		return "", 0, fmt.Errorf("invalid PR number in %q", raw)
+	}
+	return parts[0] + "/" + parts[1], number, nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/cli"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+func usageCommand() *cli.Command {
+	cmd := &cli.Command{
+		Use:   "usage",
+		Short: "Show PR and token usage for a repository",
+		Args:  cli.NoArgs,
+	}
+	repo := cmd.Flags().String("repo", "", "repository as owner/name, optional with one configured repo")
+	days := cmd.Flags().Int("days", 30, "days to cover")
+	server := cmd.Flags().String("server", serverBaseURL(), "ggquick server URL")
+	cmd.Run = func(*cli.Command, []string) error { return handleUsage(*server, *repo, *days) }
+	return cmd
+}
+
+func handleUsage(server, repo string, days int) error {
+	if days <= 0 {
+		return configError(fmt.Errorf("--days must be positive"))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	report, err := serverClient(server).Report(ctx, repo, days)
+	if err != nil {
+		return fmt.Errorf("failed to get usage: %w", err)
+	}
+	if jsonOutput {
+		return printJSON(report)
+	}
+
+	logger := log.New(true)
+	logger.Info("📊 %s, last %d days", report.Repo, days)
+	logger.Info("🔄 PRs generated: %d (%d merged)", report.PRsGenerated, report.PRsMerged)
+	logger.Info("❌ Failed jobs: %d", report.FailedJobs)
+	logger.Info("🪙 Tokens used: %d", report.TokensUsed)
+	if report.AvgTimeToMerge != "" {
+		logger.Info("⏱️ Average time to merge: %s", report.AvgTimeToMerge)
+	}
+	for _, r := range report.References {
+		logger.Info("🔎 %s (prompt %s): %d of %d reference(s) not in the diff across %d PR(s)",
+			r.Model, r.PromptVersion, r.Unverified, r.References, r.Descriptions)
+	}
+	return nil
+}
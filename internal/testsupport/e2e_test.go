@@ -0,0 +1,126 @@
+package testsupport
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/client"
+)
+
+func TestPushOpensPullRequest(t *testing.T) {
+	env := NewEnv(t)
+	repo := BuildRepo(t, RepoSpec{
+		Branches: []Branch{{
+			Name: "feature/greeting",
+			Commits: []Commit{{
+				Message: "Add a greeting",
+				Files:   map[string]string{"greet.go": "package main\n\nfunc greet() string { return \"hi\" }\n"},
+			}},
+		}},
+	})
+	env.GitHub.AddRepo("acme/widgets", repo)
+	url := env.StartServer(t, env.Server(t))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	c := client.New(url)
+	if _, err := c.Configure(ctx, "https://github.com/acme/widgets"); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	if hooks := env.GitHub.Hooks("acme/widgets"); len(hooks) != 1 {
+		t.Errorf("got %d webhooks, want 1", len(hooks))
+	}
+
+	job, err := c.Push(ctx, client.PushRequest{Ref: "feature/greeting", SHA: repo.SHA("feature/greeting"), Repo: "acme/widgets"})
+	if err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	job, err = c.WaitJob(ctx, job.ID, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitJob: %v", err)
+	}
+	if job.Status != "succeeded" {
+		t.Fatalf("job %s is %s: %s", job.ID, job.Status, job.Error)
+	}
+
+	prs := env.GitHub.PullRequests("acme/widgets")
+	if len(prs) != 1 {
+		t.Fatalf("got %d pull requests, want 1", len(prs))
+	}
+	pr := prs[0]
+	if pr.GetHead().GetRef() != "feature/greeting" || pr.GetBase().GetRef() != "main" {
+		t.Errorf("PR is %s into %s, want feature/greeting into main", pr.GetHead().GetRef(), pr.GetBase().GetRef())
+	}
+	if job.PRNumber != pr.GetNumber() {
+		t.Errorf("job reports PR #%d, want #%d", job.PRNumber, pr.GetNumber())
+	}
+	if body := pr.GetBody(); !strings.Contains(body, "Add a greeting") || !strings.Contains(body, "greet.go") {
+		t.Errorf("PR body doesn't describe the change:\n%s", body)
+	}
+	if unhandled := env.GitHub.Unhandled(); len(unhandled) > 0 {
+		t.Logf("calls the fake GitHub didn't serve: %v", unhandled)
+	}
+}
+
+func TestWebhookPushRunsAfterResponse(t *testing.T) {
+	t.Setenv("GGQUICK_WEBHOOK_SECRET", "hook-secret")
+	env := NewEnv(t)
+	repo := BuildRepo(t, RepoSpec{
+		Branches: []Branch{{
+			Name:    "feature/greeting",
+			Commits: []Commit{{Message: "Add a greeting", Files: map[string]string{"greet.go": "package main\n"}}},
+		}},
+	})
+	env.GitHub.AddRepo("acme/widgets", repo)
+	url := env.StartServer(t, env.Server(t))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	c := client.New(url)
+	if _, err := c.Configure(ctx, "https://github.com/acme/widgets"); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+
+	payload := []byte(fmt.Sprintf(`{"ref":"refs/heads/feature/greeting","head_commit":{"id":%q,"message":"Add a greeting"},`+
+		`"repository":{"full_name":"acme/widgets"},"sender":{"login":"octocat"}}`, repo.SHA("feature/greeting")))
+	mac := hmac.New(sha256.New, []byte("hook-secret"))
+	mac.Write(payload)
+	req, err := http.NewRequest(http.MethodPost, url+"/webhook", bytes.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", "push")
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("push delivery: got %d, want 202", resp.StatusCode)
+	}
+
+	jobs, err := c.Jobs(ctx)
+	if err != nil {
+		t.Fatalf("Jobs: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].Branch != "feature/greeting" {
+		t.Fatalf("got jobs %+v, want one for feature/greeting", jobs)
+	}
+	job, err := c.WaitJob(ctx, jobs[0].ID, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitJob: %v", err)
+	}
+	if job.Status != "succeeded" {
+		t.Fatalf("job %s is %s: %s", job.ID, job.Status, job.Error)
+	}
+}
@@ -0,0 +1,102 @@
+package testsupport
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/ai"
+	ghclient "github.com/saint0x/ggquick/pkg/github"
+	"github.com/saint0x/ggquick/pkg/hooks"
+	"github.com/saint0x/ggquick/pkg/log"
+	"github.com/saint0x/ggquick/pkg/server"
+)
+
+// Env wires the real GitHub client and hooks manager to a fake GitHub API
+// and the generator to the mock AI provider
+type Env struct {
+	GitHub    *GitHub
+	Client    *ghclient.Client
+	Hooks     *hooks.Manager
+	Generator *ai.Generator
+	Logger    *log.Logger
+}
+
+// NewEnv starts a fake GitHub API and builds clients for it
+func NewEnv(t testing.TB) *Env {
+	t.Helper()
+	logger := log.New(testing.Verbose())
+	gh := NewGitHub(t)
+
+	client, err := ghclient.NewWithBaseURL(logger, "test-token", gh.URL())
+	if err != nil {
+		t.Fatalf("failed to create GitHub client: %v", err)
+	}
+	mgr := hooks.New(logger)
+	if err := mgr.InitGitHubWithBaseURL("test-token", gh.URL()); err != nil {
+		t.Fatalf("failed to create hooks manager: %v", err)
+	}
+	gen := ai.New(logger)
+	gen.SetProviders([]ai.Provider{ai.MockProvider{}})
+
+	return &Env{GitHub: gh, Client: client, Hooks: mgr, Generator: gen, Logger: logger}
+}
+
+// Server creates a ggquick server on the environment's clients
+func (e *Env) Server(t testing.TB) *server.Server {
+	t.Helper()
+	srv, err := server.New(e.Logger, e.Generator, e.Client, e.Hooks)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	return srv
+}
+
+// StartServer starts srv on a free local port and returns its URL once
+// it answers /health. It stops when the test ends.
+func (e *Env) StartServer(t testing.TB, srv *server.Server) string {
+	t.Helper()
+	port := freePort(t)
+	t.Setenv("PORT", strconv.Itoa(port))
+	t.Setenv("BIND", "")
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	t.Setenv("OPENAI_API_KEY", "test-key")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() {
+		if err := srv.Start(ctx); err != nil {
+			t.Errorf("server stopped: %v", err)
+		}
+	}()
+
+	url := fmt.Sprintf("http://127.0.0.1:%d", port)
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := http.Get(url + "/health")
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return url
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("server didn't answer /health at %s", url)
+	return ""
+}
+
+// freePort finds a port nothing listens on
+func freePort(t testing.TB) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find a free port: %v", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
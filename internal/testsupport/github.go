@@ -0,0 +1,617 @@
+package testsupport
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// Request is a call the fake GitHub API received
+type Request struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+// GitHub is a fake GitHub REST API on an httptest server. Repositories are
+// backed by local git repositories, so comparisons, commits, branches,
+// tags and file contents come from real history; pull requests, labels,
+// comments, reviewers, hooks and releases are kept in memory. Unknown
+// endpoints answer 404 and are listed by Unhandled.
+type GitHub struct {
+	Server *httptest.Server
+	// Login is returned for the authenticated user
+	Login string
+
+	mu        sync.Mutex
+	repos     map[string]*Repo
+	prs       map[string][]*github.PullRequest
+	comments  map[string][]*github.IssueComment
+	hooks     map[string][]*github.Hook
+	releases  map[string][]*github.RepositoryRelease
+	requests  []Request
+	unhandled []Request
+	nextID    int64
+}
+
+// NewGitHub starts a fake GitHub API, closed when the test ends
+func NewGitHub(t testing.TB) *GitHub {
+	t.Helper()
+	g := &GitHub{
+		Login:    "test-user",
+		repos:    make(map[string]*Repo),
+		prs:      make(map[string][]*github.PullRequest),
+		comments: make(map[string][]*github.IssueComment),
+		hooks:    make(map[string][]*github.Hook),
+		releases: make(map[string][]*github.RepositoryRelease),
+	}
+	g.Server = httptest.NewServer(http.HandlerFunc(g.serve))
+	t.Cleanup(g.Server.Close)
+	return g
+}
+
+// URL returns the API base URL to configure clients with
+func (g *GitHub) URL() string {
+	return g.Server.URL
+}
+
+// AddRepo serves repo as owner/name
+func (g *GitHub) AddRepo(fullName string, repo *Repo) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.repos[fullName] = repo
+}
+
+// PullRequests returns a repository's pull requests, oldest first
+func (g *GitHub) PullRequests(fullName string) []*github.PullRequest {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]*github.PullRequest(nil), g.prs[fullName]...)
+}
+
+// Comments returns the comments on an issue or pull request
+func (g *GitHub) Comments(fullName string, number int) []*github.IssueComment {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]*github.IssueComment(nil), g.comments[fmt.Sprintf("%s#%d", fullName, number)]...)
+}
+
+// Hooks returns a repository's webhooks
+func (g *GitHub) Hooks(fullName string) []*github.Hook {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]*github.Hook(nil), g.hooks[fullName]...)
+}
+
+// Releases returns a repository's releases
+func (g *GitHub) Releases(fullName string) []*github.RepositoryRelease {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]*github.RepositoryRelease(nil), g.releases[fullName]...)
+}
+
+// Requests returns every call received, in order
+func (g *GitHub) Requests() []Request {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]Request(nil), g.requests...)
+}
+
+// Unhandled returns the calls no fake endpoint served
+func (g *GitHub) Unhandled() []Request {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]Request(nil), g.unhandled...)
+}
+
+// apiError is GitHub's error body
+type apiError struct {
+	Message string `json:"message"`
+}
+
+// reply writes v as JSON
+func reply(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// serve routes a call to its fake endpoint
+func (g *GitHub) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	req := Request{Method: r.Method, Path: r.URL.Path, Body: string(body)}
+	g.mu.Lock()
+	g.requests = append(g.requests, req)
+	g.mu.Unlock()
+
+	status, v := g.route(r, body)
+	if status == http.StatusNotFound && v == nil {
+		g.mu.Lock()
+		g.unhandled = append(g.unhandled, req)
+		g.mu.Unlock()
+		v = apiError{Message: "Not Found"}
+	}
+	if status == http.StatusNoContent {
+		w.WriteHeader(status)
+		return
+	}
+	reply(w, status, v)
+}
+
+// route answers a call; a 404 with no body marks an unknown endpoint
+func (g *GitHub) route(r *http.Request, body []byte) (int, interface{}) {
+	path := strings.Trim(r.URL.Path, "/")
+	switch {
+	case path == "user" && r.Method == http.MethodGet:
+		return http.StatusOK, &github.User{Login: github.String(g.Login)}
+	case path == "search/issues" && r.Method == http.MethodGet:
+		return http.StatusOK, &github.IssuesSearchResult{Total: github.Int(0)}
+	}
+
+	parts := strings.SplitN(path, "/", 4)
+	if len(parts) < 3 || parts[0] != "repos" {
+		return http.StatusNotFound, nil
+	}
+	fullName := parts[1] + "/" + parts[2]
+	g.mu.Lock()
+	repo := g.repos[fullName]
+	g.mu.Unlock()
+	if repo == nil {
+		return http.StatusNotFound, apiError{Message: "Not Found"}
+	}
+	rest := ""
+	if len(parts) == 4 {
+		rest = parts[3]
+	}
+	return g.routeRepo(r, body, fullName, repo, rest)
+}
+
+// routeRepo answers a call below /repos/owner/name
+func (g *GitHub) routeRepo(r *http.Request, body []byte, fullName string, repo *Repo, rest string) (int, interface{}) {
+	method := r.Method
+	segments := strings.Split(rest, "/")
+	switch {
+	case rest == "" && method == http.MethodGet:
+		return http.StatusOK, &github.Repository{
+			Name:          github.String(fullName[strings.Index(fullName, "/")+1:]),
+			FullName:      github.String(fullName),
+			DefaultBranch: github.String("main"),
+			Fork:          github.Bool(false),
+		}
+	case rest == "" && method == http.MethodPatch:
+		var edit github.Repository
+		_ = json.Unmarshal(body, &edit)
+		edit.FullName = github.String(fullName)
+		return http.StatusOK, &edit
+	case strings.HasPrefix(rest, "compare/") && method == http.MethodGet:
+		base, head, ok := strings.Cut(strings.TrimPrefix(rest, "compare/"), "...")
+		if !ok {
+			return http.StatusNotFound, apiError{Message: "Not Found"}
+		}
+		comp, err := compare(repo, base, head)
+		if err != nil {
+			return http.StatusNotFound, apiError{Message: err.Error()}
+		}
+		return http.StatusOK, comp
+	case segments[0] == "git" && len(segments) == 3 && segments[1] == "commits" && method == http.MethodGet:
+		commit, err := gitCommit(repo, segments[2])
+		if err != nil {
+			return http.StatusNotFound, apiError{Message: err.Error()}
+		}
+		return http.StatusOK, commit
+	case strings.HasPrefix(rest, "git/ref/heads/") && method == http.MethodGet:
+		ref := strings.TrimPrefix(rest, "git/ref/")
+		sha, err := repo.output("rev-parse", "--verify", "refs/"+ref)
+		if err != nil {
+			return http.StatusNotFound, apiError{Message: "Not Found"}
+		}
+		return http.StatusOK, &github.Reference{
+			Ref:    github.String("refs/" + ref),
+			Object: &github.GitObject{Type: github.String("commit"), SHA: github.String(strings.TrimSpace(sha))},
+		}
+	case rest == "branches" && method == http.MethodGet:
+		return http.StatusOK, branches(repo)
+	case rest == "tags" && method == http.MethodGet:
+		return http.StatusOK, tags(repo)
+	case rest == "languages" && method == http.MethodGet:
+		return http.StatusOK, map[string]int{}
+	case strings.HasPrefix(rest, "contents/") && method == http.MethodGet:
+		ref := r.URL.Query().Get("ref")
+		if ref == "" {
+			ref = "main"
+		}
+		file := strings.TrimPrefix(rest, "contents/")
+		content, err := repo.output("show", ref+":"+file)
+		if err != nil {
+			return http.StatusNotFound, apiError{Message: "Not Found"}
+		}
+		return http.StatusOK, &github.RepositoryContent{
+			Type:     github.String("file"),
+			Path:     github.String(file),
+			Encoding: github.String("base64"),
+			Content:  github.String(base64.StdEncoding.EncodeToString([]byte(content))),
+		}
+	case rest == "actions/runs" && method == http.MethodGet:
+		return http.StatusOK, &github.WorkflowRuns{TotalCount: github.Int(0), WorkflowRuns: []*github.WorkflowRun{}}
+	case segments[0] == "pulls" || (segments[0] == "commits" && len(segments) == 3 && segments[2] == "pulls"):
+		return g.routePulls(r, body, fullName, repo, segments)
+	case segments[0] == "issues" && len(segments) == 3:
+		return g.routeIssue(method, body, fullName, segments)
+	case segments[0] == "hooks":
+		return g.routeHooks(method, body, fullName, segments)
+	case rest == "releases" && method == http.MethodPost:
+		var release github.RepositoryRelease
+		_ = json.Unmarshal(body, &release)
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		release.ID = github.Int64(g.id())
+		g.releases[fullName] = append(g.releases[fullName], &release)
+		return http.StatusCreated, &release
+	}
+	return http.StatusNotFound, nil
+}
+
+// id returns the next object ID; the caller holds the lock
+func (g *GitHub) id() int64 {
+	g.nextID++
+	return g.nextID
+}
+
+// routePulls answers pull request calls
+func (g *GitHub) routePulls(r *http.Request, body []byte, fullName string, repo *Repo, segments []string) (int, interface{}) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	prs := g.prs[fullName]
+
+	if segments[0] == "commits" {
+		var found []*github.PullRequest
+		for _, pr := range prs {
+			if pr.GetHead().GetSHA() == segments[1] {
+				found = append(found, pr)
+			}
+		}
+		return http.StatusOK, nonNil(found)
+	}
+
+	switch {
+	case len(segments) == 1 && r.Method == http.MethodGet:
+		state, head := r.URL.Query().Get("state"), r.URL.Query().Get("head")
+		if state == "" {
+			state = "open"
+		}
+		var found []*github.PullRequest
+		for i := len(prs) - 1; i >= 0; i-- {
+			pr := prs[i]
+			if state != "all" && pr.GetState() != state {
+				continue
+			}
+			if head != "" && head != pr.GetHead().GetLabel() && head != pr.GetHead().GetRef() {
+				continue
+			}
+			found = append(found, pr)
+		}
+		return http.StatusOK, nonNil(found)
+	case len(segments) == 1 && r.Method == http.MethodPost:
+		var req github.NewPullRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return http.StatusUnprocessableEntity, apiError{Message: err.Error()}
+		}
+		head := req.GetHead()
+		if _, branch, ok := strings.Cut(head, ":"); ok {
+			head = branch
+		}
+		headSHA, err := repo.output("rev-parse", head+"^{commit}")
+		if err != nil {
+			return http.StatusUnprocessableEntity, apiError{Message: "Validation Failed: head " + head + " not found"}
+		}
+		for _, pr := range prs {
+			if pr.GetHead().GetRef() == head && pr.GetState() == "open" {
+				return http.StatusUnprocessableEntity, apiError{Message: "Validation Failed: A pull request already exists for " + req.GetHead()}
+			}
+		}
+		owner := fullName[:strings.Index(fullName, "/")]
+		repository := &github.Repository{FullName: github.String(fullName)}
+		pr := &github.PullRequest{
+			ID:        github.Int64(g.id()),
+			Number:    github.Int(len(prs) + 1),
+			State:     github.String("open"),
+			Title:     github.String(req.GetTitle()),
+			Body:      github.String(req.GetBody()),
+			Draft:     github.Bool(req.GetDraft()),
+			HTMLURL:   github.String(fmt.Sprintf("https://github.com/%s/pull/%d", fullName, len(prs)+1)),
+			CreatedAt: &github.Timestamp{Time: time.Now()},
+			User:      &github.User{Login: github.String(g.Login)},
+			Head:      &github.PullRequestBranch{Ref: github.String(head), Label: github.String(owner + ":" + head), SHA: github.String(strings.TrimSpace(headSHA)), Repo: repository},
+			Base:      &github.PullRequestBranch{Ref: github.String(req.GetBase()), Repo: repository},
+		}
+		g.prs[fullName] = append(prs, pr)
+		return http.StatusCreated, pr
+	}
+
+	number, err := strconv.Atoi(segments[1])
+	if err != nil || number < 1 || number > len(prs) {
+		return http.StatusNotFound, apiError{Message: "Not Found"}
+	}
+	pr := prs[number-1]
+	switch {
+	case len(segments) == 2 && r.Method == http.MethodGet:
+		return http.StatusOK, pr
+	case len(segments) == 2 && r.Method == http.MethodPatch:
+		var edit github.PullRequest
+		_ = json.Unmarshal(body, &edit)
+		if edit.Body != nil {
+			pr.Body = edit.Body
+		}
+		if edit.Title != nil {
+			pr.Title = edit.Title
+		}
+		if edit.State != nil {
+			pr.State = edit.State
+		}
+		return http.StatusOK, pr
+	case len(segments) == 3 && segments[2] == "requested_reviewers" && r.Method == http.MethodPost:
+		var req github.ReviewersRequest
+		_ = json.Unmarshal(body, &req)
+		for _, login := range req.Reviewers {
+			pr.RequestedReviewers = append(pr.RequestedReviewers, &github.User{Login: github.String(login)})
+		}
+		return http.StatusCreated, pr
+	}
+	return http.StatusNotFound, nil
+}
+
+// routeIssue answers label and comment calls on issues and pull requests
+func (g *GitHub) routeIssue(method string, body []byte, fullName string, segments []string) (int, interface{}) {
+	number, err := strconv.Atoi(segments[1])
+	if err != nil || method != http.MethodPost {
+		return http.StatusNotFound, nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	switch segments[2] {
+	case "labels":
+		var names []string
+		_ = json.Unmarshal(body, &names)
+		var labels []*github.Label
+		for _, name := range names {
+			labels = append(labels, &github.Label{Name: github.String(name)})
+		}
+		if prs := g.prs[fullName]; number >= 1 && number <= len(prs) {
+			prs[number-1].Labels = append(prs[number-1].Labels, labels...)
+		}
+		return http.StatusOK, nonNil(labels)
+	case "comments":
+		var comment github.IssueComment
+		_ = json.Unmarshal(body, &comment)
+		comment.ID = github.Int64(g.id())
+		comment.User = &github.User{Login: github.String(g.Login)}
+		comment.CreatedAt = &github.Timestamp{Time: time.Now()}
+		key := fmt.Sprintf("%s#%d", fullName, number)
+		g.comments[key] = append(g.comments[key], &comment)
+		return http.StatusCreated, &comment
+	}
+	return http.StatusNotFound, nil
+}
+
+// routeHooks answers webhook calls
+func (g *GitHub) routeHooks(method string, body []byte, fullName string, segments []string) (int, interface{}) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	hooks := g.hooks[fullName]
+	if len(segments) == 1 {
+		switch method {
+		case http.MethodGet:
+			return http.StatusOK, nonNil(hooks)
+		case http.MethodPost:
+			var hook github.Hook
+			_ = json.Unmarshal(body, &hook)
+			hook.ID = github.Int64(g.id())
+			g.hooks[fullName] = append(hooks, &hook)
+			return http.StatusCreated, &hook
+		}
+		return http.StatusNotFound, nil
+	}
+
+	id, err := strconv.ParseInt(segments[1], 10, 64)
+	if err != nil || len(segments) != 2 {
+		return http.StatusNotFound, nil
+	}
+	for i, hook := range hooks {
+		if hook.GetID() != id {
+			continue
+		}
+		switch method {
+		case http.MethodGet:
+			return http.StatusOK, hook
+		case http.MethodPatch:
+			var edit github.Hook
+			_ = json.Unmarshal(body, &edit)
+			if edit.Events != nil {
+				hook.Events = edit.Events
+			}
+			if edit.Config != nil {
+				hook.Config = edit.Config
+			}
+			return http.StatusOK, hook
+		case http.MethodDelete:
+			g.hooks[fullName] = append(hooks[:i:i], hooks[i+1:]...)
+			return http.StatusNoContent, nil
+		}
+	}
+	return http.StatusNotFound, apiError{Message: "Not Found"}
+}
+
+// nonNil keeps empty lists encoding as [] rather than null
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+// branches lists a repository's branches with their head commits
+func branches(repo *Repo) []*github.Branch {
+	out, _ := repo.output("for-each-ref", "--format=%(refname:short) %(objectname)", "refs/heads")
+	list := []*github.Branch{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		name, sha, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		list = append(list, &github.Branch{Name: github.String(name), Commit: &github.RepositoryCommit{SHA: github.String(sha)}})
+	}
+	return list
+}
+
+// tags lists a repository's tags, newest version first
+func tags(repo *Repo) []*github.RepositoryTag {
+	out, _ := repo.output("for-each-ref", "--sort=-v:refname", "--format=%(refname:short) %(objectname)", "refs/tags")
+	list := []*github.RepositoryTag{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		name, sha, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		list = append(list, &github.RepositoryTag{Name: github.String(name), Commit: &github.Commit{SHA: github.String(sha)}})
+	}
+	return list
+}
+
+// gitCommit returns a commit with its message and parents
+func gitCommit(repo *Repo, ref string) (*github.Commit, error) {
+	out, err := repo.output("show", "--no-patch", "--format=%H%x1f%P%x1f%an%x1f%ae%x1f%B", ref)
+	if err != nil {
+		return nil, fmt.Errorf("no commit %s", ref)
+	}
+	fields := strings.SplitN(out, "\x1f", 5)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("unexpected git output for %s", ref)
+	}
+	commit := &github.Commit{
+		SHA:     github.String(fields[0]),
+		Message: github.String(strings.TrimSpace(fields[4])),
+		Author:  &github.CommitAuthor{Name: github.String(fields[2]), Email: github.String(fields[3])},
+	}
+	for _, parent := range strings.Fields(fields[1]) {
+		commit.Parents = append(commit.Parents, &github.Commit{SHA: github.String(parent)})
+	}
+	return commit, nil
+}
+
+// compare builds GitHub's comparison of head against base from git
+func compare(repo *Repo, base, head string) (*github.CommitsComparison, error) {
+	mergeBase, err := repo.output("merge-base", base, head)
+	if err != nil {
+		return nil, fmt.Errorf("no common ancestor between %s and %s", base, head)
+	}
+	mergeBase = strings.TrimSpace(mergeBase)
+
+	log, _ := repo.output("log", "--reverse", "--format=%H", base+".."+head)
+	var commits []*github.RepositoryCommit
+	for _, sha := range strings.Fields(log) {
+		commit, err := gitCommit(repo, sha)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, &github.RepositoryCommit{SHA: commit.SHA, Commit: commit})
+	}
+	baseSHA, _ := repo.output("rev-parse", base+"^{commit}")
+	behind, _ := repo.output("rev-list", "--count", head+".."+base)
+	behindBy, _ := strconv.Atoi(strings.TrimSpace(behind))
+
+	status := "identical"
+	switch {
+	case len(commits) > 0 && behindBy > 0:
+		status = "diverged"
+	case len(commits) > 0:
+		status = "ahead"
+	case behindBy > 0:
+		status = "behind"
+	}
+
+	files, err := changedFiles(repo, mergeBase, head)
+	if err != nil {
+		return nil, err
+	}
+	return &github.CommitsComparison{
+		Status:          github.String(status),
+		AheadBy:         github.Int(len(commits)),
+		BehindBy:        github.Int(behindBy),
+		TotalCommits:    github.Int(len(commits)),
+		BaseCommit:      &github.RepositoryCommit{SHA: github.String(strings.TrimSpace(baseSHA))},
+		MergeBaseCommit: &github.RepositoryCommit{SHA: github.String(mergeBase)},
+		Commits:         nonNil(commits),
+		Files:           files,
+	}, nil
+}
+
+// gitStatuses maps git's name-status letters to GitHub's file statuses
+var gitStatuses = map[byte]string{'A': "added", 'D': "removed", 'M': "modified", 'R': "renamed", 'C': "copied", 'T': "changed"}
+
+// changedFiles lists the files changed from base to head with counts and
+// patches
+func changedFiles(repo *Repo, base, head string) ([]*github.CommitFile, error) {
+	names, err := repo.output("diff", "--name-status", "-M", base, head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff %s and %s", base, head)
+	}
+	stats, _ := repo.output("diff", "--numstat", "-M", base, head)
+	counts := make(map[string][2]int)
+	for _, line := range strings.Split(strings.TrimSpace(stats), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 3 {
+			continue
+		}
+		add, _ := strconv.Atoi(fields[0])
+		del, _ := strconv.Atoi(fields[1])
+		counts[fields[len(fields)-1]] = [2]int{add, del}
+	}
+
+	files := []*github.CommitFile{}
+	for _, line := range strings.Split(strings.TrimSpace(names), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 || fields[0] == "" {
+			continue
+		}
+		name := fields[len(fields)-1]
+		file := &github.CommitFile{
+			Filename: github.String(name),
+			Status:   github.String(gitStatuses[fields[0][0]]),
+		}
+		if len(fields) == 3 {
+			file.PreviousFilename = github.String(fields[1])
+		}
+		c := counts[name]
+		file.Additions, file.Deletions, file.Changes = github.Int(c[0]), github.Int(c[1]), github.Int(c[0]+c[1])
+		if patch := filePatch(repo, base, head, name); patch != "" {
+			file.Patch = github.String(patch)
+		}
+		files = append(files, file)
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].GetFilename() < files[j].GetFilename() })
+	return files, nil
+}
+
+// filePatch returns a file's hunks without the diff header, like the
+// patch field of GitHub's API
+func filePatch(repo *Repo, base, head, name string) string {
+	diff, err := repo.output("diff", "-M", base, head, "--", name)
+	if err != nil {
+		return ""
+	}
+	if i := strings.Index(diff, "\n@@"); i >= 0 {
+		return strings.TrimSuffix(diff[i+1:], "\n")
+	}
+	return ""
+}
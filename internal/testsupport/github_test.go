@@ -0,0 +1,29 @@
+package testsupport
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	ghclient "github.com/saint0x/ggquick/pkg/github"
+)
+
+func TestCompareMissingBranch(t *testing.T) {
+	env := NewEnv(t)
+	env.GitHub.AddRepo("acme/widgets", BuildRepo(t, RepoSpec{}))
+	ctx := context.Background()
+
+	_, err := env.Client.CompareBranches(ctx, "acme", "widgets", "main", "feature/unpushed")
+	if !errors.Is(err, ghclient.ErrBranchNotFound) {
+		t.Errorf("unpushed branch: got %v, want ErrBranchNotFound", err)
+	}
+	// A repository the token can't see 404s the same way, but isn't a
+	// missing branch
+	_, err = env.Client.CompareBranches(ctx, "acme", "secret", "main", "feature/unpushed")
+	if err == nil || errors.Is(err, ghclient.ErrBranchNotFound) {
+		t.Errorf("unknown repository: got %v, want another error", err)
+	}
+	if _, err := env.Client.CompareBranches(ctx, "acme", "widgets", "main", "main"); err != nil {
+		t.Errorf("pushed branch: %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+// Package testsupport builds fixtures for end-to-end tests: temporary git
+// repositories with scripted branches and commits, and a fake GitHub API
+// backed by them, so the server and hooks run without tokens
+package testsupport
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Commit is a scripted commit. Files are written with their contents and
+// Delete removes files; a commit with neither is empty.
+type Commit struct {
+	Message string
+	Files   map[string]string
+	Delete  []string
+}
+
+// Branch is a scripted branch, created from From (default main) with its
+// commits applied in order
+type Branch struct {
+	Name    string
+	From    string
+	Commits []Commit
+}
+
+// RepoSpec scripts a repository: commits on main, then each branch
+type RepoSpec struct {
+	Main     []Commit
+	Branches []Branch
+}
+
+// Repo is a temporary git repository, removed when the test ends
+type Repo struct {
+	Dir string
+	t   testing.TB
+}
+
+// NewRepo creates an empty repository on main with a test identity
+func NewRepo(t testing.TB) *Repo {
+	t.Helper()
+	r := &Repo{Dir: t.TempDir(), t: t}
+	r.Git("init", "--quiet", "--initial-branch=main")
+	r.Git("config", "user.name", "Test User")
+	r.Git("config", "user.email", "test@example.com")
+	r.Git("config", "commit.gpgsign", "false")
+	return r
+}
+
+// BuildRepo creates a repository from spec. Main gets an initial commit
+// when the spec has none, so branches have a base.
+func BuildRepo(t testing.TB, spec RepoSpec) *Repo {
+	t.Helper()
+	r := NewRepo(t)
+	main := spec.Main
+	if len(main) == 0 {
+		main = []Commit{{Message: "Initial commit", Files: map[string]string{"README.md": "# Test\n"}}}
+	}
+	for _, c := range main {
+		r.Commit(c)
+	}
+	for _, b := range spec.Branches {
+		from := b.From
+		if from == "" {
+			from = "main"
+		}
+		r.Git("checkout", "--quiet", "-b", b.Name, from)
+		for _, c := range b.Commits {
+			r.Commit(c)
+		}
+	}
+	r.Git("checkout", "--quiet", "main")
+	return r
+}
+
+// Git runs git in the repository and returns its trimmed output, failing
+// the test on error
+func (r *Repo) Git(args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.Dir
+	cmd.Env = append(os.Environ(), "GIT_CONFIG_NOSYSTEM=1", "GIT_TERMINAL_PROMPT=0")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// output runs git and returns its output and error without failing the
+// test, for lookups that may miss
+func (r *Repo) output(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.Dir
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// Commit applies c on the current branch and returns the new commit's SHA
+func (r *Repo) Commit(c Commit) string {
+	r.t.Helper()
+	paths := make([]string, 0, len(c.Files))
+	for path := range c.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		full := filepath.Join(r.Dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			r.t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(full, []byte(c.Files[path]), 0644); err != nil {
+			r.t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+	for _, path := range c.Delete {
+		r.Git("rm", "--quiet", "--", path)
+	}
+	r.Git("add", "--all")
+	message := c.Message
+	if message == "" {
+		message = "Update files"
+	}
+	r.Git("commit", "--quiet", "--allow-empty", "-m", message)
+	return r.Head()
+}
+
+// Checkout switches to branch, creating it from the current commit when
+// create is set
+func (r *Repo) Checkout(branch string, create bool) {
+	r.t.Helper()
+	if create {
+		r.Git("checkout", "--quiet", "-b", branch)
+		return
+	}
+	r.Git("checkout", "--quiet", branch)
+}
+
+// Head returns the SHA of the current commit
+func (r *Repo) Head() string {
+	r.t.Helper()
+	return r.Git("rev-parse", "HEAD")
+}
+
+// SHA returns the commit a branch, tag or SHA points to
+func (r *Repo) SHA(ref string) string {
+	r.t.Helper()
+	return r.Git("rev-parse", ref+"^{commit}")
+}
@@ -0,0 +1,69 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/saint0x/ggquick/pkg/openai"
+)
+
+// maxReviewPrompt caps the review comments sent to collect action items
+const maxReviewPrompt = 16000
+
+// ReviewComment is one piece of review feedback on a pull request
+type ReviewComment struct {
+	Author string
+	Path   string // file commented on, "" for a review's summary
+	Line   int
+	Body   string
+}
+
+// ActionItems consolidates review feedback into a Markdown checklist of
+// what the author still has to do, returning it with the tokens used
+func (g *Generator) ActionItems(ctx context.Context, title string, comments []ReviewComment, instructions string) (string, int, error) {
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "Review feedback on the pull request %q, oldest first:\n\n", title)
+	for _, c := range comments {
+		entry := "- " + c.Author
+		if c.Path != "" {
+			entry += " on " + c.Path
+			if c.Line > 0 {
+				entry += fmt.Sprintf(":%d", c.Line)
+			}
+		}
+		entry += ": " + strings.Join(strings.Fields(c.Body), " ") + "\n"
+		if prompt.Len()+len(entry) > maxReviewPrompt {
+			prompt.WriteString("- ...\n")
+			break
+		}
+		prompt.WriteString(entry)
+	}
+
+	system := `You turn pull request review feedback into action items for the author.
+Merge duplicate requests, drop praise, questions already answered and anything later feedback withdraws, and keep file paths and identifiers as written.
+Reply with a Markdown checklist of short imperative items, one "- [ ] " line each, naming the file when there is one, and nothing else. Reply with "none" when nothing is left to do.`
+	if instructions != "" {
+		system += "\n\n" + instructions
+	}
+
+	resp, err := g.chat(ctx, []openai.ChatCompletionMessage{
+		{Role: "system", Content: system},
+		{Role: "user", Content: prompt.String()},
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to collect action items: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", 0, fmt.Errorf("no completion choices returned")
+	}
+
+	var items []string
+	for _, line := range strings.Split(resp.Choices[0].Message.Content, "\n") {
+		line = strings.TrimSpace(line)
+		if text, ok := strings.CutPrefix(line, "- [ ]"); ok && strings.TrimSpace(text) != "" {
+			items = append(items, "- [ ] "+strings.TrimSpace(text))
+		}
+	}
+	return strings.Join(items, "\n"), resp.Usage.TotalTokens, nil
+}
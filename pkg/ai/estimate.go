@@ -0,0 +1,64 @@
+package ai
+
+import (
+	"strings"
+
+	"github.com/saint0x/ggquick/pkg/openai"
+)
+
+// charsPerToken approximates English and code for GPT tokenizers
+const charsPerToken = 4
+
+// messageOverhead is the tokens each chat message costs beyond its text
+const messageOverhead = 4
+
+// ExpectedCompletionTokens is the typical length of a PR description,
+// used to estimate the cost of a call before it's made
+const ExpectedCompletionTokens = 500
+
+// Price is a model's cost in US dollars per thousand tokens
+type Price struct {
+	Prompt     float64
+	Completion float64
+}
+
+// prices are list prices, matched by model name prefix, longest first
+var prices = []struct {
+	prefix string
+	price  Price
+}{
+	{"gpt-4o-mini", Price{0.00015, 0.0006}},
+	{"gpt-4o", Price{0.0025, 0.01}},
+	{"gpt-4-turbo", Price{0.01, 0.03}},
+	{"gpt-4", Price{0.03, 0.06}},
+	{"gpt-3.5-turbo", Price{0.0005, 0.0015}},
+}
+
+// ModelPrice returns a model's list price, and false for unknown models
+func ModelPrice(model string) (Price, bool) {
+	for _, p := range prices {
+		if strings.HasPrefix(model, p.prefix) {
+			return p.price, true
+		}
+	}
+	return Price{}, false
+}
+
+// EstimateTokens approximates the prompt tokens of a chat
+func EstimateTokens(messages []openai.ChatCompletionMessage) int {
+	tokens := 0
+	for _, m := range messages {
+		tokens += (len(m.Content)+charsPerToken-1)/charsPerToken + messageOverhead
+	}
+	return tokens
+}
+
+// EstimateCost prices a call from its prompt and completion tokens, and
+// returns false for unknown models
+func EstimateCost(model string, promptTokens, completionTokens int) (float64, bool) {
+	price, ok := ModelPrice(model)
+	if !ok {
+		return 0, false
+	}
+	return float64(promptTokens)/1000*price.Prompt + float64(completionTokens)/1000*price.Completion, true
+}
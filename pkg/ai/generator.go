@@ -3,6 +3,10 @@ package ai
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/saint0x/ggquick/pkg/log"
 	"github.com/saint0x/ggquick/pkg/openai"
@@ -10,8 +14,9 @@ import (
 
 // Generator handles AI operations
 type Generator struct {
-	logger *log.Logger
-	client *openai.Client
+	logger    *log.Logger
+	client    *openai.Client // OpenAI, also used for moderation
+	providers []*breaker     // failover chain, first provider first
 }
 
 // New creates a new AI generator
@@ -29,44 +34,521 @@ func New(logger *log.Logger) *Generator {
 func (g *Generator) Initialize(key string) error {
 	client := openai.NewClient(key)
 	g.client = client
+	g.SetProviders([]Provider{&openAIProvider{name: ProviderOpenAI, client: client}})
 	return nil
 }
 
+// PRPromptVersion identifies the PR description prompt. Bump it when the
+// prompt changes so quality metrics can compare versions.
+const PRPromptVersion = "pr-4"
+
+// neutralConfidence is used when the model doesn't rate itself
+const neutralConfidence = 0.5
+
+// confidenceLine matches the self-assessment the model ends with
+var confidenceLine = regexp.MustCompile(`(?i)^\W*confidence\W*:?\W*([01](?:\.\d+)?)\W*$`)
+
 // GeneratePR generates a pull request description
 func (g *Generator) GeneratePR(ctx context.Context, info RepoInfo) (*PRContent, error) {
-	// Create chat completion request
-	messages := []openai.ChatCompletionMessage{
+	resp, err := g.complete(ctx, info.Models, PRMessages(info))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate PR: %w", err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no completion choices returned")
+	}
+
+	// Extract title and description
+	content := resp.Choices[0].Message.Content
+	title := info.CommitMessage // Use commit message as title for now
+	description, confidence := splitConfidence(content)
+
+	return &PRContent{
+		Title:         title,
+		Description:   description,
+		TokensUsed:    resp.Usage.TotalTokens,
+		Model:         resp.Model,
+		Provider:      resp.Provider,
+		PromptVersion: PRPromptVersion,
+		Confidence:    confidence,
+	}, nil
+}
+
+// PRMessages builds the chat GeneratePR sends for a branch
+func PRMessages(info RepoInfo) []openai.ChatCompletionMessage {
+	prompt := fmt.Sprintf("Generate a PR description for branch '%s' with commit message: %s",
+		info.BranchName, info.CommitMessage)
+	if len(info.Notes) > 0 {
+		prompt += "\n\nAdditional context:\n- " + strings.Join(info.Notes, "\n- ")
+	}
+	if len(info.Summaries) > 0 {
+		prompt += "\n\nPer-file summaries:"
+		for _, s := range info.Summaries {
+			prompt += fmt.Sprintf("\n- %s: %s", s.File, s.Summary)
+		}
+	}
+	if len(info.Topics) > 1 {
+		prompt += "\n\nThe commits cover unrelated topics:"
+		for _, t := range info.Topics {
+			prompt += fmt.Sprintf("\n- %s: %s", t.Title, strings.Join(t.Subjects, "; "))
+		}
+	}
+	prompt += diffPrompt(info.Diff, info.Omitted)
+
+	system := `You are a helpful AI that generates clear and concise pull request descriptions.
+Focus on explaining the changes and their impact. Be professional but conversational.
+Organize the description under these Markdown headings, in this order:
+## Summary, ## Changes, ## Test Plan and ## Risk.
+End with a last line "Confidence: " and a number from 0 to 1 rating how well the commit message and context let you describe the change. Rate low when you had to guess.`
+	if len(info.Topics) > 1 {
+		system += "\n\nThe branch covers several unrelated topics. Say so in the summary, and under ## Changes give each topic its own ### subsection named after it instead of mixing them."
+	}
+	if guidance := languageGuidance(info.Languages); guidance != "" {
+		system += "\n\n" + guidance
+	}
+	if info.Instructions != "" {
+		system += "\n\n" + info.Instructions
+	}
+
+	return []openai.ChatCompletionMessage{
 		{
-			Role: "system",
-			Content: `You are a helpful AI that generates clear and concise pull request descriptions.
-Focus on explaining the changes and their impact. Be professional but conversational.`,
+			Role:    "system",
+			Content: system,
 		},
 		{
-			Role: "user",
-			Content: fmt.Sprintf("Generate a PR description for branch '%s' with commit message: %s",
-				info.BranchName, info.CommitMessage),
+			Role:    "user",
+			Content: prompt,
 		},
 	}
+}
 
-	resp, err := g.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model:    openai.GPT4,
-		Messages: messages,
+// maxOmittedNames caps the omitted files named in the prompt
+const maxOmittedNames = 30
+
+// diffPrompt renders the diff chunks, most relevant first, and says which
+// files were left out so the model doesn't guess at them
+func diffPrompt(chunks []DiffChunk, omitted []string) string {
+	if len(chunks) == 0 && len(omitted) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	if len(chunks) > 0 {
+		b.WriteString("\n\nDiff, most relevant files first:\n")
+		for _, c := range chunks {
+			if c.Summary != "" {
+				fmt.Fprintf(&b, "\n--- %s (collapsed): %s\n", c.File, c.Summary)
+				continue
+			}
+			note := ""
+			if c.Partial {
+				note = " (some hunks omitted)"
+			}
+			fmt.Fprintf(&b, "\n--- %s%s\n%s\n", c.File, note, c.Patch)
+		}
+	}
+	if len(omitted) > 0 {
+		names := omitted
+		if len(names) > maxOmittedNames {
+			names = names[:maxOmittedNames]
+		}
+		fmt.Fprintf(&b, "\n%d file(s) omitted from the diff for length: %s", len(omitted), strings.Join(names, ", "))
+		if len(omitted) > len(names) {
+			fmt.Fprintf(&b, " and %d more", len(omitted)-len(names))
+		}
+		b.WriteString(". Mention them only by name, don't guess at their changes.")
+	}
+	return b.String()
+}
+
+// splitConfidence removes the model's closing confidence line from a
+// description, returning the rating or neutralConfidence without one
+func splitConfidence(content string) (string, float64) {
+	lines := strings.Split(strings.TrimRight(content, "\n "), "\n")
+	last := len(lines) - 1
+	m := confidenceLine.FindStringSubmatch(strings.TrimSpace(lines[last]))
+	if m == nil {
+		return content, neutralConfidence
+	}
+	confidence, err := strconv.ParseFloat(m[1], 64)
+	if err != nil || confidence > 1 {
+		return content, neutralConfidence
+	}
+	return strings.TrimSpace(strings.Join(lines[:last], "\n")), confidence
+}
+
+// SuggestCommitMessages proposes a Conventional Commits subject for each
+// message, returning them in order along with the tokens used
+func (g *Generator) SuggestCommitMessages(ctx context.Context, messages []string) ([]string, int, error) {
+	var prompt strings.Builder
+	prompt.WriteString("Rewrite each commit message below as a single Conventional Commits subject line ")
+	prompt.WriteString("under 72 characters. Reply with exactly one line per message, in order, with no numbering.\n")
+	for i, msg := range messages {
+		fmt.Fprintf(&prompt, "\n%d. %s", i+1, strings.ReplaceAll(strings.TrimSpace(msg), "\n", " "))
+	}
+
+	resp, err := g.chat(ctx, []openai.ChatCompletionMessage{
+		{Role: "system", Content: "You write concise, accurate git commit messages."},
+		{Role: "user", Content: prompt.String()},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate PR: %w", err)
+		return nil, 0, fmt.Errorf("failed to suggest commit messages: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, 0, fmt.Errorf("no completion choices returned")
+	}
+
+	var suggestions []string
+	for _, line := range strings.Split(resp.Choices[0].Message.Content, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			suggestions = append(suggestions, line)
+		}
+	}
+	if len(suggestions) != len(messages) {
+		return nil, resp.Usage.TotalTokens, fmt.Errorf("expected %d suggestions, got %d", len(messages), len(suggestions))
 	}
+	return suggestions, resp.Usage.TotalTokens, nil
+}
 
+// maxContextBody bounds how much of an earlier PR description goes into
+// a prompt
+const maxContextBody = 4000
+
+// GenerateRevert writes the description of a PR reverting a change
+func (g *Generator) GenerateRevert(ctx context.Context, info RevertInfo) (*PRContent, error) {
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "Write a PR description for reverting commit %s in %s.\n\n", info.SHA, info.Repo)
+	fmt.Fprintf(&prompt, "Original change: %s\n", info.Title)
+	if body := strings.TrimSpace(info.Body); body != "" {
+		if len(body) > maxContextBody {
+			body = body[:maxContextBody] + "..."
+		}
+		fmt.Fprintf(&prompt, "\nOriginal description:\n%s\n", body)
+	}
+	if info.Reason != "" {
+		fmt.Fprintf(&prompt, "\nReason for the revert: %s\n", info.Reason)
+	}
+
+	system := `You write pull request descriptions for reverts.
+Explain briefly what the original change did, what reverting it removes, and why it is being reverted.
+If no reason is given, say the reason should be added rather than inventing one.
+Organize the description under these Markdown headings, in this order:
+## Summary, ## What Is Reverted and ## Risk.`
+	if info.Instructions != "" {
+		system += "\n\n" + info.Instructions
+	}
+
+	resp, err := g.chat(ctx, []openai.ChatCompletionMessage{
+		{Role: "system", Content: system},
+		{Role: "user", Content: prompt.String()},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate revert description: %w", err)
+	}
 	if len(resp.Choices) == 0 {
 		return nil, fmt.Errorf("no completion choices returned")
 	}
 
-	// Extract title and description
-	content := resp.Choices[0].Message.Content
-	title := info.CommitMessage // Use commit message as title for now
-	description := content
+	return &PRContent{
+		Title:       fmt.Sprintf("Revert %q", info.Title),
+		Description: resp.Choices[0].Message.Content,
+		TokensUsed:  resp.Usage.TotalTokens,
+	}, nil
+}
+
+// maxConflictPatch bounds each patch shown when explaining conflicts
+const maxConflictPatch = 1500
+
+// GenerateBackport writes the description of a backport PR or, when
+// info has conflicts, an explanation of why it couldn't be applied
+func (g *Generator) GenerateBackport(ctx context.Context, info BackportInfo) (*PRContent, error) {
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "PR #%d in %s, %q, is being backported to %s.\n", info.Number, info.Repo, info.Title, info.Target)
+	if body := strings.TrimSpace(info.Body); body != "" {
+		if len(body) > maxContextBody {
+			body = body[:maxContextBody] + "..."
+		}
+		fmt.Fprintf(&prompt, "\nOriginal description:\n%s\n", body)
+	}
+
+	system := `You write pull request descriptions for backports.
+Summarize what the original change does and anything reviewers of the release branch should check.
+Organize the description under these Markdown headings, in this order:
+## Summary and ## Backport Notes.`
+	if len(info.Conflicts) > 0 {
+		system = `You explain why a backport could not be applied automatically.
+For each conflicting file, say what the PR changed, what changed on the target branch instead, and how to resolve it.
+Be brief and concrete. Use Markdown with one bullet per file.`
+		prompt.WriteString("\nThe cherry-pick conflicts in these files:\n")
+		for _, f := range info.Conflicts {
+			fmt.Fprintf(&prompt, "\n### %s\nPR change:\n%s\nTarget branch changes:\n%s\n",
+				f.Path, truncatePatch(f.Patch), truncatePatch(f.TargetPatch))
+		}
+	}
+	if info.Instructions != "" {
+		system += "\n\n" + info.Instructions
+	}
+
+	resp, err := g.chat(ctx, []openai.ChatCompletionMessage{
+		{Role: "system", Content: system},
+		{Role: "user", Content: prompt.String()},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate backport description: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no completion choices returned")
+	}
 
 	return &PRContent{
-		Title:       title,
-		Description: description,
+		Title:       fmt.Sprintf("[%s] %s", info.Target, info.Title),
+		Description: resp.Choices[0].Message.Content,
+		TokensUsed:  resp.Usage.TotalTokens,
 	}, nil
 }
+
+// truncatePatch shortens a patch for a prompt
+func truncatePatch(patch string) string {
+	if patch == "" {
+		return "(no textual diff)"
+	}
+	if len(patch) > maxConflictPatch {
+		return patch[:maxConflictPatch] + "\n..."
+	}
+	return patch
+}
+
+// GenerateReleaseNotes writes release notes from the PRs in a release
+func (g *Generator) GenerateReleaseNotes(ctx context.Context, info ReleaseInfo) (*PRContent, error) {
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "Write release notes for %s %s", info.Repo, info.Version)
+	if info.Previous != "" {
+		fmt.Fprintf(&prompt, " (previous release %s)", info.Previous)
+	}
+	prompt.WriteString(".\n\nMerged PRs:\n")
+	for _, pr := range info.PRs {
+		fmt.Fprintf(&prompt, "- #%d %s (@%s)", pr.Number, pr.Title, pr.Author)
+		if len(pr.Labels) > 0 {
+			fmt.Fprintf(&prompt, " [%s]", strings.Join(pr.Labels, ", "))
+		}
+		prompt.WriteString("\n")
+	}
+
+	system := `You write release notes for software releases.
+Group the changes for users, not by PR, and reference PRs by number like #12.
+Call out breaking changes first. Leave out purely internal changes unless nothing else changed.
+Organize the notes under these Markdown headings, skipping empty ones:
+## Highlights, ## Breaking Changes, ## Features, ## Fixes and ## Other Changes.`
+	if info.Instructions != "" {
+		system += "\n\n" + info.Instructions
+	}
+
+	resp, err := g.chat(ctx, []openai.ChatCompletionMessage{
+		{Role: "system", Content: system},
+		{Role: "user", Content: prompt.String()},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate release notes: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no completion choices returned")
+	}
+
+	return &PRContent{
+		Title:       "release " + info.Version,
+		Description: resp.Choices[0].Message.Content,
+		TokensUsed:  resp.Usage.TotalTokens,
+	}, nil
+}
+
+// Issue severities, least to most urgent
+var Severities = []string{"low", "medium", "high", "critical"}
+
+// TriageIssue guesses an issue's severity and affected component, picks
+// labels for it and summarizes it
+func (g *Generator) TriageIssue(ctx context.Context, info IssueInfo) (*Triage, error) {
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "Triage this new issue in %s.\n\nTitle: %s\n", info.Repo, info.Title)
+	if body := strings.TrimSpace(info.Body); body != "" {
+		if len(body) > maxContextBody {
+			body = body[:maxContextBody] + "..."
+		}
+		fmt.Fprintf(&prompt, "\n%s\n", body)
+	}
+	fmt.Fprintf(&prompt, "\nReply with exactly these four lines:\nSeverity: one of %s\n", strings.Join(Severities, ", "))
+	if len(info.Components) > 0 {
+		fmt.Fprintf(&prompt, "Component: one of %s, or none\n", strings.Join(info.Components, ", "))
+	} else {
+		prompt.WriteString("Component: none\n")
+	}
+	if len(info.Labels) > 0 {
+		fmt.Fprintf(&prompt, "Labels: comma-separated, chosen from %s, or none\n", strings.Join(info.Labels, ", "))
+	} else {
+		prompt.WriteString("Labels: none\n")
+	}
+	prompt.WriteString("Summary: one or two sentences on the problem or request\n")
+
+	system := `You triage GitHub issues.
+Judge severity by user impact: data loss, security or outages are critical, broken core features high, workarounds medium, cosmetic low.
+Only pick components and labels from the lists given.`
+	if info.Instructions != "" {
+		system += "\n\n" + info.Instructions
+	}
+
+	resp, err := g.chat(ctx, []openai.ChatCompletionMessage{
+		{Role: "system", Content: system},
+		{Role: "user", Content: prompt.String()},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to triage issue: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no completion choices returned")
+	}
+
+	triage := &Triage{TokensUsed: resp.Usage.TotalTokens}
+	for _, line := range strings.Split(resp.Choices[0].Message.Content, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "severity":
+			triage.Severity = pick(Severities, value)
+		case "component":
+			triage.Component = pick(info.Components, value)
+		case "labels":
+			for _, label := range strings.Split(value, ",") {
+				if label = pick(info.Labels, strings.TrimSpace(label)); label != "" {
+					triage.Labels = append(triage.Labels, label)
+				}
+			}
+		case "summary":
+			triage.Summary = value
+		}
+	}
+	if triage.Severity == "" && triage.Summary == "" {
+		return nil, fmt.Errorf("unexpected triage reply")
+	}
+	return triage, nil
+}
+
+// pick returns the option matching value, ignoring case, or "" so the
+// model can't invent labels
+func pick(options []string, value string) string {
+	for _, option := range options {
+		if strings.EqualFold(option, value) {
+			return option
+		}
+	}
+	return ""
+}
+
+// maxGuide bounds how much of a contributing guide goes into a prompt
+const maxGuide = 8000
+
+// CondenseGuide picks the parts of a contributing guide that matter for
+// changes to files, returning them with the tokens used
+func (g *Generator) CondenseGuide(ctx context.Context, guide string, files []string, instructions string) (string, int, error) {
+	if len(guide) > maxGuide {
+		guide = guide[:maxGuide] + "..."
+	}
+	var prompt strings.Builder
+	prompt.WriteString("A first-time contributor changed these files:\n")
+	for _, file := range files {
+		fmt.Fprintf(&prompt, "- %s\n", file)
+	}
+	fmt.Fprintf(&prompt, "\nThe project's contributing guide:\n\n%s\n", guide)
+
+	system := `You help first-time contributors.
+Condense the contributing guide to the few points that matter for the files they changed, such as tests, style, changelog or sign-off rules.
+Reply with at most six short Markdown bullets and nothing else. Don't invent rules the guide doesn't state.`
+	if instructions != "" {
+		system += "\n\n" + instructions
+	}
+
+	resp, err := g.chat(ctx, []openai.ChatCompletionMessage{
+		{Role: "system", Content: system},
+		{Role: "user", Content: prompt.String()},
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to condense contributing guide: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", 0, fmt.Errorf("no completion choices returned")
+	}
+	return strings.TrimSpace(resp.Choices[0].Message.Content), resp.Usage.TotalTokens, nil
+}
+
+// maxSummaryPatch caps the patch sent to summarize one file
+const maxSummaryPatch = 12000
+
+// SummarizeFile summarizes one file's change in a sentence, returning the
+// tokens used
+func (g *Generator) SummarizeFile(ctx context.Context, file, patch string) (string, int, error) {
+	if len(patch) > maxSummaryPatch {
+		patch = patch[:maxSummaryPatch] + "\n..."
+	}
+	resp, err := g.chat(ctx, []openai.ChatCompletionMessage{
+		{Role: "system", Content: "You summarize code changes. Reply with one plain sentence saying what changed in the file and why it matters, nothing else."},
+		{Role: "user", Content: fmt.Sprintf("File: %s\n\n%s", file, patch)},
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to summarize %s: %w", file, err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", 0, fmt.Errorf("no completion choices returned")
+	}
+	return strings.TrimSpace(resp.Choices[0].Message.Content), resp.Usage.TotalTokens, nil
+}
+
+// ExplainHunk explains to a reviewer what one hunk of a file's change does
+// in a few sentences, returning it with the tokens used
+func (g *Generator) ExplainHunk(ctx context.Context, file, hunk string) (string, int, error) {
+	if len(hunk) > maxSummaryPatch {
+		hunk = hunk[:maxSummaryPatch] + "\n..."
+	}
+	resp, err := g.chat(ctx, []openai.ChatCompletionMessage{
+		{Role: "system", Content: "You explain code changes to reviewers. In two or three plain sentences, say what this hunk changes, how the new code works and what deserves a careful look. Don't praise or judge the change, and reply with nothing else."},
+		{Role: "user", Content: fmt.Sprintf("File: %s\n\n%s", file, hunk)},
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to explain %s: %w", file, err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", 0, fmt.Errorf("no completion choices returned")
+	}
+	return strings.TrimSpace(resp.Choices[0].Message.Content), resp.Usage.TotalTokens, nil
+}
+
+// Moderate checks text with the moderation API, returning the categories
+// it was flagged for, sorted, or none when it passed
+func (g *Generator) Moderate(ctx context.Context, text string) ([]string, error) {
+	if g.client == nil {
+		return nil, fmt.Errorf("moderation needs an OpenAI key")
+	}
+	resp, err := g.client.CreateModeration(ctx, text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to moderate text: %w", err)
+	}
+	flagged := make(map[string]bool)
+	for _, result := range resp.Results {
+		if !result.Flagged {
+			continue
+		}
+		flagged["flagged"] = true
+		for category, hit := range result.Categories {
+			if hit {
+				delete(flagged, "flagged")
+				flagged[category] = true
+			}
+		}
+	}
+	categories := make([]string, 0, len(flagged))
+	for category := range flagged {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	return categories, nil
+}
@@ -0,0 +1,100 @@
+package ai
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// maxPromptLanguages is how many language templates go into one prompt
+const maxPromptLanguages = 2
+
+// languageExtensions maps file extensions to the languages with prompt
+// templates
+var languageExtensions = map[string]string{
+	".go":     "Go",
+	".ts":     "TypeScript",
+	".tsx":    "TypeScript",
+	".js":     "JavaScript",
+	".jsx":    "JavaScript",
+	".mjs":    "JavaScript",
+	".py":     "Python",
+	".tf":     "Terraform",
+	".tfvars": "Terraform",
+}
+
+// languageAliases maps GitHub linguist names to template languages
+var languageAliases = map[string]string{
+	"HCL": "Terraform",
+}
+
+// languagePrompts ask for the details reviewers of each language care about
+var languagePrompts = map[string]string{
+	"Go": `For Go changes, call out changes to exported functions, types and interfaces,
+concurrency or context handling, error wrapping, and go.mod dependency updates.`,
+	"TypeScript": `For TypeScript changes, call out API route or endpoint changes, changes to
+exported types and component props, and package.json dependency updates.`,
+	"JavaScript": `For JavaScript changes, call out API route or endpoint changes, component
+and module interface changes, and package.json dependency updates.`,
+	"Python": `For Python changes, call out database migrations (Django, Alembic), API route
+and view changes (Django, Flask, FastAPI), settings changes, and requirements updates.`,
+	"Terraform": `For Terraform changes, summarize resources added, changed and destroyed,
+provider and module version bumps, and anything that replaces or deletes
+existing infrastructure.`,
+}
+
+// DetectLanguages returns the template languages of paths, most common
+// first. Files in other languages are ignored.
+func DetectLanguages(paths []string) []string {
+	counts := make(map[string]int)
+	for _, p := range paths {
+		if lang := languageExtensions[strings.ToLower(path.Ext(p))]; lang != "" {
+			counts[lang]++
+		}
+	}
+	return rankLanguages(counts)
+}
+
+// RepoLanguages returns the template languages in a GitHub languages
+// breakdown (bytes per language), largest first
+func RepoLanguages(bytes map[string]int) []string {
+	counts := make(map[string]int)
+	for name, n := range bytes {
+		if alias, ok := languageAliases[name]; ok {
+			name = alias
+		}
+		if _, ok := languagePrompts[name]; ok {
+			counts[name] += n
+		}
+	}
+	return rankLanguages(counts)
+}
+
+// rankLanguages sorts languages by count, then name
+func rankLanguages(counts map[string]int) []string {
+	langs := make([]string, 0, len(counts))
+	for lang := range counts {
+		langs = append(langs, lang)
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		if counts[langs[i]] != counts[langs[j]] {
+			return counts[langs[i]] > counts[langs[j]]
+		}
+		return langs[i] < langs[j]
+	})
+	return langs
+}
+
+// languageGuidance returns the prompt templates for the dominant languages
+func languageGuidance(langs []string) string {
+	var parts []string
+	for _, lang := range langs {
+		if prompt, ok := languagePrompts[lang]; ok {
+			parts = append(parts, prompt)
+		}
+		if len(parts) == maxPromptLanguages {
+			break
+		}
+	}
+	return strings.Join(parts, "\n")
+}
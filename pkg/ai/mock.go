@@ -0,0 +1,165 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/saint0x/ggquick/pkg/openai"
+)
+
+// ProviderMock is the deterministic fake provider
+const ProviderMock = "mock"
+
+var (
+	// mockCommit finds the commit message in a PR prompt
+	mockCommit = regexp.MustCompile(`with commit message: (.*)`)
+	// mockFile finds the files of the prompt diff
+	mockFile = regexp.MustCompile(`(?m)^--- (\S+)`)
+	// mockNumbered finds the numbered commit messages to rewrite
+	mockNumbered = regexp.MustCompile(`(?m)^\d+\. (.*)$`)
+	// mockListed finds the PRs of a release
+	mockListed = regexp.MustCompile(`(?m)^- (#\d+ .*)$`)
+	// mockHeading finds the conflicting files of a backport
+	mockHeading = regexp.MustCompile(`(?m)^### (.+)$`)
+	// mockField finds a labelled line like "Title: ..."
+	mockField = regexp.MustCompile(`(?m)^(Title|File|Original change): (.*)$`)
+	// mockChanged finds the files to order for review or split
+	mockChanged = regexp.MustCompile(`(?m)^- (\S+) \(`)
+	// mockFeedback finds the review comments to collect action items from
+	mockFeedback = regexp.MustCompile(`(?m)^- [^\s:]+.*?: (.+)$`)
+)
+
+// MockProvider answers without calling a model. Its replies depend only on
+// the prompt, so the same prompt always gets the same reply and a changed
+// prompt shows up as a changed reply.
+type MockProvider struct{}
+
+func (MockProvider) Name() string { return ProviderMock }
+
+func (MockProvider) Models([]string) []string { return []string{ProviderMock} }
+
+func (MockProvider) Complete(_ context.Context, _ string, messages []openai.ChatCompletionMessage) (*openai.ChatCompletionResponse, error) {
+	var system, user string
+	for _, m := range messages {
+		if m.Role == "system" {
+			system += m.Content
+		} else {
+			user += m.Content
+		}
+	}
+
+	var reply string
+	switch {
+	case strings.Contains(system, "pull request descriptions for reverts"):
+		reply = fmt.Sprintf("## Summary\n\nReverts %s.\n\n## What Is Reverted\n\nEverything the original change did.\n\n## Risk\n\nLow.", mockValue(user, "Original change"))
+	case strings.Contains(system, "pull request descriptions for backports"):
+		reply = "## Summary\n\nBackports the change unmodified.\n\n## Backport Notes\n\nNone."
+	case strings.Contains(system, "why a backport could not be applied"):
+		reply = mockList(mockHeading.FindAllStringSubmatch(user, -1), "- `%s` changed on both branches, resolve by hand.", "- The cherry-pick conflicts.")
+	case strings.Contains(system, "order in which files should be reviewed"):
+		reply = mockList(mockChanged.FindAllStringSubmatch(user, -1), "%s: Changed in this branch.", "")
+	case strings.Contains(system, "split a large pull request"):
+		reply = mockSplit(mockChanged.FindAllStringSubmatch(user, -1))
+	case strings.Contains(system, "group a branch's commits by topic"):
+		reply = "none"
+	case strings.Contains(system, "review feedback into action items"):
+		reply = mockList(mockFeedback.FindAllStringSubmatch(user, -1), "- [ ] %s", "none")
+	case strings.Contains(system, "You translate"):
+		// Echoing keeps the structure and placeholders a translation keeps
+		reply = user
+	case strings.Contains(system, "pull request descriptions"):
+		reply = mockDescription(user)
+	case strings.Contains(system, "release notes"):
+		reply = "## Other Changes\n\n" + mockList(mockListed.FindAllStringSubmatch(user, -1), "- %s", "- Maintenance.")
+	case strings.Contains(system, "triage GitHub issues"):
+		reply = fmt.Sprintf("Severity: medium\nComponent: none\nLabels: none\nSummary: %s", mockValue(user, "Title"))
+	case strings.Contains(system, "commit messages"):
+		reply = mockList(mockNumbered.FindAllStringSubmatch(user, -1), "chore: %s", "")
+	case strings.Contains(system, "first-time contributors"):
+		reply = "- Read the contributing guide before your next change."
+	case strings.Contains(system, "explain code changes to reviewers"):
+		reply = fmt.Sprintf("Reworks this part of %s.", mockValue(user, "File"))
+	case strings.Contains(system, "summarize code changes"):
+		reply = fmt.Sprintf("Updates %s.", mockValue(user, "File"))
+	default:
+		reply = "Mock reply to: " + firstLine(user)
+	}
+	tokens := EstimateTokens(messages)
+	return &openai.ChatCompletionResponse{
+		ID:      "mock",
+		Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Role: "assistant", Content: reply}}},
+		Usage:   openai.Usage{PromptTokens: tokens, CompletionTokens: len(reply) / charsPerToken, TotalTokens: tokens + len(reply)/charsPerToken},
+	}, nil
+}
+
+// mockDescription builds a PR description from the commit message and the
+// files in the prompt
+func mockDescription(prompt string) string {
+	subject := "Update the code"
+	if m := mockCommit.FindStringSubmatch(prompt); m != nil && strings.TrimSpace(m[1]) != "" {
+		subject = strings.TrimSpace(m[1])
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Summary\n\n%s.\n\n## Changes\n\n", strings.TrimSuffix(subject, "."))
+	files := mockFile.FindAllStringSubmatch(prompt, -1)
+	if len(files) == 0 {
+		b.WriteString("- No diff was provided.\n")
+	}
+	for _, f := range files {
+		fmt.Fprintf(&b, "- Updates `%s`.\n", f[1])
+	}
+	b.WriteString("\n## Test Plan\n\nNot run, this description comes from the mock provider.\n\n## Risk\n\nLow.\n\nConfidence: 0.5")
+	return b.String()
+}
+
+// mockSplit groups the changed files by top-level directory
+func mockSplit(matches [][]string) string {
+	var dirs []string
+	groups := make(map[string][]string)
+	for _, m := range matches {
+		dir, _, ok := strings.Cut(m[1], "/")
+		if !ok {
+			dir = "root files"
+		}
+		if groups[dir] == nil {
+			dirs = append(dirs, dir)
+		}
+		groups[dir] = append(groups[dir], "- "+m[1])
+	}
+	var b strings.Builder
+	for _, dir := range dirs {
+		fmt.Fprintf(&b, "## Update %s\n%s\n", dir, strings.Join(groups[dir], "\n"))
+	}
+	return b.String()
+}
+
+// mockValue returns a labelled line's value from a prompt
+func mockValue(prompt, label string) string {
+	for _, m := range mockField.FindAllStringSubmatch(prompt, -1) {
+		if m[1] == label {
+			return strings.TrimSpace(m[2])
+		}
+	}
+	return "the change"
+}
+
+// mockList formats each match as a line, or returns empty when there are
+// none
+func mockList(matches [][]string, format, empty string) string {
+	if len(matches) == 0 {
+		return empty
+	}
+	lines := make([]string, 0, len(matches))
+	for _, m := range matches {
+		lines = append(lines, fmt.Sprintf(format, strings.TrimSpace(m[1])))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// firstLine returns the first line of s
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
@@ -0,0 +1,308 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/anthropic"
+	"github.com/saint0x/ggquick/pkg/openai"
+)
+
+// Provider names
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAzure     = "azure"
+	ProviderAnthropic = "anthropic"
+	ProviderLocal     = "local"
+)
+
+// Circuit breaker settings: a provider failing breakerFailures times in a
+// row is skipped for breakerCooldown, then tried again
+const (
+	breakerFailures = 3
+	breakerCooldown = time.Minute
+)
+
+// Defaults for providers configured from the environment
+const (
+	defaultAzureVersion   = "2024-06-01"
+	defaultAnthropicModel = "claude-3-5-sonnet-latest"
+	anthropicMaxTokens    = 4096
+)
+
+// Provider is an AI backend the generator can fail over to
+type Provider interface {
+	Name() string
+	// Models returns the models to try for the requested ones; providers
+	// with a fixed model return just that
+	Models(requested []string) []string
+	Complete(ctx context.Context, model string, messages []openai.ChatCompletionMessage) (*openai.ChatCompletionResponse, error)
+}
+
+// ProviderHealth is a provider's circuit breaker state
+type ProviderHealth struct {
+	Name      string    `json:"name"`
+	Healthy   bool      `json:"healthy"`
+	Failures  int       `json:"consecutive_failures,omitempty"`
+	OpenUntil time.Time `json:"open_until,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+	Requests  int       `json:"requests"`
+	Errors    int       `json:"errors"`
+}
+
+// breaker tracks a provider's health
+type breaker struct {
+	Provider
+	mu     sync.Mutex
+	health ProviderHealth
+}
+
+// available reports whether the circuit is closed or its cooldown is over
+func (b *breaker) available(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !now.Before(b.health.OpenUntil)
+}
+
+// record counts a call, opening the circuit after repeated failures
+func (b *breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.health.Requests++
+	if err == nil {
+		b.health.Failures = 0
+		b.health.OpenUntil = time.Time{}
+		return
+	}
+	b.health.Errors++
+	b.health.Failures++
+	b.health.LastError = err.Error()
+	if b.health.Failures >= breakerFailures {
+		b.health.OpenUntil = time.Now().Add(breakerCooldown)
+	}
+}
+
+// snapshot returns the provider's health
+func (b *breaker) snapshot() ProviderHealth {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	h := b.health
+	h.Name = b.Name()
+	h.Healthy = !time.Now().Before(h.OpenUntil)
+	if h.Healthy {
+		h.OpenUntil = time.Time{}
+	}
+	return h
+}
+
+// openAIProvider serves OpenAI, Azure OpenAI and OpenAI compatible local
+// servers
+type openAIProvider struct {
+	name   string
+	client *openai.Client
+	model  string // replaces the requested models when set
+}
+
+func (p *openAIProvider) Name() string { return p.name }
+
+func (p *openAIProvider) Models(requested []string) []string {
+	if p.model != "" {
+		return []string{p.model}
+	}
+	return requested
+}
+
+func (p *openAIProvider) Complete(ctx context.Context, model string, messages []openai.ChatCompletionMessage) (*openai.ChatCompletionResponse, error) {
+	return p.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{Model: model, Messages: messages})
+}
+
+// anthropicProvider adapts the Anthropic messages API
+type anthropicProvider struct {
+	client *anthropic.Client
+	model  string
+}
+
+func (p *anthropicProvider) Name() string { return ProviderAnthropic }
+
+func (p *anthropicProvider) Models([]string) []string { return []string{p.model} }
+
+func (p *anthropicProvider) Complete(ctx context.Context, model string, messages []openai.ChatCompletionMessage) (*openai.ChatCompletionResponse, error) {
+	req := anthropic.MessageRequest{Model: model, MaxTokens: anthropicMaxTokens}
+	var system []string
+	for _, m := range messages {
+		if m.Role == "system" {
+			system = append(system, m.Content)
+			continue
+		}
+		req.Messages = append(req.Messages, anthropic.Message{Role: m.Role, Content: m.Content})
+	}
+	req.System = strings.Join(system, "\n\n")
+
+	resp, err := p.client.CreateMessage(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	var text strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	return &openai.ChatCompletionResponse{
+		ID:      resp.ID,
+		Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Role: "assistant", Content: text.String()}}},
+		Usage: openai.Usage{
+			PromptTokens:     resp.Usage.InputTokens,
+			CompletionTokens: resp.Usage.OutputTokens,
+			TotalTokens:      resp.Usage.InputTokens + resp.Usage.OutputTokens,
+		},
+	}, nil
+}
+
+// ProvidersFromEnv builds the failover chain GGQUICK_AI_PROVIDERS names,
+// like "openai,azure,anthropic,local", defaulting to OpenAI alone
+func ProvidersFromEnv(openAIKey string) ([]Provider, error) {
+	names := os.Getenv("GGQUICK_AI_PROVIDERS")
+	if names == "" {
+		names = ProviderOpenAI
+	}
+
+	var providers []Provider
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		switch name {
+		case ProviderOpenAI:
+			if openAIKey == "" {
+				return nil, fmt.Errorf("OPENAI_API_KEY not configured")
+			}
+			providers = append(providers, &openAIProvider{name: name, client: openai.NewClient(openAIKey)})
+		case ProviderAzure:
+			endpoint, key := os.Getenv("AZURE_OPENAI_ENDPOINT"), os.Getenv("AZURE_OPENAI_API_KEY")
+			if endpoint == "" || key == "" {
+				return nil, fmt.Errorf("AZURE_OPENAI_ENDPOINT and AZURE_OPENAI_API_KEY are required for the azure provider")
+			}
+			version := os.Getenv("AZURE_OPENAI_API_VERSION")
+			if version == "" {
+				version = defaultAzureVersion
+			}
+			providers = append(providers, &openAIProvider{
+				name:   name,
+				client: openai.NewAzureClient(endpoint, key, version),
+				model:  os.Getenv("AZURE_OPENAI_DEPLOYMENT"),
+			})
+		case ProviderAnthropic:
+			key := os.Getenv("ANTHROPIC_API_KEY")
+			if key == "" {
+				return nil, fmt.Errorf("ANTHROPIC_API_KEY is required for the anthropic provider")
+			}
+			model := os.Getenv("ANTHROPIC_MODEL")
+			if model == "" {
+				model = defaultAnthropicModel
+			}
+			providers = append(providers, &anthropicProvider{client: anthropic.NewClient(key), model: model})
+		case ProviderLocal:
+			url, model := os.Getenv("GGQUICK_LOCAL_AI_URL"), os.Getenv("GGQUICK_LOCAL_AI_MODEL")
+			if url == "" || model == "" {
+				return nil, fmt.Errorf("GGQUICK_LOCAL_AI_URL and GGQUICK_LOCAL_AI_MODEL are required for the local provider")
+			}
+			providers = append(providers, &openAIProvider{
+				name:   name,
+				client: openai.NewClientWithBaseURL(url, os.Getenv("GGQUICK_LOCAL_AI_KEY")),
+				model:  model,
+			})
+		default:
+			return nil, fmt.Errorf("unknown AI provider %q", name)
+		}
+	}
+	return providers, nil
+}
+
+// SetProviders replaces the failover chain, first provider first
+func (g *Generator) SetProviders(providers []Provider) {
+	g.providers = make([]*breaker, 0, len(providers))
+	for _, p := range providers {
+		g.providers = append(g.providers, &breaker{Provider: p})
+	}
+}
+
+// Health returns the circuit breaker state of each provider in order
+func (g *Generator) Health() []ProviderHealth {
+	health := make([]ProviderHealth, 0, len(g.providers))
+	for _, b := range g.providers {
+		health = append(health, b.snapshot())
+	}
+	return health
+}
+
+// completion is a chat response and what produced it
+type completion struct {
+	*openai.ChatCompletionResponse
+	Provider string
+	Model    string
+}
+
+// complete runs a chat through the provider chain. Each provider tries
+// its models in order; when all fail, or its circuit is open, the next
+// provider is tried. If every circuit is open they are all tried anyway.
+func (g *Generator) complete(ctx context.Context, models []string, messages []openai.ChatCompletionMessage) (*completion, error) {
+	if len(models) == 0 {
+		models = []string{openai.GPT4}
+	}
+	now := time.Now()
+	var candidates []*breaker
+	for _, b := range g.providers {
+		if b.available(now) {
+			candidates = append(candidates, b)
+		} else {
+			g.logger.Debug("Skipping AI provider %s, circuit open", b.Name())
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = g.providers
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no AI provider configured")
+	}
+
+	var err error
+	for i, b := range candidates {
+		tried := b.Models(models)
+		for j, model := range tried {
+			var resp *openai.ChatCompletionResponse
+			resp, err = b.Complete(ctx, model, messages)
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			b.record(err)
+			if err == nil {
+				return &completion{ChatCompletionResponse: resp, Provider: b.Name(), Model: model}, nil
+			}
+			if j < len(tried)-1 {
+				g.logger.Warning("Model %s failed on %s, falling back to %s: %v", model, b.Name(), tried[j+1], err)
+			}
+		}
+		if i < len(candidates)-1 {
+			g.logger.Warning("AI provider %s failed, failing over to %s: %v", b.Name(), candidates[i+1].Name(), err)
+		}
+	}
+	return nil, err
+}
+
+// chat runs a chat with the default model through the provider chain
+func (g *Generator) chat(ctx context.Context, messages []openai.ChatCompletionMessage) (*openai.ChatCompletionResponse, error) {
+	c, err := g.complete(ctx, nil, messages)
+	if err != nil {
+		return nil, err
+	}
+	return c.ChatCompletionResponse, nil
+}
@@ -0,0 +1,91 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/saint0x/ggquick/pkg/openai"
+)
+
+// maxOrderExcerpt caps the patch excerpt of each file sent to order files
+const maxOrderExcerpt = 600
+
+// listMarker matches the bullet or number a reply line may start with
+var listMarker = regexp.MustCompile(`^\s*(?:[-*]|\d+\.)?\s*`)
+
+// ReviewFile is a changed file to place in the review order
+type ReviewFile struct {
+	Path      string
+	Status    string // added, modified, removed or renamed
+	Additions int
+	Deletions int
+	Summary   string // the file's summary, when one was made
+	Patch     string
+}
+
+// ReviewStep is a file in the suggested review order and why it's there
+type ReviewStep struct {
+	Path      string
+	Rationale string
+}
+
+// ReviewOrder proposes the order a reviewer should read files in, entry
+// points first and tests last, with a line on each. Every file is in the
+// order exactly once; files the model leaves out keep their place at the
+// end. It returns the order with the tokens used.
+func (g *Generator) ReviewOrder(ctx context.Context, files []ReviewFile) ([]ReviewStep, int, error) {
+	var prompt strings.Builder
+	prompt.WriteString("Changed files:\n")
+	for _, f := range files {
+		fmt.Fprintf(&prompt, "\n- %s (%s, +%d -%d)\n", f.Path, f.Status, f.Additions, f.Deletions)
+		if f.Summary != "" {
+			fmt.Fprintf(&prompt, "  %s\n", f.Summary)
+		} else if excerpt := strings.TrimSpace(f.Patch); excerpt != "" {
+			if len(excerpt) > maxOrderExcerpt {
+				excerpt = excerpt[:maxOrderExcerpt] + "\n..."
+			}
+			fmt.Fprintf(&prompt, "```diff\n%s\n```\n", excerpt)
+		}
+	}
+
+	system := `You plan the order in which files should be reviewed.
+Put entry points and the changes everything else depends on first, then the code they call, then configuration and docs, and tests last.
+Reply with one line per file, in review order, as "path: why it comes here" in a short sentence, and nothing else. List every file exactly once.`
+	resp, err := g.chat(ctx, []openai.ChatCompletionMessage{
+		{Role: "system", Content: system},
+		{Role: "user", Content: prompt.String()},
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to order files for review: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, 0, fmt.Errorf("no completion choices returned")
+	}
+
+	known := make(map[string]bool, len(files))
+	for _, f := range files {
+		known[f.Path] = true
+	}
+	var steps []ReviewStep
+	for _, line := range strings.Split(resp.Choices[0].Message.Content, "\n") {
+		line = listMarker.ReplaceAllString(line, "")
+		path, rationale, ok := strings.Cut(line, ": ")
+		path = strings.Trim(path, "`")
+		if !ok || !known[path] {
+			continue
+		}
+		known[path] = false
+		steps = append(steps, ReviewStep{Path: path, Rationale: strings.TrimSpace(rationale)})
+	}
+	if len(steps) == 0 {
+		return nil, resp.Usage.TotalTokens, fmt.Errorf("unexpected review order reply")
+	}
+	for _, f := range files {
+		if known[f.Path] {
+			steps = append(steps, ReviewStep{Path: f.Path})
+		}
+	}
+	return steps, resp.Usage.TotalTokens, nil
+}
@@ -0,0 +1,83 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/saint0x/ggquick/pkg/openai"
+)
+
+// SplitGroup is one smaller PR of a proposed split
+type SplitGroup struct {
+	Title string
+	Files []string
+}
+
+// SplitPlan proposes splitting a large change into smaller PRs of related
+// files, in the order they could merge. Files the model leaves out are
+// put in a final untitled group. It returns the groups with the tokens
+// used.
+func (g *Generator) SplitPlan(ctx context.Context, files []ReviewFile) ([]SplitGroup, int, error) {
+	var prompt strings.Builder
+	prompt.WriteString("Changed files:\n")
+	for _, f := range files {
+		fmt.Fprintf(&prompt, "\n- %s (%s, +%d -%d)", f.Path, f.Status, f.Additions, f.Deletions)
+		if f.Summary != "" {
+			fmt.Fprintf(&prompt, ": %s", f.Summary)
+		}
+	}
+
+	system := `You plan how to split a large pull request into smaller ones.
+Group files that belong together, such as a change and its tests or the packages of one feature, into two to six pull requests that can each be reviewed and merged alone, in the order they should merge.
+Reply with a "## " heading holding a short title for each pull request, followed by its files as "- path" lines, and nothing else. List every file exactly once.`
+	resp, err := g.chat(ctx, []openai.ChatCompletionMessage{
+		{Role: "system", Content: system},
+		{Role: "user", Content: prompt.String()},
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to plan a split: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, 0, fmt.Errorf("no completion choices returned")
+	}
+
+	known := make(map[string]bool, len(files))
+	for _, f := range files {
+		known[f.Path] = true
+	}
+	var groups []SplitGroup
+	for _, line := range strings.Split(resp.Choices[0].Message.Content, "\n") {
+		line = strings.TrimSpace(line)
+		if title, ok := strings.CutPrefix(line, "## "); ok {
+			groups = append(groups, SplitGroup{Title: strings.TrimSpace(title)})
+			continue
+		}
+		path := strings.Trim(listMarker.ReplaceAllString(line, ""), "`")
+		if len(groups) == 0 || !known[path] {
+			continue
+		}
+		known[path] = false
+		groups[len(groups)-1].Files = append(groups[len(groups)-1].Files, path)
+	}
+
+	var planned []SplitGroup
+	for _, group := range groups {
+		if len(group.Files) > 0 {
+			planned = append(planned, group)
+		}
+	}
+	if len(planned) < 2 {
+		return nil, resp.Usage.TotalTokens, fmt.Errorf("unexpected split reply")
+	}
+	var rest SplitGroup
+	for _, f := range files {
+		if known[f.Path] {
+			rest.Files = append(rest.Files, f.Path)
+		}
+	}
+	if len(rest.Files) > 0 {
+		planned = append(planned, rest)
+	}
+	return planned, resp.Usage.TotalTokens, nil
+}
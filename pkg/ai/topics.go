@@ -0,0 +1,79 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/saint0x/ggquick/pkg/openai"
+)
+
+// Topic is a group of a branch's commits about one thing, with a title
+// for a PR of its own
+type Topic struct {
+	Title    string
+	Subjects []string
+}
+
+// CommitTopics groups a branch's commit messages by topic. It returns nil
+// when the commits are about one thing, or the model can't tell them
+// apart clearly, along with the tokens used. Commits the model leaves out
+// aren't in any topic.
+func (g *Generator) CommitTopics(ctx context.Context, messages []string) ([]Topic, int, error) {
+	subjects := make([]string, 0, len(messages))
+	var prompt strings.Builder
+	prompt.WriteString("Commits on the branch, oldest first:\n")
+	for i, msg := range messages {
+		subject, body, _ := strings.Cut(strings.TrimSpace(msg), "\n")
+		subjects = append(subjects, strings.TrimSpace(subject))
+		fmt.Fprintf(&prompt, "\n%d. %s", i+1, strings.TrimSpace(subject))
+		if body = strings.TrimSpace(body); body != "" {
+			if len(body) > 200 {
+				body = body[:200] + "..."
+			}
+			fmt.Fprintf(&prompt, "\n   %s", strings.ReplaceAll(body, "\n", " "))
+		}
+	}
+
+	system := `You group a branch's commits by topic.
+Only split them when they clearly cover unrelated work, such as a bug fix and an unrelated feature. Refactors, tests and fixups of a change belong with it.
+If the commits are about one thing, reply "none". Otherwise reply with a "## " heading holding a short pull request title for each topic, followed by the numbers of its commits as "- N" lines, and nothing else. List every commit exactly once.`
+	resp, err := g.chat(ctx, []openai.ChatCompletionMessage{
+		{Role: "system", Content: system},
+		{Role: "user", Content: prompt.String()},
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to group commits: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, 0, fmt.Errorf("no completion choices returned")
+	}
+
+	placed := make([]bool, len(subjects))
+	var topics []Topic
+	for _, line := range strings.Split(resp.Choices[0].Message.Content, "\n") {
+		line = strings.TrimSpace(line)
+		if title, ok := strings.CutPrefix(line, "## "); ok {
+			topics = append(topics, Topic{Title: strings.TrimSpace(title)})
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "-")))
+		if err != nil || len(topics) == 0 || n < 1 || n > len(subjects) || placed[n-1] {
+			continue
+		}
+		placed[n-1] = true
+		topics[len(topics)-1].Subjects = append(topics[len(topics)-1].Subjects, subjects[n-1])
+	}
+
+	var grouped []Topic
+	for _, topic := range topics {
+		if len(topic.Subjects) > 0 {
+			grouped = append(grouped, topic)
+		}
+	}
+	if len(grouped) < 2 {
+		return nil, resp.Usage.TotalTokens, nil
+	}
+	return grouped, resp.Usage.TotalTokens, nil
+}
@@ -0,0 +1,61 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/saint0x/ggquick/pkg/openai"
+)
+
+// maxTranslateBody caps the body sent for translation
+const maxTranslateBody = 16000
+
+// fencedBlockPattern matches fenced code blocks, which are kept out of the
+// prompt so the model can't alter them
+var fencedBlockPattern = regexp.MustCompile("(?ms)^[ \t]*(```|~~~).*?^[ \t]*(```|~~~)[ \t]*$")
+
+// codePlaceholder stands in for the i-th code block
+func codePlaceholder(i int) string {
+	return fmt.Sprintf("@@GGQUICK_CODE_%d@@", i)
+}
+
+// TranslateBody translates a PR description into the language with tag
+// lang, keeping its Markdown structure and code blocks, returning it with
+// the tokens used
+func (g *Generator) TranslateBody(ctx context.Context, body, lang string) (string, int, error) {
+	var blocks []string
+	masked := fencedBlockPattern.ReplaceAllStringFunc(body, func(block string) string {
+		blocks = append(blocks, block)
+		return codePlaceholder(len(blocks) - 1)
+	})
+	if len(masked) > maxTranslateBody {
+		return "", 0, fmt.Errorf("description too long to translate (%d bytes)", len(masked))
+	}
+
+	system := fmt.Sprintf(`You translate GitHub pull request descriptions into the language with tag %q.
+Keep the Markdown structure exactly: headings, lists, tables, links, checkboxes and HTML comments stay where they are.
+Leave inline code, identifiers, file paths, URLs, @mentions and #references untranslated, and keep every @@GGQUICK_CODE_n@@ placeholder unchanged on its own line.
+Reply with the translated description and nothing else.`, lang)
+	resp, err := g.chat(ctx, []openai.ChatCompletionMessage{
+		{Role: "system", Content: system},
+		{Role: "user", Content: masked},
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to translate description: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", 0, fmt.Errorf("no completion choices returned")
+	}
+
+	translated := strings.TrimSpace(resp.Choices[0].Message.Content)
+	for i, block := range blocks {
+		placeholder := codePlaceholder(i)
+		if !strings.Contains(translated, placeholder) {
+			return "", resp.Usage.TotalTokens, fmt.Errorf("translation dropped a code block")
+		}
+		translated = strings.Replace(translated, placeholder, block, 1)
+	}
+	return translated, resp.Usage.TotalTokens, nil
+}
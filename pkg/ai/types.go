@@ -2,9 +2,32 @@ package ai
 
 // RepoInfo contains repository information
 type RepoInfo struct {
+	Repo          string // owner/name
 	BranchName    string
 	CommitMessage string
 	Changes       map[string]Change
+	Notes         []string    // extra context for the model, added by pipeline stages
+	Languages     []string    // dominant languages, most common first
+	Instructions  string      // org and repository guidance for the model
+	Diff          []DiffChunk // patches, most relevant files first
+	Omitted       []string    // changed files left out of Diff for length
+	Summaries     []FileSummary
+	Topics        []Topic  // unrelated topics the commits cover, when more than one
+	Models        []string // tried in order on provider errors, default gpt-4
+}
+
+// FileSummary is a model's one-line summary of a file's change
+type FileSummary struct {
+	File    string
+	Summary string
+}
+
+// DiffChunk is the part of a file's patch sent to the model
+type DiffChunk struct {
+	File    string
+	Patch   string
+	Partial bool   // some hunks were left out
+	Summary string // replaces the patch of a lockfile or generated file
 }
 
 // Change represents a file change
@@ -18,4 +41,76 @@ type Change struct {
 type PRContent struct {
 	Title       string
 	Description string
+	TokensUsed  int
+	// Model and PromptVersion identify what wrote a generated description,
+	// empty when it was built from a template
+	Model         string
+	Provider      string
+	PromptVersion string
+	// Confidence is the model's rating of its description from 0 to 1
+	Confidence float64
+}
+
+// RevertInfo describes a change being reverted
+type RevertInfo struct {
+	Repo         string // owner/name
+	SHA          string // commit being reverted
+	Title        string // original PR title or commit subject
+	Body         string // original PR description, if any
+	Reason       string // why it is reverted, from the requester
+	Instructions string // org and repository guidance for the model
+}
+
+// BackportInfo describes a merged PR being backported to another branch
+type BackportInfo struct {
+	Repo         string // owner/name
+	Number       int    // original PR
+	Title        string
+	Body         string
+	Target       string // branch backported to
+	Conflicts    []ConflictFile
+	Instructions string // org and repository guidance for the model
+}
+
+// ConflictFile is a file the PR and the target branch both changed
+type ConflictFile struct {
+	Path        string
+	Patch       string // the PR's change
+	TargetPatch string // changes on the target branch since the PR's base
+}
+
+// ReleaseInfo describes the PRs going into a release
+type ReleaseInfo struct {
+	Repo         string // owner/name
+	Version      string // e.g. v1.4.0
+	Previous     string // previous version, if any
+	PRs          []ReleasePR
+	Instructions string // org and repository guidance for the model
+}
+
+// ReleasePR is a merged PR included in a release
+type ReleasePR struct {
+	Number int
+	Title  string
+	Author string
+	Labels []string
+}
+
+// IssueInfo describes a newly opened issue to triage
+type IssueInfo struct {
+	Repo         string // owner/name
+	Title        string
+	Body         string
+	Components   []string // components the issue may affect
+	Labels       []string // labels the model may suggest
+	Instructions string   // org and repository guidance for the model
+}
+
+// Triage is the model's read of an issue
+type Triage struct {
+	Severity   string // low, medium, high or critical
+	Component  string // one of the issue's components, or ""
+	Labels     []string
+	Summary    string
+	TokensUsed int
 }
@@ -0,0 +1,310 @@
+package analyze
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// APISpec maps each element of an API definition, such as
+// "rpc Greeter.SayHello" or "endpoint GET /users", to its signature
+type APISpec map[string]string
+
+// APIChange is an element added, removed or changed between two specs
+type APIChange struct {
+	Kind     string // endpoint, schema, field, rpc, message, enum, value
+	Name     string
+	Change   string // added, removed, changed
+	Before   string
+	After    string
+	Breaking bool
+}
+
+// IsProto reports whether file is a Protocol Buffers definition
+func IsProto(file string) bool {
+	return strings.HasSuffix(strings.ToLower(file), ".proto")
+}
+
+// IsOpenAPI reports whether file looks like an OpenAPI or Swagger spec,
+// judged by its extension and content
+func IsOpenAPI(file, content string) bool {
+	switch strings.ToLower(path.Ext(file)) {
+	case ".yaml", ".yml", ".json":
+	default:
+		return false
+	}
+	return openAPIMarker.MatchString(content)
+}
+
+// openAPIMarker finds the top-level openapi or swagger version key
+var openAPIMarker = regexp.MustCompile(`(?m)^\s*["']?(openapi|swagger)["']?\s*:`)
+
+// DiffAPI compares two specs. Removals and signature changes are
+// breaking; additions are not.
+func DiffAPI(before, after APISpec) []APIChange {
+	var changes []APIChange
+	for key, sig := range after {
+		kind, name, _ := strings.Cut(key, " ")
+		old, ok := before[key]
+		switch {
+		case !ok:
+			changes = append(changes, APIChange{Kind: kind, Name: name, Change: "added", After: sig})
+		case old != sig:
+			changes = append(changes, APIChange{Kind: kind, Name: name, Change: "changed", Before: old, After: sig, Breaking: true})
+		}
+	}
+	for key, sig := range before {
+		if _, ok := after[key]; !ok {
+			kind, name, _ := strings.Cut(key, " ")
+			changes = append(changes, APIChange{Kind: kind, Name: name, Change: "removed", Before: sig, Breaking: true})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Breaking != changes[j].Breaking {
+			return changes[i].Breaking
+		}
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+// protoToken splits proto source into identifiers, numbers, strings and
+// punctuation
+var protoToken = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'|[A-Za-z_.][\w.]*|-?\d[\w.]*|\S`)
+
+// protoComment matches line and block comments
+var protoComment = regexp.MustCompile(`(?s)//[^\n]*|/\*.*?\*/`)
+
+// ParseProto reads the services, RPCs, messages, fields and enum values of
+// a .proto file. Options are ignored.
+func ParseProto(src string) APISpec {
+	spec := APISpec{}
+	tokens := protoToken.FindAllString(protoComment.ReplaceAllString(src, ""), -1)
+
+	type scope struct{ kind, name string }
+	var stack []scope
+	// messageName qualifies a nested declaration with its enclosing messages
+	messageName := func(name string) string {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].kind == "message" {
+				return stack[i].name + "." + name
+			}
+		}
+		return name
+	}
+	// skipStatement advances past the next ";" or balanced "{ }" block
+	skipStatement := func(i int) int {
+		depth := 0
+		for ; i < len(tokens); i++ {
+			switch tokens[i] {
+			case "{":
+				depth++
+			case "}":
+				depth--
+				if depth <= 0 {
+					return i
+				}
+			case ";":
+				if depth == 0 {
+					return i
+				}
+			}
+		}
+		return i
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		var top scope
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+
+		switch {
+		case tok == "}":
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case tok == ";":
+		case tok == "syntax" || tok == "package" || tok == "import" || tok == "option" ||
+			tok == "reserved" || tok == "extensions" || tok == "extend":
+			i = skipStatement(i)
+		case (tok == "message" || tok == "enum" || tok == "service") && i+2 < len(tokens) && tokens[i+2] == "{":
+			name := tokens[i+1]
+			if tok != "service" {
+				name = messageName(name)
+			}
+			spec[tok+" "+name] = ""
+			stack = append(stack, scope{tok, name})
+			i += 2
+		case tok == "oneof" && i+2 < len(tokens) && tokens[i+2] == "{":
+			stack = append(stack, scope{"oneof", top.name})
+			i += 2
+		case tok == "rpc" && top.kind == "service":
+			// rpc Name ( [stream] Req ) returns ( [stream] Resp )
+			var parts []string
+			j := i + 1
+			for ; j < len(tokens) && tokens[j] != ";" && tokens[j] != "{"; j++ {
+				if t := tokens[j]; t != "(" && t != ")" && t != "returns" {
+					parts = append(parts, t)
+				}
+			}
+			if len(parts) >= 3 {
+				name, req, resp := parts[0], parts[1], parts[len(parts)-1]
+				if req == "stream" && len(parts) > 3 {
+					req = "stream " + parts[2]
+				}
+				if len(parts) >= 4 && parts[len(parts)-2] == "stream" {
+					resp = "stream " + resp
+				}
+				spec["rpc "+top.name+"."+name] = req + " -> " + resp
+			}
+			i = skipStatement(j)
+		case top.kind == "enum":
+			// NAME = number [options];
+			if i+2 < len(tokens) && tokens[i+1] == "=" {
+				spec["value "+top.name+"."+tok] = tokens[i+2]
+			}
+			i = skipStatement(i)
+		case top.kind == "message" || top.kind == "oneof":
+			// [label] type name = number [options];
+			j := skipStatement(i)
+			field := tokens[i:j]
+			label := ""
+			if len(field) > 0 && (field[0] == "repeated" || field[0] == "optional" || field[0] == "required") {
+				label, field = field[0]+" ", field[1:]
+			}
+			typ := ""
+			if len(field) > 0 && field[0] == "map" {
+				end := 0
+				for end < len(field) && field[end] != ">" {
+					end++
+				}
+				if end < len(field) {
+					typ, field = strings.Join(field[:end+1], ""), field[end+1:]
+				}
+			} else if len(field) > 0 {
+				typ, field = field[0], field[1:]
+			}
+			if typ != "" && len(field) >= 3 && field[1] == "=" {
+				spec["field "+top.name+"."+field[0]] = fmt.Sprintf("%s%s = %s", label, typ, field[2])
+			}
+			i = j
+		case i+1 < len(tokens) && tokens[i+1] == "{":
+			stack = append(stack, scope{"block", top.name})
+			i++
+		}
+	}
+	return spec
+}
+
+// httpMethods are the operation keys under an OpenAPI path
+var httpMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+// ParseOpenAPI reads the endpoints, schemas and schema properties of an
+// OpenAPI 3 or Swagger 2 document in JSON or YAML
+func ParseOpenAPI(src string) (APISpec, error) {
+	if trimmed := strings.TrimSpace(src); strings.HasPrefix(trimmed, "{") {
+		var doc map[string]interface{}
+		if err := json.Unmarshal([]byte(trimmed), &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse spec: %w", err)
+		}
+		return openAPIFromJSON(doc), nil
+	}
+	return openAPIFromYAML(src), nil
+}
+
+// openAPIFromJSON walks a decoded JSON document
+func openAPIFromJSON(doc map[string]interface{}) APISpec {
+	spec := APISpec{}
+	paths, _ := doc["paths"].(map[string]interface{})
+	for p, ops := range paths {
+		ops, _ := ops.(map[string]interface{})
+		for method := range ops {
+			if httpMethods[strings.ToLower(method)] {
+				spec["endpoint "+strings.ToUpper(method)+" "+p] = ""
+			}
+		}
+	}
+
+	schemas, _ := doc["definitions"].(map[string]interface{})
+	if components, ok := doc["components"].(map[string]interface{}); ok {
+		schemas, _ = components["schemas"].(map[string]interface{})
+	}
+	for name, schema := range schemas {
+		spec["schema "+name] = ""
+		schema, _ := schema.(map[string]interface{})
+		props, _ := schema["properties"].(map[string]interface{})
+		for field, prop := range props {
+			prop, _ := prop.(map[string]interface{})
+			sig, _ := prop["type"].(string)
+			if ref, ok := prop["$ref"].(string); ok {
+				sig = ref
+			}
+			spec["field "+name+"."+field] = sig
+		}
+	}
+	return spec
+}
+
+// yamlKey matches a "key:" line, with an optional inline value
+var yamlKey = regexp.MustCompile(`^(\s*)("[^"]*"|'[^']*'|[^\s:#'"-][^:#]*?)\s*:(?:\s+(.*))?$`)
+
+// openAPIFromYAML scans a YAML document by indentation. It understands
+// block-style mappings, which is how specs are almost always written.
+func openAPIFromYAML(src string) APISpec {
+	spec := APISpec{}
+	type key struct {
+		indent int
+		name   string
+	}
+	var stack []key
+	for _, line := range strings.Split(src, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "-") {
+			continue
+		}
+		m := yamlKey.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		indent, name := len(m[1]), strings.Trim(m[2], `"'`)
+		value := strings.Trim(strings.TrimSpace(strings.SplitN(m[3], " #", 2)[0]), `"'`)
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, key{indent, name})
+
+		names := make([]string, len(stack))
+		for i, k := range stack {
+			names[i] = k.name
+		}
+		// Schemas live under components.schemas (3.x) or definitions (2.0)
+		schemaPath := names
+		if len(names) >= 2 && names[0] == "components" && names[1] == "schemas" {
+			schemaPath = append([]string{"definitions"}, names[2:]...)
+		}
+
+		switch {
+		case len(names) == 3 && names[0] == "paths" && httpMethods[strings.ToLower(names[2])]:
+			spec["endpoint "+strings.ToUpper(names[2])+" "+names[1]] = ""
+		case len(schemaPath) == 2 && schemaPath[0] == "definitions":
+			spec["schema "+schemaPath[1]] = ""
+		case len(schemaPath) == 4 && schemaPath[0] == "definitions" && schemaPath[2] == "properties":
+			spec["field "+schemaPath[1]+"."+schemaPath[3]] = ""
+		case len(schemaPath) == 5 && schemaPath[0] == "definitions" && schemaPath[2] == "properties" &&
+			(schemaPath[4] == "type" || schemaPath[4] == "$ref"):
+			spec["field "+schemaPath[1]+"."+schemaPath[3]] = value
+		}
+	}
+	return spec
+}
@@ -0,0 +1,158 @@
+package analyze
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FileDiff is a changed file with its unified diff patch
+type FileDiff struct {
+	Filename  string
+	Status    string // added, modified, removed, renamed
+	Patch     string
+	Additions int
+	Deletions int
+}
+
+// BreakingChange is a likely incompatible API change
+type BreakingChange struct {
+	File   string
+	Kind   string // removed, signature
+	Symbol string
+	Detail string
+}
+
+// BreakingDetector finds breaking changes for one language
+type BreakingDetector interface {
+	// Match reports whether the detector handles the file
+	Match(filename string) bool
+	// Detect inspects a single file diff
+	Detect(diff FileDiff) []BreakingChange
+}
+
+var (
+	detectorsMu sync.RWMutex
+	detectors   = map[string]BreakingDetector{
+		"go": goDetector{},
+	}
+)
+
+// RegisterDetector adds or replaces the detector for a language
+func RegisterDetector(language string, d BreakingDetector) {
+	detectorsMu.Lock()
+	defer detectorsMu.Unlock()
+	detectors[language] = d
+}
+
+// DetectBreaking runs the detectors for the given languages, or all
+// registered detectors when languages is empty
+func DetectBreaking(diffs []FileDiff, languages ...string) []BreakingChange {
+	detectorsMu.RLock()
+	var active []BreakingDetector
+	if len(languages) == 0 {
+		for _, d := range detectors {
+			active = append(active, d)
+		}
+	} else {
+		for _, lang := range languages {
+			if d, ok := detectors[lang]; ok {
+				active = append(active, d)
+			}
+		}
+	}
+	detectorsMu.RUnlock()
+
+	var changes []BreakingChange
+	for _, diff := range diffs {
+		for _, d := range active {
+			if d.Match(diff.Filename) {
+				changes = append(changes, d.Detect(diff)...)
+			}
+		}
+	}
+	return changes
+}
+
+// goDecl matches exported top-level Go declarations on a diff line
+var goDecl = regexp.MustCompile(`^(func(?:\s*\([^)]*\))?|type|const|var)\s+([A-Z]\w*)(.*)$`)
+
+// goDetector flags removed exported identifiers and changed signatures
+type goDetector struct{}
+
+func (goDetector) Match(filename string) bool {
+	return strings.HasSuffix(filename, ".go") &&
+		!strings.HasSuffix(filename, "_test.go") &&
+		!strings.Contains("/"+filename, "/internal/") &&
+		!strings.HasPrefix(filename, "cmd/")
+}
+
+func (goDetector) Detect(diff FileDiff) []BreakingChange {
+	if diff.Status == "removed" {
+		return []BreakingChange{{
+			File:   diff.Filename,
+			Kind:   "removed",
+			Detail: "file deleted, any exported identifiers in it are gone",
+		}}
+	}
+
+	removed := make(map[string]string)
+	added := make(map[string]string)
+	for _, line := range strings.Split(diff.Patch, "\n") {
+		if len(line) < 2 || strings.HasPrefix(line, "---") || strings.HasPrefix(line, "+++") {
+			continue
+		}
+		m := goDecl.FindStringSubmatch(strings.TrimSpace(line[1:]))
+		if m == nil {
+			continue
+		}
+		key := m[1] + " " + m[2]
+		sig := normalizeSig(m[1] + " " + m[2] + m[3])
+		switch line[0] {
+		case '-':
+			removed[key] = sig
+		case '+':
+			added[key] = sig
+		}
+	}
+
+	var changes []BreakingChange
+	keys := make([]string, 0, len(removed))
+	for k := range removed {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		symbol := key[strings.LastIndex(key, " ")+1:]
+		newSig, ok := added[key]
+		switch {
+		case !ok:
+			changes = append(changes, BreakingChange{
+				File:   diff.Filename,
+				Kind:   "removed",
+				Symbol: symbol,
+				Detail: removed[key],
+			})
+		case newSig != removed[key] && strings.HasPrefix(key, "func"):
+			changes = append(changes, BreakingChange{
+				File:   diff.Filename,
+				Kind:   "signature",
+				Symbol: symbol,
+				Detail: removed[key] + " → " + newSig,
+			})
+		}
+	}
+	return changes
+}
+
+// normalizeSig trims bodies and collapses whitespace in a declaration
+func normalizeSig(s string) string {
+	if i := strings.Index(s, "{"); i >= 0 && strings.HasPrefix(s, "func") {
+		s = s[:i]
+	}
+	if i := strings.Index(s, "//"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,61 @@
+package analyze
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxSubjectLength is the longest commit subject considered readable
+const MaxSubjectLength = 72
+
+// conventionalSubject matches "type(scope)!: description"
+var conventionalSubject = regexp.MustCompile(`^(feat|fix|docs|style|refactor|perf|test|build|ci|chore|revert)(\([\w./-]+\))?!?: \S`)
+
+// CommitIssue lists the problems with one commit message
+type CommitIssue struct {
+	SHA      string
+	Subject  string
+	Problems []string
+}
+
+// CheckCommitMessage returns the Conventional Commits and formatting
+// problems in a commit message
+func CheckCommitMessage(msg string) []string {
+	lines := strings.Split(strings.TrimRight(msg, "\n"), "\n")
+	subject := lines[0]
+
+	var problems []string
+	if !conventionalSubject.MatchString(subject) {
+		problems = append(problems, "subject is not in Conventional Commits form (`type(scope): description`)")
+	}
+	if len(subject) > MaxSubjectLength {
+		problems = append(problems, fmt.Sprintf("subject is %d characters, keep it to %d or fewer", len(subject), MaxSubjectLength))
+	}
+	if strings.HasSuffix(subject, ".") {
+		problems = append(problems, "subject ends with a period")
+	}
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		problems = append(problems, "no blank line between subject and body")
+	}
+	return problems
+}
+
+// CheckCommits returns the commits whose messages have problems. Merge
+// commits are skipped.
+func CheckCommits(shas, messages []string) []CommitIssue {
+	var issues []CommitIssue
+	for i, msg := range messages {
+		if strings.HasPrefix(msg, "Merge ") {
+			continue
+		}
+		if problems := CheckCommitMessage(msg); len(problems) > 0 {
+			issues = append(issues, CommitIssue{
+				SHA:      shas[i],
+				Subject:  strings.SplitN(msg, "\n", 2)[0],
+				Problems: problems,
+			})
+		}
+	}
+	return issues
+}
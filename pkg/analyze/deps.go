@@ -0,0 +1,104 @@
+package analyze
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Dependency ecosystems, named as in the OSV schema
+const (
+	EcosystemGo   = "Go"
+	EcosystemNPM  = "npm"
+	EcosystemPyPI = "PyPI"
+)
+
+// DependencyChange is a dependency a diff adds or moves to a new version
+type DependencyChange struct {
+	Ecosystem string
+	Name      string
+	From      string // empty when added
+	To        string
+	File      string
+}
+
+var (
+	// goModLine matches "module version" in a require line or block
+	goModLine = regexp.MustCompile(`^\s*(?:require\s+)?([^\s()]+)\s+(v[^\s]+)(?:\s*//.*)?$`)
+	// goSumLine matches "module version h1:hash", not the /go.mod entries
+	goSumLine = regexp.MustCompile(`^([^\s]+)\s+(v[^\s/]+)\s+h1:`)
+	// packageJSONLine matches `"name": "version"` with a semver-ish version
+	packageJSONLine = regexp.MustCompile(`^\s*"(@?[a-z0-9][\w.-]*(?:/[\w.-]+)?)"\s*:\s*"[\^~=v]*(\d+\.\d+\.\d+[\w.+-]*)"\s*,?\s*$`)
+	// requirementsLine matches "name==version"
+	requirementsLine = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)(?:\[[^\]]*\])?\s*==\s*([^\s;#]+)`)
+)
+
+// DependencyChanges returns the dependencies added or updated in go.mod,
+// go.sum, package.json and requirements.txt files. Removed dependencies
+// and unchanged lines are ignored.
+func DependencyChanges(diffs []FileDiff) []DependencyChange {
+	var changes []DependencyChange
+	seen := make(map[string]bool)
+	for _, d := range diffs {
+		if d.Status == "removed" || d.Patch == "" {
+			continue
+		}
+		ecosystem, pattern := manifestPattern(d.Filename)
+		if pattern == nil {
+			continue
+		}
+
+		removed := make(map[string]string)
+		var added [][2]string
+		for _, line := range strings.Split(d.Patch, "\n") {
+			if len(line) == 0 || (line[0] != '+' && line[0] != '-') {
+				continue
+			}
+			m := pattern.FindStringSubmatch(line[1:])
+			if m == nil || (ecosystem == EcosystemNPM && m[1] == "version") {
+				continue
+			}
+			if line[0] == '-' {
+				removed[m[1]] = m[2]
+			} else {
+				added = append(added, [2]string{m[1], m[2]})
+			}
+		}
+
+		for _, a := range added {
+			name, version := a[0], a[1]
+			if removed[name] == version {
+				continue
+			}
+			key := ecosystem + " " + name + " " + version
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			changes = append(changes, DependencyChange{
+				Ecosystem: ecosystem,
+				Name:      name,
+				From:      removed[name],
+				To:        version,
+				File:      d.Filename,
+			})
+		}
+	}
+	return changes
+}
+
+// manifestPattern returns the ecosystem and line pattern for a manifest
+// file, or a nil pattern for other files
+func manifestPattern(file string) (string, *regexp.Regexp) {
+	switch base := path.Base(file); {
+	case base == "go.mod":
+		return EcosystemGo, goModLine
+	case base == "go.sum":
+		return EcosystemGo, goSumLine
+	case base == "package.json":
+		return EcosystemNPM, packageJSONLine
+	case base == "requirements.txt" || (strings.HasPrefix(base, "requirements") && strings.HasSuffix(base, ".txt")):
+		return EcosystemPyPI, requirementsLine
+	}
+	return "", nil
+}
@@ -0,0 +1,80 @@
+package analyze
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Hotspot is a hunk complex enough that a reviewer may want it explained
+type Hotspot struct {
+	File    string
+	Line    int // last new-file line of the hunk, where a comment can go
+	Changed int // lines added and removed in the hunk
+	Churn   int // lines added and removed in the whole file
+	Hunk    string
+}
+
+// Hotspots returns up to max hunks changing at least minLines lines,
+// largest first, at most one per file. Hunks in busier files rank higher.
+// Tests, lockfiles and generated files are left out.
+func Hotspots(diffs []FileDiff, minLines, max int) []Hotspot {
+	var spots []Hotspot
+	for _, d := range diffs {
+		if d.Status == "removed" || d.Patch == "" || Relevance(d.Filename, d.Patch) != TierSource {
+			continue
+		}
+		best := Hotspot{}
+		for _, hunk := range hunks(d.Patch) {
+			if h := measureHunk(hunk); h.Changed >= minLines && h.Line > 0 && h.Changed > best.Changed {
+				best = h
+			}
+		}
+		if best.Changed > 0 {
+			best.File, best.Churn = d.Filename, d.Additions+d.Deletions
+			spots = append(spots, best)
+		}
+	}
+	sort.SliceStable(spots, func(i, j int) bool {
+		return spots[i].score() > spots[j].score()
+	})
+	if len(spots) > max {
+		spots = spots[:max]
+	}
+	return spots
+}
+
+// score weighs the hunk's own size over the churn of its file
+func (h Hotspot) score() int {
+	return 4*h.Changed + h.Churn
+}
+
+// measureHunk counts a hunk's changed lines and finds its last new-file
+// line, 0 when it has no @@ header
+func measureHunk(hunk string) Hotspot {
+	h := Hotspot{Hunk: hunk}
+	line := 0
+	for _, l := range strings.Split(hunk, "\n") {
+		if m := hunkHeader.FindStringSubmatch(l); m != nil {
+			line, _ = strconv.Atoi(m[1])
+			continue
+		}
+		if line == 0 || l == "" {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(l, "+"):
+			h.Changed++
+			h.Line = line
+			line++
+		case strings.HasPrefix(l, "-"):
+			h.Changed++
+		case strings.HasPrefix(l, `\`):
+			// "\ No newline at end of file"
+		default:
+			h.Line = line
+			line++
+		}
+	}
+	return h
+}
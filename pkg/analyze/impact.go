@@ -0,0 +1,194 @@
+package analyze
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/sandbox"
+)
+
+// maxImpactNodes caps the number of packages drawn in the diagram
+const maxImpactNodes = 20
+
+// goPackage is the subset of `go list -json` output we need
+type goPackage struct {
+	ImportPath   string
+	Dir          string
+	Imports      []string
+	TestImports  []string
+	XTestImports []string
+	TestGoFiles  []string
+	XTestGoFiles []string
+	Module       *struct {
+		Path string
+		Dir  string
+	}
+}
+
+// Impact describes changed Go packages and the packages importing them
+type Impact struct {
+	Module    string
+	Changed   []string            // changed packages, module-relative
+	Importers map[string][]string // changed package -> importing packages
+}
+
+// GoImpact lists the module's packages in ws and finds which ones import
+// the packages containing changedFiles
+func GoImpact(ctx context.Context, ws *sandbox.Workspace, changedFiles []string) (*Impact, error) {
+	pkgs, err := goList(ctx, ws)
+	if err != nil {
+		return nil, err
+	}
+
+	impact := &Impact{Importers: make(map[string][]string)}
+	if pkgs[0].Module != nil {
+		impact.Module = pkgs[0].Module.Path
+	}
+
+	changed := changedPackages(ws, pkgs, changedFiles)
+
+	for _, p := range pkgs {
+		for _, imp := range p.Imports {
+			if changed[imp] && !changed[p.ImportPath] {
+				impact.Importers[imp] = append(impact.Importers[imp], p.ImportPath)
+			}
+		}
+	}
+	for pkg := range changed {
+		impact.Changed = append(impact.Changed, pkg)
+	}
+	sort.Strings(impact.Changed)
+
+	return impact, nil
+}
+
+// goList lists the packages of the Go module in ws
+func goList(ctx context.Context, ws *sandbox.Workspace) ([]goPackage, error) {
+	if !ws.Exists("go.mod") {
+		return nil, fmt.Errorf("not a Go module")
+	}
+
+	res, err := ws.Run(ctx, 2*time.Minute, sandbox.GoEnv, "go", "list", "-e", "-json", "./...")
+	if err != nil {
+		return nil, err
+	}
+	if !res.OK() {
+		return nil, fmt.Errorf("go list failed: %s", strings.TrimSpace(res.Output))
+	}
+
+	// Module downloads are reported on stderr, so only stdout is JSON
+	var pkgs []goPackage
+	dec := json.NewDecoder(strings.NewReader(res.Stdout))
+	for {
+		var p goPackage
+		if err := dec.Decode(&p); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse go list output: %w", err)
+		}
+		pkgs = append(pkgs, p)
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("no Go packages found")
+	}
+	return pkgs, nil
+}
+
+// changedPackages maps changed Go files to the packages in their
+// directories
+func changedPackages(ws *sandbox.Workspace, pkgs []goPackage, changedFiles []string) map[string]bool {
+	byDir := make(map[string]string)
+	for _, p := range pkgs {
+		rel := strings.TrimPrefix(strings.TrimPrefix(p.Dir, ws.Dir), "/")
+		if rel == "" {
+			rel = "."
+		}
+		byDir[rel] = p.ImportPath
+	}
+	changed := make(map[string]bool)
+	for _, f := range changedFiles {
+		if !strings.HasSuffix(f, ".go") {
+			continue
+		}
+		if pkg, ok := byDir[path.Dir(f)]; ok {
+			changed[pkg] = true
+		}
+	}
+	return changed
+}
+
+// Mermaid renders the impact as a Mermaid flowchart, importers pointing
+// at the changed packages they depend on
+func (i *Impact) Mermaid() string {
+	if len(i.Changed) == 0 {
+		return ""
+	}
+
+	ids := make(map[string]string)
+	var nodes []string
+	node := func(pkg string) (string, bool) {
+		if id, ok := ids[pkg]; ok {
+			return id, true
+		}
+		if len(ids) >= maxImpactNodes {
+			return "", false
+		}
+		id := fmt.Sprintf("p%d", len(ids))
+		ids[pkg] = id
+		nodes = append(nodes, fmt.Sprintf("  %s[\"%s\"]", id, i.label(pkg)))
+		return id, true
+	}
+
+	var edges, changed []string
+	omitted := 0
+	for _, pkg := range i.Changed {
+		to, ok := node(pkg)
+		if !ok {
+			omitted++
+			continue
+		}
+		changed = append(changed, to)
+
+		importers := append([]string(nil), i.Importers[pkg]...)
+		sort.Strings(importers)
+		for _, imp := range importers {
+			from, ok := node(imp)
+			if !ok {
+				omitted++
+				continue
+			}
+			edges = append(edges, fmt.Sprintf("  %s --> %s", from, to))
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	b.WriteString(strings.Join(nodes, "\n") + "\n")
+	if len(edges) > 0 {
+		b.WriteString(strings.Join(edges, "\n") + "\n")
+	}
+	b.WriteString("  classDef changed fill:#ffe4e1,stroke:#d33\n")
+	fmt.Fprintf(&b, "  class %s changed\n", strings.Join(changed, ","))
+	if omitted > 0 {
+		fmt.Fprintf(&b, "  more[\"…%d more\"]\n", omitted)
+	}
+	return b.String()
+}
+
+// label shortens an import path relative to the module
+func (i *Impact) label(pkg string) string {
+	if i.Module == "" || !strings.HasPrefix(pkg, i.Module) {
+		return pkg
+	}
+	rel := strings.TrimPrefix(strings.TrimPrefix(pkg, i.Module), "/")
+	if rel == "" {
+		return path.Base(i.Module)
+	}
+	return rel
+}
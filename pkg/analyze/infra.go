@@ -0,0 +1,198 @@
+package analyze
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Infrastructure change actions
+const (
+	InfraAdded     = "add"
+	InfraChanged   = "change"
+	InfraDestroyed = "destroy"
+)
+
+// InfraChange is a Terraform or Kubernetes resource a diff touches
+type InfraChange struct {
+	File     string
+	Resource string // e.g. aws_s3_bucket.logs or Deployment/api
+	Action   string // add, change, destroy
+	Note     string // why a change is risky, e.g. force_destroy enabled
+}
+
+// Destructive reports whether the change may delete infrastructure
+func (c InfraChange) Destructive() bool {
+	return c.Action == InfraDestroyed || c.Note != ""
+}
+
+var (
+	// tfBlock matches the opening line of a resource or module block
+	tfBlock = regexp.MustCompile(`^\s*(resource|module)\s+"([^"]+)"(?:\s+"([^"]+)")?\s*\{`)
+	// tfRisky matches settings that let Terraform delete data
+	tfRisky = regexp.MustCompile(`^\s*(force_destroy\s*=\s*true|prevent_destroy\s*=\s*false|deletion_protection\s*=\s*false|skip_final_snapshot\s*=\s*true)`)
+	// yamlKind and yamlName read a manifest's kind and metadata name
+	yamlKind = regexp.MustCompile(`^kind:\s*["']?([A-Za-z]+)`)
+	yamlName = regexp.MustCompile(`^  name:\s*["']?([^"'\s#]+)`)
+)
+
+// InfraChanges summarizes the Terraform resources and Kubernetes objects
+// added, changed and destroyed by the diffs. It reads only the patch, so
+// lines outside a hunk's visible block are attributed to the file.
+func InfraChanges(diffs []FileDiff) []InfraChange {
+	var changes []InfraChange
+	for _, d := range diffs {
+		switch ext := strings.ToLower(path.Ext(d.Filename)); {
+		case ext == ".tf":
+			changes = append(changes, terraformChanges(d)...)
+		case (ext == ".yaml" || ext == ".yml") && isManifest(d.Patch):
+			changes = append(changes, manifestChanges(d)...)
+		}
+	}
+	return changes
+}
+
+// terraformChanges walks a .tf patch tracking the enclosing block
+func terraformChanges(d FileDiff) []InfraChange {
+	type block struct {
+		added, removed, changed bool
+		note                    string
+	}
+	blocks := make(map[string]*block)
+	var order []string
+	get := func(addr string) *block {
+		if b, ok := blocks[addr]; ok {
+			return b
+		}
+		b := &block{}
+		blocks[addr] = b
+		order = append(order, addr)
+		return b
+	}
+
+	current := ""
+	for _, line := range strings.Split(d.Patch, "\n") {
+		if strings.HasPrefix(line, "@@") {
+			current = ""
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		op, text := line[0], line[1:]
+		if m := tfBlock.FindStringSubmatch(text); m != nil {
+			current = tfAddress(m[1], m[2], m[3])
+			switch op {
+			case '+':
+				get(current).added = true
+			case '-':
+				get(current).removed = true
+			}
+			continue
+		}
+		if op != '+' && op != '-' {
+			continue
+		}
+		addr := current
+		if addr == "" {
+			addr = d.Filename
+		}
+		b := get(addr)
+		b.changed = true
+		if op == '+' && tfRisky.MatchString(text) {
+			b.note = strings.TrimSpace(text) + " set"
+		}
+	}
+
+	var changes []InfraChange
+	for _, addr := range order {
+		b := blocks[addr]
+		c := InfraChange{File: d.Filename, Resource: addr, Action: InfraChanged, Note: b.note}
+		switch {
+		case d.Status == "added" || (b.added && !b.removed):
+			c.Action = InfraAdded
+		case d.Status == "removed" || (b.removed && !b.added):
+			c.Action = InfraDestroyed
+		case !b.changed && !b.added:
+			continue
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// tfAddress formats a block as Terraform addresses it
+func tfAddress(kind, typ, name string) string {
+	if kind == "module" {
+		return "module." + typ
+	}
+	return typ + "." + name
+}
+
+// isManifest reports whether a YAML patch looks like a Kubernetes object
+func isManifest(patch string) bool {
+	return strings.Contains(patch, "apiVersion:") && strings.Contains(patch, "kind:")
+}
+
+// manifestChanges reports the objects in a Kubernetes manifest patch.
+// Objects whose kind line was added or removed are added or destroyed;
+// other objects with edited lines are changed.
+func manifestChanges(d FileDiff) []InfraChange {
+	type object struct {
+		kind, name string
+		op         byte
+		changed    bool
+	}
+	var objects []*object
+	var current *object
+	for _, line := range strings.Split(d.Patch, "\n") {
+		if line == "" || strings.HasPrefix(line, "@@") {
+			continue
+		}
+		op, text := line[0], line[1:]
+		if strings.TrimSpace(text) == "---" {
+			current = nil
+			continue
+		}
+		if m := yamlKind.FindStringSubmatch(text); m != nil {
+			current = &object{kind: m[1], op: op}
+			objects = append(objects, current)
+			continue
+		}
+		if current == nil {
+			if (op == '+' || op == '-') && !strings.HasPrefix(text, "apiVersion:") {
+				current = &object{changed: true}
+				objects = append(objects, current)
+			}
+			continue
+		}
+		if m := yamlName.FindStringSubmatch(text); m != nil && current.name == "" {
+			current.name = m[1]
+		}
+		if op == '+' || op == '-' {
+			current.changed = true
+		}
+	}
+
+	var changes []InfraChange
+	for _, o := range objects {
+		resource := d.Filename
+		if o.kind != "" {
+			resource = o.kind
+			if o.name != "" {
+				resource += "/" + o.name
+			}
+		}
+		c := InfraChange{File: d.Filename, Resource: resource, Action: InfraChanged}
+		switch {
+		case d.Status == "added" || o.op == '+':
+			c.Action = InfraAdded
+		case d.Status == "removed" || o.op == '-':
+			c.Action = InfraDestroyed
+		case !o.changed:
+			continue
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
@@ -0,0 +1,38 @@
+package analyze
+
+import "fmt"
+
+// DefaultMaxLines is the changed-line count above which a file is large
+const DefaultMaxLines = 1000
+
+// FileWarning is a changed file that needs a reviewer's eye
+type FileWarning struct {
+	File   string
+	Kind   string // binary, large
+	Detail string
+}
+
+// LargeFiles returns binary files and files whose change exceeds maxLines.
+// GitHub sends no patch for binary files or diffs too big to render, so a
+// missing patch on a file that wasn't removed is the binary/large signal.
+func LargeFiles(diffs []FileDiff, maxLines int) []FileWarning {
+	if maxLines <= 0 {
+		maxLines = DefaultMaxLines
+	}
+
+	var warnings []FileWarning
+	for _, d := range diffs {
+		changed := d.Additions + d.Deletions
+		switch {
+		case d.Status == "removed":
+			continue
+		case d.Patch == "" && changed == 0 && d.Status != "renamed":
+			warnings = append(warnings, FileWarning{File: d.Filename, Kind: "binary", Detail: "binary file"})
+		case d.Patch == "" && changed > 0:
+			warnings = append(warnings, FileWarning{File: d.Filename, Kind: "large", Detail: "diff too large to display"})
+		case changed > maxLines:
+			warnings = append(warnings, FileWarning{File: d.Filename, Kind: "large", Detail: fmt.Sprintf("%d lines changed", changed)})
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,64 @@
+package analyze
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// DefaultLicenseFiles are the globs checked for headers when none are set
+var DefaultLicenseFiles = []string{
+	"*.go", "*.js", "*.jsx", "*.ts", "*.tsx", "*.py", "*.rb", "*.rs",
+	"*.java", "*.kt", "*.c", "*.h", "*.cc", "*.cpp", "*.swift", "*.sh",
+}
+
+// licenseHeadLines is how far into a file the header may appear
+const licenseHeadLines = 20
+
+// MissingHeaders returns the files added by the diffs whose opening lines
+// don't match header. Globs are matched against the base name and the
+// full path. Added files without a patch (too large or binary) are skipped.
+func MissingHeaders(diffs []FileDiff, header *regexp.Regexp, globs []string) []string {
+	if len(globs) == 0 {
+		globs = DefaultLicenseFiles
+	}
+
+	var missing []string
+	for _, d := range diffs {
+		if d.Status != "added" || d.Patch == "" || !matchesAny(globs, d.Filename) {
+			continue
+		}
+		if !header.MatchString(fileHead(d.Patch, licenseHeadLines)) {
+			missing = append(missing, d.Filename)
+		}
+	}
+	return missing
+}
+
+// fileHead returns the first n added lines of a new file's patch
+func fileHead(patch string, n int) string {
+	var lines []string
+	for _, line := range strings.Split(patch, "\n") {
+		if !strings.HasPrefix(line, "+") {
+			continue
+		}
+		lines = append(lines, line[1:])
+		if len(lines) == n {
+			break
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// matchesAny reports whether file matches one of the globs
+func matchesAny(globs []string, file string) bool {
+	for _, g := range globs {
+		if ok, _ := path.Match(g, path.Base(file)); ok {
+			return true
+		}
+		if ok, _ := path.Match(g, file); ok {
+			return true
+		}
+	}
+	return false
+}
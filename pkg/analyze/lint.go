@@ -0,0 +1,78 @@
+package analyze
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LintIssue is a single finding from a linter
+type LintIssue struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+// lintLine matches the common file:line[:col]: message output format
+var lintLine = regexp.MustCompile(`^(?:\./)?([^\s:]+):(\d+)(?::(\d+))?:?\s+(.+)$`)
+
+// hunkHeader matches the new-file range of a unified diff hunk
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// ParseLintOutput extracts issues from linter output in file:line:col form
+func ParseLintOutput(out string) []LintIssue {
+	var issues []LintIssue
+	for _, line := range strings.Split(out, "\n") {
+		m := lintLine.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(m[2])
+		col, _ := strconv.Atoi(m[3])
+		issues = append(issues, LintIssue{File: m[1], Line: n, Column: col, Message: m[4]})
+	}
+	return issues
+}
+
+// AddedLines returns the new-file line numbers added by a patch
+func AddedLines(patch string) map[int]bool {
+	added := make(map[int]bool)
+	line := 0
+	for _, l := range strings.Split(patch, "\n") {
+		if m := hunkHeader.FindStringSubmatch(l); m != nil {
+			line, _ = strconv.Atoi(m[1])
+			continue
+		}
+		if line == 0 {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(l, "+"):
+			added[line] = true
+			line++
+		case strings.HasPrefix(l, "-"):
+		default:
+			line++
+		}
+	}
+	return added
+}
+
+// NewIssues keeps the issues that fall on lines added by the diffs, so
+// pre-existing findings in touched files aren't blamed on the branch
+func NewIssues(issues []LintIssue, diffs []FileDiff) []LintIssue {
+	added := make(map[string]map[int]bool, len(diffs))
+	for _, d := range diffs {
+		added[d.Filename] = AddedLines(d.Patch)
+	}
+
+	var fresh []LintIssue
+	for _, issue := range issues {
+		lines, ok := added[issue.File]
+		if ok && lines[issue.Line] {
+			fresh = append(fresh, issue)
+		}
+	}
+	return fresh
+}
@@ -0,0 +1,113 @@
+package analyze
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Migration is a database migration added or changed by a diff
+type Migration struct {
+	File        string
+	Version     string // leading number or timestamp of the file name
+	Tool        string // goose, migrate, atlas or sql
+	Reversible  bool   // a down migration with statements is present
+	Destructive []string
+}
+
+var (
+	// migrationVersion reads the version prefix of a migration file name
+	migrationVersion = regexp.MustCompile(`^(\d+)`)
+	// destructiveSQL matches statements that lose data or break readers
+	destructiveSQL = regexp.MustCompile(`(?i)\b(DROP\s+(?:TABLE|COLUMN|INDEX|SCHEMA|DATABASE|VIEW)|TRUNCATE|DELETE\s+FROM|RENAME\s+(?:COLUMN|TO)|ALTER\s+COLUMN\s+\S+\s+(?:SET\s+DATA\s+)?TYPE)\b`)
+)
+
+// isMigration reports whether file looks like a SQL migration
+func isMigration(file string) bool {
+	if !strings.HasSuffix(strings.ToLower(file), ".sql") {
+		return false
+	}
+	dir := "/" + path.Dir(file) + "/"
+	return strings.Contains(dir, "/migrations/") || strings.Contains(dir, "/migrate/") ||
+		strings.Contains(dir, "/migration/") || migrationVersion.MatchString(path.Base(file))
+}
+
+// Migrations returns the migrations the diffs add or change, ordered by
+// version. golang-migrate up/down pairs are reported once, as the up file.
+func Migrations(diffs []FileDiff) []Migration {
+	downs := make(map[string]bool)
+	atlas := false
+	for _, d := range diffs {
+		if path.Base(d.Filename) == "atlas.sum" {
+			atlas = true
+		}
+		if strings.HasSuffix(d.Filename, ".down.sql") && d.Status != "removed" && hasStatements(d.Patch) {
+			downs[strings.TrimSuffix(d.Filename, ".down.sql")] = true
+		}
+	}
+
+	var migrations []Migration
+	for _, d := range diffs {
+		if d.Status == "removed" || !isMigration(d.Filename) || strings.HasSuffix(d.Filename, ".down.sql") {
+			continue
+		}
+		m := Migration{File: d.Filename, Tool: "sql"}
+		if v := migrationVersion.FindString(path.Base(d.Filename)); v != "" {
+			m.Version = v
+		}
+
+		up := d.Patch
+		switch {
+		case strings.HasSuffix(d.Filename, ".up.sql"):
+			m.Tool = "migrate"
+			// An edited up file's down file may be unchanged
+			m.Reversible = downs[strings.TrimSuffix(d.Filename, ".up.sql")] || d.Status != "added"
+		case strings.Contains(d.Patch, "+goose Up"):
+			m.Tool = "goose"
+			if i := strings.Index(d.Patch, "+goose Down"); i >= 0 {
+				up = d.Patch[:i]
+				_, down, _ := strings.Cut(d.Patch[i:], "\n")
+				m.Reversible = hasStatements(down)
+			}
+		case atlas:
+			m.Tool = "atlas"
+		}
+
+		for _, line := range strings.Split(up, "\n") {
+			if !strings.HasPrefix(line, "+") || strings.HasPrefix(strings.TrimSpace(line[1:]), "--") {
+				continue
+			}
+			if op := destructiveSQL.FindString(line[1:]); op != "" {
+				m.Destructive = append(m.Destructive, strings.ToUpper(strings.Join(strings.Fields(op), " ")))
+			}
+		}
+		migrations = append(migrations, m)
+	}
+
+	sort.SliceStable(migrations, func(i, j int) bool {
+		vi, vj := migrations[i].Version, migrations[j].Version
+		if len(vi) != len(vj) {
+			return len(vi) < len(vj)
+		}
+		if vi != vj {
+			return vi < vj
+		}
+		return migrations[i].File < migrations[j].File
+	})
+	return migrations
+}
+
+// hasStatements reports whether a patch leaves any non-comment SQL
+func hasStatements(patch string) bool {
+	for _, line := range strings.Split(patch, "\n") {
+		if !strings.HasPrefix(line, "+") && !strings.HasPrefix(line, " ") {
+			continue
+		}
+		text := strings.TrimSpace(line[1:])
+		if text != "" && !strings.HasPrefix(text, "--") {
+			return true
+		}
+	}
+	return false
+}
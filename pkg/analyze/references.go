@@ -0,0 +1,214 @@
+package analyze
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Ways to handle unverified references
+const (
+	ReferencesStrike = "strike"
+	ReferencesRemove = "remove"
+)
+
+var (
+	// codeSpan matches inline code, which is how descriptions name files
+	// and symbols
+	codeSpan = regexp.MustCompile("`([^`\n]+)`")
+	// barePath matches unquoted paths like pkg/server/server.go
+	barePath = regexp.MustCompile(`(?:^|[\s(])((?:[\w.-]+/)+[\w.-]+\.[A-Za-z0-9]+)\b`)
+	// symbolShape matches identifiers like Run, pkg.Func or (*T).Method()
+	symbolShape = regexp.MustCompile(`^\(?\*?[A-Za-z_]\w*\)?(?:\.[A-Za-z_]\w*)*(?:\(\))?$`)
+	// fileShape matches a file name with an extension
+	fileShape = regexp.MustCompile(`^[\w./-]*[\w-]\.[A-Za-z0-9]{1,8}$`)
+)
+
+// minSymbolLength skips short spans like `id` or `ok` that match anything
+const minSymbolLength = 4
+
+// fileExtensions tell a file like main.go from a symbol like pkg.Func
+var fileExtensions = map[string]bool{
+	"c": true, "cfg": true, "conf": true, "cpp": true, "cs": true, "css": true, "dart": true,
+	"env": true, "go": true, "gradle": true, "h": true, "html": true, "ini": true, "java": true,
+	"js": true, "json": true, "jsx": true, "kt": true, "lock": true, "lua": true, "md": true,
+	"mod": true, "php": true, "proto": true, "py": true, "rb": true, "rs": true, "scss": true,
+	"sh": true, "sql": true, "sum": true, "svelte": true, "swift": true, "tf": true, "toml": true,
+	"ts": true, "tsx": true, "txt": true, "vue": true, "xml": true, "yaml": true, "yml": true,
+}
+
+// Reference is a file path or symbol a description names
+type Reference struct {
+	Text     string // as written, without backticks
+	File     bool   // a path rather than a symbol
+	Verified bool
+}
+
+// CheckReferences finds the file paths and symbols a generated description
+// names and whether each appears in the diff. Unverified references are
+// struck through, or with remove their bullet line is dropped; references
+// outside bullets are always struck, since dropping prose loses meaning.
+func CheckReferences(description string, diffs []FileDiff, mode string) (string, []Reference) {
+	files := make(map[string]bool, len(diffs))
+	var patches strings.Builder
+	for _, d := range diffs {
+		files[d.Filename] = true
+		patches.WriteString(d.Patch)
+		patches.WriteString("\n")
+	}
+	patchText := patches.String()
+
+	var refs []Reference
+	seen := make(map[string]bool)
+	check := func(text string) *Reference {
+		ref := classify(text, files, patchText)
+		if ref == nil {
+			return nil
+		}
+		if !seen[text] {
+			seen[text] = true
+			refs = append(refs, *ref)
+		}
+		return ref
+	}
+
+	lines := strings.Split(description, "\n")
+	kept := lines[:0]
+	inFence := false
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if inFence || strings.HasPrefix(strings.TrimSpace(line), "```") {
+			kept = append(kept, line)
+			continue
+		}
+
+		unverified := false
+		line = codeSpan.ReplaceAllStringFunc(line, func(span string) string {
+			ref := check(span[1 : len(span)-1])
+			if ref == nil || ref.Verified {
+				return span
+			}
+			unverified = true
+			return "~~" + span + "~~"
+		})
+		line = replaceBarePaths(line, func(p string) string {
+			ref := check(p)
+			if ref == nil || ref.Verified {
+				return p
+			}
+			unverified = true
+			return "~~" + p + "~~"
+		})
+
+		if unverified && mode == ReferencesRemove && isBullet(line) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n"), refs
+}
+
+// replaceBarePaths applies fn to unquoted paths outside code spans and
+// links
+func replaceBarePaths(line string, fn func(string) string) string {
+	var b strings.Builder
+	rest := line
+	for rest != "" {
+		// Leave code spans alone, they were checked already
+		start := strings.Index(rest, "`")
+		if start < 0 {
+			b.WriteString(replaceBareSegment(rest, fn))
+			break
+		}
+		end := strings.Index(rest[start+1:], "`")
+		if end < 0 {
+			b.WriteString(replaceBareSegment(rest, fn))
+			break
+		}
+		end += start + 2
+		b.WriteString(replaceBareSegment(rest[:start], fn))
+		b.WriteString(rest[start:end])
+		rest = rest[end:]
+	}
+	return b.String()
+}
+
+// replaceBareSegment applies fn to the bare paths in text without code
+func replaceBareSegment(text string, fn func(string) string) string {
+	if strings.Contains(text, "://") {
+		// URLs look like paths
+		return text
+	}
+	return barePath.ReplaceAllStringFunc(text, func(m string) string {
+		sub := barePath.FindStringSubmatch(m)
+		return strings.Replace(m, sub[1], fn(sub[1]), 1)
+	})
+}
+
+// classify returns the reference text names, or nil when it names neither
+// a file nor a symbol
+func classify(text string, files map[string]bool, patches string) *Reference {
+	text = strings.TrimSpace(text)
+	switch {
+	case strings.ContainsAny(text, " \t\"'=<>{}[],;$@#"), strings.HasPrefix(text, "-"), strings.Contains(text, "://"):
+		// Commands, values, flags and URLs
+		return nil
+	case fileShape.MatchString(text) && (strings.Contains(text, "/") || fileExtensions[strings.ToLower(path.Ext(text)[1:])]):
+		return &Reference{Text: text, File: true, Verified: fileInDiff(text, files)}
+	case symbolShape.MatchString(text):
+		name := symbolName(text)
+		if len(name) < minSymbolLength || isNumber(name) {
+			return nil
+		}
+		return &Reference{Text: text, Verified: strings.Contains(patches, name) || symbolInPaths(name, files)}
+	}
+	return nil
+}
+
+// fileInDiff reports whether a path names a changed file, allowing
+// shortened paths like server.go for pkg/server/server.go
+func fileInDiff(p string, files map[string]bool) bool {
+	p = strings.TrimPrefix(p, "./")
+	if files[p] {
+		return true
+	}
+	for name := range files {
+		if strings.HasSuffix(name, "/"+p) || path.Base(name) == p {
+			return true
+		}
+	}
+	return false
+}
+
+// symbolName returns the last identifier of pkg.Func() or (*T).Method
+func symbolName(text string) string {
+	text = strings.TrimSuffix(text, "()")
+	if i := strings.LastIndex(text, "."); i >= 0 {
+		text = text[i+1:]
+	}
+	return strings.Trim(text, "(*)")
+}
+
+// symbolInPaths reports whether a symbol is named by a changed file or
+// directory, like a package name
+func symbolInPaths(name string, files map[string]bool) bool {
+	for file := range files {
+		if strings.Contains(file, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// isNumber reports whether s is all digits
+func isNumber(s string) bool {
+	return strings.Trim(s, "0123456789") == ""
+}
+
+// isBullet reports whether a line is a list item
+func isBullet(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* ")
+}
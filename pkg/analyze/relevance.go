@@ -0,0 +1,169 @@
+package analyze
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Relevance tiers, most relevant first
+const (
+	TierSource = iota
+	TierTest
+	TierLockfile
+	TierGenerated
+)
+
+// lockfiles are dependency lock files, rewritten by tools
+var lockfiles = map[string]bool{
+	"go.sum": true, "package-lock.json": true, "yarn.lock": true, "pnpm-lock.yaml": true,
+	"Cargo.lock": true, "Gemfile.lock": true, "poetry.lock": true, "composer.lock": true,
+	"Pipfile.lock": true, "bun.lockb": true, "flake.lock": true, "mix.lock": true,
+}
+
+// generatedMarkers are path fragments of generated or vendored files
+var generatedMarkers = []string{
+	"_gen.go", ".pb.go", "_generated.", ".generated.", ".min.js", ".min.css", ".snap",
+}
+
+// generatedDirs hold build output and vendored code
+var generatedDirs = []string{"dist", "vendor", "node_modules", "generated", "__generated__"}
+
+// Relevance returns the tier of a changed file: source, tests, lockfiles,
+// then generated files
+func Relevance(filename, patch string) int {
+	base := path.Base(filename)
+	switch {
+	case lockfiles[base]:
+		return TierLockfile
+	case isGenerated(filename, patch):
+		return TierGenerated
+	case isTest(filename):
+		return TierTest
+	}
+	return TierSource
+}
+
+// Mechanical returns "lockfile" or "generated" for files tools rewrite,
+// whose diffs say little beyond their size, and "" for the rest
+func Mechanical(filename, patch string) string {
+	switch Relevance(filename, patch) {
+	case TierLockfile:
+		return "lockfile"
+	case TierGenerated:
+		return "generated"
+	}
+	return ""
+}
+
+// isGenerated reports whether a file is build output, vendored or
+// carries a generated-code header
+func isGenerated(filename, patch string) bool {
+	for _, marker := range generatedMarkers {
+		if strings.Contains(filename, marker) {
+			return true
+		}
+	}
+	for _, dir := range strings.Split(path.Dir(filename), "/") {
+		for _, generated := range generatedDirs {
+			if dir == generated {
+				return true
+			}
+		}
+	}
+	head := patch
+	if len(head) > 500 {
+		head = head[:500]
+	}
+	return strings.Contains(head, "Code generated") && strings.Contains(head, "DO NOT EDIT")
+}
+
+// isTest reports whether a file holds tests
+func isTest(filename string) bool {
+	base := path.Base(filename)
+	if strings.HasSuffix(base, "_test.go") || strings.HasPrefix(base, "test_") ||
+		strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+		strings.HasSuffix(strings.TrimSuffix(base, path.Ext(base)), "_test") {
+		return true
+	}
+	for _, dir := range strings.Split(path.Dir(filename), "/") {
+		if dir == "test" || dir == "tests" || dir == "__tests__" || dir == "testdata" || dir == "spec" {
+			return true
+		}
+	}
+	return false
+}
+
+// Chunk is the part of a file's patch that fits a prompt
+type Chunk struct {
+	File    string
+	Tier    int
+	Patch   string
+	Partial bool   // some hunks didn't fit
+	Summary string // a one-line summary replacing a mechanical patch
+}
+
+// FitChunks ranks changed files by relevance and fits their hunks into
+// budget bytes, most relevant first. Lockfiles and generated files are
+// collapsed to a one-line summary. It returns the chunks in rank order
+// and the files none of whose hunks fit, or that have no patch.
+func FitChunks(diffs []FileDiff, budget int) ([]Chunk, []string) {
+	ranked := make([]FileDiff, len(diffs))
+	copy(ranked, diffs)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return Relevance(ranked[i].Filename, ranked[i].Patch) < Relevance(ranked[j].Filename, ranked[j].Patch)
+	})
+
+	var chunks []Chunk
+	var omitted []string
+	left := budget
+	for _, d := range ranked {
+		chunk := Chunk{File: d.Filename, Tier: Relevance(d.Filename, d.Patch)}
+		if kind := Mechanical(d.Filename, d.Patch); kind != "" {
+			chunk.Summary = fmt.Sprintf("%s, %d additions and %d deletions", kind, d.Additions, d.Deletions)
+			if len(chunk.Summary) > left {
+				omitted = append(omitted, d.Filename)
+				continue
+			}
+			left -= len(chunk.Summary)
+			chunks = append(chunks, chunk)
+			continue
+		}
+		if d.Patch == "" {
+			omitted = append(omitted, d.Filename)
+			continue
+		}
+		var kept []string
+		for _, hunk := range hunks(d.Patch) {
+			// Smaller hunks later in the file may still fit
+			if len(hunk)+1 > left {
+				chunk.Partial = true
+				continue
+			}
+			kept = append(kept, hunk)
+			left -= len(hunk) + 1
+		}
+		if len(kept) == 0 {
+			omitted = append(omitted, d.Filename)
+			continue
+		}
+		chunk.Patch = strings.Join(kept, "\n")
+		chunks = append(chunks, chunk)
+	}
+	return chunks, omitted
+}
+
+// hunks splits a patch at its @@ headers
+func hunks(patch string) []string {
+	var out []string
+	lines := strings.Split(patch, "\n")
+	start := 0
+	for i, line := range lines {
+		if i > start && strings.HasPrefix(line, "@@") {
+			out = append(out, strings.Join(lines[start:i], "\n"))
+			start = i
+		}
+	}
+	return append(out, strings.Join(lines[start:], "\n"))
+}
@@ -0,0 +1,133 @@
+package analyze
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// mention matches @user and @org/team, but not emails
+	mention = regexp.MustCompile(`(^|[^\w@/.` + "`" + `])@([A-Za-z0-9][A-Za-z0-9-]*(?:/[A-Za-z0-9_.-]+)?)`)
+	// closingKeyword matches the keywords GitHub closes issues with, like
+	// Fixes #12 or Resolves owner/repo#12
+	closingKeyword = regexp.MustCompile(`(?i)\b(close[sd]?|fix(?:e[sd])?|resolve[sd]?):?(\s+)((?:[\w.-]+/[\w.-]+)?#\d+)`)
+	// htmlTag matches the start of a tag or comment
+	htmlTag = regexp.MustCompile(`<([A-Za-z/!])`)
+	// mdImage and mdLink match inline images and links
+	mdImage = regexp.MustCompile(`!\[([^\]\n]*)\]\([^)\n]*\)`)
+	mdLink  = regexp.MustCompile(`\[([^\]\n]+)\]\([^)\n]*\)`)
+)
+
+// SanitizeOptions picks what Sanitize neutralizes
+type SanitizeOptions struct {
+	Mentions bool // wrap @mentions in code so nobody is pinged
+	Closing  bool // turn closing keywords into Refs unless intended
+	HTML     bool // escape HTML tags
+	// Intended holds the text whose closing keywords are deliberate, like
+	// the commit messages
+	Intended string
+	// MaxImages and MaxLinks cap inline images and links; the rest keep
+	// only their text. Negative means no limit.
+	MaxImages int
+	MaxLinks  int
+}
+
+// Sanitize neutralizes generated markdown before it's posted: mentions
+// that would ping people, keywords that would close issues, raw HTML and
+// runs of images or links. Code spans and fences are left alone.
+func Sanitize(text string, opts SanitizeOptions) string {
+	intended := make(map[string]bool)
+	for _, m := range closingKeyword.FindAllStringSubmatch(opts.Intended, -1) {
+		intended[strings.ToLower(m[3])] = true
+	}
+	images, links := 0, 0
+
+	lines := strings.Split(text, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		lines[i] = outsideCode(line, func(s string) string {
+			if opts.HTML {
+				s = htmlTag.ReplaceAllString(s, "&lt;$1")
+			}
+			if opts.MaxImages >= 0 {
+				s = mdImage.ReplaceAllStringFunc(s, func(m string) string {
+					if images++; images > opts.MaxImages {
+						return mdImage.FindStringSubmatch(m)[1]
+					}
+					return m
+				})
+			}
+			if opts.MaxLinks >= 0 {
+				s = capLinks(s, &links, opts.MaxLinks)
+			}
+			if opts.Closing {
+				s = closingKeyword.ReplaceAllStringFunc(s, func(m string) string {
+					sub := closingKeyword.FindStringSubmatch(m)
+					if intended[strings.ToLower(sub[3])] {
+						return m
+					}
+					return "Refs" + sub[2] + sub[3]
+				})
+			}
+			if opts.Mentions {
+				s = mention.ReplaceAllString(s, "$1`@$2`")
+			}
+			return s
+		})
+	}
+	return strings.Join(lines, "\n")
+}
+
+// capLinks keeps the first max links in s, counting from *seen, and
+// replaces the rest with their text. Images were handled already.
+func capLinks(s string, seen *int, max int) string {
+	var b strings.Builder
+	for {
+		loc := mdLink.FindStringSubmatchIndex(s)
+		if loc == nil {
+			b.WriteString(s)
+			return b.String()
+		}
+		link := s[loc[0]:loc[1]]
+		if loc[0] > 0 && s[loc[0]-1] == '!' {
+			b.WriteString(s[:loc[1]])
+		} else if *seen++; *seen > max {
+			b.WriteString(s[:loc[0]])
+			b.WriteString(s[loc[2]:loc[3]])
+		} else {
+			b.WriteString(s[:loc[0]])
+			b.WriteString(link)
+		}
+		s = s[loc[1]:]
+	}
+}
+
+// outsideCode applies fn to the parts of line outside code spans
+func outsideCode(line string, fn func(string) string) string {
+	var b strings.Builder
+	rest := line
+	for rest != "" {
+		start := strings.Index(rest, "`")
+		if start < 0 {
+			b.WriteString(fn(rest))
+			break
+		}
+		end := strings.Index(rest[start+1:], "`")
+		if end < 0 {
+			b.WriteString(fn(rest))
+			break
+		}
+		end += start + 2
+		b.WriteString(fn(rest[:start]))
+		b.WriteString(rest[start:end])
+		rest = rest[end:]
+	}
+	return b.String()
+}
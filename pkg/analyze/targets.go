@@ -0,0 +1,145 @@
+package analyze
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/sandbox"
+)
+
+// bazelWorkspaceFiles mark the root of a Bazel workspace
+var bazelWorkspaceFiles = []string{"MODULE.bazel", "WORKSPACE.bazel", "WORKSPACE"}
+
+// Targets are the build targets a change affects
+type Targets struct {
+	Tool     string   // go or bazel
+	Affected []string // changed targets and everything depending on them
+	Tests    []string // test targets to run
+	All      bool     // a module-wide file changed, so everything is affected
+}
+
+// AffectedTargets computes the targets affected by changedFiles. Bazel
+// workspaces are queried with bazel when it is installed; Go modules fall
+// back to go list.
+func AffectedTargets(ctx context.Context, ws *sandbox.Workspace, changedFiles []string) (*Targets, error) {
+	for _, f := range bazelWorkspaceFiles {
+		if !ws.Exists(f) {
+			continue
+		}
+		targets, err := bazelTargets(ctx, ws, changedFiles)
+		if err == nil || !ws.Exists("go.mod") {
+			return targets, err
+		}
+		break
+	}
+	return goTargets(ctx, ws, changedFiles)
+}
+
+// goTargets walks the reverse import graph of the module's packages
+func goTargets(ctx context.Context, ws *sandbox.Workspace, changedFiles []string) (*Targets, error) {
+	pkgs, err := goList(ctx, ws)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := &Targets{Tool: "go"}
+	for _, f := range changedFiles {
+		if base := path.Base(f); base == "go.mod" || base == "go.sum" {
+			targets.All = true
+		}
+	}
+
+	affected := changedPackages(ws, pkgs, changedFiles)
+	if targets.All {
+		for _, p := range pkgs {
+			affected[p.ImportPath] = true
+		}
+	}
+	// Repeat until no importer of an affected package is left unmarked
+	for grew := true; grew; {
+		grew = false
+		for _, p := range pkgs {
+			if affected[p.ImportPath] {
+				continue
+			}
+			for _, imp := range p.Imports {
+				if affected[imp] {
+					affected[p.ImportPath] = true
+					grew = true
+					break
+				}
+			}
+		}
+	}
+
+	for _, p := range pkgs {
+		pattern := "./" + strings.TrimPrefix(strings.TrimPrefix(p.Dir, ws.Dir), "/")
+		pattern = strings.TrimSuffix(pattern, "/")
+		if affected[p.ImportPath] {
+			targets.Affected = append(targets.Affected, pattern)
+		}
+		if len(p.TestGoFiles)+len(p.XTestGoFiles) == 0 {
+			continue
+		}
+		tested := affected[p.ImportPath]
+		for _, imp := range append(p.TestImports, p.XTestImports...) {
+			tested = tested || affected[imp]
+		}
+		if tested {
+			targets.Tests = append(targets.Tests, pattern)
+		}
+	}
+	sort.Strings(targets.Affected)
+	sort.Strings(targets.Tests)
+	return targets, nil
+}
+
+// bazelTargets asks bazel for the reverse dependencies of the changed
+// files. Deleted files and files outside any package are skipped by
+// --keep_going.
+func bazelTargets(ctx context.Context, ws *sandbox.Workspace, changedFiles []string) (*Targets, error) {
+	var files []string
+	for _, f := range changedFiles {
+		if ws.Exists(f) && !strings.ContainsAny(f, " ()'\"") {
+			files = append(files, f)
+		}
+	}
+	targets := &Targets{Tool: "bazel"}
+	if len(files) == 0 {
+		return targets, nil
+	}
+
+	rdeps := fmt.Sprintf("rdeps(//..., set(%s))", strings.Join(files, " "))
+	var err error
+	if targets.Affected, err = bazelQuery(ctx, ws, "kind(rule, "+rdeps+")"); err != nil {
+		return nil, err
+	}
+	if targets.Tests, err = bazelQuery(ctx, ws, "tests("+rdeps+")"); err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
+
+// bazelQuery runs a query and returns the labels it prints. Exit code 3
+// means some patterns failed under --keep_going, which is expected.
+func bazelQuery(ctx context.Context, ws *sandbox.Workspace, expr string) ([]string, error) {
+	res, err := ws.Run(ctx, 5*time.Minute, nil, "bazel", "query", "--keep_going", "--output=label", expr)
+	if err != nil {
+		return nil, err
+	}
+	if !res.OK() && res.ExitCode != 3 {
+		return nil, fmt.Errorf("bazel query failed: %s", strings.TrimSpace(res.Output))
+	}
+	var labels []string
+	for _, line := range strings.Split(res.Output, "\n") {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, "//") || strings.HasPrefix(line, "@") {
+			labels = append(labels, line)
+		}
+	}
+	sort.Strings(labels)
+	return labels, nil
+}
@@ -0,0 +1,108 @@
+package analyze
+
+import (
+	"regexp"
+	"strings"
+)
+
+// PreservedTrailers are the trailers carried from branch commits into PRs
+var PreservedTrailers = []string{"Co-authored-by", "Signed-off-by"}
+
+// trailerLine matches a "Key: value" git trailer
+var trailerLine = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*):\s+(\S.*)$`)
+
+// Trailer is a "Key: value" line at the end of a commit message
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+// String formats the trailer as it appears in a commit message
+func (t Trailer) String() string {
+	return t.Key + ": " + t.Value
+}
+
+// ParseTrailers returns the trailers in the last paragraph of msg. Like
+// git, the paragraph only counts when every line is a trailer or an
+// indented continuation, and a subject alone has no trailers.
+func ParseTrailers(msg string) []Trailer {
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(msg, "\r\n", "\n")), "\n\n")
+	if len(paragraphs) < 2 {
+		return nil
+	}
+
+	var trailers []Trailer
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		if line != "" && (line[0] == ' ' || line[0] == '\t') && len(trailers) > 0 {
+			trailers[len(trailers)-1].Value += " " + strings.TrimSpace(line)
+			continue
+		}
+		m := trailerLine.FindStringSubmatch(strings.TrimRight(line, " \t"))
+		if m == nil {
+			return nil
+		}
+		trailers = append(trailers, Trailer{Key: m[1], Value: m[2]})
+	}
+	return trailers
+}
+
+// CollectTrailers returns the preserved trailers across messages in
+// first-seen order, without duplicates. Keys are normalized to the
+// spelling in PreservedTrailers.
+func CollectTrailers(messages []string) []Trailer {
+	seen := make(map[string]bool)
+	var collected []Trailer
+	for _, msg := range messages {
+		for _, t := range ParseTrailers(msg) {
+			key := preservedKey(t.Key)
+			if key == "" {
+				continue
+			}
+			t.Key = key
+			id := strings.ToLower(t.String())
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			collected = append(collected, t)
+		}
+	}
+	return collected
+}
+
+// preservedKey returns the canonical spelling of key, or "" when it
+// isn't preserved
+func preservedKey(key string) string {
+	for _, k := range PreservedTrailers {
+		if strings.EqualFold(k, key) {
+			return k
+		}
+	}
+	return ""
+}
+
+// UnsignedCommits returns the commits without a Signed-off-by trailer.
+// Merge commits are skipped.
+func UnsignedCommits(shas, messages []string) []CommitIssue {
+	var unsigned []CommitIssue
+	for i, msg := range messages {
+		if strings.HasPrefix(msg, "Merge ") {
+			continue
+		}
+		signed := false
+		for _, t := range ParseTrailers(msg) {
+			if strings.EqualFold(t.Key, "Signed-off-by") {
+				signed = true
+				break
+			}
+		}
+		if !signed {
+			unsigned = append(unsigned, CommitIssue{
+				SHA:      shas[i],
+				Subject:  strings.SplitN(msg, "\n", 2)[0],
+				Problems: []string{"missing Signed-off-by trailer"},
+			})
+		}
+	}
+	return unsigned
+}
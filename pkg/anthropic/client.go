@@ -0,0 +1,92 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/saint0x/ggquick/pkg/httpclient"
+)
+
+const (
+	baseURL    = "https://api.anthropic.com/v1"
+	apiVersion = "2023-06-01"
+)
+
+type Client struct {
+	key        string
+	httpClient *http.Client
+}
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type MessageRequest struct {
+	Model     string    `json:"model"`
+	System    string    `json:"system,omitempty"`
+	Messages  []Message `json:"messages"`
+	MaxTokens int       `json:"max_tokens"`
+}
+
+type ContentBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type MessageResponse struct {
+	ID      string         `json:"id"`
+	Model   string         `json:"model"`
+	Content []ContentBlock `json:"content"`
+	Usage   Usage          `json:"usage"`
+}
+
+func NewClient(key string) *Client {
+	return &Client{
+		key:        key,
+		httpClient: httpclient.New(0), // generation is bounded by the caller's context
+	}
+}
+
+func (c *Client) CreateMessage(ctx context.Context, req MessageRequest) (*MessageResponse, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/messages", bytes.NewBuffer(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("x-api-key", c.key)
+	httpReq.Header.Set("anthropic-version", apiVersion)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result MessageResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,176 @@
+// Package app wires the ggquick server together from the environment
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/saint0x/ggquick/pkg/ai"
+	"github.com/saint0x/ggquick/pkg/config"
+	"github.com/saint0x/ggquick/pkg/fake"
+	"github.com/saint0x/ggquick/pkg/github"
+	"github.com/saint0x/ggquick/pkg/hooks"
+	"github.com/saint0x/ggquick/pkg/httpclient"
+	"github.com/saint0x/ggquick/pkg/log"
+	"github.com/saint0x/ggquick/pkg/notify"
+	"github.com/saint0x/ggquick/pkg/pipeline"
+	"github.com/saint0x/ggquick/pkg/secrets"
+	"github.com/saint0x/ggquick/pkg/server"
+)
+
+// ErrEnvironment is returned by Serve when required settings are missing
+// or invalid
+var ErrEnvironment = errors.New("environment validation failed")
+
+// Serve validates the environment, starts the server and blocks until ctx
+// is cancelled
+func Serve(ctx context.Context, logger *log.Logger) error {
+	logger.Loading("🚀 Starting ggquick server...")
+	logger.Info("🔧 Debug mode: %v", os.Getenv("DEBUG") == "true")
+
+	sandbox := os.Getenv("GGQUICK_SANDBOX") == "true"
+
+	// Validate environment
+	env := &config.Environment{}
+	if sandbox {
+		logger.Warning("Sandbox mode: no calls to GitHub or AI providers")
+	} else {
+		logger.Loading("🔍 Validating environment...")
+		var err error
+		if env, err = config.Validate(logger); err != nil {
+			return fmt.Errorf("%w: %v", ErrEnvironment, err)
+		}
+		logger.Success("✅ Environment validated")
+	}
+
+	if proxy := os.Getenv("GGQUICK_HTTP_PROXY"); proxy != "" {
+		if err := httpclient.SetProxy(proxy); err != nil {
+			return fmt.Errorf("%w: %v", ErrEnvironment, err)
+		}
+		logger.Success("✅ Outbound proxy enabled")
+	}
+
+	// Initialize components
+	logger.Loading("⚙️ Initializing components...")
+
+	aiGen := ai.New(logger)
+	if aiGen == nil {
+		return fmt.Errorf("failed to initialize AI generator")
+	}
+	providers := []ai.Provider{ai.MockProvider{}}
+	if !sandbox {
+		if err := aiGen.Initialize(env.OpenAIKey); err != nil {
+			return fmt.Errorf("failed to initialize AI generator: %w", err)
+		}
+		var err error
+		if providers, err = ai.ProvidersFromEnv(env.OpenAIKey); err != nil {
+			return fmt.Errorf("%w: %v", ErrEnvironment, err)
+		}
+	}
+	aiGen.SetProviders(providers)
+	if len(providers) > 1 {
+		names := make([]string, 0, len(providers))
+		for _, p := range providers {
+			names = append(names, p.Name())
+		}
+		logger.Success("✅ AI provider failover: %s", strings.Join(names, " → "))
+	}
+	logger.Success("✅ AI generator ready")
+
+	var ghClient server.GitHubClient
+	var hooksMgr server.HooksManager
+	var sandboxGitHub *fake.GitHub
+	if sandbox {
+		sandboxGitHub = fake.NewGitHub(server.BaseURL())
+		ghClient, hooksMgr = sandboxGitHub, fake.Hooks{}
+		logger.Success("✅ Sandbox GitHub ready")
+	} else {
+		realClient := github.New(logger)
+		if realClient == nil {
+			return fmt.Errorf("failed to initialize GitHub client")
+		}
+		ghClient = realClient
+		logger.Success("✅ GitHub client ready")
+	}
+
+	plugins, err := pipeline.LoadPluginsFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to load plugins: %w", err)
+	}
+	if os.Getenv("GGQUICK_WASM_STAGES") != "" {
+		logger.Warning("GGQUICK_WASM_STAGES is ignored, list wasm modules in each repository's \"wasm\" setting")
+	}
+	for _, stage := range plugins {
+		logger.Success("✅ Loaded pipeline stage: %s", stage.Name())
+	}
+
+	if !sandbox {
+		realHooks := hooks.New(logger)
+		if realHooks == nil {
+			return fmt.Errorf("failed to initialize hooks manager")
+		}
+		if err := realHooks.InitGitHub(env.GitHubToken); err != nil {
+			return fmt.Errorf("failed to initialize hooks manager: %w", err)
+		}
+		hooksMgr = realHooks
+		logger.Success("✅ Git hooks ready")
+	}
+
+	// Create and start server
+	srv, err := server.New(logger, aiGen, ghClient, hooksMgr)
+	if err != nil {
+		return fmt.Errorf("failed to create server: %w", err)
+	}
+	if sandbox {
+		// Pull requests are opened by the sandbox, never with user tokens,
+		// and nothing is notified
+		srv.UseSandbox(sandboxGitHub)
+	} else {
+		srv.SetUserClients(func(token string) server.UserClient {
+			return github.NewWithToken(logger, token)
+		})
+		if n := notify.FromEnv(); n != nil {
+			srv.SetNotifier(n)
+			logger.Success("✅ Notifications enabled")
+		}
+	}
+	if h := pipeline.ExecHooksFromEnv(); h != nil {
+		srv.SetExecHooks(h)
+		logger.Success("✅ Exec hooks enabled")
+	}
+	if queueURL := os.Getenv("GGQUICK_QUEUE_URL"); queueURL != "" {
+		backend, err := server.BackendFromURL(queueURL)
+		if err != nil {
+			return fmt.Errorf("failed to configure queue backend: %w", err)
+		}
+		srv.SetQueueBackend(backend)
+		logger.Success("✅ Queue backend enabled")
+	}
+	if path := os.Getenv("GGQUICK_STATE_FILE"); path != "" {
+		keys, err := secrets.KeyringFromEnv()
+		if err != nil {
+			return fmt.Errorf("failed to load master key: %w", err)
+		}
+		if err := srv.UseStateFile(path, keys); err != nil {
+			return fmt.Errorf("failed to load state: %w", err)
+		}
+	}
+	if path := os.Getenv("GGQUICK_RECORD_FILE"); path != "" {
+		if err := srv.RecordTo(path); err != nil {
+			return fmt.Errorf("%w: %v", ErrEnvironment, err)
+		}
+	}
+	logger.Success("✅ Server initialized")
+
+	if err := srv.Start(ctx); err != nil {
+		return fmt.Errorf("server error: %w", err)
+	}
+
+	// Wait for shutdown
+	<-ctx.Done()
+	logger.Success("✨ Server shutdown complete")
+	return nil
+}
@@ -0,0 +1,233 @@
+// Package cli is a small command framework for the ggquick binary: nested
+// subcommands with their own flags, generated help, shell completions and
+// man pages.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Command is a CLI command with optional subcommands
+type Command struct {
+	// Use is the command name followed by an argument synopsis, e.g.
+	// "apply REPO_URL"
+	Use     string
+	Aliases []string
+	Short   string
+	Long    string
+	Example string
+	// Args validates positional arguments before Run
+	Args func(args []string) error
+	// Run executes the command. Commands without one print their help.
+	Run func(cmd *Command, args []string) error
+	// PersistentPreRun runs before Run of this command and every command
+	// below it; only the nearest one runs
+	PersistentPreRun func(cmd *Command, args []string) error
+
+	parent     *Command
+	commands   []*Command
+	flags      *flag.FlagSet
+	persistent *flag.FlagSet
+}
+
+// UsageError reports invalid flags, arguments or commands
+type UsageError struct {
+	Err   error
+	Usage string
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error() + "\n\n" + e.Usage
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// Name returns the command name
+func (c *Command) Name() string {
+	name, _, _ := strings.Cut(c.Use, " ")
+	return name
+}
+
+// CommandPath returns the full invocation, e.g. "ggquick rules add"
+func (c *Command) CommandPath() string {
+	if c.parent == nil {
+		return c.Name()
+	}
+	return c.parent.CommandPath() + " " + c.Name()
+}
+
+// AddCommand registers subcommands
+func (c *Command) AddCommand(cmds ...*Command) {
+	for _, cmd := range cmds {
+		cmd.parent = c
+		c.commands = append(c.commands, cmd)
+	}
+}
+
+// Commands returns the subcommands in registration order
+func (c *Command) Commands() []*Command {
+	return c.commands
+}
+
+// Parent returns the command this one is registered under
+func (c *Command) Parent() *Command {
+	return c.parent
+}
+
+// Flags returns the command's flag set
+func (c *Command) Flags() *flag.FlagSet {
+	if c.flags == nil {
+		c.flags = flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+		c.flags.SetOutput(io.Discard)
+	}
+	return c.flags
+}
+
+// PersistentFlags returns flags accepted by this command and every
+// command below it
+func (c *Command) PersistentFlags() *flag.FlagSet {
+	if c.persistent == nil {
+		c.persistent = flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	}
+	return c.persistent
+}
+
+// inheritedFlags returns the persistent flags of c and its ancestors
+func (c *Command) inheritedFlags() []*flag.Flag {
+	var flags []*flag.Flag
+	for cmd := c; cmd != nil; cmd = cmd.parent {
+		if cmd.persistent != nil {
+			cmd.persistent.VisitAll(func(f *flag.Flag) { flags = append(flags, f) })
+		}
+	}
+	return flags
+}
+
+// find returns the subcommand called name or one of its aliases
+func (c *Command) find(name string) *Command {
+	for _, cmd := range c.commands {
+		if cmd.Name() == name {
+			return cmd
+		}
+		for _, alias := range cmd.Aliases {
+			if alias == name {
+				return cmd
+			}
+		}
+	}
+	return nil
+}
+
+// Execute runs the subcommand named by args
+func (c *Command) Execute(args []string) error {
+	help := len(args) > 0 && args[0] == "help"
+	if help {
+		args = args[1:]
+	}
+
+	cmd := c
+	for len(args) > 0 {
+		sub := cmd.find(args[0])
+		if sub == nil {
+			break
+		}
+		cmd, args = sub, args[1:]
+	}
+	if help {
+		fmt.Fprint(os.Stdout, cmd.UsageString())
+		return nil
+	}
+
+	positional, err := cmd.parseFlags(args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprint(os.Stdout, cmd.UsageString())
+		return nil
+	}
+	if err != nil {
+		return &UsageError{Err: err, Usage: cmd.UsageString()}
+	}
+
+	if cmd.Run == nil {
+		if len(positional) > 0 {
+			err := fmt.Errorf("unknown command %q for %q", positional[0], cmd.CommandPath())
+			return &UsageError{Err: err, Usage: cmd.UsageString()}
+		}
+		fmt.Fprint(os.Stdout, cmd.UsageString())
+		return nil
+	}
+	if cmd.Args != nil {
+		if err := cmd.Args(positional); err != nil {
+			return &UsageError{Err: err, Usage: cmd.UsageString()}
+		}
+	}
+	for p := cmd; p != nil; p = p.parent {
+		if p.PersistentPreRun != nil {
+			if err := p.PersistentPreRun(cmd, positional); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return cmd.Run(cmd, positional)
+}
+
+// parseFlags parses flags anywhere among the arguments, returning the
+// positional ones. Everything after "--" is positional.
+func (c *Command) parseFlags(args []string) ([]string, error) {
+	fs := c.Flags()
+	for _, f := range c.inheritedFlags() {
+		if fs.Lookup(f.Name) == nil {
+			fs.Var(f.Value, f.Name, f.Usage)
+		}
+	}
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// NoArgs rejects any positional argument
+func NoArgs(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected argument %q", args[0])
+	}
+	return nil
+}
+
+// ExactArgs requires exactly n positional arguments
+func ExactArgs(n int) func([]string) error {
+	return func(args []string) error {
+		if len(args) != n {
+			return fmt.Errorf("expected %d argument(s), got %d", n, len(args))
+		}
+		return nil
+	}
+}
+
+// MaximumNArgs allows at most n positional arguments
+func MaximumNArgs(n int) func([]string) error {
+	return func(args []string) error {
+		if len(args) > n {
+			return fmt.Errorf("expected at most %d argument(s), got %d", n, len(args))
+		}
+		return nil
+	}
+}
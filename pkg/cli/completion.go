@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// walk calls fn for c and every command below it
+func (c *Command) walk(fn func(*Command)) {
+	fn(c)
+	for _, cmd := range c.commands {
+		cmd.walk(fn)
+	}
+}
+
+// words returns the subcommand names and flags completed after c
+func (c *Command) words() []string {
+	var words []string
+	for _, cmd := range c.commands {
+		words = append(words, cmd.Name())
+	}
+	for _, f := range c.allFlags() {
+		words = append(words, "--"+f.Name)
+	}
+	return append(words, "--help")
+}
+
+// GenBashCompletion writes a bash completion script for c
+func (c *Command) GenBashCompletion(w io.Writer) error {
+	name := c.Name()
+	fn := "_" + strings.ReplaceAll(name, "-", "_")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# bash completion for %s\n\n", name)
+	fmt.Fprintf(&b, "%s() {\n", fn)
+	b.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	fmt.Fprintf(&b, "    local cmd=%q i\n", name)
+	b.WriteString("    for ((i = 1; i < COMP_CWORD; i++)); do\n")
+	b.WriteString("        case \"$cmd ${COMP_WORDS[i]}\" in\n")
+	c.walk(func(cmd *Command) {
+		if cmd.parent == nil {
+			return
+		}
+		parent := cmd.parent.CommandPath()
+		for _, n := range append([]string{cmd.Name()}, cmd.Aliases...) {
+			fmt.Fprintf(&b, "            %q) cmd=%q ;;\n", parent+" "+n, cmd.CommandPath())
+		}
+	})
+	b.WriteString("        esac\n")
+	b.WriteString("    done\n\n")
+	b.WriteString("    case \"$cmd\" in\n")
+	c.walk(func(cmd *Command) {
+		fmt.Fprintf(&b, "        %q) COMPREPLY=($(compgen -W %q -- \"$cur\")) ;;\n",
+			cmd.CommandPath(), strings.Join(cmd.words(), " "))
+	})
+	b.WriteString("    esac\n")
+	b.WriteString("}\n\n")
+	fmt.Fprintf(&b, "complete -o default -F %s %s\n", fn, name)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// GenZshCompletion writes a zsh completion script for c
+func (c *Command) GenZshCompletion(w io.Writer) error {
+	name := c.Name()
+	fn := "_" + strings.ReplaceAll(name, "-", "_")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "#compdef %s\n\n", name)
+	fmt.Fprintf(&b, "%s() {\n", fn)
+	fmt.Fprintf(&b, "    local cmd=%q i\n", name)
+	b.WriteString("    local -a opts\n")
+	b.WriteString("    for ((i = 2; i < CURRENT; i++)); do\n")
+	b.WriteString("        case \"$cmd ${words[i]}\" in\n")
+	c.walk(func(cmd *Command) {
+		if cmd.parent == nil {
+			return
+		}
+		parent := cmd.parent.CommandPath()
+		for _, n := range append([]string{cmd.Name()}, cmd.Aliases...) {
+			fmt.Fprintf(&b, "            %q) cmd=%q ;;\n", parent+" "+n, cmd.CommandPath())
+		}
+	})
+	b.WriteString("        esac\n")
+	b.WriteString("    done\n\n")
+	b.WriteString("    case \"$cmd\" in\n")
+	c.walk(func(cmd *Command) {
+		fmt.Fprintf(&b, "        %q)\n", cmd.CommandPath())
+		b.WriteString("            opts=(\n")
+		for _, sub := range cmd.commands {
+			fmt.Fprintf(&b, "                %s\n", zshQuote(sub.Name()+":"+sub.Short))
+		}
+		for _, f := range cmd.allFlags() {
+			fmt.Fprintf(&b, "                %s\n", zshQuote("--"+f.Name+":"+f.Usage))
+		}
+		b.WriteString("            )\n")
+		b.WriteString("            ;;\n")
+	})
+	b.WriteString("    esac\n")
+	b.WriteString("    _describe -t commands 'command' opts\n")
+	b.WriteString("}\n\n")
+	fmt.Fprintf(&b, "compdef %s %s\n", fn, name)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// GenFishCompletion writes a fish completion script for c
+func (c *Command) GenFishCompletion(w io.Writer) error {
+	name := c.Name()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# fish completion for %s\n\n", name)
+	fmt.Fprintf(&b, "complete -c %s -f\n", name)
+	c.walk(func(cmd *Command) {
+		// Condition that cmd is the deepest command typed so far
+		cond := "__fish_use_subcommand"
+		if cmd.parent != nil {
+			cond = "__fish_seen_subcommand_from " + strings.Join(append([]string{cmd.Name()}, cmd.Aliases...), " ")
+			if len(cmd.commands) > 0 {
+				var subs []string
+				for _, sub := range cmd.commands {
+					subs = append(subs, sub.Name())
+				}
+				cond += "; and not __fish_seen_subcommand_from " + strings.Join(subs, " ")
+			}
+		}
+		for _, sub := range cmd.commands {
+			fmt.Fprintf(&b, "complete -c %s -n %s -a %s -d %s\n", name, fishQuote(cond), sub.Name(), fishQuote(sub.Short))
+		}
+		flags := cmd.localFlags()
+		if cmd.parent == nil {
+			// Persistent flags apply everywhere
+			flags, cond = cmd.inheritedFlags(), ""
+		} else {
+			cond = " -n " + fishQuote(cond)
+		}
+		for _, f := range flags {
+			fmt.Fprintf(&b, "complete -c %s%s -l %s -d %s", name, cond, f.Name, fishQuote(f.Usage))
+			if typ, _ := flag.UnquoteUsage(f); typ != "" {
+				b.WriteString(" -r")
+			}
+			b.WriteString("\n")
+		}
+	})
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// allFlags returns the command's own and inherited flags
+func (c *Command) allFlags() []*flag.Flag {
+	return append(c.localFlags(), c.inheritedFlags()...)
+}
+
+// zshQuote single-quotes s for a zsh array entry
+func zshQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fishQuote single-quotes s for fish
+func fishQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
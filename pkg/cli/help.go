@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// UsageString renders the command's help text
+func (c *Command) UsageString() string {
+	var b strings.Builder
+	if c.Long != "" {
+		b.WriteString(strings.TrimSpace(c.Long) + "\n\n")
+	} else if c.Short != "" {
+		b.WriteString(c.Short + "\n\n")
+	}
+
+	b.WriteString("Usage:\n")
+	if c.Run != nil {
+		fmt.Fprintf(&b, "  %s\n", c.useLine())
+	}
+	if len(c.commands) > 0 {
+		fmt.Fprintf(&b, "  %s COMMAND\n", c.CommandPath())
+	}
+
+	if len(c.Aliases) > 0 {
+		fmt.Fprintf(&b, "\nAliases:\n  %s\n", strings.Join(append([]string{c.Name()}, c.Aliases...), ", "))
+	}
+
+	if len(c.commands) > 0 {
+		b.WriteString("\nCommands:\n")
+		tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		for _, cmd := range c.commands {
+			fmt.Fprintf(tw, "  %s\t%s\n", cmd.Name(), cmd.Short)
+		}
+		tw.Flush()
+	}
+
+	writeFlags(&b, "Flags", c.localFlags())
+	writeFlags(&b, "Global Flags", c.inheritedFlags())
+
+	if c.Example != "" {
+		fmt.Fprintf(&b, "\nExamples:\n%s\n", strings.TrimRight(c.Example, "\n"))
+	}
+
+	if len(c.commands) > 0 {
+		fmt.Fprintf(&b, "\nRun \"%s help COMMAND\" for more about a command.\n", c.root().Name())
+	}
+	return b.String()
+}
+
+// useLine returns the full synopsis, e.g. "ggquick apply REPO_URL [flags]"
+func (c *Command) useLine() string {
+	line := c.CommandPath()
+	if _, args, ok := strings.Cut(c.Use, " "); ok {
+		line += " " + args
+	}
+	if c.hasFlags() {
+		line += " [flags]"
+	}
+	return line
+}
+
+// hasFlags reports whether the command accepts any flags
+func (c *Command) hasFlags() bool {
+	return len(c.localFlags())+len(c.inheritedFlags()) > 0
+}
+
+// localFlags returns the command's own flags, without inherited ones
+func (c *Command) localFlags() []*flag.Flag {
+	inherited := c.inheritedFlags()
+	var flags []*flag.Flag
+	c.Flags().VisitAll(func(f *flag.Flag) {
+		for _, i := range inherited {
+			if i.Value == f.Value {
+				return
+			}
+		}
+		flags = append(flags, f)
+	})
+	return flags
+}
+
+// writeFlags renders a titled flag table
+func writeFlags(b *strings.Builder, title string, flags []*flag.Flag) {
+	if len(flags) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n%s:\n", title)
+	tw := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+	for _, f := range flags {
+		typ, usage := flag.UnquoteUsage(f)
+		name := "--" + f.Name
+		if typ != "" {
+			name += " " + typ
+		}
+		if f.DefValue != "" && f.DefValue != "false" {
+			usage += fmt.Sprintf(" (default %q)", f.DefValue)
+		}
+		fmt.Fprintf(tw, "  %s\t%s\n", name, usage)
+	}
+	tw.Flush()
+}
+
+// root returns the top-level command
+func (c *Command) root() *Command {
+	for c.parent != nil {
+		c = c.parent
+	}
+	return c
+}
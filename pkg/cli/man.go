@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GenManTree writes a section 1 man page for c and every subcommand to
+// dir, named after the command path, e.g. ggquick-rules-add.1
+func (c *Command) GenManTree(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	var err error
+	c.walk(func(cmd *Command) {
+		if err != nil {
+			return
+		}
+		file := filepath.Join(dir, cmd.manName()+".1")
+		err = os.WriteFile(file, []byte(cmd.manPage()), 0o644)
+		if err != nil {
+			err = fmt.Errorf("failed to write %s: %w", file, err)
+		}
+	})
+	return err
+}
+
+// manName returns the page name, e.g. ggquick-rules-add
+func (c *Command) manName() string {
+	return strings.ReplaceAll(c.CommandPath(), " ", "-")
+}
+
+// manPage renders the command as roff
+func (c *Command) manPage() string {
+	var b strings.Builder
+	root := c.root().Name()
+	fmt.Fprintf(&b, ".TH %q 1 \"\" %q %q\n", strings.ToUpper(c.manName()), root, root+" Manual")
+
+	b.WriteString(".SH NAME\n")
+	fmt.Fprintf(&b, "%s \\- %s\n", roffEscape(c.manName()), roffEscape(c.Short))
+
+	b.WriteString(".SH SYNOPSIS\n")
+	if c.Run != nil {
+		fmt.Fprintf(&b, ".B %s\n.br\n", roffEscape(c.useLine()))
+	}
+	if len(c.commands) > 0 {
+		fmt.Fprintf(&b, ".B %s\n", roffEscape(c.CommandPath()+" COMMAND"))
+	}
+
+	if desc := c.Long; desc != "" || c.Short != "" {
+		if desc == "" {
+			desc = c.Short
+		}
+		b.WriteString(".SH DESCRIPTION\n")
+		b.WriteString(roffText(desc, false) + "\n")
+	}
+
+	if len(c.commands) > 0 {
+		b.WriteString(".SH COMMANDS\n")
+		for _, cmd := range c.commands {
+			fmt.Fprintf(&b, ".TP\n.B %s\n%s\n", roffEscape(cmd.Name()), roffEscape(cmd.Short))
+		}
+	}
+
+	if c.hasFlags() {
+		b.WriteString(".SH OPTIONS\n")
+		for _, f := range c.allFlags() {
+			typ, usage := flag.UnquoteUsage(f)
+			fmt.Fprintf(&b, ".TP\n\\fB\\-\\-%s\\fR", roffEscape(f.Name))
+			if typ != "" {
+				fmt.Fprintf(&b, " \\fI%s\\fR", roffEscape(typ))
+			}
+			b.WriteString("\n" + roffEscape(usage))
+			if f.DefValue != "" && f.DefValue != "false" {
+				fmt.Fprintf(&b, " (default %s)", roffEscape(f.DefValue))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if c.Example != "" {
+		b.WriteString(".SH EXAMPLES\n.nf\n")
+		b.WriteString(roffText(strings.TrimRight(c.Example, "\n"), true) + "\n")
+		b.WriteString(".fi\n")
+	}
+
+	var related []string
+	if c.parent != nil {
+		related = append(related, c.parent.manName())
+	}
+	for _, cmd := range c.commands {
+		related = append(related, cmd.manName())
+	}
+	if len(related) > 0 {
+		b.WriteString(".SH SEE ALSO\n")
+		for i, name := range related {
+			sep := ","
+			if i == len(related)-1 {
+				sep = ""
+			}
+			fmt.Fprintf(&b, ".BR %s (1)%s\n", roffEscape(name), sep)
+		}
+	}
+	return b.String()
+}
+
+// roffEscape escapes backslashes and hyphens for roff
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	return strings.ReplaceAll(s, "-", `\-`)
+}
+
+// roffText escapes multi-line text, guarding lines that would otherwise
+// be read as requests. Blank lines start a new paragraph unless the text
+// is preformatted.
+func roffText(s string, preformatted bool) string {
+	lines := strings.Split(strings.Trim(s, "\n"), "\n")
+	for i, line := range lines {
+		line = roffEscape(line)
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			line = `\&` + line
+		}
+		if line == "" && !preformatted {
+			line = ".PP"
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
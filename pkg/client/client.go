@@ -0,0 +1,780 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBaseURL is the hosted ggquick server
+const DefaultBaseURL = "https://ggquick.fly.dev"
+
+// Client wraps the ggquick server HTTP API
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	token      string
+	userKey    string
+	pushSecret string
+
+	// MaxRetries is how many times a failed request is retried
+	MaxRetries int
+	// RetryDelay is the initial backoff, doubled after every attempt
+	RetryDelay time.Duration
+
+	// etags caches GET bodies by path so unchanged responses come back
+	// as 304 Not Modified
+	mu    sync.Mutex
+	etags map[string]cachedResponse
+}
+
+// cachedResponse is a GET body and the ETag it was served with
+type cachedResponse struct {
+	etag string
+	body []byte
+}
+
+// maxCachedResponses bounds the ETag cache
+const maxCachedResponses = 64
+
+// APIError is returned when the server responds with a non-success status
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("server returned status %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is a 404 from the server
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// ConfigResponse is returned after configuring a repository
+type ConfigResponse struct {
+	Status string `json:"status"`
+	Owner  string `json:"owner"`
+	Name   string `json:"name"`
+}
+
+// PushRequest triggers PR generation for a branch
+type PushRequest struct {
+	Ref  string `json:"ref"`
+	SHA  string `json:"sha,omitempty"`
+	Repo string `json:"repo,omitempty"` // owner/name, optional with one configured repo
+	// Author is the pusher's GitHub login, credited on the PR
+	Author string `json:"author,omitempty"`
+	// Hooks fingerprints the installed git hooks, so the server notices
+	// when they stop reporting
+	Hooks string `json:"hooks,omitempty"`
+	// Files and Commits are the branch's changed files and commit subjects,
+	// newest first, as computed in the pusher's clone. The server falls
+	// back to them when it can't compare the branch on GitHub.
+	Files   []string `json:"files,omitempty"`
+	Commits []string `json:"commits,omitempty"`
+}
+
+// MaxPushFiles and MaxPushCommits bound the Files and Commits of a push
+const (
+	MaxPushFiles   = 1000
+	MaxPushCommits = 100
+)
+
+// Job tracks a single PR generation request
+type Job struct {
+	ID        string    `json:"id"`
+	Owner     string    `json:"owner"`
+	Repo      string    `json:"repo"`
+	Branch    string    `json:"branch"`
+	SHA       string    `json:"sha,omitempty"`
+	Status    string    `json:"status"`
+	PRNumber  int       `json:"pr_number,omitempty"`
+	PRURL     string    `json:"pr_url,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Tokens    int       `json:"tokens,omitempty"`
+	Author    string    `json:"author,omitempty"`
+	Priority  string    `json:"priority,omitempty"` // interactive, normal or background
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Model         string  `json:"model,omitempty"`
+	Provider      string  `json:"provider,omitempty"` // the AI provider that answered
+	PromptVersion string  `json:"prompt_version,omitempty"`
+	References    int     `json:"references,omitempty"` // files and symbols the description named
+	Unverified    int     `json:"unverified,omitempty"` // of those, not found in the diff
+	Confidence    float64 `json:"confidence,omitempty"` // description score from 0 to 1
+
+	IdempotencyKey string `json:"idempotency_key,omitempty"` // of the push that created the job
+}
+
+// Done reports whether the job has finished
+func (j *Job) Done() bool {
+	return j.Status == "succeeded" || j.Status == "failed" || j.Status == "cancelled"
+}
+
+// New creates a client for the server at baseURL
+func New(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		MaxRetries: 3,
+		RetryDelay: 500 * time.Millisecond,
+		etags:      make(map[string]cachedResponse),
+	}
+}
+
+// WithHTTPClient replaces the underlying HTTP client
+func (c *Client) WithHTTPClient(hc *http.Client) *Client {
+	c.httpClient = hc
+	return c
+}
+
+// WithToken sets the bearer token sent with every request, required for
+// admin endpoints when the server sets GGQUICK_ADMIN_TOKEN
+func (c *Client) WithToken(token string) *Client {
+	c.token = token
+	return c
+}
+
+// WithUserKey sets the key returned by RegisterUser, sent with pushes so
+// PRs can be opened with the user's own token
+func (c *Client) WithUserKey(key string) *Client {
+	c.userKey = key
+	return c
+}
+
+// WithPushSecret sets the repository's push secret. Configure registers
+// it with the server, and request bodies are signed with it so the server
+// can tell pushes from the repository's clones from forged ones.
+func (c *Client) WithPushSecret(secret string) *Client {
+	c.pushSecret = secret
+	return c
+}
+
+// SignatureHeader and TimestampHeader carry the signature of a request
+// body made with the push secret, and the Unix time it was made
+const (
+	SignatureHeader = "X-Ggquick-Signature"
+	TimestampHeader = "X-Ggquick-Timestamp"
+)
+
+// Sign returns the signature of body at timestamp: "sha256=" and the hex
+// HMAC-SHA256 of the timestamp, a dot and the body
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// BaseURL returns the server address the client talks to
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// Health checks that the server is up
+func (c *Client) Health(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/health", nil, nil)
+}
+
+// Configure registers a repository with the server
+func (c *Client) Configure(ctx context.Context, repoURL string) (*ConfigResponse, error) {
+	if repoURL == "" {
+		return nil, fmt.Errorf("repository URL is required")
+	}
+
+	var resp ConfigResponse
+	body := map[string]string{"repo_url": repoURL}
+	if c.pushSecret != "" {
+		body["push_secret"] = c.pushSecret
+	}
+	if err := c.do(ctx, http.MethodPost, "/config", body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Status != "config_stored" {
+		return nil, fmt.Errorf("server did not confirm config storage, got status: %s", resp.Status)
+	}
+	return &resp, nil
+}
+
+// Push triggers PR generation for a branch and returns the queued job
+func (c *Client) Push(ctx context.Context, req PushRequest) (*Job, error) {
+	if req.Ref == "" {
+		return nil, fmt.Errorf("ref is required")
+	}
+
+	var job Job
+	if err := c.do(ctx, http.MethodPost, "/push", req, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// User is a GitHub user whose token is registered with the server
+type User struct {
+	Login string `json:"login"`
+	Key   string `json:"key,omitempty"` // send with WithUserKey
+}
+
+// RegisterUser stores a GitHub token so PRs can be opened as its owner.
+// The returned key identifies the user's pushes.
+func (c *Client) RegisterUser(ctx context.Context, token string) (*User, error) {
+	if token == "" {
+		return nil, fmt.Errorf("token is required")
+	}
+	var user User
+	if err := c.do(ctx, http.MethodPost, "/users", map[string]string{"token": token}, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// RemoveUser forgets the token registered for the client's user key
+func (c *Client) RemoveUser(ctx context.Context) (*User, error) {
+	if c.userKey == "" {
+		return nil, fmt.Errorf("user key is required")
+	}
+	var user User
+	if err := c.do(ctx, http.MethodDelete, "/users", nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// BackfillRequest asks the server to generate PRs for recent unmerged
+// branches. Branches come a page at a time in name order: set After to the
+// last branch of the previous page to get the next one.
+type BackfillRequest struct {
+	Repo   string `json:"repo"`
+	Since  string `json:"since,omitempty"` // e.g. "30d", "2w", "12h"
+	DryRun bool   `json:"dry_run,omitempty"`
+	After  string `json:"after,omitempty"`
+	Limit  int    `json:"limit,omitempty"` // the server's maximum, 25, when unset
+}
+
+// BackfillBranch is a branch picked up by a backfill run
+type BackfillBranch struct {
+	Name       string    `json:"name"`
+	AheadBy    int       `json:"ahead_by"`
+	Author     string    `json:"author"`
+	LastCommit time.Time `json:"last_commit"`
+	Message    string    `json:"message"`
+	JobID      string    `json:"job_id,omitempty"`
+}
+
+// RevertRequest asks the server to revert a merged PR or a commit. Set
+// exactly one of PR and SHA.
+type RevertRequest struct {
+	Repo   string `json:"repo"`
+	PR     int    `json:"pr,omitempty"`
+	SHA    string `json:"sha,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// RevertResult is the revert PR the server opened
+type RevertResult struct {
+	Number  int    `json:"number"`
+	URL     string `json:"url"`
+	Branch  string `json:"branch"`
+	Reverts int    `json:"reverts,omitempty"` // the original PR, when known
+}
+
+// Revert opens a PR reverting a merged PR or commit, with a description
+// generated from the original PR
+func (c *Client) Revert(ctx context.Context, req RevertRequest) (*RevertResult, error) {
+	var result RevertResult
+	if err := c.do(ctx, http.MethodPost, "/revert", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// BackportRequest asks the server to backport a merged PR to a branch
+type BackportRequest struct {
+	Repo string `json:"repo"`
+	PR   int    `json:"pr"`
+	To   string `json:"to"`
+}
+
+// BackportResult is the backport PR the server opened
+type BackportResult struct {
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+	Branch string `json:"branch"`
+	Target string `json:"target"`
+}
+
+// Backport cherry-picks a merged PR onto another branch and opens the
+// backport PR. Conflicts are explained on the original PR and returned
+// as an error.
+func (c *Client) Backport(ctx context.Context, req BackportRequest) (*BackportResult, error) {
+	var result BackportResult
+	if err := c.do(ctx, http.MethodPost, "/backport", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// TranslateRequest asks the server to translate a PR description into
+// the language with tag Lang, like ja or pt-BR
+type TranslateRequest struct {
+	Repo string `json:"repo"`
+	PR   int    `json:"pr"`
+	Lang string `json:"lang"`
+}
+
+// TranslateResult is the PR whose description got a translation
+type TranslateResult struct {
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+	Lang   string `json:"lang"`
+	Tokens int    `json:"tokens,omitempty"`
+}
+
+// Translate appends a translation of a PR's description to it, in a
+// collapsed section that later translations into the language replace
+func (c *Client) Translate(ctx context.Context, req TranslateRequest) (*TranslateResult, error) {
+	var result TranslateResult
+	if err := c.do(ctx, http.MethodPost, "/translate", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ActionItemsRequest asks the server to summarize a PR's review comments
+type ActionItemsRequest struct {
+	Repo string `json:"repo"`
+	PR   int    `json:"pr"`
+}
+
+// ActionItemsResult is the action items checklist posted on the PR
+type ActionItemsResult struct {
+	Number   int    `json:"number"`
+	URL      string `json:"url"`
+	Comments int    `json:"comments"`
+	Items    int    `json:"items"`
+	Tokens   int    `json:"tokens,omitempty"`
+}
+
+// ActionItems collects a PR's review comments into a checklist of action
+// items and posts it, editing the checklist from an earlier run
+func (c *Client) ActionItems(ctx context.Context, req ActionItemsRequest) (*ActionItemsResult, error) {
+	var result ActionItemsResult
+	if err := c.do(ctx, http.MethodPost, "/action-items", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ReleaseRequest asks the server for a release PR. Version overrides Bump.
+type ReleaseRequest struct {
+	Repo    string `json:"repo"`
+	Version string `json:"version,omitempty"`
+	Bump    string `json:"bump,omitempty"` // major, minor or patch
+}
+
+// ReleaseResult is the release PR the server opened
+type ReleaseResult struct {
+	Number  int    `json:"number"`
+	URL     string `json:"url"`
+	Version string `json:"version"`
+	Branch  string `json:"branch"`
+	PRs     int    `json:"prs"`
+}
+
+// Release opens a release PR with notes generated from the merged PRs it
+// includes
+func (c *Client) Release(ctx context.Context, req ReleaseRequest) (*ReleaseResult, error) {
+	var result ReleaseResult
+	if err := c.do(ctx, http.MethodPost, "/release", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// PromptRequest asks for the prompt a branch would be sent with
+type PromptRequest struct {
+	Repo    string `json:"repo"`
+	Branch  string `json:"branch"`
+	Message string `json:"message"` // the head commit message
+	Author  string `json:"author,omitempty"`
+}
+
+// PromptMessage is one message of a prompt
+type PromptMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// PromptResult is the prompt with its estimated size and cost
+type PromptResult struct {
+	Messages         []PromptMessage `json:"messages"`
+	Models           []string        `json:"models"`
+	PromptTokens     int             `json:"prompt_tokens"`
+	CompletionTokens int             `json:"completion_tokens"` // expected
+	Cost             float64         `json:"cost,omitempty"`    // US dollars, for the first model
+	Omitted          []string        `json:"omitted,omitempty"`
+	Notes            []string        `json:"notes,omitempty"`
+}
+
+// Prompt builds the prompt the server would send for a branch, without
+// calling the model
+func (c *Client) Prompt(ctx context.Context, req PromptRequest) (*PromptResult, error) {
+	var result PromptResult
+	if err := c.do(ctx, http.MethodPost, "/prompt", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Backfill queues PR generation for a page of unmerged branches without a
+// PR. With DryRun set, the branches are listed but no jobs are created. A
+// page shorter than the limit is the last.
+func (c *Client) Backfill(ctx context.Context, req BackfillRequest) ([]BackfillBranch, error) {
+	var branches []BackfillBranch
+	if err := c.do(ctx, http.MethodPost, "/backfill", req, &branches); err != nil {
+		return nil, err
+	}
+	return branches, nil
+}
+
+// Rule customizes PR generation for branches matching a glob pattern
+type Rule struct {
+	ID      string `json:"id,omitempty"`
+	Kind    string `json:"kind"` // branch, ignore, base, label or reviewer
+	Pattern string `json:"pattern"`
+	Value   string `json:"value,omitempty"`
+}
+
+// Rules lists the rules of a repository (owner/name, optional with one
+// configured repo)
+func (c *Client) Rules(ctx context.Context, repo string) ([]Rule, error) {
+	var rules []Rule
+	if err := c.do(ctx, http.MethodGet, "/rules?repo="+url.QueryEscape(repo), nil, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// AddRule adds a rule to a repository and returns it with its ID
+func (c *Client) AddRule(ctx context.Context, repo string, rule Rule) (*Rule, error) {
+	req := struct {
+		Repo string `json:"repo,omitempty"`
+		Rule
+	}{repo, rule}
+
+	var added Rule
+	if err := c.do(ctx, http.MethodPost, "/rules", req, &added); err != nil {
+		return nil, err
+	}
+	return &added, nil
+}
+
+// RemoveRule deletes a rule from a repository
+func (c *Client) RemoveRule(ctx context.Context, repo, id string) error {
+	q := url.Values{"repo": {repo}, "id": {id}}
+	return c.do(ctx, http.MethodDelete, "/rules?"+q.Encode(), nil, nil)
+}
+
+// HookReport is the last hook fingerprint a user's CLI sent the server.
+// Missing means GitHub saw a push from them their hooks never reported.
+type HookReport struct {
+	Repo         string     `json:"repo"`
+	User         string     `json:"user"`
+	Fingerprint  string     `json:"fingerprint"`
+	ReportedAt   time.Time  `json:"reported_at"`
+	Missing      bool       `json:"missing"`
+	MissedCommit string     `json:"missed_commit,omitempty"`
+	MissingSince *time.Time `json:"missing_since,omitempty"`
+}
+
+// HookReports lists the hook reports for a repository (owner/name,
+// optional with one configured repo), only user's when user is set
+func (c *Client) HookReports(ctx context.Context, repo, user string) ([]HookReport, error) {
+	q := url.Values{"repo": {repo}, "user": {user}}
+	var reports []HookReport
+	if err := c.do(ctx, http.MethodGet, "/hooks?"+q.Encode(), nil, &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// Report summarizes ggquick activity for a repository
+type Report struct {
+	Repo            string    `json:"repo"`
+	Since           time.Time `json:"since"`
+	Until           time.Time `json:"until"`
+	PRsGenerated    int       `json:"prs_generated"`
+	PRsMerged       int       `json:"prs_merged"`
+	FailedJobs      int       `json:"failed_jobs"`
+	AvgTimeToMerge  string    `json:"avg_time_to_merge,omitempty"`
+	TokensUsed      int       `json:"tokens_used"`
+	TopContributors []struct {
+		Login string `json:"login"`
+		PRs   int    `json:"prs"`
+	} `json:"top_contributors"`
+	// References are reference check results per model and prompt version
+	References []struct {
+		Model         string  `json:"model"`
+		PromptVersion string  `json:"prompt_version"`
+		Descriptions  int     `json:"descriptions"`
+		References    int     `json:"references"`
+		Unverified    int     `json:"unverified"`
+		Rate          float64 `json:"unverified_rate"`
+	} `json:"references,omitempty"`
+}
+
+// Report fetches the activity report for a repository (owner/name,
+// optional with one configured repo) over the last days
+func (c *Client) Report(ctx context.Context, repo string, days int) (*Report, error) {
+	q := url.Values{"repo": {repo}, "days": {strconv.Itoa(days)}}
+	var report Report
+	if err := c.do(ctx, http.MethodGet, "/reports?"+q.Encode(), nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// Export downloads every repository configuration from the server. The
+// document is returned as-is so fields unknown to this client survive a
+// round trip.
+func (c *Client) Export(ctx context.Context) (json.RawMessage, error) {
+	var data json.RawMessage
+	if err := c.do(ctx, http.MethodGet, "/admin/export", nil, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Import loads a document produced by Export and returns how many
+// repositories were imported
+func (c *Client) Import(ctx context.Context, data json.RawMessage) (int, error) {
+	var resp struct {
+		Imported int `json:"imported"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/admin/import", data, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Imported, nil
+}
+
+// Jobs lists recent jobs, newest first
+func (c *Client) Jobs(ctx context.Context) ([]Job, error) {
+	var jobs []Job
+	if err := c.do(ctx, http.MethodGet, "/jobs", nil, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// Job fetches a single job by ID
+func (c *Client) Job(ctx context.Context, id string) (*Job, error) {
+	var job Job
+	if err := c.do(ctx, http.MethodGet, "/jobs/"+id, nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ApproveJob runs a job held because its author is over the daily cap.
+// It needs the admin token.
+func (c *Client) ApproveJob(ctx context.Context, id string) (*Job, error) {
+	var job Job
+	if err := c.do(ctx, http.MethodPost, "/jobs/"+id+"/approve", nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// CancelJob stops a queued, waiting or running job. The server accepts
+// the admin token or the user key of the job's author.
+func (c *Client) CancelJob(ctx context.Context, id string) (*Job, error) {
+	var job Job
+	if err := c.do(ctx, http.MethodDelete, "/jobs/"+id, nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// WaitJob polls a job until it finishes or ctx is done
+func (c *Client) WaitJob(ctx context.Context, id string, interval time.Duration) (*Job, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		job, err := c.Job(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if job.Done() {
+			return job, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return job, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// do sends a request, retrying network errors and 5xx/429 responses
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var data []byte
+	if in != nil {
+		var err error
+		if data, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	// One key for every attempt, so the server runs a retried POST once
+	key := ""
+	if method == http.MethodPost {
+		key = newIdempotencyKey()
+	}
+
+	delay := c.RetryDelay
+	var lastErr error
+	for attempt := 0; attempt <= c.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		retry, err := c.send(ctx, method, path, key, data, out)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			return err
+		}
+	}
+	return lastErr
+}
+
+// send performs a single request and reports whether a failure is retryable
+func (c *Client) send(ctx context.Context, method, path, key string, data []byte, out interface{}) (bool, error) {
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.userKey != "" {
+		req.Header.Set("X-Ggquick-User-Key", c.userKey)
+	}
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	if c.pushSecret != "" && data != nil {
+		// Signed per attempt so retries carry a fresh timestamp
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, Sign(c.pushSecret, timestamp, data))
+	}
+	cached, hasCached := c.cached(method, path)
+	if hasCached {
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && hasCached {
+		if out == nil {
+			return false, nil
+		}
+		if err := json.Unmarshal(cached.body, out); err != nil {
+			return false, fmt.Errorf("failed to decode response: %w", err)
+		}
+		return false, nil
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(resp.Body)
+		retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return retry, &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(msg))}
+	}
+
+	if out == nil {
+		return false, nil
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return false, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if etag := resp.Header.Get("ETag"); method == http.MethodGet && etag != "" {
+		c.cache(path, cachedResponse{etag: etag, body: respBody})
+	}
+	return false, nil
+}
+
+// newIdempotencyKey returns a random key for one submission
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// cached returns the cached response for a GET path
+func (c *Client) cached(method, path string) (cachedResponse, bool) {
+	if method != http.MethodGet {
+		return cachedResponse{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	resp, ok := c.etags[path]
+	return resp, ok
+}
+
+// cache stores a GET response, dropping the cache when it is full
+func (c *Client) cache(path string, resp cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.etags == nil || len(c.etags) >= maxCachedResponses {
+		c.etags = make(map[string]cachedResponse)
+	}
+	c.etags[path] = resp
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Event is a server event received from the /events stream
+type Event struct {
+	Type    string    `json:"type"`
+	Time    time.Time `json:"time"`
+	JobID   string    `json:"job_id,omitempty"`
+	Owner   string    `json:"owner,omitempty"`
+	Repo    string    `json:"repo,omitempty"`
+	Branch  string    `json:"branch,omitempty"`
+	PRURL   string    `json:"pr_url,omitempty"`
+	Message string    `json:"message,omitempty"`
+}
+
+// Events streams server events to fn until ctx is done, the stream
+// closes, or fn returns an error
+func (c *Client) Events(ctx context.Context, fn func(Event) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/events", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	// The stream is long-lived, so the request timeout must not apply
+	stream := *c.httpClient
+	stream.Timeout = 0
+
+	resp, err := stream.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to event stream: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(msg))}
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data.Len() == 0 {
+				continue
+			}
+			var e Event
+			if err := json.Unmarshal([]byte(data.String()), &e); err != nil {
+				return fmt.Errorf("failed to decode event: %w", err)
+			}
+			data.Reset()
+			if err := fn(e); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "data:"):
+			data.WriteString(strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+		}
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("event stream failed: %w", err)
+	}
+	return io.EOF
+}
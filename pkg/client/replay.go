@@ -0,0 +1,95 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxRecordingLine fits the largest webhook payload GitHub sends
+const maxRecordingLine = 32 << 20
+
+// Recording is a request the server recorded with "ggquick serve --record"
+type Recording struct {
+	Time   time.Time         `json:"time"`
+	Method string            `json:"method"`
+	Path   string            `json:"path"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   string            `json:"body"`
+}
+
+// Event is the GitHub event of a recorded webhook, or "push" for /push
+func (r Recording) Event() string {
+	if event := r.Header["X-GitHub-Event"]; event != "" {
+		return event
+	}
+	return strings.TrimPrefix(r.Path, "/")
+}
+
+// ReadRecordings reads a record file, one request per line
+func ReadRecordings(path string) ([]Recording, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recordings: %w", err)
+	}
+	defer file.Close()
+
+	var recordings []Recording
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordingLine)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var rec Recording
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("failed to parse recording on line %d: %w", line, err)
+		}
+		recordings = append(recordings, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recordings: %w", err)
+	}
+	return recordings, nil
+}
+
+// Replay sends a recorded request to the server again and returns the
+// response status and body. It isn't retried, so a replay runs once, and
+// is sent without its webhook delivery ID so it isn't deduplicated.
+func (c *Client) Replay(ctx context.Context, rec Recording) (int, string, error) {
+	method := rec.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+rec.Path, strings.NewReader(rec.Body))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, value := range rec.Header {
+		// Without the delivery ID the server doesn't ignore it as a redelivery
+		if http.CanonicalHeaderKey(name) == "X-Github-Delivery" {
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.userKey != "" {
+		req.Header.Set("X-Ggquick-User-Key", c.userKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, strings.TrimSpace(string(body)), nil
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvFiles returns the files LoadEnvFiles reads, highest precedence first:
+// .env.local and .env in the working directory, then ~/.ggquick/env
+func EnvFiles() []string {
+	files := []string{".env.local", ".env"}
+	if home, err := os.UserHomeDir(); err == nil {
+		files = append(files, filepath.Join(home, ".ggquick", "env"))
+	}
+	return files
+}
+
+// UserEnvFile returns the per-user env file written by ggquick init
+func UserEnvFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".ggquick", "env"), nil
+}
+
+// LoadEnvFiles sets variables from the env files that aren't already set,
+// so the CLI and the server read the same settings. It returns the files
+// that were loaded.
+func LoadEnvFiles() ([]string, error) {
+	var loaded []string
+	for _, path := range EnvFiles() {
+		ok, err := loadEnvFile(path)
+		if err != nil {
+			return loaded, err
+		}
+		if ok {
+			loaded = append(loaded, path)
+		}
+	}
+	return loaded, nil
+}
+
+// loadEnvFile reads KEY=VALUE lines from path, skipping comments and
+// variables already set. A missing file is not an error.
+func loadEnvFile(path string) (bool, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			return false, fmt.Errorf("%s:%d: expected KEY=VALUE", path, n)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		// Drop a trailing comment from unquoted values
+		if i := strings.Index(value, " #"); i >= 0 && !strings.HasPrefix(value, `"`) && !strings.HasPrefix(value, "'") {
+			value = strings.TrimSpace(value[:i])
+		}
+		value = strings.Trim(value, `"'`)
+		if _, set := os.LookupEnv(key); !set {
+			os.Setenv(key, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// SetUserEnv sets key to value in the per-user env file, replacing an
+// existing assignment or appending one. An empty value removes the key.
+func SetUserEnv(key, value string) (string, error) {
+	path, err := UserEnvFile()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var lines []string
+	if content := strings.TrimRight(string(data), "\n"); content != "" {
+		for _, line := range strings.Split(content, "\n") {
+			name, _, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(line), "export "), "=")
+			if strings.TrimSpace(name) != key {
+				lines = append(lines, line)
+			}
+		}
+	}
+	if value != "" {
+		lines = append(lines, key+"="+value)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PushSecretFile returns where the push secret for repo (owner/name) is
+// kept, ~/.ggquick/push-secrets/owner/name
+func PushSecretFile(repo string) (string, error) {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" || strings.ContainsAny(name, `/\`) || owner == ".." || name == ".." {
+		return "", fmt.Errorf("invalid repository %q, want owner/name", repo)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".ggquick", "push-secrets", owner, name), nil
+}
+
+// PushSecret returns the secret that signs pushes for repo, from
+// GGQUICK_PUSH_SECRET or the secret file, or "" when there is none
+func PushSecret(repo string) string {
+	if secret := os.Getenv("GGQUICK_PUSH_SECRET"); secret != "" {
+		return secret
+	}
+	path, err := PushSecretFile(repo)
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// EnsurePushSecret returns the push secret for repo, generating and
+// saving a random one first if there is none. created reports a new
+// secret, which the server only learns when the repository is applied.
+func EnsurePushSecret(repo string) (secret string, created bool, err error) {
+	path, err := PushSecretFile(repo)
+	if err != nil {
+		return "", false, err
+	}
+	data, err := os.ReadFile(path)
+	if err == nil && strings.TrimSpace(string(data)) != "" {
+		return strings.TrimSpace(string(data)), false, nil
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", false, fmt.Errorf("failed to generate push secret: %w", err)
+	}
+	secret = hex.EncodeToString(b)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", false, fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(secret+"\n"), 0o600); err != nil {
+		return "", false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return secret, true, nil
+}
@@ -0,0 +1,153 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/ai"
+	"github.com/saint0x/ggquick/pkg/server"
+)
+
+// DefaultThreshold is the similarity a description needs to its golden
+const DefaultThreshold = 0.85
+
+// goldenExt is the extension of a case's expected description
+const goldenExt = ".golden.md"
+
+// Results of a case
+const (
+	StatusPass    = "pass"
+	StatusDrift   = "drift"
+	StatusMissing = "missing" // no golden yet
+	StatusUpdated = "updated"
+	StatusError   = "error"
+)
+
+// Case is a recorded change: the branch, commit message and the files of a
+// GitHub compare, in the API's JSON format
+type Case struct {
+	Name    string               `json:"-"`
+	Branch  string               `json:"branch"`
+	Message string               `json:"message"`
+	Files   []*github.CommitFile `json:"files"`
+	Config  *server.Config       `json:"config,omitempty"` // repository settings, default none
+	path    string
+}
+
+// Result is how a case's description compares to its golden
+type Result struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	Similarity float64 `json:"similarity"`
+	Headings   bool    `json:"headings_match"`
+	Diff       string  `json:"diff,omitempty"`
+	Error      string  `json:"error,omitempty"`
+	Tokens     int     `json:"tokens"`
+	Model      string  `json:"model,omitempty"`
+}
+
+// Options control a run
+type Options struct {
+	Threshold float64 // default DefaultThreshold
+	Update    bool    // write the descriptions as the new goldens
+}
+
+// Load reads the cases in dir, every .json file, sorted by name
+func Load(dir string) ([]Case, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cases: %w", err)
+	}
+	sort.Strings(paths)
+
+	cases := make([]Case, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read case: %w", err)
+		}
+		var c Case
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, fmt.Errorf("failed to parse case %s: %w", filepath.Base(path), err)
+		}
+		c.Name = strings.TrimSuffix(filepath.Base(path), ".json")
+		c.path = path
+		cases = append(cases, c)
+	}
+	return cases, nil
+}
+
+// golden returns the path of a case's expected description
+func (c Case) golden() string {
+	return strings.TrimSuffix(c.path, ".json") + goldenExt
+}
+
+// Run generates a description for each case and compares it to the case's
+// golden. Cases run one at a time so a real provider isn't flooded.
+func Run(ctx context.Context, gen *ai.Generator, cases []Case, opts Options) []Result {
+	if opts.Threshold == 0 {
+		opts.Threshold = DefaultThreshold
+	}
+	results := make([]Result, 0, len(cases))
+	for _, c := range cases {
+		results = append(results, runCase(ctx, gen, c, opts))
+	}
+	return results
+}
+
+// runCase generates and checks one case
+func runCase(ctx context.Context, gen *ai.Generator, c Case, opts Options) Result {
+	result := Result{Name: c.Name}
+	content, err := server.DescribeRecorded(ctx, gen, c.Config, c.Branch, c.Message, c.Files)
+	if err != nil {
+		result.Status, result.Error = StatusError, err.Error()
+		return result
+	}
+	result.Tokens, result.Model = content.TokensUsed, content.Model
+	got := strings.TrimSpace(content.Description)
+
+	if opts.Update {
+		if err := os.WriteFile(c.golden(), []byte(got+"\n"), 0644); err != nil {
+			result.Status, result.Error = StatusError, fmt.Sprintf("failed to write golden: %v", err)
+			return result
+		}
+		result.Status, result.Similarity, result.Headings = StatusUpdated, 1, true
+		return result
+	}
+
+	data, err := os.ReadFile(c.golden())
+	if os.IsNotExist(err) {
+		result.Status = StatusMissing
+		return result
+	}
+	if err != nil {
+		result.Status, result.Error = StatusError, fmt.Sprintf("failed to read golden: %v", err)
+		return result
+	}
+	want := strings.TrimSpace(string(data))
+
+	result.Similarity = Similarity(want, got)
+	result.Headings = equal(headings(want), headings(got))
+	result.Status = StatusPass
+	if result.Similarity < opts.Threshold || !result.Headings {
+		result.Status = StatusDrift
+		result.Diff = lineDiff(want, got)
+	}
+	return result
+}
+
+// Failed reports whether any result drifted, errored or lacks a golden
+func Failed(results []Result) bool {
+	for _, r := range results {
+		if r.Status != StatusPass && r.Status != StatusUpdated {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package eval
+
+import (
+	"strings"
+)
+
+// maxDiffLines caps the lines of a drift report
+const maxDiffLines = 40
+
+// Similarity scores two texts from 0 to 1 by their longest common
+// subsequence of words, so rewording a sentence costs little but dropping
+// or reordering sections costs a lot
+func Similarity(a, b string) float64 {
+	wa, wb := strings.Fields(a), strings.Fields(b)
+	if len(wa)+len(wb) == 0 {
+		return 1
+	}
+	return 2 * float64(lcs(wa, wb)) / float64(len(wa)+len(wb))
+}
+
+// lcs returns the length of the longest common subsequence of a and b
+func lcs(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] >= cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// headings returns the markdown section headings of a description
+func headings(text string) []string {
+	var out []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "#") {
+			out = append(out, strings.TrimSpace(line))
+		}
+	}
+	return out
+}
+
+// equal reports whether two string slices match
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// lineDiff is a short line diff from want to got, "-" for lines only in
+// the golden and "+" for lines only in the new description
+func lineDiff(want, got string) string {
+	a, b := strings.Split(want, "\n"), strings.Split(got, "\n")
+
+	// table[i][j] is the LCS of a[i:] and b[j:]
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else if table[i+1][j] >= table[i][j+1] {
+				table[i][j] = table[i+1][j]
+			} else {
+				table[i][j] = table[i][j+1]
+			}
+		}
+	}
+
+	var out []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i, j = i+1, j+1
+			continue
+		case j == len(b) || (i < len(a) && table[i+1][j] >= table[i][j+1]):
+			out = append(out, "- "+a[i])
+			i++
+		default:
+			out = append(out, "+ "+b[j])
+			j++
+		}
+		if len(out) == maxDiffLines {
+			out = append(out, "…")
+			break
+		}
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,507 @@
+// Package fake provides deterministic stand-ins for GitHub, used by the
+// server's sandbox mode so demos and integration tests make no external
+// calls
+package fake
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// DefaultBranch is every sandbox repository's default branch
+const DefaultBranch = "main"
+
+// nonWord is replaced in branch names to make file names
+var nonWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Comment is a comment left on a sandbox issue or pull request
+type Comment struct {
+	Repo   string    `json:"repo"`
+	Number int       `json:"number"`
+	Body   string    `json:"body"`
+	Time   time.Time `json:"time"`
+}
+
+// GitHub is an in-memory GitHub. Every branch is one commit ahead of its
+// base with a small change derived from the branch name, and pull
+// requests, comments, labels, tags and releases are kept in memory.
+type GitHub struct {
+	mu       sync.Mutex
+	baseURL  string // pull request links point here
+	prs      []*github.PullRequest
+	comments []Comment
+	tags     map[string][]string
+	commits  map[string]string // sha to message, for commits made here
+}
+
+// NewGitHub creates an empty sandbox GitHub whose pull request links
+// point below baseURL
+func NewGitHub(baseURL string) *GitHub {
+	return &GitHub{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		tags:    make(map[string][]string),
+		commits: make(map[string]string),
+	}
+}
+
+// PullRequests returns every pull request created, newest first
+func (g *GitHub) PullRequests() []*github.PullRequest {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	prs := make([]*github.PullRequest, len(g.prs))
+	for i, pr := range g.prs {
+		prs[len(prs)-1-i] = pr
+	}
+	return prs
+}
+
+// Comments returns the comments on a pull request or issue, oldest first
+func (g *GitHub) Comments(repo string, number int) []Comment {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var comments []Comment
+	for _, c := range g.comments {
+		if c.Repo == repo && c.Number == number {
+			comments = append(comments, c)
+		}
+	}
+	return comments
+}
+
+// sha derives a stable commit SHA from its parts
+func sha(parts ...string) string {
+	sum := sha1.Sum([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// find returns a pull request; the caller holds the lock
+func (g *GitHub) find(owner, repo string, number int) *github.PullRequest {
+	name := owner + "/" + repo
+	for _, pr := range g.prs {
+		if pr.GetBase().GetRepo().GetFullName() == name && pr.GetNumber() == number {
+			return pr
+		}
+	}
+	return nil
+}
+
+// CreatePullRequest stores a pull request, numbered per repository
+func (g *GitHub) CreatePullRequest(_ context.Context, owner, repo string, pr *github.NewPullRequest) (*github.PullRequest, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	name := owner + "/" + repo
+	head := pr.GetHead()
+	if _, branch, ok := strings.Cut(head, ":"); ok {
+		head = branch
+	}
+	number := 1
+	for _, existing := range g.prs {
+		if existing.GetBase().GetRepo().GetFullName() == name {
+			if existing.GetHead().GetRef() == head && existing.GetState() == "open" {
+				return nil, fmt.Errorf("a pull request already exists for %s", pr.GetHead())
+			}
+			number = existing.GetNumber() + 1
+		}
+	}
+
+	repository := &github.Repository{FullName: github.String(name), Name: github.String(repo)}
+	created := &github.PullRequest{
+		Number:    github.Int(number),
+		State:     github.String("open"),
+		Title:     github.String(pr.GetTitle()),
+		Body:      github.String(pr.GetBody()),
+		Draft:     github.Bool(pr.GetDraft()),
+		HTMLURL:   github.String(fmt.Sprintf("%s/sandbox#%s/%d", g.baseURL, name, number)),
+		CreatedAt: &github.Timestamp{Time: time.Now()},
+		User:      &github.User{Login: github.String("ggquick-sandbox")},
+		Head:      &github.PullRequestBranch{Ref: github.String(head), SHA: github.String(sha(name, head)), Repo: repository},
+		Base:      &github.PullRequestBranch{Ref: github.String(pr.GetBase()), SHA: github.String(sha(name, pr.GetBase())), Repo: repository},
+	}
+	g.prs = append(g.prs, created)
+	return created, nil
+}
+
+// GetDefaultBranch returns main
+func (g *GitHub) GetDefaultBranch(context.Context, string, string) (string, error) {
+	return DefaultBranch, nil
+}
+
+// GetCommitMessage returns the message of a commit made in the sandbox,
+// or one derived from the SHA
+func (g *GitHub) GetCommitMessage(_ context.Context, _, _, sha string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if message, ok := g.commits[sha]; ok {
+		return message, nil
+	}
+	if len(sha) > 7 {
+		sha = sha[:7]
+	}
+	return "Sandbox commit " + sha, nil
+}
+
+// GetBranches returns the default branch and the heads of open pull
+// requests
+func (g *GitHub) GetBranches(_ context.Context, owner, repo string) ([]*github.Branch, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	name := owner + "/" + repo
+	branches := []*github.Branch{{Name: github.String(DefaultBranch), Commit: &github.RepositoryCommit{SHA: github.String(sha(name, DefaultBranch))}}}
+	for _, pr := range g.prs {
+		if pr.GetBase().GetRepo().GetFullName() == name && pr.GetState() == "open" {
+			branches = append(branches, &github.Branch{Name: pr.Head.Ref, Commit: &github.RepositoryCommit{SHA: pr.Head.SHA}})
+		}
+	}
+	return branches, nil
+}
+
+// CompareBranches returns head one commit ahead of base, adding a source
+// file and its test named after the branch
+func (g *GitHub) CompareBranches(_ context.Context, owner, repo, base, head string) (*github.CommitsComparison, error) {
+	name := owner + "/" + repo
+	slug := strings.Trim(nonWord.ReplaceAllString(strings.ToLower(head[strings.LastIndex(head, "/")+1:]), "_"), "_")
+	if slug == "" {
+		slug = "change"
+	}
+	var ident string
+	for _, word := range strings.Split(slug, "_") {
+		ident += strings.ToUpper(word[:1]) + word[1:]
+	}
+
+	source := fmt.Sprintf("@@ -0,0 +1,6 @@\n+package sandbox\n+\n+// %s is the change made on %s\n+func %s() string {\n+\treturn %q\n+}", ident, head, ident, head)
+	test := fmt.Sprintf("@@ -0,0 +1,9 @@\n+package sandbox\n+\n+import \"testing\"\n+\n+func Test%s(t *testing.T) {\n+\tif %s() == \"\" {\n+\t\tt.Fatal(\"empty\")\n+\t}\n+}", ident, ident)
+	commit := &github.RepositoryCommit{
+		SHA: github.String(shaOf(owner, repo, head)),
+		Commit: &github.Commit{
+			Message: github.String("Add " + strings.ReplaceAll(slug, "_", " ")),
+			Author:  &github.CommitAuthor{Name: github.String("Sandbox"), Email: github.String("sandbox@example.com")},
+		},
+		Author: &github.User{Login: github.String("ggquick-sandbox")},
+	}
+	return &github.CommitsComparison{
+		Status:          github.String("ahead"),
+		AheadBy:         github.Int(1),
+		TotalCommits:    github.Int(1),
+		BaseCommit:      &github.RepositoryCommit{SHA: github.String(sha(name, base))},
+		MergeBaseCommit: &github.RepositoryCommit{SHA: github.String(sha(name, base))},
+		Commits:         []*github.RepositoryCommit{commit},
+		Files: []*github.CommitFile{
+			{Filename: github.String("sandbox/" + slug + ".go"), Status: github.String("added"), Additions: github.Int(6), Changes: github.Int(6), Patch: github.String(source)},
+			{Filename: github.String("sandbox/" + slug + "_test.go"), Status: github.String("added"), Additions: github.Int(9), Changes: github.Int(9), Patch: github.String(test)},
+		},
+	}, nil
+}
+
+// HasOpenPR reports whether a branch has an open sandbox pull request
+func (g *GitHub) HasOpenPR(_ context.Context, owner, repo, head string) (bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, branch, ok := strings.Cut(head, ":"); ok {
+		head = branch
+	}
+	for _, pr := range g.prs {
+		if pr.GetBase().GetRepo().GetFullName() == owner+"/"+repo && pr.GetHead().GetRef() == head && pr.GetState() == "open" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetPRs returns a repository's pull requests, newest first
+func (g *GitHub) GetPRs(_ context.Context, owner, repo string, limit int) ([]*github.PullRequest, error) {
+	var prs []*github.PullRequest
+	for _, pr := range g.PullRequests() {
+		if pr.GetBase().GetRepo().GetFullName() == owner+"/"+repo && (limit <= 0 || len(prs) < limit) {
+			prs = append(prs, pr)
+		}
+	}
+	return prs, nil
+}
+
+// GetMergedPRs returns a repository's pull requests merged since the
+// given time
+func (g *GitHub) GetMergedPRs(ctx context.Context, owner, repo string, since time.Time) ([]*github.PullRequest, error) {
+	prs, _ := g.GetPRs(ctx, owner, repo, 0)
+	var merged []*github.PullRequest
+	for _, pr := range prs {
+		if pr.MergedAt != nil && !pr.GetMergedAt().Before(since) {
+			merged = append(merged, pr)
+		}
+	}
+	return merged, nil
+}
+
+// GetOpenPRTitles returns the titles of a repository's open pull requests
+func (g *GitHub) GetOpenPRTitles(ctx context.Context, owner, repo string) ([]string, error) {
+	prs, _ := g.GetPRs(ctx, owner, repo, 0)
+	var titles []string
+	for _, pr := range prs {
+		if pr.GetState() == "open" {
+			titles = append(titles, pr.GetTitle())
+		}
+	}
+	return titles, nil
+}
+
+// GetOpenPRs returns up to limit open pull requests, newest first
+func (g *GitHub) GetOpenPRs(ctx context.Context, owner, repo string, limit int) ([]*github.PullRequest, error) {
+	prs, _ := g.GetPRs(ctx, owner, repo, 0)
+	var open []*github.PullRequest
+	for _, pr := range prs {
+		if pr.GetState() == "open" && (limit <= 0 || len(open) < limit) {
+			open = append(open, pr)
+		}
+	}
+	return open, nil
+}
+
+// GetPRFiles returns the files of a sandbox pull request's branch
+func (g *GitHub) GetPRFiles(ctx context.Context, owner, repo string, number int) ([]*github.CommitFile, error) {
+	pr, err := g.GetPullRequest(ctx, owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+	comp, err := g.CompareBranches(ctx, owner, repo, pr.GetBase().GetRef(), pr.GetHead().GetRef())
+	if err != nil {
+		return nil, err
+	}
+	return comp.Files, nil
+}
+
+// GetFileCommits returns no commits; sandbox files have no history
+func (g *GitHub) GetFileCommits(context.Context, string, string, string, string, int) ([]*github.RepositoryCommit, error) {
+	return nil, nil
+}
+
+// AddLabels labels a sandbox pull request; labels on issues are dropped
+func (g *GitHub) AddLabels(_ context.Context, owner, repo string, number int, labels []string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if pr := g.find(owner, repo, number); pr != nil {
+		for _, label := range labels {
+			pr.Labels = append(pr.Labels, &github.Label{Name: github.String(label)})
+		}
+	}
+	return nil
+}
+
+// RequestReviewers records the reviewers requested on a pull request
+func (g *GitHub) RequestReviewers(_ context.Context, owner, repo string, number int, reviewers []string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	pr := g.find(owner, repo, number)
+	if pr == nil {
+		return fmt.Errorf("pull request #%d not found", number)
+	}
+	for _, login := range reviewers {
+		pr.RequestedReviewers = append(pr.RequestedReviewers, &github.User{Login: github.String(login)})
+	}
+	return nil
+}
+
+// CreateComment stores a comment
+func (g *GitHub) CreateComment(_ context.Context, owner, repo string, number int, body string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.comments = append(g.comments, Comment{Repo: owner + "/" + repo, Number: number, Body: body, Time: time.Now()})
+	return nil
+}
+
+// CreateReview stores the review's body and each of its line comments
+// as comments
+func (g *GitHub) CreateReview(ctx context.Context, owner, repo string, number int, review *github.PullRequestReviewRequest) error {
+	if body := review.GetBody(); body != "" {
+		if err := g.CreateComment(ctx, owner, repo, number, body); err != nil {
+			return err
+		}
+	}
+	for _, c := range review.Comments {
+		body := fmt.Sprintf("%s:%d: %s", c.GetPath(), c.GetLine(), c.GetBody())
+		if err := g.CreateComment(ctx, owner, repo, number, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpsertComment replaces the body of the first comment containing
+// marker, or stores a new one
+func (g *GitHub) UpsertComment(ctx context.Context, owner, repo string, number int, marker, body string) error {
+	g.mu.Lock()
+	for i, c := range g.comments {
+		if c.Repo == owner+"/"+repo && c.Number == number && strings.Contains(c.Body, marker) {
+			g.comments[i].Body = body
+			g.mu.Unlock()
+			return nil
+		}
+	}
+	g.mu.Unlock()
+	return g.CreateComment(ctx, owner, repo, number, body)
+}
+
+// GetReviews returns no reviews; nobody reviews sandbox pull requests
+func (g *GitHub) GetReviews(context.Context, string, string, int) ([]*github.PullRequestReview, error) {
+	return nil, nil
+}
+
+// GetReviewComments returns no review comments
+func (g *GitHub) GetReviewComments(context.Context, string, string, int) ([]*github.PullRequestComment, error) {
+	return nil, nil
+}
+
+// GetPRsForCommit returns the pull requests whose head is sha
+func (g *GitHub) GetPRsForCommit(ctx context.Context, owner, repo, sha string) ([]*github.PullRequest, error) {
+	prs, _ := g.GetPRs(ctx, owner, repo, 0)
+	var found []*github.PullRequest
+	for _, pr := range prs {
+		if pr.GetHead().GetSHA() == sha {
+			found = append(found, pr)
+		}
+	}
+	return found, nil
+}
+
+// UpdatePRBody replaces a pull request's description
+func (g *GitHub) UpdatePRBody(_ context.Context, owner, repo string, number int, body string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	pr := g.find(owner, repo, number)
+	if pr == nil {
+		return fmt.Errorf("pull request #%d not found", number)
+	}
+	pr.Body = github.String(body)
+	return nil
+}
+
+// GetWorkflowRuns returns no runs; the sandbox has no CI
+func (g *GitHub) GetWorkflowRuns(context.Context, string, string, string) ([]*github.WorkflowRun, error) {
+	return nil, nil
+}
+
+// CreateStatus does nothing; the sandbox shows no checks
+func (g *GitHub) CreateStatus(context.Context, string, string, string, *github.RepoStatus) error {
+	return nil
+}
+
+// GetPullRequest returns a sandbox pull request
+func (g *GitHub) GetPullRequest(_ context.Context, owner, repo string, number int) (*github.PullRequest, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	pr := g.find(owner, repo, number)
+	if pr == nil {
+		return nil, fmt.Errorf("failed to get pull request #%d: not found", number)
+	}
+	return pr, nil
+}
+
+// CreateRevertBranch records the revert commit
+func (g *GitHub) CreateRevertBranch(_ context.Context, owner, repo, _, sha, branch, message string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.commits[shaOf(owner, repo, branch)] = message
+	return nil
+}
+
+// CreateCherryPickBranch succeeds without conflicts
+func (g *GitHub) CreateCherryPickBranch(context.Context, string, string, string, string, string) error {
+	return nil
+}
+
+// GetTags returns the tags created in the sandbox, newest first
+func (g *GitHub) GetTags(_ context.Context, owner, repo string) ([]string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	tags := append([]string(nil), g.tags[owner+"/"+repo]...)
+	sort.Sort(sort.Reverse(sort.StringSlice(tags)))
+	return tags, nil
+}
+
+// CreateTag stores a tag
+func (g *GitHub) CreateTag(_ context.Context, owner, repo, tag, _ string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	name := owner + "/" + repo
+	for _, existing := range g.tags[name] {
+		if existing == tag {
+			return fmt.Errorf("tag %s already exists", tag)
+		}
+	}
+	g.tags[name] = append(g.tags[name], tag)
+	return nil
+}
+
+// CreateRelease succeeds; the notes are already on the release job
+func (g *GitHub) CreateRelease(context.Context, string, string, string, string, string) error {
+	return nil
+}
+
+// CommitFiles records the commit on the branch
+func (g *GitHub) CommitFiles(_ context.Context, owner, repo, _, branch, message string, _ map[string]string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.commits[shaOf(owner, repo, branch)] = message
+	return nil
+}
+
+// GetUpstream returns no upstream; sandbox repositories aren't forks
+func (g *GitHub) GetUpstream(context.Context, string, string) (string, error) {
+	return "", nil
+}
+
+// GetContributingGuide returns no guide
+func (g *GitHub) GetContributingGuide(context.Context, string, string) (string, error) {
+	return "", nil
+}
+
+// CountMergedPRs counts the author's merged sandbox pull requests, none
+func (g *GitHub) CountMergedPRs(context.Context, string, string, string) (int, error) {
+	return 0, nil
+}
+
+// GetLanguages reports Go, the language of every sandbox change
+func (g *GitHub) GetLanguages(context.Context, string, string) (map[string]int, error) {
+	return map[string]int{"Go": 1}, nil
+}
+
+// GetFileContent finds no files
+func (g *GitHub) GetFileContent(_ context.Context, _, _, path, _ string) (string, error) {
+	return "", fmt.Errorf("failed to get %s: not found", path)
+}
+
+// UsePRContentForSquash does nothing
+func (g *GitHub) UsePRContentForSquash(context.Context, string, string) error {
+	return nil
+}
+
+// shaOf returns the head SHA of a sandbox branch
+func shaOf(owner, repo, branch string) string {
+	return sha(owner+"/"+repo, branch)
+}
+
+// Hooks stands in for webhook management
+type Hooks struct{}
+
+// CreateHook does nothing; sandbox webhooks are sent by hand
+func (Hooks) CreateHook(context.Context, string, string, string, string, []string) error {
+	return nil
+}
+
+// DeleteHook does nothing
+func (Hooks) DeleteHook(context.Context, string, string) error { return nil }
+
+// FailedDeliveries finds none; the sandbox has no delivery log
+func (Hooks) FailedDeliveries(context.Context, string, string, time.Time) ([]*github.HookDelivery, error) {
+	return nil, nil
+}
+
+// Redeliver does nothing
+func (Hooks) Redeliver(context.Context, string, string, int64) error { return nil }
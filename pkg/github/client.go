@@ -2,12 +2,16 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/httpclient"
 	"github.com/saint0x/ggquick/pkg/log"
 	"golang.org/x/oauth2"
 )
@@ -25,11 +29,18 @@ func New(logger *log.Logger) *Client {
 		logger.Error("GITHUB_TOKEN environment variable not set")
 		return nil
 	}
+	return NewWithToken(logger, token)
+}
 
+// NewWithToken creates a GitHub client authenticated as token's owner
+func NewWithToken(logger *log.Logger, token string) *Client {
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
-	tc := oauth2.NewClient(context.Background(), ts)
+	// oauth2 builds on the client in the context, so GitHub calls share
+	// the tuned transport
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, httpclient.New(0))
+	tc := oauth2.NewClient(ctx, ts)
 
 	return &Client{
 		client: github.NewClient(tc),
@@ -37,6 +48,18 @@ func New(logger *log.Logger) *Client {
 	}
 }
 
+// NewWithBaseURL creates a client for the GitHub API at baseURL, such as a
+// test server
+func NewWithBaseURL(logger *log.Logger, token, baseURL string) (*Client, error) {
+	c := NewWithToken(logger, token)
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/") + "/")
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub API URL: %w", err)
+	}
+	c.client.BaseURL = u
+	return c, nil
+}
+
 // CreatePullRequest creates a new pull request
 func (c *Client) CreatePullRequest(ctx context.Context, owner, repo string, pr *github.NewPullRequest) (*github.PullRequest, error) {
 	pullRequest, _, err := c.client.PullRequests.Create(ctx, owner, repo, pr)
@@ -46,6 +69,15 @@ func (c *Client) CreatePullRequest(ctx context.Context, owner, repo string, pr *
 	return pullRequest, nil
 }
 
+// Login returns the login of the user the client is authenticated as
+func (c *Client) Login(ctx context.Context) (string, error) {
+	user, _, err := c.client.Users.Get(ctx, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to get authenticated user: %w", err)
+	}
+	return user.GetLogin(), nil
+}
+
 // GetDefaultBranch gets the default branch for a repository
 func (c *Client) GetDefaultBranch(ctx context.Context, owner, repo string) (string, error) {
 	repository, _, err := c.client.Repositories.Get(ctx, owner, repo)
@@ -115,6 +147,17 @@ func (c *Client) GetContributingGuide(ctx context.Context, owner, repo string) (
 	return "", fmt.Errorf("no contributing guide found")
 }
 
+// CountMergedPRs returns how many PRs by author have been merged into a
+// repository
+func (c *Client) CountMergedPRs(ctx context.Context, owner, repo, author string) (int, error) {
+	query := fmt.Sprintf("repo:%s/%s is:pr is:merged author:%s", owner, repo, author)
+	result, _, err := c.client.Search.Issues(ctx, query, &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 1}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to search merged PRs: %w", err)
+	}
+	return result.GetTotal(), nil
+}
+
 // GetBranches gets all branches for a repository
 func (c *Client) GetBranches(ctx context.Context, owner, repo string) ([]*github.Branch, error) {
 	var allBranches []*github.Branch
@@ -141,23 +184,108 @@ func (c *Client) GetBranches(ctx context.Context, owner, repo string) ([]*github
 	return allBranches, nil
 }
 
-// GetPRs gets recent pull requests
-func (c *Client) GetPRs(ctx context.Context, owner, repo string, limit int) ([]*github.PullRequest, error) {
+// GetMergedPRs lists the pull requests merged since the given time,
+// most recently updated first
+func (c *Client) GetMergedPRs(ctx context.Context, owner, repo string, since time.Time) ([]*github.PullRequest, error) {
 	opts := &github.PullRequestListOptions{
-		State: "all",
-		ListOptions: github.ListOptions{
-			PerPage: limit,
-		},
+		State:       "closed",
+		Sort:        "updated",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var merged []*github.PullRequest
+	for {
+		prs, resp, err := c.client.PullRequests.List(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list PRs: %w", err)
+		}
+		for _, pr := range prs {
+			// Merging updates a PR, so the rest were merged earlier
+			if pr.GetUpdatedAt().Before(since) {
+				return merged, nil
+			}
+			if pr.MergedAt != nil && !pr.GetMergedAt().Before(since) {
+				merged = append(merged, pr)
+			}
+		}
+		if resp.NextPage == 0 {
+			return merged, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// GetOpenPRTitles lists the titles of all open pull requests
+func (c *Client) GetOpenPRTitles(ctx context.Context, owner, repo string) ([]string, error) {
+	opts := &github.PullRequestListOptions{
+		State:       "open",
+		ListOptions: github.ListOptions{PerPage: 100},
 	}
 
+	var titles []string
+	for {
+		prs, resp, err := c.client.PullRequests.List(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list PRs: %w", err)
+		}
+		for _, pr := range prs {
+			titles = append(titles, pr.GetTitle())
+		}
+		if resp.NextPage == 0 {
+			return titles, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// GetOpenPRs lists up to limit open pull requests, most recently
+// updated first
+func (c *Client) GetOpenPRs(ctx context.Context, owner, repo string, limit int) ([]*github.PullRequest, error) {
+	opts := &github.PullRequestListOptions{
+		State:       "open",
+		Sort:        "updated",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: limit},
+	}
 	prs, _, err := c.client.PullRequests.List(ctx, owner, repo, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list PRs: %w", err)
 	}
-
 	return prs, nil
 }
 
+// GetPRFiles returns the files a pull request changes
+func (c *Client) GetPRFiles(ctx context.Context, owner, repo string, number int) ([]*github.CommitFile, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	var all []*github.CommitFile
+	for {
+		files, resp, err := c.client.PullRequests.ListFiles(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files of PR #%d: %w", number, err)
+		}
+		all = append(all, files...)
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// GetFileCommits returns up to limit of the latest commits on ref that
+// changed path, newest first
+func (c *Client) GetFileCommits(ctx context.Context, owner, repo, path, ref string, limit int) ([]*github.RepositoryCommit, error) {
+	commits, _, err := c.client.Repositories.ListCommits(ctx, owner, repo, &github.CommitsListOptions{
+		SHA:         ref,
+		Path:        path,
+		ListOptions: github.ListOptions{PerPage: limit},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits for %s: %w", path, err)
+	}
+	return commits, nil
+}
+
 // GetDiff gets the diff for a branch
 func (c *Client) GetDiff(ctx context.Context, owner, repo, base, head string) (string, error) {
 	comp, _, err := c.client.Repositories.CompareCommits(
@@ -184,3 +312,469 @@ func (c *Client) GetCommitMessage(ctx context.Context, owner, repo, sha string)
 
 	return commit.GetMessage(), nil
 }
+
+// ErrBranchNotFound is returned when a branch isn't on GitHub, e.g. one
+// committed locally but not pushed yet
+var ErrBranchNotFound = errors.New("branch not found")
+
+// CompareBranches compares head against base. A head branch that isn't
+// on GitHub returns ErrBranchNotFound.
+func (c *Client) CompareBranches(ctx context.Context, owner, repo, base, head string) (*github.CommitsComparison, error) {
+	comp, resp, err := c.client.Repositories.CompareCommits(ctx, owner, repo, base, head, &github.ListOptions{})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound && c.branchMissing(ctx, owner, repo, head) {
+			err = ErrBranchNotFound
+		}
+		return nil, fmt.Errorf("failed to compare %s...%s: %w", base, head, err)
+	}
+	return comp, nil
+}
+
+// branchMissing reports whether branch is missing from a repository the
+// token can read. Missing repositories and ones the token can't see 404
+// as well, and aren't missing branches.
+func (c *Client) branchMissing(ctx context.Context, owner, repo, branch string) bool {
+	_, resp, err := c.client.Git.GetRef(ctx, owner, repo, "heads/"+branch)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusNotFound {
+		return false
+	}
+	_, _, err = c.client.Repositories.Get(ctx, owner, repo)
+	return err == nil
+}
+
+// HasOpenPR reports whether a head already has an open pull request. A
+// bare branch name is qualified with owner; forks pass "forkowner:branch".
+func (c *Client) HasOpenPR(ctx context.Context, owner, repo, head string) (bool, error) {
+	if !strings.Contains(head, ":") {
+		head = owner + ":" + head
+	}
+	opts := &github.PullRequestListOptions{
+		State: "open",
+		Head:  head,
+		ListOptions: github.ListOptions{
+			PerPage: 1,
+		},
+	}
+
+	prs, _, err := c.client.PullRequests.List(ctx, owner, repo, opts)
+	if err != nil {
+		return false, fmt.Errorf("failed to list PRs: %w", err)
+	}
+	return len(prs) > 0, nil
+}
+
+// RequestReviewers requests reviews on a pull request. Entries of the
+// form org/team are requested as team reviewers.
+func (c *Client) RequestReviewers(ctx context.Context, owner, repo string, number int, reviewers []string) error {
+	var req github.ReviewersRequest
+	for _, r := range reviewers {
+		if i := strings.Index(r, "/"); i >= 0 {
+			req.TeamReviewers = append(req.TeamReviewers, r[i+1:])
+		} else {
+			req.Reviewers = append(req.Reviewers, r)
+		}
+	}
+
+	_, _, err := c.client.PullRequests.RequestReviewers(ctx, owner, repo, number, req)
+	if err != nil {
+		return fmt.Errorf("failed to request reviewers: %w", err)
+	}
+	return nil
+}
+
+// CreateComment posts a comment on an issue or pull request
+func (c *Client) CreateComment(ctx context.Context, owner, repo string, number int, body string) error {
+	_, _, err := c.client.Issues.CreateComment(ctx, owner, repo, number, &github.IssueComment{Body: github.String(body)})
+	if err != nil {
+		return fmt.Errorf("failed to create comment: %w", err)
+	}
+	return nil
+}
+
+// CreateReview submits a review on a pull request
+func (c *Client) CreateReview(ctx context.Context, owner, repo string, number int, review *github.PullRequestReviewRequest) error {
+	if _, _, err := c.client.PullRequests.CreateReview(ctx, owner, repo, number, review); err != nil {
+		return fmt.Errorf("failed to create review: %w", err)
+	}
+	return nil
+}
+
+// UpsertComment edits the first comment on an issue or pull request
+// containing marker to body, or posts body when there is none
+func (c *Client) UpsertComment(ctx context.Context, owner, repo string, number int, marker, body string) error {
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := c.client.Issues.ListComments(ctx, owner, repo, number, opts)
+		if err != nil {
+			return fmt.Errorf("failed to list comments: %w", err)
+		}
+		for _, comment := range comments {
+			if strings.Contains(comment.GetBody(), marker) {
+				_, _, err := c.client.Issues.EditComment(ctx, owner, repo, comment.GetID(), &github.IssueComment{Body: github.String(body)})
+				if err != nil {
+					return fmt.Errorf("failed to edit comment: %w", err)
+				}
+				return nil
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return c.CreateComment(ctx, owner, repo, number, body)
+}
+
+// GetReviews returns the reviews submitted on a pull request, oldest first
+func (c *Client) GetReviews(ctx context.Context, owner, repo string, number int) ([]*github.PullRequestReview, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	var all []*github.PullRequestReview
+	for {
+		reviews, resp, err := c.client.PullRequests.ListReviews(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list reviews: %w", err)
+		}
+		all = append(all, reviews...)
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// GetReviewComments returns the line comments left in reviews of a pull
+// request, oldest first
+func (c *Client) GetReviewComments(ctx context.Context, owner, repo string, number int) ([]*github.PullRequestComment, error) {
+	opts := &github.PullRequestListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	var all []*github.PullRequestComment
+	for {
+		comments, resp, err := c.client.PullRequests.ListComments(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list review comments: %w", err)
+		}
+		all = append(all, comments...)
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// GetPRsForCommit returns the pull requests containing a commit
+func (c *Client) GetPRsForCommit(ctx context.Context, owner, repo, sha string) ([]*github.PullRequest, error) {
+	prs, _, err := c.client.PullRequests.ListPullRequestsWithCommit(ctx, owner, repo, sha, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests for commit: %w", err)
+	}
+	return prs, nil
+}
+
+// GetPullRequest returns a pull request by number
+func (c *Client) GetPullRequest(ctx context.Context, owner, repo string, number int) (*github.PullRequest, error) {
+	pr, _, err := c.client.PullRequests.Get(ctx, owner, repo, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request #%d: %w", number, err)
+	}
+	return pr, nil
+}
+
+// CreateRevertBranch creates branch from base with sha reverted. The
+// inverse of sha (its first parent's tree on top of it) is merged into the
+// branch, so later changes to the same files are kept. The branch is
+// removed again if the revert conflicts or changes nothing.
+func (c *Client) CreateRevertBranch(ctx context.Context, owner, repo, base, sha, branch, message string) error {
+	commit, _, err := c.client.Git.GetCommit(ctx, owner, repo, sha)
+	if err != nil {
+		return fmt.Errorf("failed to get commit %s: %w", sha, err)
+	}
+	if len(commit.Parents) == 0 {
+		return fmt.Errorf("commit %s has no parent to revert to", sha)
+	}
+	parent, _, err := c.client.Git.GetCommit(ctx, owner, repo, commit.Parents[0].GetSHA())
+	if err != nil {
+		return fmt.Errorf("failed to get parent commit: %w", err)
+	}
+	inverse, _, err := c.client.Git.CreateCommit(ctx, owner, repo, &github.Commit{
+		Message: github.String(message),
+		Tree:    parent.Tree,
+		Parents: []*github.Commit{{SHA: github.String(sha)}},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create revert commit: %w", err)
+	}
+
+	baseRef, _, err := c.client.Git.GetRef(ctx, owner, repo, "refs/heads/"+base)
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", base, err)
+	}
+	_, _, err = c.client.Git.CreateRef(ctx, owner, repo, &github.Reference{
+		Ref:    github.String("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: baseRef.Object.SHA},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create branch %s: %w", branch, err)
+	}
+
+	merged, resp, err := c.client.Repositories.Merge(ctx, owner, repo, &github.RepositoryMergeRequest{
+		Base:          github.String(branch),
+		Head:          inverse.SHA,
+		CommitMessage: github.String(message),
+	})
+	if err == nil && merged != nil {
+		return nil
+	}
+	c.client.Git.DeleteRef(ctx, owner, repo, "refs/heads/"+branch)
+	switch {
+	case resp != nil && resp.StatusCode == http.StatusConflict:
+		return fmt.Errorf("reverting %s conflicts with later changes on %s, revert it locally", sha, base)
+	case err != nil:
+		return fmt.Errorf("failed to apply revert: %w", err)
+	}
+	return fmt.Errorf("%s is already reverted on %s", sha, base)
+}
+
+// ErrConflict is returned when a change doesn't apply cleanly
+var ErrConflict = errors.New("merge conflict")
+
+// CreateCherryPickBranch creates branch from base with sha cherry-picked
+// onto it, as git cherry-pick -x -m 1 would. A temporary commit with base's
+// tree on sha's first parent lets the merges API apply just sha's changes;
+// the result is then recommitted on base. Conflicts return ErrConflict and
+// leave no branch behind.
+func (c *Client) CreateCherryPickBranch(ctx context.Context, owner, repo, base, sha, branch string) error {
+	commit, _, err := c.client.Git.GetCommit(ctx, owner, repo, sha)
+	if err != nil {
+		return fmt.Errorf("failed to get commit %s: %w", sha, err)
+	}
+	if len(commit.Parents) == 0 {
+		return fmt.Errorf("commit %s has no parent to diff against", sha)
+	}
+	baseRef, _, err := c.client.Git.GetRef(ctx, owner, repo, "refs/heads/"+base)
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", base, err)
+	}
+	baseCommit, _, err := c.client.Git.GetCommit(ctx, owner, repo, baseRef.Object.GetSHA())
+	if err != nil {
+		return fmt.Errorf("failed to get %s head: %w", base, err)
+	}
+
+	sibling, _, err := c.client.Git.CreateCommit(ctx, owner, repo, &github.Commit{
+		Message: github.String("ggquick cherry-pick base"),
+		Tree:    baseCommit.Tree,
+		Parents: []*github.Commit{{SHA: commit.Parents[0].SHA}},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create cherry-pick base: %w", err)
+	}
+	_, _, err = c.client.Git.CreateRef(ctx, owner, repo, &github.Reference{
+		Ref:    github.String("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: sibling.SHA},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create branch %s: %w", branch, err)
+	}
+
+	merged, resp, err := c.client.Repositories.Merge(ctx, owner, repo, &github.RepositoryMergeRequest{
+		Base: github.String(branch),
+		Head: github.String(sha),
+	})
+	if err != nil || merged == nil {
+		c.client.Git.DeleteRef(ctx, owner, repo, "refs/heads/"+branch)
+		switch {
+		case resp != nil && resp.StatusCode == http.StatusConflict:
+			return ErrConflict
+		case err != nil:
+			return fmt.Errorf("failed to apply %s: %w", sha, err)
+		}
+		return fmt.Errorf("%s is already on %s", sha, base)
+	}
+
+	picked, _, err := c.client.Git.CreateCommit(ctx, owner, repo, &github.Commit{
+		Message: github.String(fmt.Sprintf("%s\n\n(cherry picked from commit %s)", strings.TrimSpace(commit.GetMessage()), sha)),
+		Tree:    &github.Tree{SHA: merged.GetCommit().GetTree().SHA},
+		Parents: []*github.Commit{{SHA: baseCommit.SHA}},
+	}, nil)
+	if err == nil {
+		_, _, err = c.client.Git.UpdateRef(ctx, owner, repo, &github.Reference{
+			Ref:    github.String("refs/heads/" + branch),
+			Object: &github.GitObject{SHA: picked.SHA},
+		}, true)
+	}
+	if err != nil {
+		c.client.Git.DeleteRef(ctx, owner, repo, "refs/heads/"+branch)
+		return fmt.Errorf("failed to commit cherry-pick: %w", err)
+	}
+	return nil
+}
+
+// GetWorkflowRuns returns the GitHub Actions runs for a commit
+func (c *Client) GetWorkflowRuns(ctx context.Context, owner, repo, sha string) ([]*github.WorkflowRun, error) {
+	runs, _, err := c.client.Actions.ListRepositoryWorkflowRuns(ctx, owner, repo, &github.ListWorkflowRunsOptions{
+		HeadSHA:     sha,
+		ListOptions: github.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workflow runs: %w", err)
+	}
+	return runs.WorkflowRuns, nil
+}
+
+// CreateStatus sets a commit status on a commit
+func (c *Client) CreateStatus(ctx context.Context, owner, repo, sha string, status *github.RepoStatus) error {
+	if _, _, err := c.client.Repositories.CreateStatus(ctx, owner, repo, sha, status); err != nil {
+		return fmt.Errorf("failed to set commit status: %w", err)
+	}
+	return nil
+}
+
+// UpdatePRBody replaces a pull request's description
+func (c *Client) UpdatePRBody(ctx context.Context, owner, repo string, number int, body string) error {
+	_, _, err := c.client.PullRequests.Edit(ctx, owner, repo, number, &github.PullRequest{Body: github.String(body)})
+	if err != nil {
+		return fmt.Errorf("failed to update pull request: %w", err)
+	}
+	return nil
+}
+
+// GetLanguages returns the bytes of code per language in a repository
+func (c *Client) GetLanguages(ctx context.Context, owner, repo string) (map[string]int, error) {
+	langs, _, err := c.client.Repositories.ListLanguages(ctx, owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list languages: %w", err)
+	}
+	return langs, nil
+}
+
+// GetFileContent returns the content of a file at ref
+func (c *Client) GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error) {
+	file, _, _, err := c.client.Repositories.GetContents(ctx, owner, repo, path, &github.RepositoryContentGetOptions{Ref: ref})
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s: %w", path, err)
+	}
+	if file == nil {
+		return "", fmt.Errorf("%s is a directory", path)
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return "", fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return content, nil
+}
+
+// GetTags returns the names of a repository's most recent tags
+func (c *Client) GetTags(ctx context.Context, owner, repo string) ([]string, error) {
+	tags, _, err := c.client.Repositories.ListTags(ctx, owner, repo, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.GetName())
+	}
+	return names, nil
+}
+
+// CreateTag creates a lightweight tag pointing at sha
+func (c *Client) CreateTag(ctx context.Context, owner, repo, tag, sha string) error {
+	_, _, err := c.client.Git.CreateRef(ctx, owner, repo, &github.Reference{
+		Ref:    github.String("refs/tags/" + tag),
+		Object: &github.GitObject{SHA: github.String(sha)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create tag %s: %w", tag, err)
+	}
+	return nil
+}
+
+// CreateRelease creates a GitHub Release, and its tag at sha when the tag
+// doesn't exist yet
+func (c *Client) CreateRelease(ctx context.Context, owner, repo, tag, sha, notes string) error {
+	_, _, err := c.client.Repositories.CreateRelease(ctx, owner, repo, &github.RepositoryRelease{
+		TagName:         github.String(tag),
+		TargetCommitish: github.String(sha),
+		Name:            github.String(tag),
+		Body:            github.String(notes),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create release %s: %w", tag, err)
+	}
+	return nil
+}
+
+// CommitFiles creates branch from base with one commit writing files,
+// which maps paths to their new content
+func (c *Client) CommitFiles(ctx context.Context, owner, repo, base, branch, message string, files map[string]string) error {
+	baseRef, _, err := c.client.Git.GetRef(ctx, owner, repo, "refs/heads/"+base)
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", base, err)
+	}
+	parent, _, err := c.client.Git.GetCommit(ctx, owner, repo, baseRef.Object.GetSHA())
+	if err != nil {
+		return fmt.Errorf("failed to get %s head: %w", base, err)
+	}
+
+	entries := make([]*github.TreeEntry, 0, len(files))
+	for path, content := range files {
+		entries = append(entries, &github.TreeEntry{
+			Path:    github.String(path),
+			Mode:    github.String("100644"),
+			Type:    github.String("blob"),
+			Content: github.String(content),
+		})
+	}
+	tree, _, err := c.client.Git.CreateTree(ctx, owner, repo, parent.GetTree().GetSHA(), entries)
+	if err != nil {
+		return fmt.Errorf("failed to create tree: %w", err)
+	}
+	commit, _, err := c.client.Git.CreateCommit(ctx, owner, repo, &github.Commit{
+		Message: github.String(message),
+		Tree:    tree,
+		Parents: []*github.Commit{{SHA: parent.SHA}},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create commit: %w", err)
+	}
+	_, _, err = c.client.Git.CreateRef(ctx, owner, repo, &github.Reference{
+		Ref:    github.String("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: commit.SHA},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create branch %s: %w", branch, err)
+	}
+	return nil
+}
+
+// GetUpstream returns the parent repository (owner/name) of a fork, or
+// "" when the repository is not a fork
+func (c *Client) GetUpstream(ctx context.Context, owner, repo string) (string, error) {
+	repository, _, err := c.client.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		return "", fmt.Errorf("failed to get repository: %w", err)
+	}
+	if !repository.GetFork() {
+		return "", nil
+	}
+	return repository.GetParent().GetFullName(), nil
+}
+
+// UsePRContentForSquash makes squash merges default to the pull request
+// title and body for the commit message
+func (c *Client) UsePRContentForSquash(ctx context.Context, owner, repo string) error {
+	_, _, err := c.client.Repositories.Edit(ctx, owner, repo, &github.Repository{
+		SquashMergeCommitTitle:   github.String("PR_TITLE"),
+		SquashMergeCommitMessage: github.String("PR_BODY"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update merge settings: %w", err)
+	}
+	return nil
+}
+
+// AddLabels adds labels to an issue or pull request
+func (c *Client) AddLabels(ctx context.Context, owner, repo string, number int, labels []string) error {
+	_, _, err := c.client.Issues.AddLabelsToIssue(ctx, owner, repo, number, labels)
+	if err != nil {
+		return fmt.Errorf("failed to add labels: %w", err)
+	}
+	return nil
+}
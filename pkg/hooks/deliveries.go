@@ -0,0 +1,81 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// FailedDeliveries lists deliveries of our webhook since the given time
+// that never succeeded, the latest attempt of each. A delivery GitHub
+// redelivered successfully isn't listed.
+func (m *Manager) FailedDeliveries(ctx context.Context, owner, repo string, since time.Time) ([]*github.HookDelivery, error) {
+	hook, err := m.findHook(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	if hook == nil {
+		return nil, nil
+	}
+
+	// Deliveries come newest first, so the first attempt seen is the latest
+	latest := make(map[string]*github.HookDelivery)
+	succeeded := make(map[string]bool)
+	var order []string
+	opts := &github.ListCursorOptions{PerPage: 100}
+	for {
+		deliveries, resp, err := m.github.Repositories.ListHookDeliveries(ctx, owner, repo, hook.GetID(), opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+		}
+		done := resp.Cursor == ""
+		for _, d := range deliveries {
+			if d.GetDeliveredAt().Time.Before(since) {
+				done = true
+				break
+			}
+			guid := d.GetGUID()
+			if code := d.GetStatusCode(); code >= 200 && code < 300 {
+				succeeded[guid] = true
+			}
+			if latest[guid] == nil {
+				latest[guid] = d
+				order = append(order, guid)
+			}
+		}
+		if done {
+			break
+		}
+		opts.Cursor = resp.Cursor
+	}
+
+	var failed []*github.HookDelivery
+	for _, guid := range order {
+		if !succeeded[guid] {
+			failed = append(failed, latest[guid])
+		}
+	}
+	return failed, nil
+}
+
+// Redeliver asks GitHub to send a delivery of our webhook again
+func (m *Manager) Redeliver(ctx context.Context, owner, repo string, deliveryID int64) error {
+	hook, err := m.findHook(ctx, owner, repo)
+	if err != nil {
+		return err
+	}
+	if hook == nil {
+		return fmt.Errorf("webhook not found")
+	}
+	if _, _, err := m.github.Repositories.RedeliverHookDelivery(ctx, owner, repo, hook.GetID(), deliveryID); err != nil {
+		// GitHub answers 202, which go-github reports as an error
+		var accepted *github.AcceptedError
+		if !errors.As(err, &accepted) {
+			return fmt.Errorf("failed to redeliver webhook delivery %d: %w", deliveryID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package hooks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Hook states reported by Status
+const (
+	HooksCurrent  = "current"
+	HooksOutdated = "outdated"
+	HooksMissing  = "missing"
+)
+
+// HookStatus describes the ggquick hooks installed in a repository
+type HookStatus struct {
+	State       string   `json:"state"`
+	Fingerprint string   `json:"fingerprint,omitempty"`
+	Version     int      `json:"version,omitempty"`
+	Latest      int      `json:"latest"`
+	Missing     []string `json:"missing,omitempty"`
+	Outdated    []string `json:"outdated,omitempty"`
+}
+
+// Fingerprint returns the fingerprint of the hooks installed in repoPath,
+// or "" when any of them is missing or isn't a ggquick hook
+func Fingerprint(repoPath string) (string, error) {
+	h := sha256.New()
+	for _, name := range HookNames {
+		content, err := readHook(repoPath, name)
+		if err != nil {
+			return "", err
+		}
+		if !isGGQuickHook(content) {
+			return "", nil
+		}
+		fmt.Fprintf(h, "%s\x00%s\x00", name, content)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
+// Status compares the hooks installed in repoPath with the template
+// version this build writes. A fresh clone has none, so it reports missing.
+func (m *Manager) Status(repoPath string) (HookStatus, error) {
+	status := HookStatus{Latest: HookVersion}
+	for _, name := range HookNames {
+		content, err := readHook(repoPath, name)
+		if err != nil {
+			return status, err
+		}
+		if !isGGQuickHook(content) {
+			status.Missing = append(status.Missing, name)
+			continue
+		}
+		version := hookVersion(content)
+		if status.Version == 0 || version < status.Version {
+			status.Version = version
+		}
+		if version < HookVersion {
+			status.Outdated = append(status.Outdated, name)
+		}
+	}
+
+	fingerprint, err := Fingerprint(repoPath)
+	if err != nil {
+		return status, err
+	}
+	status.Fingerprint = fingerprint
+	switch {
+	case len(status.Missing) > 0:
+		status.State = HooksMissing
+	case len(status.Outdated) > 0:
+		status.State = HooksOutdated
+	default:
+		status.State = HooksCurrent
+	}
+	return status, nil
+}
+
+// readHook returns a hook's content, or "" when it doesn't exist
+func readHook(repoPath, name string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(repoPath, ".git", "hooks", name))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s hook: %w", name, err)
+	}
+	return string(content), nil
+}
+
+// isGGQuickHook reports whether a hook script was written by ggquick, so a
+// user's own hook isn't mistaken for an outdated one
+func isGGQuickHook(content string) bool {
+	return strings.Contains(content, "# ggquick ")
+}
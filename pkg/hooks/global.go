@@ -0,0 +1,96 @@
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GlobalTemplateDir returns the git template directory InstallGlobal
+// writes, ~/.ggquick/git-template
+func GlobalTemplateDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".ggquick", "git-template"), nil
+}
+
+// InstallGlobal writes the hooks into a git template directory and points
+// init.templateDir at it, so every repository cloned or initialized from
+// now on gets them. Running git init in an existing repository adds them
+// there too. An init.templateDir set to another directory is left alone.
+func (m *Manager) InstallGlobal(opts Options) (string, error) {
+	dir, err := GlobalTemplateDir()
+	if err != nil {
+		return "", err
+	}
+	if current := globalTemplateDir(); current != "" && !sameDir(current, dir) {
+		return "", fmt.Errorf("init.templateDir is already set to %s; add the hooks there with ggquick init or unset it first", current)
+	}
+
+	hooksDir := filepath.Join(dir, "hooks")
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create template directory: %w", err)
+	}
+	for _, name := range HookNames {
+		content, err := renderHook(name, opts)
+		if err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(filepath.Join(hooksDir, name), []byte(content), 0755); err != nil {
+			return "", fmt.Errorf("failed to write %s template: %w", name, err)
+		}
+	}
+
+	if out, err := exec.Command("git", "config", "--global", "init.templateDir", dir).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to set init.templateDir: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return dir, nil
+}
+
+// UninstallGlobal unsets init.templateDir when it points at the ggquick
+// template and removes the template. Hooks already copied into
+// repositories stay; remove them with RemoveHooks.
+func (m *Manager) UninstallGlobal() error {
+	dir, err := GlobalTemplateDir()
+	if err != nil {
+		return err
+	}
+	if current := globalTemplateDir(); current != "" && sameDir(current, dir) {
+		if out, err := exec.Command("git", "config", "--global", "--unset", "init.templateDir").CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to unset init.templateDir: %s: %w", strings.TrimSpace(string(out)), err)
+		}
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove template directory: %w", err)
+	}
+	return nil
+}
+
+// GlobalInstalled reports whether init.templateDir points at the ggquick
+// template
+func GlobalInstalled() bool {
+	dir, err := GlobalTemplateDir()
+	if err != nil {
+		return false
+	}
+	current := globalTemplateDir()
+	return current != "" && sameDir(current, dir)
+}
+
+// globalTemplateDir returns the configured init.templateDir, or ""
+func globalTemplateDir() string {
+	out, err := exec.Command("git", "config", "--global", "--path", "--get", "init.templateDir").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// sameDir reports whether two paths name the same directory
+func sameDir(a, b string) bool {
+	return filepath.Clean(a) == filepath.Clean(b)
+}
@@ -3,6 +3,7 @@ package hooks
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -61,6 +62,20 @@ func (m *Manager) InitGitHub(token string) error {
 	return nil
 }
 
+// InitGitHubWithBaseURL initializes the GitHub client against the API at
+// baseURL, such as a test server
+func (m *Manager) InitGitHubWithBaseURL(token, baseURL string) error {
+	if err := m.InitGitHub(token); err != nil {
+		return err
+	}
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/") + "/")
+	if err != nil {
+		return fmt.Errorf("invalid GitHub API URL: %w", err)
+	}
+	m.github.BaseURL = u
+	return nil
+}
+
 // CreatePullRequest creates a new pull request
 func (m *Manager) CreatePullRequest(ctx context.Context, owner, repo string, opts *PullRequestOptions) (*github.PullRequest, error) {
 	pr, _, err := m.github.PullRequests.Create(ctx, owner, repo, &github.NewPullRequest{
@@ -85,37 +100,61 @@ func (m *Manager) CreatePullRequest(ctx context.Context, owner, repo string, opt
 	return pr, nil
 }
 
-// InstallHooks installs git hooks in the repository
+// InstallHooks installs git hooks in the repository, keeping the options
+// of hooks already installed so upgrades don't lose them
 func (m *Manager) InstallHooks(repoPath string) error {
-	// Install post-commit hook
-	hook := `#!/bin/sh
-# ggquick post-commit hook
-if [ -z "$GGQUICK_DISABLED" ]; then
-	curl -s -X POST "https://ggquick.fly.dev/push" \
-		-H "Content-Type: application/json" \
-		-d "{\"ref\":\"$(git rev-parse --abbrev-ref HEAD)\",\"sha\":\"$(git rev-parse HEAD)\"}" >/dev/null || true
-fi
-`
-
-	// Write hook file
-	if err := writeHook(repoPath, "post-commit", hook); err != nil {
-		return fmt.Errorf("failed to install post-commit hook: %w", err)
+	return m.InstallHooksWith(repoPath, InstalledOptions(repoPath))
+}
+
+// InstallHooksWith installs git hooks generated with opts. A hook that
+// ggquick didn't write is moved to <name>.local, which the new hook runs.
+func (m *Manager) InstallHooksWith(repoPath string, opts Options) error {
+	hooksDir := filepath.Join(repoPath, ".git", "hooks")
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
 	}
+	for _, name := range HookNames {
+		content, err := renderHook(name, opts)
+		if err != nil {
+			return err
+		}
+		if err := keepLocalHook(repoPath, name); err != nil {
+			return err
+		}
+		if err := writeHook(repoPath, name, content); err != nil {
+			return fmt.Errorf("failed to install %s hook: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// HookNames lists the git hooks InstallHooks writes
+var HookNames = []string{"post-commit", "post-push"}
 
-	// Install post-push hook
-	hook = `#!/bin/sh
-# ggquick post-push hook
-if [ -z "$GGQUICK_DISABLED" ]; then
-	curl -s -X POST "https://ggquick.fly.dev/push" \
-		-H "Content-Type: application/json" \
-		-d "{\"ref\":\"$(git rev-parse --abbrev-ref HEAD)\",\"sha\":\"$(git rev-parse HEAD)\"}" >/dev/null || true
-fi
-`
-
-	if err := writeHook(repoPath, "post-push", hook); err != nil {
-		return fmt.Errorf("failed to install post-push hook: %w", err)
+// InstalledOptions returns the options of the installed hooks, or the
+// defaults when there are none
+func InstalledOptions(repoPath string) Options {
+	content, err := readHook(repoPath, HookNames[0])
+	if err != nil || !isGGQuickHook(content) {
+		return Options{}
 	}
+	return hookOptions(content)
+}
 
+// keepLocalHook moves a hook ggquick didn't write aside to <name>.local
+// so installing doesn't destroy it
+func keepLocalHook(repoPath, name string) error {
+	content, err := readHook(repoPath, name)
+	if err != nil || content == "" || isGGQuickHook(content) {
+		return err
+	}
+	path := filepath.Join(repoPath, ".git", "hooks", name)
+	if _, err := os.Stat(path + ".local"); err == nil {
+		return fmt.Errorf("both %s and %s.local exist, move one of them first", name, name)
+	}
+	if err := os.Rename(path, path+".local"); err != nil {
+		return fmt.Errorf("failed to keep existing %s hook: %w", name, err)
+	}
 	return nil
 }
 
@@ -134,60 +173,32 @@ func (m *Manager) UpdateRepo(info *RepoInfo) error {
 	if info.Path == "" {
 		return fmt.Errorf("repository path is required")
 	}
-
-	// Create hooks directory if it doesn't exist
-	hooksDir := filepath.Join(info.Path, ".git", "hooks")
-	if err := os.MkdirAll(hooksDir, 0755); err != nil {
-		return fmt.Errorf("failed to create hooks directory: %w", err)
-	}
-
-	// Install post-commit hook
-	postCommitPath := filepath.Join(hooksDir, "post-commit")
-	if err := os.WriteFile(postCommitPath, []byte(postCommitHook), 0755); err != nil {
-		return fmt.Errorf("failed to install post-commit: %w", err)
-	}
-
-	// Install post-push hook
-	postPushPath := filepath.Join(hooksDir, "post-push")
-	if err := os.WriteFile(postPushPath, []byte(postPushHook), 0755); err != nil {
-		return fmt.Errorf("failed to install post-push: %w", err)
-	}
-
-	return nil
+	return m.InstallHooks(info.Path)
 }
 
-const postCommitHook = `#!/bin/sh
-# ggquick post-commit hook
-if [ -z "$GGQUICK_DISABLED" ]; then
-	curl -s -X POST "https://ggquick.fly.dev/webhook" \
-		-H "Content-Type: application/json" \
-		-d "{\"ref\":\"$(git rev-parse --abbrev-ref HEAD)\",\"sha\":\"$(git rev-parse HEAD)\"}" >/dev/null || true
-fi
-`
-
-const postPushHook = `#!/bin/sh
-# ggquick post-push hook
-if [ -z "$GGQUICK_DISABLED" ]; then
-	curl -s -X POST "https://ggquick.fly.dev/webhook" \
-		-H "Content-Type: application/json" \
-		-d "{\"ref\":\"$(git rev-parse --abbrev-ref HEAD)\",\"sha\":\"$(git rev-parse HEAD)\"}" >/dev/null || true
-fi
-`
-
-// RemoveHooks removes all hooks from a repository
+// RemoveHooks removes all hooks from a repository, restoring the hooks
+// they replaced
 func (m *Manager) RemoveHooks(repoPath string) error {
 	// Get hooks directory path
 	hooksDir := filepath.Join(repoPath, ".git", "hooks")
 
-	// List of hooks to remove
-	hooks := []string{"post-commit", "post-push"}
-
 	// Remove each hook
-	for _, hook := range hooks {
+	for _, hook := range HookNames {
+		// Leave hooks ggquick didn't write
+		content, err := readHook(repoPath, hook)
+		if err != nil {
+			return err
+		}
+		if content != "" && !isGGQuickHook(content) {
+			continue
+		}
 		hookPath := filepath.Join(hooksDir, hook)
 		if err := os.Remove(hookPath); err != nil && !os.IsNotExist(err) {
 			return fmt.Errorf("failed to remove %s: %w", hook, err)
 		}
+		if err := os.Rename(hookPath+".local", hookPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to restore %s: %w", hook, err)
+		}
 	}
 
 	return nil
@@ -204,48 +215,72 @@ func (m *Manager) ValidateGitRepo(path string) error {
 
 // CheckWebhook checks if our webhook already exists for the repository
 func (m *Manager) CheckWebhook(ctx context.Context, owner, repo string) (bool, error) {
+	hook, err := m.findHook(ctx, owner, repo)
+	return hook != nil, err
+}
+
+// findHook returns our webhook on the repository, or nil
+func (m *Manager) findHook(ctx context.Context, owner, repo string) (*github.Hook, error) {
 	// List all hooks
 	hooks, _, err := m.github.Repositories.ListHooks(ctx, owner, repo, nil)
 	if err != nil {
-		return false, fmt.Errorf("failed to list webhooks: %w", err)
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
 	}
 
 	// Check if our webhook exists
 	for _, hook := range hooks {
 		if url, ok := hook.Config["url"].(string); ok {
 			if strings.Contains(url, "ggquick") {
-				return true, nil
+				return hook, nil
 			}
 		}
 	}
 
-	return false, nil
+	return nil, nil
 }
 
-// CreateHook creates a webhook in the GitHub repository if it doesn't exist
-func (m *Manager) CreateHook(ctx context.Context, owner, repo, url string) error {
+// CreateHook creates a webhook in the GitHub repository sending events,
+// signed with secret when it is set, or brings an existing one up to date
+func (m *Manager) CreateHook(ctx context.Context, owner, repo, url, secret string, events []string) error {
 	// Check if webhook already exists
-	exists, err := m.CheckWebhook(ctx, owner, repo)
+	existing, err := m.findHook(ctx, owner, repo)
 	if err != nil {
 		return fmt.Errorf("failed to check webhook: %w", err)
 	}
 
-	if exists {
-		m.logger.Info("✨ Webhook already exists")
-		return nil
-	}
-
 	// Create webhook configuration
 	config := map[string]interface{}{
 		"url":          url,
 		"content_type": "json",
 		"insecure_ssl": "0",
 	}
+	if secret != "" {
+		config["secret"] = secret
+	}
+
+	if existing != nil {
+		// GitHub never returns the secret, so a signed hook's config is
+		// always rewritten in case the secret changed
+		if sameEvents(existing.Events, events) && secret == "" {
+			m.logger.Info("✨ Webhook already exists")
+			return nil
+		}
+		edit := &github.Hook{Events: events}
+		if secret != "" {
+			edit.Config = config
+		}
+		_, _, err := m.github.Repositories.EditHook(ctx, owner, repo, existing.GetID(), edit)
+		if err != nil {
+			return fmt.Errorf("failed to update webhook: %w", err)
+		}
+		m.logger.Success("✅ Updated webhook events: %s", strings.Join(events, ", "))
+		return nil
+	}
 
 	// Create webhook
 	hook := &github.Hook{
 		Config: config,
-		Events: []string{"push"},
+		Events: events,
 		Active: github.Bool(true),
 	}
 
@@ -259,6 +294,23 @@ func (m *Manager) CreateHook(ctx context.Context, owner, repo, url string) error
 	return nil
 }
 
+// sameEvents reports whether two event lists hold the same events
+func sameEvents(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[string]bool, len(a))
+	for _, e := range a {
+		seen[e] = true
+	}
+	for _, e := range b {
+		if !seen[e] {
+			return false
+		}
+	}
+	return true
+}
+
 // DeleteHook deletes the webhook from the GitHub repository
 func (m *Manager) DeleteHook(ctx context.Context, owner, repo string) error {
 	// List all hooks
@@ -0,0 +1,61 @@
+package hooks
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFile lists branch patterns, one per line, that the hooks don't
+// report. A file without patterns silences the whole repository.
+const IgnoreFile = ".ggquick-ignore"
+
+// OptOut returns why the hooks shouldn't report branch in the repository
+// at root, or "" when they should. `git config ggquick.disable true`
+// silences a repository, or every repository with --global; the ignore
+// file silences matching branches.
+func OptOut(root, branch string) string {
+	out, _ := exec.Command("git", "-C", root, "config", "--bool", "ggquick.disable").Output()
+	if strings.TrimSpace(string(out)) == "true" {
+		return "ggquick.disable is set"
+	}
+
+	patterns, err := ignorePatterns(filepath.Join(root, IgnoreFile))
+	if err != nil {
+		return ""
+	}
+	if len(patterns) == 0 {
+		return IgnoreFile + " has no patterns"
+	}
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, branch); ok || p == branch {
+			return fmt.Sprintf("%s matches %s", p, IgnoreFile)
+		}
+	}
+	return ""
+}
+
+// ignorePatterns reads the branch patterns of an ignore file, skipping
+// blank lines and # comments
+func ignorePatterns(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, scanner.Err()
+}
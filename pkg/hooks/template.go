@@ -0,0 +1,213 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/client"
+)
+
+// HookVersion is written into every generated hook. Bump it when the
+// template changes so installed hooks are reported outdated and migrated.
+const HookVersion = 5
+
+// defaultHookTimeout bounds how long a hook waits for the server
+const defaultHookTimeout = 10 * time.Second
+
+// Options configure the generated hooks. They are written into the hooks
+// so a reinstall keeps them.
+type Options struct {
+	// ServerURL pins the server; empty uses GGQUICK_SERVER or the default
+	ServerURL string `json:"server_url,omitempty"`
+	// DisableEnv names the variable that skips the hook when set,
+	// GGQUICK_DISABLED by default
+	DisableEnv string `json:"disable_env,omitempty"`
+	// Blocking makes git wait for the report. By default it runs in the
+	// background so a slow network never holds up a commit.
+	Blocking bool `json:"blocking,omitempty"`
+	// Timeout bounds the report, 10s by default
+	Timeout time.Duration `json:"timeout,omitempty"`
+	// AuthHeader is sent by the curl fallback, like
+	// "X-Ggquick-User-Key: $GGQUICK_USER_KEY". Variables expand when the
+	// hook runs.
+	AuthHeader string `json:"auth_header,omitempty"`
+}
+
+var (
+	envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	versionPattern = regexp.MustCompile(`(?m)^# ggquick \S+ hook v(\d+)$`)
+	optionsPattern = regexp.MustCompile(`(?m)^# ggquick-options: (.*)$`)
+)
+
+// validate rejects options that can't be written into a shell script
+// safely
+func (o Options) validate() error {
+	if o.ServerURL != "" {
+		u, err := url.Parse(o.ServerURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid server URL %q", o.ServerURL)
+		}
+		if strings.ContainsAny(o.ServerURL, "'\"`$\\ \n") {
+			return fmt.Errorf("server URL %q contains shell metacharacters", o.ServerURL)
+		}
+	}
+	if o.DisableEnv != "" && !envNamePattern.MatchString(o.DisableEnv) {
+		return fmt.Errorf("invalid variable name %q", o.DisableEnv)
+	}
+	if o.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	if strings.ContainsAny(o.AuthHeader, "\"`\\\n") || (o.AuthHeader != "" && !strings.Contains(o.AuthHeader, ":")) {
+		return fmt.Errorf("invalid auth header %q", o.AuthHeader)
+	}
+	return nil
+}
+
+// withDefaults fills in the defaults for unset options
+func (o Options) withDefaults() Options {
+	if o.DisableEnv == "" {
+		o.DisableEnv = "GGQUICK_DISABLED"
+	}
+	if o.Timeout == 0 {
+		o.Timeout = defaultHookTimeout
+	}
+	return o
+}
+
+// hookData is what the hook template renders
+type hookData struct {
+	Options
+	Name     string
+	Version  int
+	Encoded  string
+	Server   string
+	Deadline int
+}
+
+// hookTemplate is the script for every hook. It reports through ggquick
+// notify, or curl when the CLI isn't on PATH, then runs the hook it
+// replaced, which InstallHooks keeps as <name>.local. Unless blocking,
+// notify detaches itself and curl runs in the background, both bounded by
+// the timeout. notify checks OptOut itself; ggquick_opted_out repeats the
+// check for curl with shell patterns, where * also matches /. curl signs
+// with the push secret using openssl, like notify, and its errors reach
+// stderr so a rejected push isn't silent.
+var hookTemplate = template.Must(template.New("hook").Parse(`#!/bin/sh
+# ggquick {{.Name}} hook v{{.Version}}
+# ggquick-options: {{.Encoded}}
+# Generated by ggquick. Reinstall with ggquick doctor --fix rather than editing.
+
+ggquick_opted_out() {
+	[ "$(git config --bool ggquick.disable 2>/dev/null)" = "true" ] && return 0
+	ignore="$(git rev-parse --show-toplevel 2>/dev/null)/.ggquick-ignore"
+	[ -f "$ignore" ] || return 1
+	branch=$(git rev-parse --abbrev-ref HEAD 2>/dev/null)
+	patterns=0
+	while read -r pattern || [ -n "$pattern" ]; do
+		case "$pattern" in ''|'#'*) continue ;; esac
+		patterns=1
+		case "$branch" in $pattern) return 0 ;; esac
+	done <"$ignore"
+	[ "$patterns" = 0 ]
+}
+
+ggquick_post() {
+	repo=$(git remote get-url origin 2>/dev/null | sed -n 's#/$##; s#\.git$##; s#.*github\.com[:/]\([^/]*/[^/]*\)$#\1#p')
+	body="{\"ref\":\"$(git rev-parse --abbrev-ref HEAD)\",\"sha\":\"$(git rev-parse HEAD)\",\"repo\":\"$repo\",\"author\":\"$(git config github.user)\"}"
+	secret=${GGQUICK_PUSH_SECRET:-$(cat "$HOME/.ggquick/push-secrets/$repo" 2>/dev/null)}
+	set --
+	if [ -n "$secret" ]; then
+		if ! command -v openssl >/dev/null 2>&1; then
+			echo "ggquick: install ggquick or openssl to sign pushes for $repo" >&2
+			return 1
+		fi
+		timestamp=$(date +%s)
+		signature=$(printf '%s.%s' "$timestamp" "$body" | openssl dgst -sha256 -hmac "$secret" | sed 's/^.* //')
+		set -- -H "X-Ggquick-Timestamp: $timestamp" -H "X-Ggquick-Signature: sha256=$signature"
+	fi
+	curl -sS --fail --max-time {{.Deadline}} -X POST "{{.Server}}/push" \
+		-H "Content-Type: application/json" \
+{{- if .AuthHeader}}
+		-H "{{.AuthHeader}}" \
+{{- end}}
+		"$@" -d "$body" </dev/null >/dev/null
+}
+
+ggquick_report() {
+	if command -v ggquick >/dev/null 2>&1; then
+		ggquick notify{{if not .Blocking}} --async{{end}}{{if .ServerURL}} --server '{{.ServerURL}}'{{end}} --timeout {{.Timeout}} >/dev/null 2>&1
+	elif ! ggquick_opted_out; then
+		ggquick_post{{if not .Blocking}} &{{end}}
+	fi
+}
+
+if [ -z "${{.DisableEnv}}" ]; then
+	ggquick_report || true
+fi
+
+if [ -x "$0.local" ]; then
+	"$0.local" "$@"
+fi
+`))
+
+// renderHook returns the script for hook name with opts
+func renderHook(name string, opts Options) (string, error) {
+	if err := opts.validate(); err != nil {
+		return "", err
+	}
+	encoded, err := json.Marshal(opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode hook options: %w", err)
+	}
+
+	data := hookData{
+		Options: opts.withDefaults(),
+		Name:    name,
+		Version: HookVersion,
+		Encoded: string(encoded),
+		Server:  "${GGQUICK_SERVER:-" + client.DefaultBaseURL + "}",
+	}
+	if opts.ServerURL != "" {
+		data.Server = opts.ServerURL
+	}
+	data.Deadline = int(data.Timeout.Round(time.Second) / time.Second)
+	if data.Deadline < 1 {
+		data.Deadline = 1
+	}
+
+	var buf bytes.Buffer
+	if err := hookTemplate.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s hook: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// hookVersion returns the template version a ggquick hook was generated
+// from. Hooks written before versioning count as version 1.
+func hookVersion(content string) int {
+	m := versionPattern.FindStringSubmatch(content)
+	if m == nil {
+		return 1
+	}
+	version, _ := strconv.Atoi(m[1])
+	return version
+}
+
+// hookOptions returns the options a ggquick hook was generated with, or
+// the defaults for hooks written before options existed
+func hookOptions(content string) Options {
+	var opts Options
+	if m := optionsPattern.FindStringSubmatch(content); m != nil {
+		if err := json.Unmarshal([]byte(m[1]), &opts); err != nil || opts.validate() != nil {
+			return Options{}
+		}
+	}
+	return opts
+}
@@ -0,0 +1,146 @@
+package hooks_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/saint0x/ggquick/internal/testsupport"
+	"github.com/saint0x/ggquick/pkg/client"
+	"github.com/saint0x/ggquick/pkg/hooks"
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+// received is a push the fake server got from a hook
+type received struct {
+	body      map[string]string
+	timestamp string
+	signature string
+	raw       []byte
+}
+
+// pushServer records the pushes hooks send
+func pushServer(t *testing.T) (*httptest.Server, chan received) {
+	t.Helper()
+	pushes := make(chan received, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/push" {
+			http.NotFound(w, r)
+			return
+		}
+		raw, _ := io.ReadAll(r.Body)
+		var body map[string]string
+		if err := json.Unmarshal(raw, &body); err != nil {
+			t.Errorf("hook sent invalid JSON %q: %v", raw, err)
+		}
+		pushes <- received{
+			body:      body,
+			timestamp: r.Header.Get(client.TimestampHeader),
+			signature: r.Header.Get(client.SignatureHeader),
+			raw:       raw,
+		}
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, pushes
+}
+
+// hookRepo creates a repository with ggquick hooks installed and an
+// origin on GitHub. The returned environment limits PATH to the tools the
+// hook needs, so it falls back to curl.
+func hookRepo(t *testing.T, opts hooks.Options) (*testsupport.Repo, []string) {
+	t.Helper()
+	bin := t.TempDir()
+	for _, tool := range []string{"git", "curl", "openssl", "sed", "cat", "date", "sh"} {
+		path, err := exec.LookPath(tool)
+		if err != nil {
+			t.Skipf("%s not installed", tool)
+		}
+		if err := os.Symlink(path, filepath.Join(bin, tool)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repo := testsupport.BuildRepo(t, testsupport.RepoSpec{})
+	repo.Checkout("feature", true)
+	repo.Git("remote", "add", "origin", "git@github.com:acme/widgets.git")
+	repo.Git("config", "github.user", "octocat")
+	if err := hooks.New(log.New(false)).InstallHooksWith(repo.Dir, opts); err != nil {
+		t.Fatal(err)
+	}
+	env := []string{"PATH=" + bin, "HOME=" + t.TempDir(), "GIT_CONFIG_NOSYSTEM=1"}
+	return repo, env
+}
+
+// commit makes a commit in dir, which runs the post-commit hook
+func commit(t *testing.T, repo *testsupport.Repo, env []string) string {
+	t.Helper()
+	cmd := exec.Command("git", "commit", "-q", "--allow-empty", "-m", "Add widgets")
+	cmd.Dir = repo.Dir
+	cmd.Env = env
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git commit: %v\n%s", err, out)
+	}
+	return string(out)
+}
+
+func TestHookReportsWithCurl(t *testing.T) {
+	srv, pushes := pushServer(t)
+	repo, env := hookRepo(t, hooks.Options{ServerURL: srv.URL, Blocking: true})
+	commit(t, repo, env)
+
+	push := <-pushes
+	if push.body["ref"] != "feature" || push.body["repo"] != "acme/widgets" || push.body["author"] != "octocat" {
+		t.Errorf("push = %v, want feature of acme/widgets by octocat", push.body)
+	}
+	if len(push.body["sha"]) != 40 {
+		t.Errorf("sha = %q, want a full commit hash", push.body["sha"])
+	}
+	if push.signature != "" {
+		t.Errorf("push without a secret was signed: %q", push.signature)
+	}
+}
+
+func TestHookSignsWithPushSecret(t *testing.T) {
+	srv, pushes := pushServer(t)
+	repo, env := hookRepo(t, hooks.Options{ServerURL: srv.URL, Blocking: true})
+	commit(t, repo, append(env, "GGQUICK_PUSH_SECRET=s3cret"))
+
+	push := <-pushes
+	if push.timestamp == "" {
+		t.Fatal("signed push has no timestamp")
+	}
+	if want := client.Sign("s3cret", push.timestamp, push.raw); push.signature != want {
+		t.Errorf("signature = %q, want %q", push.signature, want)
+	}
+}
+
+func TestHookReportsRejectedPush(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Push signature required", http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	repo, env := hookRepo(t, hooks.Options{ServerURL: srv.URL, Blocking: true})
+	if out := commit(t, repo, env); !strings.Contains(out, "401") {
+		t.Errorf("commit output %q doesn't report the rejected push", out)
+	}
+}
+
+func TestInstallRejectsUnsafeOptions(t *testing.T) {
+	repo := testsupport.NewRepo(t)
+	for _, opts := range []hooks.Options{
+		{ServerURL: "http://example.com/$(id)"},
+		{DisableEnv: "NOT-A-VAR"},
+		{AuthHeader: "no colon"},
+	} {
+		if err := hooks.New(log.New(false)).InstallHooksWith(repo.Dir, opts); err == nil {
+			t.Errorf("InstallHooksWith(%+v) succeeded, want an error", opts)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Package httpclient provides HTTP clients for ggquick's outbound calls.
+// They share one tuned transport, so bursts of pushes reuse warm
+// connections to OpenAI and GitHub instead of dialing for each request.
+package httpclient
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Transport tuning
+const (
+	dialTimeout         = 10 * time.Second
+	keepAlive           = 30 * time.Second
+	tlsHandshakeTimeout = 10 * time.Second
+	idleConnTimeout     = 90 * time.Second
+	maxIdleConns        = 100
+	// maxIdleConnsPerHost is well above net/http's default of 2, which
+	// makes concurrent jobs redial the same few hosts
+	maxIdleConnsPerHost = 32
+)
+
+var (
+	proxyMu  sync.RWMutex
+	proxyURL *url.URL
+
+	transport = &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   dialTimeout,
+			KeepAlive: keepAlive,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          maxIdleConns,
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+		IdleConnTimeout:       idleConnTimeout,
+		TLSHandshakeTimeout:   tlsHandshakeTimeout,
+		ExpectContinueTimeout: time.Second,
+	}
+)
+
+// Transport returns the shared transport
+func Transport() *http.Transport {
+	return transport
+}
+
+// New returns a client on the shared transport. A zero timeout leaves
+// requests bounded only by their context.
+func New(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: transport, Timeout: timeout}
+}
+
+// SetProxy sends every request through rawURL, e.g. from
+// GGQUICK_HTTP_PROXY. An empty URL goes back to HTTPS_PROXY, HTTP_PROXY
+// and NO_PROXY.
+func SetProxy(rawURL string) error {
+	var u *url.URL
+	if rawURL != "" {
+		var err error
+		if u, err = url.Parse(rawURL); err != nil || u.Host == "" {
+			return fmt.Errorf("invalid proxy url %q", rawURL)
+		}
+	}
+	proxyMu.Lock()
+	proxyURL = u
+	proxyMu.Unlock()
+	return nil
+}
+
+// proxy picks the proxy for a request
+func proxy(req *http.Request) (*url.URL, error) {
+	proxyMu.RLock()
+	u := proxyURL
+	proxyMu.RUnlock()
+	if u != nil {
+		return u, nil
+	}
+	return http.ProxyFromEnvironment(req)
+}
@@ -2,6 +2,8 @@ package log
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strings"
 )
 
@@ -38,6 +40,23 @@ const (
 	loadingEmoji = "⏳ "
 )
 
+// output is where every logger writes
+var output io.Writer = os.Stdout
+
+// quiet suppresses everything but warnings and errors
+var quiet bool
+
+// SetQuiet limits all loggers to warnings and errors
+func SetQuiet(q bool) {
+	quiet = q
+}
+
+// SetOutput redirects all loggers, e.g. to io.Discard when a command
+// prints machine-readable output instead
+func SetOutput(w io.Writer) {
+	output = w
+}
+
 // Logger struct with debug flag
 type Logger struct {
 	debug bool
@@ -84,71 +103,95 @@ func formatMessage(msg string) string {
 
 // Info prints an info message
 func (l *Logger) Info(format string, args ...interface{}) {
+	if quiet {
+		return
+	}
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("%s%s%s%s\n", blue, infoEmoji, formatMessage(msg), reset)
+	fmt.Fprintf(output, "%s%s%s%s\n", blue, infoEmoji, formatMessage(msg), reset)
 }
 
 // Success prints a success message
 func (l *Logger) Success(format string, args ...interface{}) {
+	if quiet {
+		return
+	}
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("%s%s%s%s\n", boldGreen, successEmoji, formatMessage(msg), reset)
+	fmt.Fprintf(output, "%s%s%s%s\n", boldGreen, successEmoji, formatMessage(msg), reset)
 }
 
 // Error prints an error message
 func (l *Logger) Error(format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("%s%s%s%s\n", boldRed, errorEmoji, formatMessage(msg), reset)
+	fmt.Fprintf(output, "%s%s%s%s\n", boldRed, errorEmoji, formatMessage(msg), reset)
 }
 
 // Warning prints a warning message
 func (l *Logger) Warning(format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("%s%s%s%s\n", boldYellow, warnEmoji, formatMessage(msg), reset)
+	fmt.Fprintf(output, "%s%s%s%s\n", boldYellow, warnEmoji, formatMessage(msg), reset)
 }
 
 // Step prints a step message
 func (l *Logger) Step(format string, args ...interface{}) {
+	if quiet {
+		return
+	}
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("%s%s%s%s\n", cyan, stepEmoji, formatMessage(msg), reset)
+	fmt.Fprintf(output, "%s%s%s%s\n", cyan, stepEmoji, formatMessage(msg), reset)
 }
 
 // Debug prints a debug message
 func (l *Logger) Debug(format string, args ...interface{}) {
-	if !l.debug {
+	if !l.debug || quiet {
 		return
 	}
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("%s%s%s%s\n", dim, debugEmoji, formatMessage(msg), reset)
+	fmt.Fprintf(output, "%s%s%s%s\n", dim, debugEmoji, formatMessage(msg), reset)
 }
 
 // PR prints a PR-related message
 func (l *Logger) PR(format string, args ...interface{}) {
+	if quiet {
+		return
+	}
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("%s%s%s%s\n", magenta, prEmoji, formatMessage(msg), reset)
+	fmt.Fprintf(output, "%s%s%s%s\n", magenta, prEmoji, formatMessage(msg), reset)
 }
 
 // Git prints a git-related message
 func (l *Logger) Git(format string, args ...interface{}) {
+	if quiet {
+		return
+	}
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("%s%s%s%s\n", blue, gitEmoji, formatMessage(msg), reset)
+	fmt.Fprintf(output, "%s%s%s%s\n", blue, gitEmoji, formatMessage(msg), reset)
 }
 
 // Branch prints a branch-related message
 func (l *Logger) Branch(format string, args ...interface{}) {
+	if quiet {
+		return
+	}
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("%s%s%s%s\n", green, branchEmoji, formatMessage(msg), reset)
+	fmt.Fprintf(output, "%s%s%s%s\n", green, branchEmoji, formatMessage(msg), reset)
 }
 
 // Diff prints a diff-related message
 func (l *Logger) Diff(format string, args ...interface{}) {
+	if quiet {
+		return
+	}
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("%s%s%s%s\n", yellow, diffEmoji, formatMessage(msg), reset)
+	fmt.Fprintf(output, "%s%s%s%s\n", yellow, diffEmoji, formatMessage(msg), reset)
 }
 
 // Loading prints a loading/progress message
 func (l *Logger) Loading(format string, args ...interface{}) {
+	if quiet {
+		return
+	}
 	msg := fmt.Sprintf(format, args...)
-	fmt.Printf("%s%s%s%s\n", cyan, loadingEmoji, formatMessage(msg), reset)
+	fmt.Fprintf(output, "%s%s%s%s\n", cyan, loadingEmoji, formatMessage(msg), reset)
 }
 
 // IsDebug returns whether debug logging is enabled
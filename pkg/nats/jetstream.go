@@ -0,0 +1,168 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// JetStream API error codes ggquick tells apart
+const (
+	ErrCodeNoMessage         = 10037 // the stream has no such message
+	ErrCodeStreamNameInUse   = 10058 // another stream has the name
+	ErrCodeWrongLastSequence = 10071 // an expected last sequence didn't match
+)
+
+// APIError is an error answer from the JetStream API
+type APIError struct {
+	Code        int    `json:"code"`
+	ErrCode     int    `json:"err_code"`
+	Description string `json:"description"`
+}
+
+func (e *APIError) Error() string {
+	return "nats: " + e.Description
+}
+
+// IsAPIError reports whether err is a JetStream API error with code
+func IsAPIError(err error, code int) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.ErrCode == code
+}
+
+// StreamConfig is the part of a stream's configuration ggquick sets; the
+// server defaults the rest
+type StreamConfig struct {
+	Name     string   `json:"name"`
+	Subjects []string `json:"subjects"`
+	// MaxMsgsPerSubject keeps only the latest messages of each subject
+	MaxMsgsPerSubject int64 `json:"max_msgs_per_subject,omitempty"`
+	// MaxAge drops messages older than it
+	MaxAge time.Duration `json:"max_age,omitempty"`
+}
+
+// Msg is a message stored in a stream
+type Msg struct {
+	Subject string    `json:"subject"`
+	Seq     uint64    `json:"seq"`
+	Data    []byte    `json:"data"`
+	Time    time.Time `json:"time"`
+}
+
+// AddStream creates a stream, leaving one that already exists as it is
+func (c *Client) AddStream(ctx context.Context, config StreamConfig) error {
+	var info struct {
+		Error *APIError `json:"error"`
+	}
+	if err := c.api(ctx, "$JS.API.STREAM.INFO."+config.Name, nil, &info); err != nil {
+		return err
+	}
+	if info.Error == nil {
+		return nil
+	}
+	var created struct {
+		Error *APIError `json:"error"`
+	}
+	if err := c.api(ctx, "$JS.API.STREAM.CREATE."+config.Name, config, &created); err != nil {
+		return err
+	}
+	if created.Error != nil && created.Error.ErrCode != ErrCodeStreamNameInUse {
+		// In use means another replica created it first
+		return fmt.Errorf("failed to create stream %s: %w", config.Name, created.Error)
+	}
+	return nil
+}
+
+// Publish stores data on subject and returns its sequence. When
+// expectLast is non-negative the message is only stored if the subject's
+// last message has that sequence, 0 meaning the subject has none.
+func (c *Client) Publish(ctx context.Context, subject string, data []byte, expectLast int64) (uint64, error) {
+	var header map[string]string
+	if expectLast >= 0 {
+		header = map[string]string{"Nats-Expected-Last-Subject-Sequence": strconv.FormatInt(expectLast, 10)}
+	}
+	answer, err := c.Request(ctx, subject, header, data)
+	if err != nil {
+		return 0, err
+	}
+	var ack struct {
+		Seq   uint64    `json:"seq"`
+		Error *APIError `json:"error"`
+	}
+	if err := json.Unmarshal(answer, &ack); err != nil {
+		return 0, fmt.Errorf("invalid nats publish ack: %w", err)
+	}
+	if ack.Error != nil {
+		return 0, ack.Error
+	}
+	return ack.Seq, nil
+}
+
+// LastMsg returns the last message on subject, or nil when there is none
+func (c *Client) LastMsg(ctx context.Context, stream, subject string) (*Msg, error) {
+	return c.getMsg(ctx, stream, map[string]interface{}{"last_by_subj": subject})
+}
+
+// FirstMsg returns the oldest message on subject, or nil when there is
+// none
+func (c *Client) FirstMsg(ctx context.Context, stream, subject string) (*Msg, error) {
+	return c.getMsg(ctx, stream, map[string]interface{}{"seq": 1, "next_by_subj": subject})
+}
+
+// getMsg runs a STREAM.MSG.GET request
+func (c *Client) getMsg(ctx context.Context, stream string, req map[string]interface{}) (*Msg, error) {
+	var got struct {
+		Message *Msg      `json:"message"`
+		Error   *APIError `json:"error"`
+	}
+	if err := c.api(ctx, "$JS.API.STREAM.MSG.GET."+stream, req, &got); err != nil {
+		return nil, err
+	}
+	if got.Error != nil {
+		if got.Error.ErrCode == ErrCodeNoMessage {
+			return nil, nil
+		}
+		return nil, got.Error
+	}
+	return got.Message, nil
+}
+
+// DeleteMsg removes a message from a stream. Of several callers deleting
+// the same message, only one succeeds.
+func (c *Client) DeleteMsg(ctx context.Context, stream string, seq uint64) error {
+	var deleted struct {
+		Success bool      `json:"success"`
+		Error   *APIError `json:"error"`
+	}
+	if err := c.api(ctx, "$JS.API.STREAM.MSG.DELETE."+stream, map[string]interface{}{"seq": seq, "no_erase": true}, &deleted); err != nil {
+		return err
+	}
+	if deleted.Error != nil {
+		return deleted.Error
+	}
+	if !deleted.Success {
+		return fmt.Errorf("nats: message %d of %s not deleted", seq, stream)
+	}
+	return nil
+}
+
+// api sends a JetStream API request and decodes the answer into out
+func (c *Client) api(ctx context.Context, subject string, req, out interface{}) error {
+	var data []byte
+	if req != nil {
+		var err error
+		if data, err = json.Marshal(req); err != nil {
+			return fmt.Errorf("failed to encode nats request: %w", err)
+		}
+	}
+	answer, err := c.Request(ctx, subject, nil, data)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(answer, out); err != nil {
+		return fmt.Errorf("invalid answer to %s: %w", subject, err)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// EmailConfig holds SMTP settings
+type EmailConfig struct {
+	Host     string
+	Port     string // default 587
+	Username string
+	Password string
+	From     string
+	To       []string // default recipients when a message names none
+}
+
+// Email sends notifications over SMTP as multipart plaintext/HTML mail
+type Email struct {
+	config EmailConfig
+}
+
+// NewEmail creates an SMTP notifier
+func NewEmail(config EmailConfig) *Email {
+	if config.Port == "" {
+		config.Port = "587"
+	}
+	if config.From == "" {
+		config.From = config.Username
+	}
+	return &Email{config: config}
+}
+
+var textBody = texttemplate.Must(texttemplate.New("text").Parse(`{{.Subject}}
+{{if .Repo}}Repository: {{.Repo}}
+{{end}}
+{{.Text}}
+{{if .Link}}
+{{.Link}}
+{{end}}
+-- 
+Sent by ggquick
+`))
+
+var htmlBody = htmltemplate.Must(htmltemplate.New("html").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: -apple-system, Helvetica, Arial, sans-serif; color: #24292f;">
+  <h2 style="margin-bottom: 4px;">{{.Subject}}</h2>
+  {{if .Repo}}<p style="color: #57606a; margin-top: 0;">{{.Repo}}</p>{{end}}
+  {{range .Lines}}<p style="margin: 4px 0;">{{.}}</p>
+  {{end}}
+  {{if .Link}}<p><a href="{{.Link}}">{{.Link}}</a></p>{{end}}
+  <p style="color: #8c959f; font-size: 12px;">Sent by ggquick</p>
+</body>
+</html>
+`))
+
+// Notify emails the message to its recipients, or the defaults
+func (e *Email) Notify(ctx context.Context, msg Message) error {
+	to := msg.Recipients
+	if len(to) == 0 {
+		to = e.config.To
+	}
+	if len(to) == 0 {
+		return nil
+	}
+	// Recipients and subjects can come from configs and commit messages,
+	// so a line break must not start a new header
+	recipients := make([]string, len(to))
+	for i, addr := range to {
+		recipients[i] = headerLine(addr)
+	}
+	to = recipients
+	msg.Subject = headerLine(msg.Subject)
+
+	body, err := e.render(msg, to)
+	if err != nil {
+		return err
+	}
+
+	if err := e.send(ctx, to, body); err != nil {
+		if ctx.Err() != nil {
+			// Closing the connection surfaces as a network error
+			err = ctx.Err()
+		}
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// send delivers body as smtp.SendMail does, but gives up when ctx ends
+func (e *Email) send(ctx context.Context, to []string, body []byte) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(e.config.Host, e.config.Port))
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, e.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: e.config.Host}); err != nil {
+			return err
+		}
+	}
+	if e.config.Username != "" {
+		auth := smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.Host)
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(e.config.From); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err := c.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// render builds the full MIME message
+func (e *Email) render(msg Message, to []string) ([]byte, error) {
+	data := struct {
+		Message
+		Lines []string
+	}{
+		Message: msg,
+		Lines:   strings.Split(strings.TrimSpace(msg.Text), "\n"),
+	}
+
+	var text, html bytes.Buffer
+	if err := textBody.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("failed to render text email: %w", err)
+	}
+	if err := htmlBody.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("failed to render html email: %w", err)
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "From: %s\r\n", e.config.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@ggquick>\r\n", messageID())
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+
+	for _, part := range []struct {
+		contentType string
+		body        []byte
+	}{
+		{"text/plain; charset=utf-8", text.Bytes()},
+		{"text/html; charset=utf-8", html.Bytes()},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {part.contentType}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to build email: %w", err)
+		}
+		w.Write(part.body)
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build email: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// headerLine replaces line breaks in a header value with spaces
+func headerLine(s string) string {
+	return strings.TrimSpace(strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(s))
+}
+
+// messageID returns a random message identifier
+func messageID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+)
+
+// Message is a notification delivered to a team
+type Message struct {
+	Repo       string // owner/name the message is about
+	Subject    string
+	Text       string   // plain text or markdown body
+	Link       string   // optional URL, e.g. the created PR
+	Recipients []string // per-repo email recipients, overriding defaults
+}
+
+// Notifier delivers messages to a channel such as Slack or email
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// Multi fans a message out to several notifiers
+type Multi []Notifier
+
+// Notify delivers msg to every notifier, joining any errors
+func (m Multi) Notify(ctx context.Context, msg Message) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FromEnv builds a notifier from the channels configured in the
+// environment, or returns nil when none are set
+func FromEnv() Notifier {
+	var m Multi
+	if url := os.Getenv("SLACK_WEBHOOK_URL"); url != "" {
+		m = append(m, NewSlack(url))
+	}
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		m = append(m, NewEmail(EmailConfig{
+			Host:     host,
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+			To:       splitList(os.Getenv("SMTP_TO")),
+		}))
+	}
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+// splitList splits a comma separated list, dropping empty entries
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
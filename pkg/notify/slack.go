@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/httpclient"
+)
+
+// Slack posts messages to a Slack incoming webhook
+type Slack struct {
+	webhookURL string
+	httpClient *http.Client
+}
+
+// NewSlack creates a Slack notifier for an incoming webhook URL
+func NewSlack(webhookURL string) *Slack {
+	return &Slack{
+		webhookURL: webhookURL,
+		httpClient: httpclient.New(10 * time.Second),
+	}
+}
+
+// Notify posts the message to Slack
+func (s *Slack) Notify(ctx context.Context, msg Message) error {
+	text := msg.Text
+	if msg.Subject != "" {
+		text = fmt.Sprintf("*%s*\n%s", msg.Subject, msg.Text)
+	}
+	if msg.Link != "" {
+		text += "\n" + msg.Link
+	}
+
+	data, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("failed to marshal slack message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send slack message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("slack returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
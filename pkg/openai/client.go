@@ -7,6 +7,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+
+	"github.com/saint0x/ggquick/pkg/httpclient"
 )
 
 const (
@@ -16,6 +19,8 @@ const (
 
 type Client struct {
 	token      string
+	baseURL    string
+	apiVersion string // set for Azure OpenAI
 	httpClient *http.Client
 }
 
@@ -31,18 +36,57 @@ type ChatCompletionRequest struct {
 }
 
 type ChatCompletionResponse struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Created int    `json:"created"`
-	Choices []struct {
-		Message ChatCompletionMessage `json:"message"`
-	} `json:"choices"`
+	ID      string                 `json:"id"`
+	Object  string                 `json:"object"`
+	Created int                    `json:"created"`
+	Choices []ChatCompletionChoice `json:"choices"`
+	Usage   Usage                  `json:"usage"`
+}
+
+type ChatCompletionChoice struct {
+	Message ChatCompletionMessage `json:"message"`
+}
+
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
 }
 
 func NewClient(token string) *Client {
+	return NewClientWithBaseURL(baseURL, token)
+}
+
+// NewClientWithBaseURL talks to an OpenAI compatible API, like a local
+// model server at http://localhost:11434/v1
+func NewClientWithBaseURL(url, token string) *Client {
 	return &Client{
 		token:      token,
-		httpClient: &http.Client{},
+		baseURL:    strings.TrimSuffix(url, "/"),
+		httpClient: httpclient.New(0), // generation is bounded by the caller's context
+	}
+}
+
+// NewAzureClient talks to an Azure OpenAI resource, where the model of a
+// request names the deployment
+func NewAzureClient(endpoint, key, apiVersion string) *Client {
+	c := NewClientWithBaseURL(endpoint, key)
+	c.apiVersion = apiVersion
+	return c
+}
+
+func (c *Client) url(path, model string) string {
+	if c.apiVersion == "" {
+		return c.baseURL + path
+	}
+	return c.baseURL + "/openai/deployments/" + model + path + "?api-version=" + c.apiVersion
+}
+
+func (c *Client) authorize(req *http.Request) {
+	if c.apiVersion != "" {
+		req.Header.Set("api-key", c.token)
+	} else if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
 	}
 }
 
@@ -52,12 +96,12 @@ func (c *Client) CreateChatCompletion(ctx context.Context, req ChatCompletionReq
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/chat/completions", bytes.NewBuffer(data))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.url("/chat/completions", req.Model), bytes.NewBuffer(data))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	c.authorize(httpReq)
 	httpReq.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.httpClient.Do(httpReq)
@@ -78,3 +122,46 @@ func (c *Client) CreateChatCompletion(ctx context.Context, req ChatCompletionReq
 
 	return &result, nil
 }
+
+type ModerationResult struct {
+	Flagged    bool            `json:"flagged"`
+	Categories map[string]bool `json:"categories"`
+}
+
+type ModerationResponse struct {
+	ID      string             `json:"id"`
+	Results []ModerationResult `json:"results"`
+}
+
+func (c *Client) CreateModeration(ctx context.Context, input string) (*ModerationResponse, error) {
+	data, err := json.Marshal(map[string]string{"input": input})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.url("/moderations", ""), bytes.NewBuffer(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	c.authorize(httpReq)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result ModerationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,138 @@
+// Package osv looks up known vulnerabilities in the OSV database
+package osv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/httpclient"
+)
+
+// DefaultBaseURL is the public OSV API
+const DefaultBaseURL = "https://api.osv.dev"
+
+// Package identifies a package version to check
+type Package struct {
+	Ecosystem string // Go, npm, PyPI
+	Name      string
+	Version   string
+}
+
+// Vuln is a known vulnerability affecting a package version
+type Vuln struct {
+	ID       string   `json:"id"`
+	Summary  string   `json:"summary"`
+	Aliases  []string `json:"aliases"`
+	Severity string   `json:"-"`
+	Fixed    []string `json:"-"`
+}
+
+// Client queries the OSV API
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// New creates an OSV client, using DefaultBaseURL when baseURL is empty
+func New(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpclient.New(15 * time.Second),
+	}
+}
+
+// osvVuln is the subset of the OSV schema read from query results
+type osvVuln struct {
+	Vuln
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+	Affected []struct {
+		Package struct {
+			Name string `json:"name"`
+		} `json:"package"`
+		Ranges []struct {
+			Events []struct {
+				Fixed string `json:"fixed"`
+			} `json:"events"`
+		} `json:"ranges"`
+	} `json:"affected"`
+}
+
+// Query returns the vulnerabilities affecting pkg
+func (c *Client) Query(ctx context.Context, pkg Package) ([]Vuln, error) {
+	version := pkg.Version
+	if pkg.Ecosystem == "Go" {
+		// OSV stores Go versions without the v prefix
+		version = strings.TrimPrefix(version, "v")
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"version": version,
+		"package": map[string]string{"name": pkg.Name, "ecosystem": pkg.Ecosystem},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal query: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/query", bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query OSV: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("OSV returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result struct {
+		Vulns []osvVuln `json:"vulns"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode OSV response: %w", err)
+	}
+
+	vulns := make([]Vuln, 0, len(result.Vulns))
+	for _, v := range result.Vulns {
+		vuln := v.Vuln
+		vuln.Severity = v.DatabaseSpecific.Severity
+		for _, affected := range v.Affected {
+			if affected.Package.Name != "" && affected.Package.Name != pkg.Name {
+				continue
+			}
+			for _, r := range affected.Ranges {
+				for _, e := range r.Events {
+					if e.Fixed != "" && !contains(vuln.Fixed, e.Fixed) {
+						vuln.Fixed = append(vuln.Fixed, e.Fixed)
+					}
+				}
+			}
+		}
+		vulns = append(vulns, vuln)
+	}
+	return vulns, nil
+}
+
+// contains reports whether list holds s
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
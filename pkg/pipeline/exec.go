@@ -0,0 +1,132 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Exec hook phases
+const (
+	PhasePreGeneration  = "pre-generation"
+	PhasePostGeneration = "post-generation"
+	PhasePostPR         = "post-pr"
+)
+
+// ExecPayload is the job document written to a hook's stdin. Hooks in the
+// generation phases may print an edited copy on stdout.
+type ExecPayload struct {
+	Phase         string   `json:"phase"`
+	JobID         string   `json:"job_id"`
+	Repo          string   `json:"repo"`
+	Branch        string   `json:"branch"`
+	SHA           string   `json:"sha,omitempty"`
+	CommitMessage string   `json:"commit_message,omitempty"`
+	Notes         []string `json:"notes,omitempty"`
+	Title         string   `json:"title,omitempty"`
+	Description   string   `json:"description,omitempty"`
+	PRNumber      int      `json:"pr_number,omitempty"`
+	PRURL         string   `json:"pr_url,omitempty"`
+}
+
+// VetoError is returned when a hook exits non-zero to block the job
+type VetoError struct {
+	Phase  string
+	Reason string
+}
+
+func (e *VetoError) Error() string {
+	return fmt.Sprintf("vetoed by %s hook: %s", e.Phase, e.Reason)
+}
+
+// IsVeto reports whether err is a hook veto
+func IsVeto(err error) bool {
+	var veto *VetoError
+	return errors.As(err, &veto)
+}
+
+// ExecHooks are shell commands run at fixed points of a job
+type ExecHooks struct {
+	Commands map[string]string // phase -> command
+	Timeout  time.Duration
+}
+
+// ExecHooksFromEnv reads GGQUICK_HOOK_PRE_GENERATION,
+// GGQUICK_HOOK_POST_GENERATION, GGQUICK_HOOK_POST_PR and
+// GGQUICK_HOOK_TIMEOUT. It returns nil when no hook is set.
+func ExecHooksFromEnv() *ExecHooks {
+	hooks := &ExecHooks{Commands: make(map[string]string), Timeout: 30 * time.Second}
+	for _, phase := range []string{PhasePreGeneration, PhasePostGeneration, PhasePostPR} {
+		key := "GGQUICK_HOOK_" + strings.ToUpper(strings.ReplaceAll(phase, "-", "_"))
+		if cmd := os.Getenv(key); cmd != "" {
+			hooks.Commands[phase] = cmd
+		}
+	}
+	if len(hooks.Commands) == 0 {
+		return nil
+	}
+	if d, err := time.ParseDuration(os.Getenv("GGQUICK_HOOK_TIMEOUT")); err == nil && d > 0 {
+		hooks.Timeout = d
+	}
+	return hooks
+}
+
+// Run executes the hook for phase, if any. Output on stdout replaces the
+// payload, except after the PR exists. A non-zero exit vetoes the job.
+func (h *ExecHooks) Run(ctx context.Context, phase string, payload *ExecPayload) error {
+	if h == nil {
+		return nil
+	}
+	command, ok := h.Commands[phase]
+	if !ok {
+		return nil
+	}
+
+	payload.Phase = phase
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal hook payload: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), "GGQUICK_PHASE="+phase)
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("%s hook timed out after %s", phase, h.Timeout)
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && phase != PhasePostPR {
+			reason := strings.TrimSpace(stderr.String())
+			if reason == "" {
+				reason = fmt.Sprintf("exit status %d", exitErr.ExitCode())
+			}
+			return &VetoError{Phase: phase, Reason: reason}
+		}
+		return fmt.Errorf("%s hook failed: %w", phase, err)
+	}
+
+	if phase == PhasePostPR || len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return nil
+	}
+	var edited ExecPayload
+	if err := json.Unmarshal(stdout.Bytes(), &edited); err != nil {
+		return fmt.Errorf("failed to decode %s hook output: %w", phase, err)
+	}
+	edited.Phase, edited.JobID = phase, payload.JobID
+	*payload = edited
+	return nil
+}
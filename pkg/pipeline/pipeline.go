@@ -0,0 +1,112 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"plugin"
+	"strings"
+	"sync"
+
+	"github.com/saint0x/ggquick/pkg/ai"
+)
+
+// Stage is a custom step in the PR generation pipeline. Analyze runs
+// before the model is called and may enrich the repo info; Decorate runs
+// after and may edit the generated content.
+type Stage interface {
+	Name() string
+	Analyze(ctx context.Context, info *ai.RepoInfo) error
+	Decorate(ctx context.Context, content *ai.PRContent) error
+}
+
+var (
+	mu     sync.RWMutex
+	stages []Stage
+)
+
+// Register adds a stage to the global pipeline, replacing any stage
+// with the same name
+func Register(stage Stage) {
+	mu.Lock()
+	defer mu.Unlock()
+	for i, s := range stages {
+		if s.Name() == stage.Name() {
+			stages[i] = stage
+			return
+		}
+	}
+	stages = append(stages, stage)
+}
+
+// Stages returns the registered stages in registration order
+func Stages() []Stage {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]Stage(nil), stages...)
+}
+
+// LoadPlugin opens a Go plugin built with -buildmode=plugin and registers
+// the Stage it exports as the symbol "Stage"
+func LoadPlugin(path string) (Stage, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open plugin %s: %w", path, err)
+	}
+
+	sym, err := p.Lookup("Stage")
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s does not export Stage: %w", path, err)
+	}
+
+	// Exported variables are looked up as pointers
+	var stage Stage
+	switch v := sym.(type) {
+	case Stage:
+		stage = v
+	case *Stage:
+		stage = *v
+	default:
+		return nil, fmt.Errorf("plugin %s: Stage has type %T, want pipeline.Stage", path, sym)
+	}
+
+	Register(stage)
+	return stage, nil
+}
+
+// RunAnalyze runs every stage's Analyze step, stopping at the first error
+func RunAnalyze(ctx context.Context, stages []Stage, info *ai.RepoInfo) error {
+	for _, s := range stages {
+		if err := s.Analyze(ctx, info); err != nil {
+			return fmt.Errorf("stage %s: %w", s.Name(), err)
+		}
+	}
+	return nil
+}
+
+// RunDecorate runs every stage's Decorate step, stopping at the first error
+func RunDecorate(ctx context.Context, stages []Stage, content *ai.PRContent) error {
+	for _, s := range stages {
+		if err := s.Decorate(ctx, content); err != nil {
+			return fmt.Errorf("stage %s: %w", s.Name(), err)
+		}
+	}
+	return nil
+}
+
+// LoadPluginsFromEnv loads the comma separated plugin paths in
+// GGQUICK_PLUGINS
+func LoadPluginsFromEnv() ([]Stage, error) {
+	var loaded []Stage
+	for _, path := range strings.Split(os.Getenv("GGQUICK_PLUGINS"), ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		stage, err := LoadPlugin(path)
+		if err != nil {
+			return loaded, err
+		}
+		loaded = append(loaded, stage)
+	}
+	return loaded, nil
+}
@@ -0,0 +1,157 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/ai"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+const (
+	wasmTimeout     = 10 * time.Second
+	wasmMemoryPages = 1024 // 64 MiB
+	wasmMaxOutput   = 1 << 20
+	wasmMaxStderr   = 64 << 10
+)
+
+// WasmStage is an extension compiled to WebAssembly (WASI). It speaks the
+// same protocol as WebhookStage, reading the payload on stdin and writing
+// its reply to stdout, but runs in-process with no filesystem, network or
+// environment access, so tenants can supply their own without exec hooks.
+type WasmStage struct {
+	name     string
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+}
+
+// LoadWasm compiles the module at path into a stage named after the
+// file. Modules aren't registered with the global pipeline: each
+// repository runs only the ones its configuration lists.
+func LoadWasm(ctx context.Context, path string) (Stage, error) {
+	bin, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wasm module %s: %w", path, err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	stage, err := NewWasmStage(ctx, name, bin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load wasm module %s: %w", path, err)
+	}
+	return stage, nil
+}
+
+// NewWasmStage compiles a WASI module into a stage
+func NewWasmStage(ctx context.Context, name string, bin []byte) (*WasmStage, error) {
+	config := wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(wasmMemoryPages).
+		WithCloseOnContextDone(true)
+	runtime := wazero.NewRuntimeWithConfig(ctx, config)
+
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("failed to instantiate WASI: %w", err)
+	}
+
+	compiled, err := runtime.CompileModule(ctx, bin)
+	if err != nil {
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("failed to compile module: %w", err)
+	}
+
+	return &WasmStage{name: name, runtime: runtime, compiled: compiled}, nil
+}
+
+// Name returns the stage name
+func (w *WasmStage) Name() string {
+	return w.name
+}
+
+// Analyze runs the module on the repo info and applies its notes
+func (w *WasmStage) Analyze(ctx context.Context, info *ai.RepoInfo) error {
+	return analyzeWith(ctx, info, w.call)
+}
+
+// Decorate runs the module on the generated content and applies edits
+func (w *WasmStage) Decorate(ctx context.Context, content *ai.PRContent) error {
+	return decorateWith(ctx, content, w.call)
+}
+
+// call instantiates a fresh copy of the module, so no state leaks between
+// jobs, and exchanges one payload with it
+func (w *WasmStage) call(ctx context.Context, in webhookPayload, out *webhookPayload) error {
+	ctx, cancel := context.WithTimeout(ctx, wasmTimeout)
+	defer cancel()
+
+	data, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	// Output is capped as it is written, not after the module exits, so a
+	// module writing in a loop can't exhaust the server's memory
+	stdout := &cappedWriter{limit: wasmMaxOutput, stop: cancel}
+	stderr := &cappedWriter{limit: wasmMaxStderr, stop: cancel}
+	modConfig := wazero.NewModuleConfig().
+		WithName("").
+		WithArgs(w.name, in.Phase).
+		WithStdin(bytes.NewReader(data)).
+		WithStdout(stdout).
+		WithStderr(stderr)
+
+	mod, err := w.runtime.InstantiateModule(ctx, w.compiled, modConfig)
+	if mod != nil {
+		defer mod.Close(ctx)
+	}
+	if stdout.exceeded {
+		return fmt.Errorf("module output exceeds %d bytes", wasmMaxOutput)
+	}
+	if stderr.exceeded {
+		return fmt.Errorf("module stderr exceeds %d bytes", wasmMaxStderr)
+	}
+	if err != nil {
+		var exitErr *sys.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 0 {
+			if ctx.Err() != nil {
+				return fmt.Errorf("module timed out after %s", wasmTimeout)
+			}
+			return fmt.Errorf("module failed: %w: %s", err, strings.TrimSpace(stderr.buf.String()))
+		}
+	}
+
+	if len(bytes.TrimSpace(stdout.buf.Bytes())) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(stdout.buf.Bytes(), out); err != nil {
+		return fmt.Errorf("failed to decode module output: %w", err)
+	}
+	return nil
+}
+
+// cappedWriter keeps up to limit bytes of a module's output. A write past
+// the limit fails and stops the module.
+type cappedWriter struct {
+	buf      bytes.Buffer
+	limit    int
+	stop     func()
+	exceeded bool
+}
+
+func (c *cappedWriter) Write(p []byte) (int, error) {
+	if c.exceeded || c.buf.Len()+len(p) > c.limit {
+		c.exceeded = true
+		c.stop()
+		return 0, fmt.Errorf("output limit of %d bytes exceeded", c.limit)
+	}
+	return c.buf.Write(p)
+}
@@ -0,0 +1,148 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/ai"
+	"github.com/saint0x/ggquick/pkg/httpclient"
+)
+
+// WebhookStage is an external extension reached over HTTP. It receives
+// {"phase": "analyze"|"decorate", ...} and answers with the same document,
+// modified as it sees fit. Empty responses leave the input unchanged.
+type WebhookStage struct {
+	StageName string `json:"name"`
+	URL       string `json:"url"`
+	Secret    string `json:"secret,omitempty"` // sent as a bearer token
+	Timeout   string `json:"timeout,omitempty"`
+}
+
+// webhookPayload is the document exchanged with extensions
+type webhookPayload struct {
+	Phase    string       `json:"phase"`
+	RepoInfo *repoInfoDoc `json:"repo_info,omitempty"`
+	Content  *contentDoc  `json:"content,omitempty"`
+}
+
+type repoInfoDoc struct {
+	Repo          string   `json:"repo"`
+	BranchName    string   `json:"branch"`
+	CommitMessage string   `json:"commit_message"`
+	Files         []string `json:"files"`
+	Notes         []string `json:"notes"`
+}
+
+type contentDoc struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// Name returns the configured stage name
+func (w *WebhookStage) Name() string {
+	return w.StageName
+}
+
+// Analyze sends the repo info to the extension and applies its notes
+func (w *WebhookStage) Analyze(ctx context.Context, info *ai.RepoInfo) error {
+	return analyzeWith(ctx, info, w.call)
+}
+
+// Decorate sends the generated content to the extension and applies edits
+func (w *WebhookStage) Decorate(ctx context.Context, content *ai.PRContent) error {
+	return decorateWith(ctx, content, w.call)
+}
+
+// exchangeFunc sends a payload to an extension and decodes its reply
+type exchangeFunc func(ctx context.Context, in webhookPayload, out *webhookPayload) error
+
+// analyzeWith runs the analyze phase of the extension protocol over call
+func analyzeWith(ctx context.Context, info *ai.RepoInfo, call exchangeFunc) error {
+	doc := &repoInfoDoc{
+		Repo:          info.Repo,
+		BranchName:    info.BranchName,
+		CommitMessage: info.CommitMessage,
+		Notes:         info.Notes,
+	}
+	for path := range info.Changes {
+		doc.Files = append(doc.Files, path)
+	}
+
+	var resp webhookPayload
+	if err := call(ctx, webhookPayload{Phase: "analyze", RepoInfo: doc}, &resp); err != nil {
+		return err
+	}
+	if resp.RepoInfo != nil {
+		info.Notes = resp.RepoInfo.Notes
+	}
+	return nil
+}
+
+// decorateWith runs the decorate phase of the extension protocol over call
+func decorateWith(ctx context.Context, content *ai.PRContent, call exchangeFunc) error {
+	doc := &contentDoc{Title: content.Title, Description: content.Description}
+
+	var resp webhookPayload
+	if err := call(ctx, webhookPayload{Phase: "decorate", Content: doc}, &resp); err != nil {
+		return err
+	}
+	if resp.Content != nil {
+		if resp.Content.Title != "" {
+			content.Title = resp.Content.Title
+		}
+		if resp.Content.Description != "" {
+			content.Description = resp.Content.Description
+		}
+	}
+	return nil
+}
+
+// call posts a payload to the extension and decodes the reply
+func (w *WebhookStage) call(ctx context.Context, in webhookPayload, out *webhookPayload) error {
+	timeout := 10 * time.Second
+	if d, err := time.ParseDuration(w.Timeout); err == nil && d > 0 {
+		timeout = d
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	data, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.Secret != "" {
+		req.Header.Set("Authorization", "Bearer "+w.Secret)
+	}
+
+	resp, err := httpclient.New(0).Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call extension: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read extension response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("extension returned status %d: %s", resp.StatusCode, string(body))
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode extension response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+// Package redis is a minimal Redis client speaking RESP over TCP, enough
+// for sharing ggquick's job queue, locks and job records between replicas
+package redis
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxIdle is how many connections are kept open between commands
+const maxIdle = 8
+
+// Error is an error reply from the server
+type Error string
+
+func (e Error) Error() string {
+	return "redis: " + string(e)
+}
+
+// Client sends commands over a small pool of connections
+type Client struct {
+	addr     string
+	username string
+	password string
+	db       int
+	tls      bool
+	idle     chan *conn
+	// dial replaces dialing addr, for tests
+	dial func(ctx context.Context) (net.Conn, error)
+}
+
+// conn is one connection with its reader
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// New parses a redis:// or rediss:// (TLS) URL such as
+// redis://:password@host:6379/0. No connection is made until the first
+// command.
+func New(rawURL string) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+	if u.Scheme != "redis" && u.Scheme != "rediss" {
+		return nil, fmt.Errorf("invalid redis url scheme %q", u.Scheme)
+	}
+
+	c := &Client{addr: u.Host, tls: u.Scheme == "rediss", idle: make(chan *conn, maxIdle)}
+	if u.Port() == "" {
+		c.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		c.username = u.User.Username()
+		c.password, _ = u.User.Password()
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if c.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid redis database %q", db)
+		}
+	}
+	return c, nil
+}
+
+// Do sends a command and returns the reply: a string for simple and bulk
+// strings, int64 for integers, []interface{} for arrays, or nil for a
+// null reply. Error replies are returned as Error.
+func (c *Client) Do(ctx context.Context, args ...string) (interface{}, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		cn.SetDeadline(deadline)
+	} else {
+		cn.SetDeadline(time.Time{})
+	}
+
+	reply, err := cn.do(args)
+	var replyErr Error
+	if err != nil && !errors.As(err, &replyErr) {
+		// The connection state is unknown after a network error
+		cn.Close()
+		return nil, err
+	}
+	c.put(cn)
+	return reply, err
+}
+
+// Close closes idle connections
+func (c *Client) Close() error {
+	for {
+		select {
+		case cn := <-c.idle:
+			cn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// get returns an idle connection or dials a new one
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	select {
+	case cn := <-c.idle:
+		return cn, nil
+	default:
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var nc net.Conn
+	var err error
+	switch {
+	case c.dial != nil:
+		nc, err = c.dial(ctx)
+	case c.tls:
+		host, _, _ := net.SplitHostPort(c.addr)
+		nc, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", c.addr)
+	default:
+		nc, err = dialer.DialContext(ctx, "tcp", c.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	cn := &conn{Conn: nc, r: bufio.NewReader(nc)}
+	nc.SetDeadline(time.Now().Add(10 * time.Second))
+
+	if c.password != "" {
+		args := []string{"AUTH", c.password}
+		if c.username != "" {
+			args = []string{"AUTH", c.username, c.password}
+		}
+		if _, err := cn.do(args); err != nil {
+			nc.Close()
+			return nil, fmt.Errorf("failed to authenticate to redis: %w", err)
+		}
+	}
+	if c.db != 0 {
+		if _, err := cn.do([]string{"SELECT", strconv.Itoa(c.db)}); err != nil {
+			nc.Close()
+			return nil, fmt.Errorf("failed to select redis database: %w", err)
+		}
+	}
+	return cn, nil
+}
+
+// put returns a connection to the pool, closing it when the pool is full
+func (c *Client) put(cn *conn) {
+	select {
+	case c.idle <- cn:
+	default:
+		cn.Close()
+	}
+}
+
+// do writes a command as an array of bulk strings and reads the reply
+func (cn *conn) do(args []string) (interface{}, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(cn, b.String()); err != nil {
+		return nil, fmt.Errorf("failed to send redis command: %w", err)
+	}
+	return cn.read()
+}
+
+// read parses one reply
+func (cn *conn) read() (interface{}, error) {
+	line, err := cn.r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read redis reply: %w", err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("empty redis reply")
+	}
+
+	switch kind, rest := line[0], line[1:]; kind {
+	case '+':
+		return rest, nil
+	case '-':
+		return nil, Error(rest)
+	case ':':
+		n, err := strconv.ParseInt(rest, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis integer %q", rest)
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(rest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis bulk length %q", rest)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(cn.r, buf); err != nil {
+			return nil, fmt.Errorf("failed to read redis reply: %w", err)
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(rest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis array length %q", rest)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			// Errors inside arrays (e.g. EXEC) are returned as values
+			item, err := cn.read()
+			var replyErr Error
+			if err != nil && !errors.As(err, &replyErr) {
+				return nil, err
+			}
+			if err != nil {
+				item = err
+			}
+			items[i] = item
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("unknown redis reply type %q", line[0])
+}
@@ -0,0 +1,182 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	neturl "net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// passEnv are the server variables commands in a workspace see. They run
+// the branch's code, so everything else, tokens and keys included, is
+// left out.
+var passEnv = []string{
+	"PATH", "HOME", "USER", "TMPDIR", "LANG",
+	"GOROOT", "GOPATH", "GOCACHE", "GOMODCACHE", "GOPROXY", "GOPRIVATE", "GONOSUMDB",
+}
+
+// baseEnv returns the allowed part of the server's environment
+func baseEnv() []string {
+	var env []string
+	for _, name := range passEnv {
+		if v, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+v)
+		}
+	}
+	return env
+}
+
+// GoEnv keeps go commands run on a branch from switching toolchains.
+// Run adds it to an environment without the server's secrets, since
+// builds and tests run the branch's code.
+var GoEnv = []string{"GOFLAGS=-mod=mod", "GOTOOLCHAIN=local"}
+
+// Workspace is a temporary shallow clone of a branch
+type Workspace struct {
+	Dir string
+}
+
+// Result holds the outcome of a command run in a workspace
+type Result struct {
+	Command string
+	// Output is stdout and stderr as they were written, Stdout just
+	// stdout for commands whose output is parsed
+	Output   string
+	Stdout   string
+	ExitCode int
+	Duration time.Duration
+	TimedOut bool
+}
+
+// OK reports whether the command exited successfully
+func (r *Result) OK() bool {
+	return r.ExitCode == 0 && !r.TimedOut
+}
+
+// CloneURL returns an HTTPS clone URL, authenticated when token is set
+func CloneURL(owner, repo, token string) string {
+	if token == "" {
+		return fmt.Sprintf("https://github.com/%s/%s.git", owner, repo)
+	}
+	return fmt.Sprintf("https://x-access-token:%s@github.com/%s/%s.git", token, owner, repo)
+}
+
+// Clone shallow-clones a single branch into a new temporary directory
+func Clone(ctx context.Context, url, branch string) (*Workspace, error) {
+	dir, err := os.MkdirTemp("", "ggquick-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "clone", "--quiet", "--depth", "1",
+		"--single-branch", "--branch", branch, url, dir)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		os.RemoveAll(dir)
+		// Never echo the URL back, it may carry a token
+		return nil, fmt.Errorf("failed to clone branch %s: %s", branch, redact(string(out), url))
+	}
+
+	// Commands run in the clone can read .git/config, so the token
+	// mustn't stay in the remote
+	if public := publicURL(url); public != url {
+		cmd := exec.CommandContext(ctx, "git", "-C", dir, "remote", "set-url", "origin", public)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			os.RemoveAll(dir)
+			return nil, fmt.Errorf("failed to clear clone credentials: %s", redact(string(out), url))
+		}
+	}
+
+	return &Workspace{Dir: dir}, nil
+}
+
+// publicURL returns rawURL without credentials
+func publicURL(rawURL string) string {
+	u, err := neturl.Parse(rawURL)
+	if err != nil || u.User == nil {
+		return rawURL
+	}
+	u.User = nil
+	return u.String()
+}
+
+// Cleanup removes the workspace directory
+func (w *Workspace) Cleanup() {
+	os.RemoveAll(w.Dir)
+}
+
+// Run executes a command in the workspace, killing it after timeout. It
+// sees env and only the allowed part of the server's environment. A
+// non-zero exit is reported in the result, not as an error.
+func (w *Workspace) Run(ctx context.Context, timeout time.Duration, env []string, name string, args ...string) (*Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Stdout and stderr are copied on their own goroutines, so the
+	// combined output is locked
+	var out lockedBuffer
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = w.Dir
+	cmd.Env = append(baseEnv(), env...)
+	cmd.Stdout = io.MultiWriter(&out, &stdout)
+	cmd.Stderr = &out
+
+	start := time.Now()
+	err := cmd.Run()
+	result := &Result{
+		Command:  strings.Join(append([]string{name}, args...), " "),
+		Output:   out.String(),
+		Stdout:   stdout.String(),
+		Duration: time.Since(start),
+	}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		result.TimedOut = true
+		result.ExitCode = -1
+		return result, nil
+	}
+	if err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			return nil, fmt.Errorf("failed to run %s: %w", name, err)
+		}
+		result.ExitCode = exitErr.ExitCode()
+	}
+	return result, nil
+}
+
+// lockedBuffer is a buffer safe for concurrent writes
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// Exists reports whether a path exists inside the workspace
+func (w *Workspace) Exists(rel string) bool {
+	_, err := os.Stat(w.Dir + string(os.PathSeparator) + rel)
+	return err == nil
+}
+
+// redact removes a secret-bearing URL from command output
+func redact(out, url string) string {
+	return strings.TrimSpace(strings.ReplaceAll(out, url, "<repository>"))
+}
@@ -0,0 +1,57 @@
+package sandbox
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunLeavesSecretsOut(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "ghp_secret")
+	t.Setenv("GGQUICK_ADMIN_TOKEN", "admin-secret")
+	t.Setenv("HOME", "/home/ggquick")
+
+	ws := &Workspace{Dir: t.TempDir()}
+	res, err := ws.Run(context.Background(), 10*time.Second, []string{"GOTOOLCHAIN=local"}, "sh", "-c", "env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.OK() {
+		t.Fatalf("env failed: %s", res.Output)
+	}
+	for _, leaked := range []string{"ghp_secret", "admin-secret"} {
+		if strings.Contains(res.Output, leaked) {
+			t.Errorf("command saw %s:\n%s", leaked, res.Output)
+		}
+	}
+	for _, want := range []string{"HOME=/home/ggquick", "GOTOOLCHAIN=local", "PATH="} {
+		if !strings.Contains(res.Output, want) {
+			t.Errorf("command didn't see %s:\n%s", want, res.Output)
+		}
+	}
+}
+
+func TestPublicURLDropsToken(t *testing.T) {
+	got := publicURL(CloneURL("acme", "widgets", "ghp_secret"))
+	if got != CloneURL("acme", "widgets", "") {
+		t.Errorf("publicURL = %q, want the URL without the token", got)
+	}
+	if local := "/tmp/repo"; publicURL(local) != local {
+		t.Errorf("publicURL changed a local path to %q", publicURL(local))
+	}
+}
+
+func TestRunKeepsStdoutApart(t *testing.T) {
+	ws := &Workspace{Dir: t.TempDir()}
+	res, err := ws.Run(context.Background(), 10*time.Second, nil, "sh", "-c", `echo "go: downloading x" >&2; echo '{"ImportPath":"x"}'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Stdout != "{\"ImportPath\":\"x\"}\n" {
+		t.Errorf("stdout is %q", res.Stdout)
+	}
+	if !strings.Contains(res.Output, "go: downloading x") || !strings.Contains(res.Output, "ImportPath") {
+		t.Errorf("output is missing a stream: %q", res.Output)
+	}
+}
@@ -0,0 +1,85 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// masterKeySalt is used when a master key is given as a passphrase rather
+// than 32 random bytes
+var masterKeySalt = []byte("ggquick-master-key")
+
+// Keyring encrypts with the current master key and decrypts with the
+// current or any previous one, so keys can be rotated without downtime
+type Keyring struct {
+	keys [][]byte // current first
+}
+
+// NewKeyring creates a keyring from a current key and retired ones
+func NewKeyring(current []byte, previous ...[]byte) (*Keyring, error) {
+	keys := append([][]byte{current}, previous...)
+	for _, key := range keys {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("key must be 32 bytes, got %d", len(key))
+		}
+	}
+	return &Keyring{keys: keys}, nil
+}
+
+// Encrypt seals plaintext with the current key
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	return Encrypt(k.keys[0], plaintext)
+}
+
+// Decrypt opens a value sealed with any key in the ring. stale reports
+// that a previous key was needed and the value should be re-encrypted.
+func (k *Keyring) Decrypt(value string) (plaintext string, stale bool, err error) {
+	for i, key := range k.keys {
+		if plaintext, err = Decrypt(key, value); err == nil {
+			return plaintext, i > 0, nil
+		}
+	}
+	return "", false, fmt.Errorf("no key in the keyring can decrypt the value")
+}
+
+// ParseKey accepts a base64 encoded 32-byte key, or derives one from any
+// other string treated as a passphrase
+func ParseKey(s string) []byte {
+	if key, err := base64.StdEncoding.DecodeString(s); err == nil && len(key) == 32 {
+		return key
+	}
+	return DeriveKey(s, masterKeySalt)
+}
+
+// KeyringFromEnv builds a keyring from GGQUICK_MASTER_KEY, or from the
+// output of GGQUICK_MASTER_KEY_COMMAND (e.g. a KMS decrypt call), plus
+// the comma separated retired keys in GGQUICK_MASTER_KEY_PREVIOUS.
+// It returns nil when no master key is configured.
+func KeyringFromEnv() (*Keyring, error) {
+	master := os.Getenv("GGQUICK_MASTER_KEY")
+	if command := os.Getenv("GGQUICK_MASTER_KEY_COMMAND"); master == "" && command != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, "sh", "-c", command).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to run master key command: %w", err)
+		}
+		master = strings.TrimSpace(string(out))
+	}
+	if master == "" {
+		return nil, nil
+	}
+
+	var previous [][]byte
+	for _, s := range strings.Split(os.Getenv("GGQUICK_MASTER_KEY_PREVIOUS"), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			previous = append(previous, ParseKey(s))
+		}
+	}
+	return NewKeyring(ParseKey(master), previous...)
+}
@@ -0,0 +1,92 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+)
+
+func TestKeyringRotation(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 32)
+
+	before, err := NewKeyring(oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := before.Encrypt("ghp_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// After rotating, values sealed with the old key still open, flagged
+	// for re-encryption
+	after, err := NewKeyring(newKey, oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, stale, err := after.Decrypt(sealed)
+	if err != nil || plaintext != "ghp_token" || !stale {
+		t.Fatalf("old value: got %q, stale %v, %v", plaintext, stale, err)
+	}
+
+	resealed, err := after.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plaintext, stale, err = after.Decrypt(resealed); err != nil || plaintext != "ghp_token" || stale {
+		t.Fatalf("re-encrypted value: got %q, stale %v, %v", plaintext, stale, err)
+	}
+
+	// Once the old key is retired, only re-encrypted values open
+	retired, err := NewKeyring(newKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := retired.Decrypt(sealed); err == nil {
+		t.Error("value sealed with a retired key opened")
+	}
+	if _, _, err := retired.Decrypt(resealed); err != nil {
+		t.Errorf("re-encrypted value: %v", err)
+	}
+}
+
+func TestNewKeyringRejectsShortKeys(t *testing.T) {
+	if _, err := NewKeyring(make([]byte, 32), make([]byte, 16)); err == nil {
+		t.Error("16-byte previous key accepted")
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	raw := bytes.Repeat([]byte{7}, 32)
+	if got := ParseKey(base64.StdEncoding.EncodeToString(raw)); !bytes.Equal(got, raw) {
+		t.Errorf("base64 key decoded to %x", got)
+	}
+	passphrase := ParseKey("correct horse battery staple")
+	if len(passphrase) != 32 || !bytes.Equal(passphrase, ParseKey("correct horse battery staple")) {
+		t.Errorf("passphrase derived %x, want the same 32 bytes each time", passphrase)
+	}
+}
+
+func TestKeyringFromEnv(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{3}, 32)
+	t.Setenv("GGQUICK_MASTER_KEY", "")
+	t.Setenv("GGQUICK_MASTER_KEY_COMMAND", "echo current-passphrase")
+	t.Setenv("GGQUICK_MASTER_KEY_PREVIOUS", " "+base64.StdEncoding.EncodeToString(oldKey)+", ")
+
+	ring, err := KeyringFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ring == nil || len(ring.keys) != 2 {
+		t.Fatalf("got keyring %v, want the current and one previous key", ring)
+	}
+	if !bytes.Equal(ring.keys[0], ParseKey("current-passphrase")) || !bytes.Equal(ring.keys[1], oldKey) {
+		t.Error("keys aren't the command's output followed by the previous key")
+	}
+
+	t.Setenv("GGQUICK_MASTER_KEY_COMMAND", "")
+	if ring, err := KeyringFromEnv(); ring != nil || err != nil {
+		t.Errorf("without a master key: got %v, %v, want neither", ring, err)
+	}
+}
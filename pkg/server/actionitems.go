@@ -0,0 +1,212 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/ai"
+)
+
+// WebhookPullRequestReview is sent when a review is submitted
+const WebhookPullRequestReview = "pull_request_review"
+
+// actionItemsMarker identifies the action items comment, so later runs
+// edit it instead of posting another
+const actionItemsMarker = "<!-- ggquick:action-items -->"
+
+// ActionItemsConfig keeps a checklist of what review feedback asks for in
+// one comment on the PR, updated as reviews are submitted
+type ActionItemsConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// actionItemsRequest asks for a PR's review feedback to be summarized
+type actionItemsRequest struct {
+	Repo string `json:"repo"`
+	PR   int    `json:"pr"`
+}
+
+// actionItemsResult is the checklist posted on the PR
+type actionItemsResult struct {
+	Number   int    `json:"number"`
+	URL      string `json:"url"`
+	Comments int    `json:"comments"` // review comments read
+	Items    int    `json:"items"`
+	Tokens   int    `json:"tokens,omitempty"`
+}
+
+// handleActionItems summarizes a PR's review comments into action items
+func (s *Server) handleActionItems(w http.ResponseWriter, r *http.Request) {
+	s.logger.Loading("📥 Receiving action items request...")
+
+	if r.Method != http.MethodPost {
+		s.logger.Error("❌ Invalid method: %s", r.Method)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	var req actionItemsRequest
+	if err := decodeJSON(w, r, maxBodySize, &req); err != nil {
+		s.logger.Error("❌ Failed to decode action items request: %v", err)
+		return
+	}
+	if req.PR <= 0 {
+		http.Error(w, "pr is required", http.StatusBadRequest)
+		return
+	}
+
+	config := s.repoConfig(req.Repo)
+	if config == nil {
+		s.logger.Error("❌ Repository not configured: %s", req.Repo)
+		http.Error(w, "Repository not configured", http.StatusBadRequest)
+		return
+	}
+	if !s.allowRepo(w, config.FullName()) {
+		return
+	}
+
+	owner, name := config.prTarget()
+	fetchCtx, cancel := context.WithTimeout(r.Context(), config.Timeouts.github())
+	defer cancel()
+	pr, err := s.github.GetPullRequest(fetchCtx, owner, name, req.PR)
+	if err == nil {
+		var result *actionItemsResult
+		if result, err = s.postActionItems(r.Context(), config, pr); err == nil {
+			writeJSON(w, http.StatusOK, result)
+			return
+		}
+	}
+	s.logger.Error("❌ Action items failed: %v", err)
+	http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+}
+
+// handlePullRequestReviewEvent updates a PR's action items when a review
+// is submitted
+func (s *Server) handlePullRequestReviewEvent(e *github.PullRequestReviewEvent) {
+	config := s.webhookConfig(WebhookPullRequestReview, e.GetRepo())
+	if config == nil || config.ActionItems == nil || !config.ActionItems.Enabled ||
+		e.GetAction() != "submitted" || e.GetReview().GetUser().GetType() == "Bot" {
+		return
+	}
+	pr := e.GetPullRequest()
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*config.Timeouts.github()+config.Timeouts.generate())
+		defer cancel()
+		if _, err := s.postActionItems(ctx, config, pr); err != nil {
+			s.logger.Warning("Failed to update action items on #%d: %v", pr.GetNumber(), err)
+		}
+	}()
+}
+
+// postActionItems collects a PR's review feedback, has the model turn it
+// into a checklist and posts it, editing the earlier checklist if any
+func (s *Server) postActionItems(ctx context.Context, config *Config, pr *github.PullRequest) (*actionItemsResult, error) {
+	owner, name := config.prTarget()
+	number := pr.GetNumber()
+	// Reviews submitted together would otherwise each post a comment
+	release, err := s.waitLock(ctx, fmt.Sprintf("action-items:%s/%s#%d", owner, name, number), 2*config.Timeouts.github()+config.Timeouts.generate(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock PR: %w", err)
+	}
+	defer release()
+
+	fetchCtx, cancel := context.WithTimeout(ctx, config.Timeouts.github())
+	defer cancel()
+	feedback, err := s.reviewFeedback(fetchCtx, owner, name, number)
+	if err != nil {
+		return nil, err
+	}
+	if len(feedback) == 0 {
+		return nil, fmt.Errorf("PR #%d has no review comments", number)
+	}
+
+	s.logger.Loading("📋 Collecting action items from %d review comment(s) on #%d...", len(feedback), number)
+	instructions := ""
+	if prompt := s.promptTemplate(config); prompt != "" {
+		instructions, _ = expandTemplate("prompt", prompt, s.templateVars(config, &Job{Branch: pr.GetHead().GetRef()}))
+	}
+	instructions = withLanguage(instructions, s.userLanguage(config, pr.GetUser().GetLogin()))
+	genCtx, cancelGen := context.WithTimeout(ctx, config.Timeouts.generate())
+	defer cancelGen()
+	items, tokens, err := s.generator.ActionItems(genCtx, pr.GetTitle(), feedback, instructions)
+	if err == nil {
+		err = s.checkContent(ctx, config, items)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	postCtx, cancelPost := context.WithTimeout(ctx, config.Timeouts.github())
+	defer cancelPost()
+	body := actionItemsComment(sanitize(config, items, ""), len(feedback))
+	if err := s.github.UpsertComment(postCtx, owner, name, number, actionItemsMarker, body); err != nil {
+		return nil, err
+	}
+	count := strings.Count(items, "- [ ] ")
+	s.logger.Success("✅ Posted %d action item(s) on #%d", count, number)
+	return &actionItemsResult{Number: number, URL: pr.GetHTMLURL(), Comments: len(feedback), Items: count, Tokens: tokens}, nil
+}
+
+// reviewFeedback returns the review summaries and line comments people
+// left on a PR, oldest first. Bots and comments on code that has since
+// changed are left out.
+func (s *Server) reviewFeedback(ctx context.Context, owner, name string, number int) ([]ai.ReviewComment, error) {
+	reviews, err := s.github.GetReviews(ctx, owner, name, number)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := s.github.GetReviewComments(ctx, owner, name, number)
+	if err != nil {
+		return nil, err
+	}
+
+	type dated struct {
+		at      time.Time
+		comment ai.ReviewComment
+	}
+	var all []dated
+	for _, review := range reviews {
+		if review.GetUser().GetType() == "Bot" || strings.TrimSpace(review.GetBody()) == "" {
+			continue
+		}
+		all = append(all, dated{review.GetSubmittedAt().Time, ai.ReviewComment{
+			Author: review.GetUser().GetLogin(),
+			Body:   review.GetBody(),
+		}})
+	}
+	for _, c := range comments {
+		if c.GetUser().GetType() == "Bot" || c.Position == nil {
+			continue
+		}
+		all = append(all, dated{c.GetCreatedAt().Time, ai.ReviewComment{
+			Author: c.GetUser().GetLogin(),
+			Path:   c.GetPath(),
+			Line:   c.GetLine(),
+			Body:   c.GetBody(),
+		}})
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].at.Before(all[j].at) })
+
+	feedback := make([]ai.ReviewComment, len(all))
+	for i, d := range all {
+		feedback[i] = d.comment
+	}
+	return feedback, nil
+}
+
+// actionItemsComment renders the action items comment
+func actionItemsComment(items string, comments int) string {
+	if strings.TrimSpace(items) == "" {
+		items = "Nothing left to do."
+	}
+	return fmt.Sprintf("%s\n## Review Action Items\n\n%s\n\n_Collected from %d review comment(s) and updated as reviews come in._",
+		actionItemsMarker, strings.TrimSpace(items), comments)
+}
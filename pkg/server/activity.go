@@ -0,0 +1,144 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// activityRetention is how long daily activity is kept, and so the
+// longest window a report covers
+const activityRetention = 366 * 24 * time.Hour
+
+// activityDayFormat keys daily activity by UTC date
+const activityDayFormat = "2006-01-02"
+
+// activityDay counts a repository's finished jobs on one UTC day
+type activityDay struct {
+	Day        string           `json:"day"`
+	Generated  int              `json:"generated"`
+	Failed     int              `json:"failed,omitempty"`
+	Tokens     int              `json:"tokens"`
+	PRs        []generatedPR    `json:"prs,omitempty"`
+	References []ReferenceStats `json:"references,omitempty"`
+}
+
+// generatedPR is a PR ggquick opened and who pushed its branch
+type generatedPR struct {
+	Number int    `json:"number"`
+	Author string `json:"author,omitempty"`
+}
+
+// activityStore keeps daily activity by repository for reports. Unlike
+// the job store it isn't evicted by newer jobs, and it is saved in the
+// state file so reports survive restarts.
+type activityStore struct {
+	mu   sync.Mutex
+	days map[string][]*activityDay // by repo, oldest first
+}
+
+// record counts a succeeded or failed job on the current day. Tokens
+// spent by cancelled jobs aren't counted.
+func (a *activityStore) record(job Job) {
+	if job.Status != JobSucceeded && job.Status != JobFailed {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	repo := job.Owner + "/" + job.Repo
+	now := time.Now().UTC()
+	today := now.Format(activityDayFormat)
+
+	days := a.days[repo]
+	oldest := now.Add(-activityRetention).Format(activityDayFormat)
+	for len(days) > 0 && days[0].Day < oldest {
+		days = days[1:]
+	}
+	if len(days) == 0 || days[len(days)-1].Day != today {
+		days = append(days, &activityDay{Day: today})
+	}
+	day := days[len(days)-1]
+	a.days[repo] = days
+
+	day.Tokens += job.Tokens
+	day.References = addReferenceStats(day.References, job)
+	if job.Status == JobFailed {
+		day.Failed++
+		return
+	}
+	day.Generated++
+	if job.PRNumber != 0 {
+		day.PRs = append(day.PRs, generatedPR{Number: job.PRNumber, Author: job.Author})
+	}
+}
+
+// since returns repo's activity on the days from since's onwards
+func (a *activityStore) since(repo string, since time.Time) []activityDay {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	first := since.UTC().Format(activityDayFormat)
+	var days []activityDay
+	for _, day := range a.days[repo] {
+		if day.Day >= first {
+			days = append(days, *day)
+		}
+	}
+	return days
+}
+
+// all returns a copy of the stored activity
+func (a *activityStore) all() map[string][]*activityDay {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.days) == 0 {
+		return nil
+	}
+	all := make(map[string][]*activityDay, len(a.days))
+	for repo, days := range a.days {
+		for _, day := range days {
+			copied := *day
+			all[repo] = append(all[repo], &copied)
+		}
+	}
+	return all
+}
+
+// load replaces the stored activity, e.g. with the state file's
+func (a *activityStore) load(days map[string][]*activityDay) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.days = days
+	if a.days == nil {
+		a.days = make(map[string][]*activityDay)
+	}
+}
+
+// recordActivity counts a finished job in its repository's activity and
+// saves it
+func (s *Server) recordActivity(id string) {
+	job, ok := s.jobs.get(id)
+	if !ok {
+		return
+	}
+	s.activity.record(job)
+	s.persist()
+}
+
+// mergeReferenceStats adds more to stats, per model and prompt version
+func mergeReferenceStats(stats, more []ReferenceStats) []ReferenceStats {
+	for _, m := range more {
+		i := 0
+		for i < len(stats) && (stats[i].Model != m.Model || stats[i].PromptVersion != m.PromptVersion) {
+			i++
+		}
+		if i == len(stats) {
+			stats = append(stats, ReferenceStats{Model: m.Model, PromptVersion: m.PromptVersion})
+		}
+		stats[i].Descriptions += m.Descriptions
+		stats[i].References += m.References
+		stats[i].Unverified += m.Unverified
+		if stats[i].References > 0 {
+			stats[i].Rate = float64(stats[i].Unverified) / float64(stats[i].References)
+		}
+	}
+	return stats
+}
@@ -0,0 +1,234 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/pipeline"
+	"github.com/saint0x/ggquick/pkg/secrets"
+)
+
+// exportVersion is bumped when the export format changes incompatibly
+const exportVersion = 1
+
+// Export is a portable snapshot of every repository configuration
+type Export struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	// Salt for deriving the key from GGQUICK_EXPORT_KEY, set when
+	// secrets are included
+	Salt string `json:"salt,omitempty"`
+	// SecretsOmitted is set when no export key was configured
+	SecretsOmitted bool         `json:"secrets_omitted,omitempty"`
+	Repos          []*Config    `json:"repos"`
+	Orgs           []*OrgConfig `json:"orgs,omitempty"`
+}
+
+// requireAdmin checks the bearer token when GGQUICK_ADMIN_TOKEN is set
+func requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	token := os.Getenv("GGQUICK_ADMIN_TOKEN")
+	if token == "" {
+		return true
+	}
+	got := r.Header.Get("Authorization")
+	if subtle.ConstantTimeCompare([]byte(got), []byte("Bearer "+token)) != 1 {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// isAdmin reports whether the request carries the admin token. Unlike
+// requireAdmin it is false when no token is set, for checks that must
+// not pass just because the server has no admin token.
+func isAdmin(r *http.Request) bool {
+	token := os.Getenv("GGQUICK_ADMIN_TOKEN")
+	if token == "" {
+		return false
+	}
+	got := r.Header.Get("Authorization")
+	return subtle.ConstantTimeCompare([]byte(got), []byte("Bearer "+token)) == 1
+}
+
+// exportConfig snapshots all configs, encrypting secrets with
+// GGQUICK_EXPORT_KEY or dropping them when it isn't set
+func (s *Server) exportConfig() (*Export, error) {
+	export := &Export{
+		Version:    exportVersion,
+		ExportedAt: time.Now().UTC(),
+		Repos:      []*Config{},
+	}
+
+	var key []byte
+	if passphrase := os.Getenv("GGQUICK_EXPORT_KEY"); passphrase != "" {
+		salt, err := secrets.NewSalt()
+		if err != nil {
+			return nil, err
+		}
+		export.Salt = base64.StdEncoding.EncodeToString(salt)
+		key = secrets.DeriveKey(passphrase, salt)
+	} else {
+		export.SecretsOmitted = true
+	}
+
+	s.mu.RLock()
+	for _, config := range s.configs {
+		export.Repos = append(export.Repos, cloneConfig(config))
+	}
+	for _, org := range s.orgs {
+		export.Orgs = append(export.Orgs, org)
+	}
+	s.mu.RUnlock()
+	sort.Slice(export.Repos, func(i, j int) bool {
+		return export.Repos[i].FullName() < export.Repos[j].FullName()
+	})
+	sort.Slice(export.Orgs, func(i, j int) bool {
+		return export.Orgs[i].Name < export.Orgs[j].Name
+	})
+
+	err := mapSecrets(export.Repos, func(secret string) (string, error) {
+		if key == nil {
+			return "", nil
+		}
+		return secrets.Encrypt(key, secret)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt secrets: %w", err)
+	}
+	return export, nil
+}
+
+// importConfig decrypts an export's secrets and stores its configs,
+// replacing existing configs for the same repositories
+func (s *Server) importConfig(ctx context.Context, export *Export) (int, error) {
+	if export.Version != exportVersion {
+		return 0, fmt.Errorf("unsupported export version %d", export.Version)
+	}
+
+	var key []byte
+	if export.Salt != "" {
+		passphrase := os.Getenv("GGQUICK_EXPORT_KEY")
+		if passphrase == "" {
+			return 0, fmt.Errorf("export contains encrypted secrets but GGQUICK_EXPORT_KEY is not set")
+		}
+		salt, err := base64.StdEncoding.DecodeString(export.Salt)
+		if err != nil {
+			return 0, fmt.Errorf("invalid salt: %w", err)
+		}
+		key = secrets.DeriveKey(passphrase, salt)
+	}
+
+	for _, config := range export.Repos {
+		if config.Owner == "" || config.Name == "" {
+			return 0, fmt.Errorf("repository is missing owner or name")
+		}
+		if err := config.validate(); err != nil {
+			return 0, fmt.Errorf("invalid config for %s: %w", config.FullName(), err)
+		}
+		if !s.repos.allowed(config.FullName()) {
+			return 0, fmt.Errorf("repository %s is not allowed on this server", config.FullName())
+		}
+	}
+	for _, org := range export.Orgs {
+		if err := org.validate(); err != nil {
+			return 0, fmt.Errorf("invalid org config: %w", err)
+		}
+	}
+	err := mapSecrets(export.Repos, func(secret string) (string, error) {
+		if !secrets.IsEncrypted(secret) {
+			return secret, nil
+		}
+		if key == nil {
+			return "", fmt.Errorf("secret is encrypted but the export has no salt")
+		}
+		return secrets.Decrypt(key, secret)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to decrypt secrets: %w", err)
+	}
+
+	// Point each repository's webhook at this instance
+	for _, config := range export.Repos {
+		if err := s.hooks.CreateHook(ctx, config.Owner, config.Name, webhookURL(), webhookSecret(), config.webhookEvents()); err != nil {
+			s.logger.Warning("Failed to configure webhook for %s: %v", config.FullName(), err)
+		}
+	}
+
+	s.mu.Lock()
+	for _, config := range export.Repos {
+		s.configs[config.FullName()] = config
+	}
+	for _, org := range export.Orgs {
+		s.orgs[org.Name] = org
+	}
+	s.mu.Unlock()
+	s.persist()
+	return len(export.Repos), nil
+}
+
+// cloneConfig copies a config deeply enough that secrets can be
+// rewritten without touching the live one
+func cloneConfig(config *Config) *Config {
+	c := *config
+	c.Extensions = make([]*pipeline.WebhookStage, len(config.Extensions))
+	for i, ext := range config.Extensions {
+		copied := *ext
+		c.Extensions[i] = &copied
+	}
+	return &c
+}
+
+// handleExport returns every repository configuration as an Export
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	export, err := s.exportConfig()
+	if err != nil {
+		s.logger.Error("❌ Failed to export configuration: %v", err)
+		http.Error(w, "Failed to export configuration", http.StatusInternalServerError)
+		return
+	}
+	if export.SecretsOmitted {
+		s.logger.Warning("GGQUICK_EXPORT_KEY not set, secrets omitted from export")
+	}
+	s.logger.Success("📦 Exported %d repository config(s)", len(export.Repos))
+	writeJSON(w, http.StatusOK, export)
+}
+
+// handleImport loads an Export produced by this or another instance
+func (s *Server) handleImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	var export Export
+	if err := decodeJSON(w, r, maxImportSize, &export); err != nil {
+		s.logger.Error("❌ Failed to decode import: %v", err)
+		return
+	}
+
+	n, err := s.importConfig(r.Context(), &export)
+	if err != nil {
+		s.logger.Error("❌ Failed to import configuration: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.logger.Success("📥 Imported %d repository config(s)", n)
+	writeJSON(w, http.StatusOK, map[string]int{"imported": n})
+}
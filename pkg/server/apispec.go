@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/analyze"
+)
+
+// maxAPISpecs caps how many spec files are fetched and compared per job
+const maxAPISpecs = 10
+
+// apiSpecChanges are the structural changes to one spec file
+type apiSpecChanges struct {
+	File    string
+	Changes []analyze.APIChange
+}
+
+// apiChanges fetches each changed .proto and OpenAPI file at base and head
+// and compares them structurally
+func (s *Server) apiChanges(ctx context.Context, config *Config, base, head string, files []*github.CommitFile) []apiSpecChanges {
+	var specs []apiSpecChanges
+	for _, f := range files {
+		name := f.GetFilename()
+		proto := analyze.IsProto(name)
+		if !proto && !analyze.IsOpenAPI(name, f.GetPatch()) {
+			continue
+		}
+		if len(specs) == maxAPISpecs {
+			s.logger.Warning("More than %d API specs changed, skipping the rest", maxAPISpecs)
+			break
+		}
+
+		var before, after string
+		var err error
+		if f.GetStatus() != "added" {
+			old := name
+			if f.GetPreviousFilename() != "" {
+				old = f.GetPreviousFilename()
+			}
+			if before, err = s.github.GetFileContent(ctx, config.Owner, config.Name, old, base); err != nil {
+				s.logger.Warning("Failed to read %s at base: %v", old, err)
+				continue
+			}
+		}
+		if f.GetStatus() != "removed" {
+			if after, err = s.github.GetFileContent(ctx, config.Owner, config.Name, name, head); err != nil {
+				s.logger.Warning("Failed to read %s: %v", name, err)
+				continue
+			}
+		}
+
+		var changes []analyze.APIChange
+		if proto {
+			changes = analyze.DiffAPI(analyze.ParseProto(before), analyze.ParseProto(after))
+		} else {
+			oldSpec, err := analyze.ParseOpenAPI(before)
+			if err != nil {
+				s.logger.Warning("Failed to parse %s at base: %v", name, err)
+				continue
+			}
+			newSpec, err := analyze.ParseOpenAPI(after)
+			if err != nil {
+				s.logger.Warning("Failed to parse %s: %v", name, err)
+				continue
+			}
+			changes = analyze.DiffAPI(oldSpec, newSpec)
+		}
+		if len(changes) > 0 {
+			specs = append(specs, apiSpecChanges{File: name, Changes: changes})
+		}
+	}
+	return specs
+}
+
+// apiBreaking reports whether any spec change is breaking
+func apiBreaking(specs []apiSpecChanges) bool {
+	for _, spec := range specs {
+		for _, c := range spec.Changes {
+			if c.Breaking {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// apiNote summarizes the API changes for the model
+func apiNote(specs []apiSpecChanges) string {
+	var parts []string
+	for _, spec := range specs {
+		var items []string
+		for _, c := range spec.Changes {
+			items = append(items, fmt.Sprintf("%s %s %s", c.Change, c.Kind, c.Name))
+		}
+		parts = append(parts, fmt.Sprintf("%s (%s)", spec.File, strings.Join(items, "; ")))
+	}
+	return "API definitions changed: " + strings.Join(parts, ", ")
+}
+
+// apiSection renders a table of API changes per spec file, breaking
+// changes first
+func apiSection(specs []apiSpecChanges) string {
+	var b strings.Builder
+	b.WriteString("## API Changes\n")
+	if apiBreaking(specs) {
+		b.WriteString("\n> [!WARNING]\n")
+		b.WriteString("> Removed or changed elements may break existing clients.\n")
+	}
+	for _, spec := range specs {
+		fmt.Fprintf(&b, "\n### `%s`\n\n", spec.File)
+		b.WriteString("| Change | Kind | Name | Details |\n")
+		b.WriteString("|--------|------|------|---------|\n")
+		for _, c := range spec.Changes {
+			change := c.Change
+			if c.Breaking {
+				change = "⚠️ " + change
+			}
+			details := ""
+			switch {
+			case c.Change == "changed":
+				details = fmt.Sprintf("`%s` → `%s`", c.Before, c.After)
+			case c.After != "":
+				details = "`" + c.After + "`"
+			case c.Before != "":
+				details = "`" + c.Before + "`"
+			}
+			fmt.Fprintf(&b, "| %s | %s | `%s` | %s |\n", change, c.Kind, c.Name, details)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JobHeld is a job over its author's daily cap, waiting for approval
+const JobHeld = "held"
+
+// EventJobHeld is sent when a job is held for approval
+const EventJobHeld = "job_held"
+
+// authorCapWindow is the period caps count over
+const authorCapWindow = 24 * time.Hour
+
+// unknownAuthor is the bucket jobs without a verified author count
+// against, so naming someone else in a push can't skip the cap. It can't
+// be a login.
+const unknownAuthor = "(unknown)"
+
+// AuthorCapConfig caps how many branches each author can get PRs
+// generated for per day, so a runaway bot can't open dozens. Pushes to a
+// branch already counted don't count again. Jobs over the cap are held
+// until approved.
+type AuthorCapConfig struct {
+	// Daily is the cap for every author
+	Daily int `json:"daily"`
+	// Authors overrides the cap for some logins, e.g. a busy bot
+	Authors map[string]int `json:"authors,omitempty"`
+}
+
+// validate checks the caps are positive
+func (c *AuthorCapConfig) validate() error {
+	if c.Daily <= 0 {
+		return fmt.Errorf("author_cap daily must be positive")
+	}
+	for login, n := range c.Authors {
+		if n <= 0 {
+			return fmt.Errorf("author_cap for %s must be positive", login)
+		}
+	}
+	return nil
+}
+
+// limit returns author's cap
+func (c *AuthorCapConfig) limit(author string) int {
+	for login, n := range c.Authors {
+		if strings.EqualFold(login, author) {
+			return n
+		}
+	}
+	return c.Daily
+}
+
+// heldJob is a job waiting for approval
+type heldJob struct {
+	config    *Config
+	job       *Job
+	commitMsg string
+	priority  string
+}
+
+// authorCaps counts the branches each author's jobs ran for and holds
+// jobs over the cap
+type authorCaps struct {
+	mu       sync.Mutex
+	branches map[string]map[string]time.Time // repo:author -> branch -> first admitted
+	held     map[string]*heldJob             // by job ID
+}
+
+// newAuthorCaps creates empty author caps
+func newAuthorCaps() *authorCaps {
+	return &authorCaps{
+		branches: make(map[string]map[string]time.Time),
+		held:     make(map[string]*heldJob),
+	}
+}
+
+// admit counts the branch against the author's cap and reports whether
+// it is within it. Approved jobs are counted whatever the cap.
+func (c *authorCaps) admit(repo, author, branch string, limit int, approved bool) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := repo + ":" + strings.ToLower(author)
+	branches := c.branches[key]
+	if branches == nil {
+		branches = make(map[string]time.Time)
+		c.branches[key] = branches
+	}
+	now := time.Now()
+	for b, at := range branches {
+		if now.Sub(at) >= authorCapWindow {
+			delete(branches, b)
+		}
+	}
+	if _, ok := branches[branch]; ok {
+		return true
+	}
+	if len(branches) >= limit && !approved {
+		return false
+	}
+	branches[branch] = now
+	return true
+}
+
+// capAuthor returns who a job counts against
+func capAuthor(job Job) string {
+	if job.capIdentity == "" {
+		return unknownAuthor
+	}
+	return job.capIdentity
+}
+
+// setCapAuthor counts job against author, who GitHub or a user key
+// vouches for. Authors pushes name themselves are never passed here.
+func (s *Server) setCapAuthor(job *Job, author string) {
+	if author == "" {
+		return
+	}
+	s.jobs.update(job.ID, func(j *Job) { j.capIdentity = author })
+}
+
+// holdOverCap holds job when its author is over the repository's cap and
+// reports whether it did. An earlier held job for the branch is replaced.
+// Only pushes are capped; backfills and stale branch sweeps need the
+// admin token to start or configure, and aren't held.
+func (s *Server) holdOverCap(config *Config, job *Job, commitMsg, priority string) bool {
+	if config.AuthorCap == nil {
+		return false
+	}
+	current, _ := s.jobs.get(job.ID)
+	author := capAuthor(current)
+	limit := config.AuthorCap.limit(author)
+	if s.caps.admit(config.FullName(), author, job.Branch, limit, false) {
+		return false
+	}
+
+	s.caps.mu.Lock()
+	for id, h := range s.caps.held {
+		if h.config.FullName() == config.FullName() && h.job.Branch == job.Branch {
+			delete(s.caps.held, id)
+			s.jobs.update(id, func(j *Job) {
+				j.Status = JobCancelled
+				j.Error = "superseded by job " + job.ID
+			})
+		}
+	}
+	s.caps.held[job.ID] = &heldJob{config: config, job: job, commitMsg: commitMsg, priority: priority}
+	s.caps.mu.Unlock()
+
+	s.jobs.update(job.ID, func(j *Job) { j.Status = JobHeld })
+	who := "@" + author + " is"
+	if author == unknownAuthor {
+		who = "pushes without a verified author are"
+	}
+	reason := fmt.Sprintf("%s over the cap of %d branches a day, approve with ggquick approve %s", who, limit, job.ID)
+	s.events.publish(jobEvent(EventJobHeld, job, reason))
+	s.logger.Warning("Held job %s for %s: %s", job.ID, job.Branch, reason)
+	return true
+}
+
+// approveJob queues a held job, counting it against its author's cap
+func (s *Server) approveJob(id string) (Job, error) {
+	s.caps.mu.Lock()
+	h, ok := s.caps.held[id]
+	delete(s.caps.held, id)
+	s.caps.mu.Unlock()
+	if !ok {
+		job, found := s.findJob(id)
+		if !found {
+			return Job{}, fmt.Errorf("job %s not found", id)
+		}
+		return job, fmt.Errorf("job %s is %s, not held", id, job.Status)
+	}
+
+	current, _ := s.jobs.get(id)
+	s.caps.admit(h.config.FullName(), capAuthor(current), h.job.Branch, 0, true)
+	s.jobs.update(id, func(j *Job) {
+		j.Status = JobQueued
+		j.Error = ""
+	})
+	s.logger.Info("▶️ Approved job %s for %s", id, h.job.Branch)
+	go func() {
+		if err := <-s.enqueueJob(context.Background(), h.config, h.job, h.commitMsg, h.priority); err != nil {
+			s.logger.Error("❌ Failed to run approved job %s: %v", id, err)
+		}
+	}()
+	job, _ := s.jobs.get(id)
+	return job, nil
+}
+
+// handleApprove runs a held job (POST /jobs/{id}/approve)
+func (s *Server) handleApprove(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+	job, err := s.approveJob(id)
+	if err != nil {
+		status := http.StatusConflict
+		if job.ID == "" {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
@@ -0,0 +1,515 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/redis"
+)
+
+// QueueBackend shares queued jobs, locks and job records between server
+// replicas
+type QueueBackend interface {
+	// Push queues a task on a priority lane
+	Push(ctx context.Context, priority string, task []byte) error
+	// Pop waits up to wait for a task from lanes, taken in order unless a
+	// lane's oldest task has waited past maxAge. It returns a nil task
+	// when nothing arrived.
+	Pop(ctx context.Context, lanes []string, wait, maxAge time.Duration) (task []byte, priority string, err error)
+	// Lock takes key for ttl. It returns a nil release function when
+	// another holder has it.
+	Lock(ctx context.Context, key string, ttl time.Duration) (release func(), err error)
+	// SaveJob and LoadJob share job records so any replica can report them
+	SaveJob(ctx context.Context, id string, data []byte) error
+	LoadJob(ctx context.Context, id string) ([]byte, error)
+	// Lead claims or renews key for id until ttl passes without renewal,
+	// reporting whether id holds it
+	Lead(ctx context.Context, key, id string, ttl time.Duration) (bool, error)
+	// Cancel flags a job so the replica running it stops
+	Cancel(ctx context.Context, id string) error
+	Cancelled(ctx context.Context, id string) (bool, error)
+}
+
+// backendKeyPrefix namespaces every key ggquick writes
+const backendKeyPrefix = "ggquick:"
+
+// jobRecordTTL is how long shared job records and cancel flags live
+const jobRecordTTL = 24 * time.Hour
+
+// BackendFromURL connects to the queue backend at rawURL
+func BackendFromURL(rawURL string) (QueueBackend, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid queue url: %w", err)
+	}
+	switch u.Scheme {
+	case "redis", "rediss":
+		client, err := redis.New(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		return &redisBackend{client: client}, nil
+	case "nats", "tls":
+		return newNATSBackend(rawURL)
+	}
+	return nil, fmt.Errorf("unsupported queue url scheme %q", u.Scheme)
+}
+
+// redisBackend keeps one list per lane, holding "<unix nanos> <task>"
+type redisBackend struct {
+	client *redis.Client
+}
+
+// releaseScript deletes a lock only if this holder still owns it
+const releaseScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+
+func (b *redisBackend) laneKey(priority string) string {
+	return backendKeyPrefix + "queue:" + priority
+}
+
+func (b *redisBackend) Push(ctx context.Context, priority string, task []byte) error {
+	item := strconv.FormatInt(time.Now().UnixNano(), 10) + " " + string(task)
+	if _, err := b.client.Do(ctx, "RPUSH", b.laneKey(priority), item); err != nil {
+		return fmt.Errorf("failed to queue job: %w", err)
+	}
+	return nil
+}
+
+func (b *redisBackend) Pop(ctx context.Context, lanes []string, wait, maxAge time.Duration) ([]byte, string, error) {
+	if len(lanes) == 0 {
+		time.Sleep(wait)
+		return nil, "", nil
+	}
+
+	// Starved lanes first, oldest head wins
+	starved, oldest := "", time.Now().Add(-maxAge)
+	for _, lane := range lanes {
+		reply, err := b.client.Do(ctx, "LINDEX", b.laneKey(lane), "0")
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read queue: %w", err)
+		}
+		if item, ok := reply.(string); ok {
+			if at, _, ok := splitItem(item); ok && at.Before(oldest) {
+				starved, oldest = lane, at
+			}
+		}
+	}
+	if starved != "" {
+		reply, err := b.client.Do(ctx, "LPOP", b.laneKey(starved))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to pop queue: %w", err)
+		}
+		if item, ok := reply.(string); ok {
+			_, task, _ := splitItem(item)
+			return task, starved, nil
+		}
+	}
+
+	args := []string{"BLPOP"}
+	for _, lane := range lanes {
+		args = append(args, b.laneKey(lane))
+	}
+	args = append(args, strconv.Itoa(int(wait.Seconds())))
+	ctx, cancel := context.WithTimeout(ctx, wait+10*time.Second)
+	defer cancel()
+	reply, err := b.client.Do(ctx, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to pop queue: %w", err)
+	}
+	pair, ok := reply.([]interface{})
+	if !ok || len(pair) != 2 {
+		return nil, "", nil
+	}
+	key, _ := pair[0].(string)
+	item, _ := pair[1].(string)
+	_, task, _ := splitItem(item)
+	return task, strings.TrimPrefix(key, backendKeyPrefix+"queue:"), nil
+}
+
+// splitItem separates a queued item's enqueue time from its task
+func splitItem(item string) (time.Time, []byte, bool) {
+	stamp, task, ok := strings.Cut(item, " ")
+	nanos, err := strconv.ParseInt(stamp, 10, 64)
+	if !ok || err != nil {
+		return time.Time{}, []byte(item), false
+	}
+	return time.Unix(0, nanos), []byte(task), true
+}
+
+func (b *redisBackend) Lock(ctx context.Context, key string, ttl time.Duration) (func(), error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+	key = backendKeyPrefix + "lock:" + key
+
+	reply, err := b.client.Do(ctx, "SET", key, token, "NX", "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		return nil, fmt.Errorf("failed to take lock: %w", err)
+	}
+	if reply == nil {
+		return nil, nil
+	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		b.client.Do(ctx, "EVAL", releaseScript, "1", key, token)
+	}, nil
+}
+
+// leadScript takes a lease when free or renews it for its holder
+const leadScript = `local holder = redis.call("get", KEYS[1])
+if holder == false or holder == ARGV[1] then
+	redis.call("set", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return 1
+end
+return 0`
+
+func (b *redisBackend) Lead(ctx context.Context, key, id string, ttl time.Duration) (bool, error) {
+	reply, err := b.client.Do(ctx, "EVAL", leadScript, "1", backendKeyPrefix+"leader:"+key, id, strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		return false, fmt.Errorf("failed to claim leadership: %w", err)
+	}
+	n, _ := reply.(int64)
+	return n == 1, nil
+}
+
+func (b *redisBackend) SaveJob(ctx context.Context, id string, data []byte) error {
+	ttl := strconv.Itoa(int(jobRecordTTL.Seconds()))
+	if _, err := b.client.Do(ctx, "SET", backendKeyPrefix+"job:"+id, string(data), "EX", ttl); err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+	return nil
+}
+
+func (b *redisBackend) LoadJob(ctx context.Context, id string) ([]byte, error) {
+	reply, err := b.client.Do(ctx, "GET", backendKeyPrefix+"job:"+id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job: %w", err)
+	}
+	data, _ := reply.(string)
+	if data == "" {
+		return nil, nil
+	}
+	return []byte(data), nil
+}
+
+func (b *redisBackend) Cancel(ctx context.Context, id string) error {
+	ttl := strconv.Itoa(int(jobRecordTTL.Seconds()))
+	if _, err := b.client.Do(ctx, "SET", backendKeyPrefix+"cancel:"+id, "1", "EX", ttl); err != nil {
+		return fmt.Errorf("failed to cancel job: %w", err)
+	}
+	return nil
+}
+
+func (b *redisBackend) Cancelled(ctx context.Context, id string) (bool, error) {
+	reply, err := b.client.Do(ctx, "EXISTS", backendKeyPrefix+"cancel:"+id)
+	if err != nil {
+		return false, fmt.Errorf("failed to check cancellation: %w", err)
+	}
+	n, _ := reply.(int64)
+	return n > 0, nil
+}
+
+// Polling intervals for work shared through a backend
+const (
+	popWait          = 5 * time.Second // longest a feeder blocks on an empty queue
+	lockPoll         = 2 * time.Second // how often a job retries its branch lock
+	cancelPoll       = 5 * time.Second // how often a running job checks for remote cancels
+	backendOpTimeout = 5 * time.Second // bound on job record reads and writes
+)
+
+// remoteJob is a queued job handed to whichever replica pops it. Only the
+// repository's name travels with it, never its config and secrets; the
+// replica runs it with its own config of the repository.
+type remoteJob struct {
+	Job       Job    `json:"job"`
+	Verified  bool   `json:"verified,omitempty"`
+	Repo      string `json:"repo"`
+	CommitMsg string `json:"commit_msg"`
+	// Local carries the job's localChanges, which Job doesn't encode
+	Local *localChanges `json:"local,omitempty"`
+}
+
+// sharedJob is the job record other replicas read
+type sharedJob struct {
+	Job      Job  `json:"job"`
+	Verified bool `json:"verified,omitempty"`
+}
+
+// SetQueueBackend shares the job queue, branch locks and job records with
+// other replicas through b
+func (s *Server) SetQueueBackend(b QueueBackend) {
+	s.backend = b
+	s.jobs.onChange = s.saveJob
+}
+
+// saveJob mirrors a job record to the backend
+func (s *Server) saveJob(job Job) {
+	data, err := json.Marshal(sharedJob{Job: job, Verified: job.authorVerified})
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), backendOpTimeout)
+	defer cancel()
+	if err := s.backend.SaveJob(ctx, job.ID, data); err != nil {
+		s.logger.Warning("Failed to share job %s: %v", job.ID, err)
+	}
+}
+
+// loadJob reads a job record written by any replica
+func (s *Server) loadJob(id string) (Job, bool) {
+	if s.backend == nil {
+		return Job{}, false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), backendOpTimeout)
+	defer cancel()
+	data, err := s.backend.LoadJob(ctx, id)
+	if err != nil {
+		s.logger.Warning("Failed to load job %s: %v", id, err)
+		return Job{}, false
+	}
+	var shared sharedJob
+	if data == nil || json.Unmarshal(data, &shared) != nil {
+		return Job{}, false
+	}
+	shared.Job.authorVerified = shared.Verified
+	return shared.Job, true
+}
+
+// findJob returns the latest record of a job, which another replica may
+// be running
+func (s *Server) findJob(id string) (Job, bool) {
+	if job, ok := s.loadJob(id); ok {
+		return job, true
+	}
+	return s.jobs.get(id)
+}
+
+// pushRemote queues a job on the backend for any replica to run
+func (s *Server) pushRemote(ctx context.Context, config *Config, job *Job, commitMsg, priority string) error {
+	current, _ := s.jobs.get(job.ID)
+	data, err := json.Marshal(remoteJob{Job: current, Verified: current.authorVerified, Repo: config.FullName(), CommitMsg: commitMsg, Local: current.local})
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, backendOpTimeout)
+	defer cancel()
+	return s.backend.Push(ctx, priority, data)
+}
+
+// runFeeders pulls jobs from the backend into the local worker pool until
+// ctx is done. There is one feeder per worker, so a replica only takes
+// jobs it can start.
+func (s *Server) runFeeders(ctx context.Context) {
+	for i := 0; i < s.queue.workers; i++ {
+		go s.feed(ctx)
+	}
+}
+
+// feed runs backend jobs one at a time
+func (s *Server) feed(ctx context.Context) {
+	for ctx.Err() == nil {
+		data, priority, err := s.backend.Pop(ctx, s.queue.openLanes(), popWait, starvationAge)
+		if err != nil {
+			s.logger.Warning("Failed to read job queue: %v", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(popWait):
+			}
+			continue
+		}
+		if data == nil {
+			continue
+		}
+
+		var task remoteJob
+		if err := json.Unmarshal(data, &task); err != nil || task.Repo == "" {
+			s.logger.Warning("Dropping malformed queued job: %v", err)
+			continue
+		}
+		job, ok := s.jobs.get(task.Job.ID)
+		jobPtr := &job
+		if !ok {
+			task.Job.local = task.Local
+			jobPtr = s.jobs.adopt(task.Job, task.Verified)
+		}
+		config := s.repoConfig(task.Repo)
+		if config == nil {
+			s.failJob(jobPtr, fmt.Errorf("repository %s is not configured on this replica", task.Repo))
+			continue
+		}
+		if s.remoteCancelled(jobPtr.ID) {
+			s.jobs.update(jobPtr.ID, func(j *Job) {
+				j.Status = JobCancelled
+				j.Error = "cancelled"
+			})
+			continue
+		}
+		if err := <-s.queue.submit(priority, func() error {
+			return s.runJob(ctx, config, jobPtr, task.CommitMsg)
+		}); err != nil {
+			s.logger.Error("❌ Job %s failed: %v", jobPtr.ID, err)
+		}
+	}
+}
+
+// openLanes lists the lanes a job could start from now
+func (q *jobQueue) openLanes() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var lanes []string
+	for _, lane := range priorityLanes {
+		if q.canRun(lane) {
+			lanes = append(lanes, lane)
+		}
+	}
+	return lanes
+}
+
+// remoteCancelled reports whether a job was cancelled on another replica
+func (s *Server) remoteCancelled(id string) bool {
+	if s.backend == nil {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), backendOpTimeout)
+	defer cancel()
+	cancelled, err := s.backend.Cancelled(ctx, id)
+	return err == nil && cancelled
+}
+
+// watchCancel stops a running job when another replica cancels it
+func (s *Server) watchCancel(ctx context.Context, id string) {
+	if s.backend == nil {
+		return
+	}
+	ticker := time.NewTicker(cancelPoll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if s.remoteCancelled(id) {
+				s.cancelJob(id)
+				return
+			}
+		}
+	}
+}
+
+// cancelRemote cancels a job this replica doesn't hold
+func (s *Server) cancelRemote(job Job) (Job, error) {
+	switch job.Status {
+	case JobSucceeded, JobFailed, JobCancelled:
+		return job, fmt.Errorf("job %s already %s", job.ID, job.Status)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), backendOpTimeout)
+	defer cancel()
+	if err := s.backend.Cancel(ctx, job.ID); err != nil {
+		return job, err
+	}
+	job.Status = JobCancelled
+	job.Error = "cancelled"
+	job.UpdatedAt = time.Now().UTC()
+	s.saveJob(job)
+	s.logger.Warning("Cancelled job %s for %s", job.ID, job.Branch)
+	return job, nil
+}
+
+// localLocks is the in-process lock used without a backend
+type localLocks struct {
+	mu   sync.Mutex
+	held map[string]bool
+}
+
+// lock takes key, returning nil when it is already held
+func (l *localLocks) lock(key string) func() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.held[key] {
+		return nil
+	}
+	l.held[key] = true
+	return func() {
+		l.mu.Lock()
+		delete(l.held, key)
+		l.mu.Unlock()
+	}
+}
+
+// tryLock takes key on the backend, or in process without one. A backend
+// lock expires after ttl in case its holder dies.
+func (s *Server) tryLock(ctx context.Context, key string, ttl time.Duration) (func(), error) {
+	if s.backend == nil {
+		return s.locks.lock(key), nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, backendOpTimeout)
+	defer cancel()
+	return s.backend.Lock(ctx, key, ttl)
+}
+
+// lockBranch waits until no other job for the branch is running, on this
+// or any replica, so two pushes can't race to open the same PR
+func (s *Server) lockBranch(ctx context.Context, config *Config, job *Job) (func(), error) {
+	key := "branch:" + config.FullName() + ":" + job.Branch
+	return s.waitLock(ctx, key, config.Timeouts.job()+time.Minute, func() error {
+		if s.cancelled(job.ID) {
+			return fmt.Errorf("job %s cancelled", job.ID)
+		}
+		return nil
+	})
+}
+
+// waitLock polls until key is taken or ctx is done. stop, when set, is
+// checked between attempts and ends the wait when it returns an error.
+func (s *Server) waitLock(ctx context.Context, key string, ttl time.Duration, stop func() error) (func(), error) {
+	waited := false
+	for {
+		release, err := s.tryLock(ctx, key, ttl)
+		if err != nil {
+			return nil, err
+		}
+		if release != nil {
+			return release, nil
+		}
+		if !waited {
+			s.logger.Info("ℹ️ Waiting for lock %s", key)
+			waited = true
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockPoll):
+		}
+		if stop != nil {
+			if err := stop(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// claimPeriodic reports whether this replica should run a periodic task
+// now. With a backend the first replica to claim key holds it for ttl, so
+// a new scheduler leader doesn't repeat work the last one just did.
+func (s *Server) claimPeriodic(ctx context.Context, key string, ttl time.Duration) bool {
+	if s.backend == nil {
+		return true
+	}
+	release, err := s.tryLock(ctx, "periodic:"+key, ttl)
+	if err != nil {
+		s.logger.Warning("Failed to claim %s: %v", key, err)
+		return false
+	}
+	return release != nil
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// backfillResult is a branch picked up by a backfill run
+type backfillResult struct {
+	staleBranch
+	JobID string `json:"job_id,omitempty"`
+}
+
+// parseSince parses a lookback window such as "30d", "12h" or "2w"
+func parseSince(since string) (time.Duration, error) {
+	if since == "" {
+		return 30 * 24 * time.Hour, nil
+	}
+
+	unit := since[len(since)-1]
+	if unit == 'd' || unit == 'w' {
+		n, err := strconv.Atoi(since[:len(since)-1])
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid since value: %s", since)
+		}
+		days := n
+		if unit == 'w' {
+			days *= 7
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(since)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid since value: %s", since)
+	}
+	return d, nil
+}
+
+// maxBackfillBranches caps the branches one backfill call picks up. Its
+// jobs are created up front and have to stay in the job store until the
+// caller has waited for them, pushes included, so larger backfills page
+// through their branches.
+const maxBackfillBranches = jobStoreSize / 4
+
+// backfillRequest is the body of a /backfill call
+type backfillRequest struct {
+	Repo   string `json:"repo"`
+	Since  string `json:"since"`
+	DryRun bool   `json:"dry_run"`
+	// After resumes after the last branch of the previous page
+	After string `json:"after"`
+	// Limit is the page size, at most maxBackfillBranches
+	Limit int `json:"limit"`
+}
+
+// validate checks the since window parses and the limit is in range
+func (r *backfillRequest) validate() error {
+	if r.Limit < 0 || r.Limit > maxBackfillBranches {
+		return fmt.Errorf("limit must be between 1 and %d", maxBackfillBranches)
+	}
+	_, err := parseSince(r.Since)
+	return err
+}
+
+// handleBackfill generates PRs for recent unmerged branches without one,
+// a page of branches in name order per call
+func (s *Server) handleBackfill(w http.ResponseWriter, r *http.Request) {
+	s.logger.Loading("📥 Receiving backfill request...")
+
+	if r.Method != http.MethodPost {
+		s.logger.Error("❌ Invalid method: %s", r.Method)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	var req backfillRequest
+	if err := decodeJSON(w, r, maxBodySize, &req); err != nil {
+		s.logger.Error("❌ Failed to decode backfill request: %v", err)
+		return
+	}
+	since, _ := parseSince(req.Since)
+	limit := req.Limit
+	if limit == 0 {
+		limit = maxBackfillBranches
+	}
+
+	config := s.repoConfig(req.Repo)
+	if config == nil {
+		s.logger.Error("❌ Repository not configured: %s", req.Repo)
+		http.Error(w, "Repository not configured", http.StatusBadRequest)
+		return
+	}
+	if !s.allowRepo(w, config.FullName()) {
+		return
+	}
+
+	cutoff := time.Now().Add(-since)
+	branches, err := s.unmergedBranches(r.Context(), config, req.After, limit, func(last time.Time) bool {
+		return last.After(cutoff)
+	})
+	if err != nil {
+		s.logger.Error("❌ Failed to list branches: %v", err)
+		http.Error(w, "Failed to list branches", http.StatusInternalServerError)
+		return
+	}
+	s.logger.Info("🌿 Found %d unmerged branch(es) in %s", len(branches), config.FullName())
+
+	results := make([]backfillResult, 0, len(branches))
+	var jobs []*Job
+	for _, b := range branches {
+		result := backfillResult{staleBranch: b}
+		if !req.DryRun {
+			job := s.jobs.create(config.Owner, config.Name, b.Name, "")
+			result.JobID = job.ID
+			jobs = append(jobs, job)
+		}
+		results = append(results, result)
+	}
+
+	if req.DryRun {
+		s.logger.Info("ℹ️ Dry run, no PRs will be generated")
+		writeJSON(w, http.StatusOK, results)
+		return
+	}
+
+	// Jobs run in the background lane, through the rate limiter. Backfills
+	// need the admin token, so author caps don't hold them.
+	go func() {
+		ctx := context.Background()
+		for i, job := range jobs {
+			if err := s.checkRateLimit(ctx); err != nil {
+				s.failJob(job, err)
+				continue
+			}
+			msg := branches[i].Message
+			s.events.publish(jobEvent(EventPushReceived, job, msg))
+			if err := <-s.enqueueJob(ctx, config, job, msg, PriorityBackground); err != nil {
+				s.logger.Error("❌ Backfill failed for %s: %v", job.Branch, err)
+			}
+		}
+		s.logger.Success("✨ Backfill complete for %s", config.FullName())
+	}()
+
+	writeJSON(w, http.StatusAccepted, results)
+}
@@ -0,0 +1,306 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/ai"
+	ghclient "github.com/saint0x/ggquick/pkg/github"
+)
+
+// backportTTL stops redelivered webhooks backporting a PR twice
+const backportTTL = 24 * time.Hour
+
+// BackportConfig opens backport PRs when merged PRs carry a backport label
+type BackportConfig struct {
+	Enabled bool `json:"enabled"`
+	// LabelPrefix marks backport labels, defaults to "backport-"
+	LabelPrefix string `json:"label_prefix,omitempty"`
+	// Branch maps the rest of the label to a target branch, defaults to
+	// "release/{version}" so backport-1.x targets release/1.x
+	Branch string `json:"branch,omitempty"`
+}
+
+// validate checks the branch pattern uses the label's version
+func (c *BackportConfig) validate() error {
+	if c.Branch != "" && !strings.Contains(c.Branch, "{version}") {
+		return fmt.Errorf("backport branch must contain {version}")
+	}
+	return nil
+}
+
+// target returns the branch a label backports to, or "" for other labels
+func (c *BackportConfig) target(label string) string {
+	prefix, pattern := c.LabelPrefix, c.Branch
+	if prefix == "" {
+		prefix = "backport-"
+	}
+	if pattern == "" {
+		pattern = "release/{version}"
+	}
+	version, ok := strings.CutPrefix(label, prefix)
+	if !ok || version == "" {
+		return ""
+	}
+	return strings.ReplaceAll(pattern, "{version}", version)
+}
+
+// backportRequest asks for a merged PR to be backported to a branch
+type backportRequest struct {
+	Repo string `json:"repo"`
+	PR   int    `json:"pr"`
+	To   string `json:"to"`
+}
+
+// backportResult is the backport PR that was opened
+type backportResult struct {
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+	Branch string `json:"branch"`
+	Target string `json:"target"`
+}
+
+// handleBackport opens a PR backporting a merged PR to another branch
+func (s *Server) handleBackport(w http.ResponseWriter, r *http.Request) {
+	s.logger.Loading("📥 Receiving backport request...")
+
+	if r.Method != http.MethodPost {
+		s.logger.Error("❌ Invalid method: %s", r.Method)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	var req backportRequest
+	if err := decodeJSON(w, r, maxBodySize, &req); err != nil {
+		s.logger.Error("❌ Failed to decode backport request: %v", err)
+		return
+	}
+	req.To = strings.TrimSpace(req.To)
+	if req.PR == 0 || req.To == "" {
+		http.Error(w, "pr and to are required", http.StatusBadRequest)
+		return
+	}
+
+	config := s.repoConfig(req.Repo)
+	if config == nil {
+		s.logger.Error("❌ Repository not configured: %s", req.Repo)
+		http.Error(w, "Repository not configured", http.StatusBadRequest)
+		return
+	}
+	if !s.allowRepo(w, config.FullName()) {
+		return
+	}
+
+	owner, name := config.prTarget()
+	fetchCtx, cancel := context.WithTimeout(r.Context(), config.Timeouts.github())
+	defer cancel()
+	pr, err := s.github.GetPullRequest(fetchCtx, owner, name, req.PR)
+	if err != nil {
+		s.logger.Error("❌ Backport failed: %v", err)
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	result, err := s.backport(r.Context(), config, pr, req.To)
+	if err != nil {
+		s.logger.Error("❌ Backport failed: %v", err)
+		status := http.StatusUnprocessableEntity
+		if errors.Is(err, ghclient.ErrConflict) {
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// backportLabels backports a merged PR to the branches its labels name.
+// On merge every backport label counts; afterwards only the one added.
+// The webhook payload only picks candidates: the PR is fetched from
+// GitHub again, and only merged PRs still carrying the label are
+// backported.
+func (s *Server) backportLabels(config *Config, event *github.PullRequest, labels []*github.Label) {
+	if config.Backport == nil || !config.Backport.Enabled || event.GetMergedAt().IsZero() {
+		return
+	}
+	targets := make(map[string]string) // by label
+	for _, label := range labels {
+		if target := config.Backport.target(label.GetName()); target != "" {
+			targets[label.GetName()] = target
+		}
+	}
+	if len(targets) == 0 {
+		return
+	}
+	owner, name := config.prTarget()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), config.Timeouts.github())
+		pr, err := s.github.GetPullRequest(ctx, owner, name, event.GetNumber())
+		cancel()
+		if err != nil {
+			s.logger.Warning("Backport of #%d not started: %v", event.GetNumber(), err)
+			return
+		}
+		if !pr.GetMerged() {
+			s.logger.Warning("Backport of #%d not started: GitHub doesn't report it merged", pr.GetNumber())
+			return
+		}
+		for _, label := range pr.Labels {
+			target, ok := targets[label.GetName()]
+			if !ok {
+				continue
+			}
+			go func() {
+				ctx, cancel := context.WithTimeout(context.Background(), config.Timeouts.github()+config.Timeouts.createPR()+config.Timeouts.generate())
+				defer cancel()
+
+				key := fmt.Sprintf("backport:%s/%s#%d:%s", owner, name, pr.GetNumber(), target)
+				release, err := s.tryLock(ctx, key, backportTTL)
+				if err != nil || release == nil {
+					return
+				}
+				time.AfterFunc(backportTTL, release)
+
+				if _, err := s.backport(ctx, config, pr, target); err != nil {
+					s.logger.Warning("Backport of #%d to %s failed: %v", pr.GetNumber(), target, err)
+				}
+			}()
+		}
+	}()
+}
+
+// backport cherry-picks a merged PR onto target and opens the backport
+// PR. Conflicts are explained on the original PR instead.
+func (s *Server) backport(ctx context.Context, config *Config, pr *github.PullRequest, target string) (*backportResult, error) {
+	if pr.GetMergedAt().IsZero() || pr.GetMergeCommitSHA() == "" {
+		return nil, fmt.Errorf("PR #%d is not merged", pr.GetNumber())
+	}
+	owner, name := config.prTarget()
+	sha := pr.GetMergeCommitSHA()
+	branch := fmt.Sprintf("backport-%d-to-%s", pr.GetNumber(), strings.ReplaceAll(target, "/", "-"))
+	info := ai.BackportInfo{
+		Repo:   owner + "/" + name,
+		Number: pr.GetNumber(),
+		Title:  pr.GetTitle(),
+		Body:   pr.GetBody(),
+		Target: target,
+	}
+	if prompt := s.promptTemplate(config); prompt != "" {
+		info.Instructions, _ = expandTemplate("prompt", prompt, s.templateVars(config, &Job{Branch: branch}))
+	}
+	info.Instructions = withLanguage(info.Instructions, s.userLanguage(config, pr.GetUser().GetLogin()))
+
+	s.logger.Branch("🌿 Creating %s backporting #%d to %s", branch, pr.GetNumber(), target)
+	createCtx, cancelCreate := context.WithTimeout(ctx, config.Timeouts.createPR())
+	defer cancelCreate()
+	err := s.github.CreateCherryPickBranch(createCtx, owner, name, target, sha, branch)
+	if errors.Is(err, ghclient.ErrConflict) {
+		s.reportBackportConflict(ctx, config, pr, info)
+		return nil, fmt.Errorf("PR #%d conflicts with %s, backport it by hand with git cherry-pick -x -m 1 %s: %w", pr.GetNumber(), target, sha, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	genCtx, cancelGen := context.WithTimeout(ctx, config.Timeouts.generate())
+	defer cancelGen()
+	content, err := s.generator.GenerateBackport(genCtx, info)
+	if err == nil {
+		err = s.checkContent(ctx, config, content.Title, content.Description)
+	}
+	if err != nil {
+		// The branch exists, so still open the PR
+		s.logger.Warning("Backport description not generated: %v", err)
+		content = &ai.PRContent{Title: fmt.Sprintf("[%s] %s", target, pr.GetTitle())}
+	}
+
+	body := strings.TrimSpace(sanitize(config, content.Description, "")) + fmt.Sprintf("\n\nBackport of #%d to `%s`.", pr.GetNumber(), target)
+	created, err := s.github.CreatePullRequest(createCtx, owner, name, &github.NewPullRequest{
+		Title: github.String(content.Title),
+		Head:  github.String(branch),
+		Base:  github.String(target),
+		Body:  github.String(strings.TrimSpace(body)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	comment := fmt.Sprintf("Backported to `%s` in #%d.", target, created.GetNumber())
+	if err := s.github.CreateComment(createCtx, owner, name, pr.GetNumber(), comment); err != nil {
+		s.logger.Warning("Failed to link #%d to its backport: %v", pr.GetNumber(), err)
+	}
+	s.events.publish(Event{Type: EventPRCreated, Owner: owner, Repo: name, Branch: branch, PRURL: created.GetHTMLURL(), Message: content.Title})
+	s.logger.Success("✅ Opened backport PR #%d to %s", created.GetNumber(), target)
+	return &backportResult{Number: created.GetNumber(), URL: created.GetHTMLURL(), Branch: branch, Target: target}, nil
+}
+
+// reportBackportConflict comments on the original PR which files conflict
+// with target and how to backport it by hand
+func (s *Server) reportBackportConflict(ctx context.Context, config *Config, pr *github.PullRequest, info ai.BackportInfo) {
+	owner, name := config.prTarget()
+	fetchCtx, cancel := context.WithTimeout(ctx, config.Timeouts.github())
+	defer cancel()
+	info.Conflicts = s.backportConflicts(fetchCtx, owner, name, pr, info.Target)
+
+	var explanation string
+	if len(info.Conflicts) > 0 {
+		genCtx, cancelGen := context.WithTimeout(ctx, config.Timeouts.generate())
+		defer cancelGen()
+		content, err := s.generator.GenerateBackport(genCtx, info)
+		if err == nil {
+			err = s.checkContent(ctx, config, content.Description)
+		}
+		if err != nil {
+			s.logger.Warning("Conflict summary not generated: %v", err)
+		} else {
+			explanation = strings.TrimSpace(sanitize(config, content.Description, "")) + "\n\n"
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "⚠️ Backport to `%s` failed: the changes don't apply cleanly.\n\n", info.Target)
+	b.WriteString(explanation)
+	fmt.Fprintf(&b, "To backport by hand:\n\n```sh\ngit fetch origin\ngit switch -c backport-%d origin/%s\ngit cherry-pick -x -m 1 %s\n```\n",
+		pr.GetNumber(), info.Target, pr.GetMergeCommitSHA())
+	if err := s.github.CreateComment(ctx, owner, name, pr.GetNumber(), b.String()); err != nil {
+		s.logger.Warning("Failed to report backport conflict on #%d: %v", pr.GetNumber(), err)
+	}
+	s.events.publish(Event{Type: EventError, Owner: owner, Repo: name, Branch: info.Target, PRURL: pr.GetHTMLURL(), Message: "backport conflict"})
+}
+
+// backportConflicts returns the files both the PR and target changed
+// since the PR's base, the likely source of conflicts
+func (s *Server) backportConflicts(ctx context.Context, owner, name string, pr *github.PullRequest, target string) []ai.ConflictFile {
+	base := pr.GetBase().GetSHA()
+	ours, err := s.github.CompareBranches(ctx, owner, name, base, pr.GetHead().GetSHA())
+	if err != nil {
+		s.logger.Warning("Failed to compare #%d: %v", pr.GetNumber(), err)
+		return nil
+	}
+	theirs, err := s.github.CompareBranches(ctx, owner, name, base, target)
+	if err != nil {
+		s.logger.Warning("Failed to compare %s: %v", target, err)
+		return nil
+	}
+
+	changed := make(map[string]string, len(theirs.Files))
+	for _, f := range theirs.Files {
+		changed[f.GetFilename()] = f.GetPatch()
+	}
+	var conflicts []ai.ConflictFile
+	for _, f := range ours.Files {
+		if patch, ok := changed[f.GetFilename()]; ok {
+			conflicts = append(conflicts, ai.ConflictFile{Path: f.GetFilename(), Patch: f.GetPatch(), TargetPatch: patch})
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PR body section names
+const (
+	SectionProtected    = "protected"
+	SectionBreaking     = "breaking"
+	SectionSummary      = "summary"
+	SectionChanges      = "changes"
+	SectionTestPlan     = "test_plan"
+	SectionRisk         = "risk"
+	SectionInfra        = "infra"
+	SectionMigrations   = "migrations"
+	SectionAPI          = "api"
+	SectionLargeFiles   = "large_files"
+	SectionDiffstat     = "diffstat"
+	SectionDependencies = "dependencies"
+	SectionLicense      = "license"
+	SectionLint         = "lint"
+	SectionCommits      = "commits"
+	SectionImpact       = "impact"
+	SectionTargets      = "targets"
+	SectionBuild        = "build"
+	SectionFooter       = "footer"
+	SectionChecklist    = "checklist"
+)
+
+// defaultSectionOrder is the body layout when a repository sets no order.
+// The checklist isn't listed: it always comes last so pipeline stages and
+// hooks can't drop it.
+var defaultSectionOrder = []string{
+	SectionConfidence, SectionProtected, SectionBreaking, SectionConflicts,
+	SectionSummary, SectionChanges, SectionTopics, SectionTestPlan, SectionRisk,
+	SectionPerformance, SectionInfra, SectionMigrations, SectionAPI, SectionLargeFiles,
+	SectionDiffstat, SectionSize, SectionReviewOrder, SectionDependencies, SectionLicense,
+	SectionLint, SectionCommits, SectionImpact, SectionTargets, SectionPeople,
+	SectionDocs, SectionBuild, SectionFooter,
+}
+
+// generatedHeadings maps the headings the model writes to section names
+var generatedHeadings = map[string]string{
+	"summary":   SectionSummary,
+	"changes":   SectionChanges,
+	"test plan": SectionTestPlan,
+	"testing":   SectionTestPlan,
+	"risk":      SectionRisk,
+	"risks":     SectionRisk,
+}
+
+// BodyConfig controls which sections PR bodies contain and their order
+type BodyConfig struct {
+	Order    []string `json:"order,omitempty"`    // sections placed first, in this order
+	Disabled []string `json:"disabled,omitempty"` // sections left out
+}
+
+// validate checks every section name is known
+func (c *BodyConfig) validate() error {
+	for _, name := range append(append([]string{}, c.Order...), c.Disabled...) {
+		if name != SectionChecklist && !contains(defaultSectionOrder, name) {
+			return fmt.Errorf("unknown body section %q", name)
+		}
+	}
+	if contains(c.Order, SectionChecklist) {
+		return fmt.Errorf("the checklist section is always last and can't be ordered")
+	}
+	return nil
+}
+
+// enabled reports whether a section should appear
+func (c *BodyConfig) enabled(name string) bool {
+	return c == nil || !contains(c.Disabled, name)
+}
+
+// render joins the enabled sections, configured order first, then the
+// rest in the default order
+func (c *BodyConfig) render(sections map[string]string) string {
+	order := defaultSectionOrder
+	if c != nil && len(c.Order) > 0 {
+		order = append([]string{}, c.Order...)
+		for _, name := range defaultSectionOrder {
+			if !contains(order, name) {
+				order = append(order, name)
+			}
+		}
+	}
+
+	var parts []string
+	for _, name := range order {
+		if text := strings.TrimSpace(sections[name]); text != "" && c.enabled(name) {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// splitGenerated splits a generated description into named sections by
+// its level-two headings. Text before the first heading and descriptions
+// without known headings are the summary; unknown headings stay with the
+// section before them.
+func splitGenerated(description string) map[string]string {
+	sections := make(map[string]string)
+	current := SectionSummary
+	var b strings.Builder
+	flush := func() {
+		if text := strings.TrimSpace(b.String()); text != "" {
+			if prev := sections[current]; prev != "" {
+				text = prev + "\n\n" + text
+			}
+			sections[current] = text
+		}
+		b.Reset()
+	}
+
+	for _, line := range strings.Split(description, "\n") {
+		if heading, ok := strings.CutPrefix(line, "## "); ok {
+			key := strings.ToLower(strings.Trim(strings.TrimSpace(heading), "*:"))
+			if name, ok := generatedHeadings[key]; ok {
+				flush()
+				current = name
+			}
+		}
+		b.WriteString(line + "\n")
+	}
+	flush()
+	return sections
+}
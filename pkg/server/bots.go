@@ -0,0 +1,75 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/ai"
+)
+
+// Bot branch modes
+const (
+	BotSkip     = "skip"     // no PR, the default
+	BotTemplate = "template" // PR from commit messages, no AI generation
+	BotGenerate = "generate" // treat like any other branch
+)
+
+// defaultBotBranches are the prefixes dependency update bots push to
+var defaultBotBranches = []string{"dependabot/", "renovate/"}
+
+// BotConfig controls branches pushed by dependency update bots, which
+// usually open their own PRs with generated descriptions
+type BotConfig struct {
+	Mode     string   `json:"mode,omitempty"`     // skip, template or generate
+	Branches []string `json:"branches,omitempty"` // branch prefixes, default dependabot/ and renovate/
+}
+
+// validate checks the mode is known
+func (c *BotConfig) validate() error {
+	switch c.Mode {
+	case "", BotSkip, BotTemplate, BotGenerate:
+		return nil
+	}
+	return fmt.Errorf("invalid bots mode %q", c.Mode)
+}
+
+// botMode returns how branch is handled, or "" when it isn't a bot branch
+func (c *Config) botMode(branch string) string {
+	prefixes := defaultBotBranches
+	mode := BotSkip
+	if c.Bots != nil {
+		if len(c.Bots.Branches) > 0 {
+			prefixes = c.Bots.Branches
+		}
+		if c.Bots.Mode != "" {
+			mode = c.Bots.Mode
+		}
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(branch, p) {
+			return mode
+		}
+	}
+	return ""
+}
+
+// templatePR builds PR content from the branch commits without calling
+// the model
+func templatePR(branch, commitMsg string, commits []*github.RepositoryCommit) *ai.PRContent {
+	subject, body, _ := strings.Cut(strings.TrimSpace(commitMsg), "\n")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Automated update from `%s`.", branch)
+	if body = strings.TrimSpace(body); body != "" {
+		b.WriteString("\n\n" + body)
+	}
+	if len(commits) > 1 {
+		b.WriteString("\n\n### Commits\n\n")
+		for _, c := range commits {
+			msg := strings.SplitN(c.GetCommit().GetMessage(), "\n", 2)[0]
+			fmt.Fprintf(&b, "- `%s` %s\n", shortSHA(c.GetSHA()), msg)
+		}
+	}
+	return &ai.PRContent{Title: subject, Description: strings.TrimRight(b.String(), "\n")}
+}
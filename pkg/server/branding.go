@@ -0,0 +1,114 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// variableName is the form of template variable names
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// builtinVariables are set for every job and can't be redefined
+var builtinVariables = []string{"repo", "branch", "author"}
+
+// validateVariables checks variable names are usable in templates
+func validateVariables(vars map[string]string) error {
+	for name := range vars {
+		if !variableName.MatchString(name) {
+			return fmt.Errorf("invalid variable name %q", name)
+		}
+		if contains(builtinVariables, name) {
+			return fmt.Errorf("variable %q is built in", name)
+		}
+	}
+	return nil
+}
+
+// parseTemplate parses a prompt, footer or checklist template. Unknown
+// variables are errors rather than "<no value>".
+func parseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// validateTemplates checks the prompt, footer and checklist templates
+// parse
+func validateTemplates(prompt, footer string, checklist []string) error {
+	for name, text := range map[string]string{"prompt": prompt, "footer": footer} {
+		if _, err := parseTemplate(name, text); err != nil {
+			return err
+		}
+	}
+	for _, item := range checklist {
+		if _, err := parseTemplate("checklist", item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandTemplate renders text with vars
+func expandTemplate(name, text string, vars map[string]string) (string, error) {
+	tmpl, err := parseTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", name, err)
+	}
+	return b.String(), nil
+}
+
+// org returns the org config for owner, or nil
+func (s *Server) org(owner string) *OrgConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.orgs[owner]
+}
+
+// templateVars merges org variables, the repository's overrides and the
+// built-in job variables
+func (s *Server) templateVars(config *Config, job *Job) map[string]string {
+	vars := make(map[string]string)
+	if org := s.org(config.Owner); org != nil {
+		for k, v := range org.Variables {
+			vars[k] = v
+		}
+	}
+	for k, v := range config.Variables {
+		vars[k] = v
+	}
+	vars["repo"] = config.FullName()
+	vars["branch"] = job.Branch
+	vars["author"] = job.Author
+	return vars
+}
+
+// promptTemplate returns the org's prompt followed by the repository's
+func (s *Server) promptTemplate(config *Config) string {
+	var parts []string
+	if org := s.org(config.Owner); org != nil && org.Prompt != "" {
+		parts = append(parts, org.Prompt)
+	}
+	if config.Prompt != "" {
+		parts = append(parts, config.Prompt)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// footerTemplate returns the repository's footer, else the org's
+func (s *Server) footerTemplate(config *Config) string {
+	if config.Footer != "" {
+		return config.Footer
+	}
+	if org := s.org(config.Owner); org != nil {
+		return org.Footer
+	}
+	return ""
+}
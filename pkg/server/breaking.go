@@ -0,0 +1,49 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/analyze"
+)
+
+// labelBreakingChange marks PRs with likely breaking API changes
+const labelBreakingChange = "breaking-change"
+
+// fileDiffs converts compare API files for the analyzers
+func fileDiffs(files []*github.CommitFile) []analyze.FileDiff {
+	diffs := make([]analyze.FileDiff, 0, len(files))
+	for _, f := range files {
+		diffs = append(diffs, analyze.FileDiff{
+			Filename:  f.GetFilename(),
+			Status:    f.GetStatus(),
+			Patch:     f.GetPatch(),
+			Additions: f.GetAdditions(),
+			Deletions: f.GetDeletions(),
+		})
+	}
+	return diffs
+}
+
+// breakingSection renders a warning listing likely breaking changes
+func breakingSection(changes []analyze.BreakingChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("> [!WARNING]\n")
+	b.WriteString("> **Potential breaking changes detected**\n>\n")
+	for _, c := range changes {
+		switch {
+		case c.Symbol == "":
+			fmt.Fprintf(&b, "> - `%s`: %s\n", c.File, c.Detail)
+		case c.Kind == "signature":
+			fmt.Fprintf(&b, "> - `%s` signature changed in `%s`: `%s`\n", c.Symbol, c.File, c.Detail)
+		default:
+			fmt.Fprintf(&b, "> - `%s` removed from `%s`\n", c.Symbol, c.File)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Default deadlines for each stage of a job
+const (
+	defaultGitHubTimeout   = 30 * time.Second
+	defaultGenerateTimeout = 2 * time.Minute
+	defaultCreateTimeout   = time.Minute
+	defaultJobTimeout      = 15 * time.Minute
+)
+
+// TimeoutConfig bounds the stages of a job, as Go durations like "90s"
+type TimeoutConfig struct {
+	GitHub   string `json:"github,omitempty"`    // each GitHub fetch, default 30s
+	Generate string `json:"generate,omitempty"`  // each model call, default 2m
+	CreatePR string `json:"create_pr,omitempty"` // opening the PR and triage, default 1m
+	Job      string `json:"job,omitempty"`       // the whole job, default 15m
+}
+
+// validate checks every timeout parses and is positive
+func (c *TimeoutConfig) validate() error {
+	for name, value := range map[string]string{
+		"github": c.GitHub, "generate": c.Generate, "create_pr": c.CreatePR, "job": c.Job,
+	} {
+		if value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+			return fmt.Errorf("invalid %s timeout %q", name, value)
+		}
+	}
+	return nil
+}
+
+// timeoutOr parses value, falling back to def when unset or invalid
+func timeoutOr(value string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d
+	}
+	return def
+}
+
+// github returns the deadline for a GitHub fetch
+func (c *TimeoutConfig) github() time.Duration {
+	if c == nil {
+		return defaultGitHubTimeout
+	}
+	return timeoutOr(c.GitHub, defaultGitHubTimeout)
+}
+
+// generate returns the deadline for a model call
+func (c *TimeoutConfig) generate() time.Duration {
+	if c == nil {
+		return defaultGenerateTimeout
+	}
+	return timeoutOr(c.Generate, defaultGenerateTimeout)
+}
+
+// createPR returns the deadline for opening the PR
+func (c *TimeoutConfig) createPR() time.Duration {
+	if c == nil {
+		return defaultCreateTimeout
+	}
+	return timeoutOr(c.CreatePR, defaultCreateTimeout)
+}
+
+// job returns the deadline for a whole job
+func (c *TimeoutConfig) job() time.Duration {
+	if c == nil {
+		return defaultJobTimeout
+	}
+	return timeoutOr(c.Job, defaultJobTimeout)
+}
+
+// jobCancels holds the cancel functions of running jobs by ID
+type jobCancels struct {
+	mu    sync.Mutex
+	funcs map[string]context.CancelFunc
+}
+
+// startJob derives a context for a running job, bounded by timeout and
+// cancelled by cancelJob. The returned function must be called when the
+// job returns.
+func (s *Server) startJob(ctx context.Context, job *Job, timeout time.Duration) (context.Context, func()) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	s.cancels.mu.Lock()
+	s.cancels.funcs[job.ID] = cancel
+	s.cancels.mu.Unlock()
+	return ctx, func() {
+		s.cancels.mu.Lock()
+		delete(s.cancels.funcs, job.ID)
+		s.cancels.mu.Unlock()
+		cancel()
+	}
+}
+
+// cancelJob marks a job cancelled and stops it if running. Queued jobs
+// are skipped when their turn comes, and waiting and held jobs stop
+// waiting.
+func (s *Server) cancelJob(id string) (Job, error) {
+	job, ok := s.jobs.get(id)
+	if !ok {
+		return Job{}, fmt.Errorf("job %s not found", id)
+	}
+	switch job.Status {
+	case JobSucceeded, JobFailed, JobCancelled:
+		return job, fmt.Errorf("job %s already %s", id, job.Status)
+	}
+
+	s.jobs.update(id, func(j *Job) {
+		j.Status = JobCancelled
+		j.Error = "cancelled"
+	})
+
+	s.pending.mu.Lock()
+	for key, p := range s.pending.jobs {
+		if p.job.ID == id {
+			delete(s.pending.jobs, key)
+		}
+	}
+	s.pending.mu.Unlock()
+
+	s.caps.mu.Lock()
+	delete(s.caps.held, id)
+	s.caps.mu.Unlock()
+
+	s.cancels.mu.Lock()
+	if cancel, ok := s.cancels.funcs[id]; ok {
+		cancel()
+	}
+	s.cancels.mu.Unlock()
+
+	// Another replica may pop or be running it
+	if s.backend != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), backendOpTimeout)
+		if err := s.backend.Cancel(ctx, id); err != nil {
+			s.logger.Warning("Failed to share cancellation of %s: %v", id, err)
+		}
+		cancel()
+	}
+
+	job, _ = s.jobs.get(id)
+	s.events.publish(jobEvent(EventJobCancelled, &job, ""))
+	s.logger.Warning("Cancelled job %s for %s", id, job.Branch)
+	return job, nil
+}
+
+// cancelled reports whether a job was cancelled
+func (s *Server) cancelled(id string) bool {
+	job, ok := s.jobs.get(id)
+	return ok && job.Status == JobCancelled
+}
+
+// canCancel allows the admin, or the job's verified author when an admin
+// token is set
+func (s *Server) canCancel(w http.ResponseWriter, r *http.Request, job Job) bool {
+	if key := r.Header.Get(userKeyHeader); key != "" {
+		if account := s.users.byKey(key); account != nil && job.authorVerified && account.Login == job.Author {
+			return true
+		}
+	}
+	return requireAdmin(w, r)
+}
@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// Webhook events for CI results
+const (
+	WebhookCheckRun = "check_run"
+	WebhookStatus   = "status"
+)
+
+// minCheckNameLength stops short check names like "ci" ticking every item
+// that happens to contain them
+const minCheckNameLength = 3
+
+// checklistItem matches a markdown task list item
+var checklistItem = regexp.MustCompile(`^(\s*[-*] \[)([ xX])(\] )(.+)$`)
+
+// ChecklistSyncConfig ticks PR checklist items when the CI checks they
+// name pass, and unticks them when those checks fail
+type ChecklistSyncConfig struct {
+	Enabled bool `json:"enabled"`
+	// Items maps checklist item text to the check run or status context
+	// that ticks it. Items without an entry are ticked by any check whose
+	// name they mention.
+	Items map[string]string `json:"items,omitempty"`
+}
+
+// validate checks every mapped item names a check
+func (c *ChecklistSyncConfig) validate() error {
+	for text, name := range c.Items {
+		if strings.TrimSpace(text) == "" || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("checklist_sync items need both item text and a check name")
+		}
+	}
+	return nil
+}
+
+// ticks reports whether check ticks an item
+func (c *ChecklistSyncConfig) ticks(item, check string) bool {
+	item = strings.TrimSpace(item)
+	for text, name := range c.Items {
+		if strings.EqualFold(strings.TrimSpace(text), item) {
+			return strings.EqualFold(name, check)
+		}
+	}
+	return len(check) >= minCheckNameLength && strings.Contains(strings.ToLower(item), strings.ToLower(check))
+}
+
+// syncChecklist sets the box of every item ticked by check to passed. It
+// returns the new body and the items that changed.
+func syncChecklist(body string, sync *ChecklistSyncConfig, check string, passed bool) (string, []string) {
+	mark := " "
+	if passed {
+		mark = "x"
+	}
+
+	var changed []string
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		m := checklistItem.FindStringSubmatch(strings.TrimSuffix(line, "\r"))
+		if m == nil || strings.EqualFold(m[2], mark) || !sync.ticks(m[4], check) {
+			continue
+		}
+		lines[i] = m[1] + mark + m[3] + m[4]
+		changed = append(changed, m[4])
+	}
+	return strings.Join(lines, "\n"), changed
+}
+
+// checkResult maps a check run conclusion or commit status state to
+// passed or failed. ok is false for results that say neither, like
+// skipped or pending.
+func checkResult(result string) (passed, ok bool) {
+	switch result {
+	case "success":
+		return true, true
+	case "failure", "error", "timed_out":
+		return false, true
+	}
+	return false, false
+}
+
+// handleCheckRunEvent syncs checklists with a completed check run
+func (s *Server) handleCheckRunEvent(ctx context.Context, e *github.CheckRunEvent) {
+	run := e.GetCheckRun()
+	if e.GetAction() != "completed" {
+		return
+	}
+	s.syncPRChecklists(ctx, WebhookCheckRun, e.GetRepo(), run.GetHeadSHA(), run.GetName(), run.GetConclusion())
+}
+
+// handleStatusEvent syncs checklists with a commit status
+func (s *Server) handleStatusEvent(ctx context.Context, e *github.StatusEvent) {
+	s.syncPRChecklists(ctx, WebhookStatus, e.GetRepo(), e.GetSHA(), e.GetContext(), e.GetState())
+}
+
+// syncPRChecklists updates the checklist of each open PR whose head is sha
+func (s *Server) syncPRChecklists(ctx context.Context, kind string, repo *github.Repository, sha, check, result string) {
+	config := s.webhookConfig(kind, repo)
+	if config == nil || config.ChecklistSync == nil || !config.ChecklistSync.Enabled || sha == "" || check == "" {
+		return
+	}
+	passed, ok := checkResult(result)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.Timeouts.github())
+	defer cancel()
+	owner, name := config.prTarget()
+	prs, err := s.headPRs(ctx, owner, name, sha)
+	if err != nil {
+		s.logger.Warning("Checklist sync skipped for %s: %v", check, err)
+		return
+	}
+
+	for _, pr := range prs {
+		if err := s.syncPRChecklist(ctx, config, owner, name, pr.GetNumber(), sha, check, passed); err != nil {
+			s.logger.Warning("Checklist sync failed for #%d: %v", pr.GetNumber(), err)
+		}
+	}
+}
+
+// headPRs returns the open PRs whose head is sha. Results for older
+// commits don't describe a PR any more.
+func (s *Server) headPRs(ctx context.Context, owner, name, sha string) ([]*github.PullRequest, error) {
+	prs, err := s.github.GetPRsForCommit(ctx, owner, name, sha)
+	if err != nil {
+		return nil, err
+	}
+	var open []*github.PullRequest
+	for _, pr := range prs {
+		if pr.GetState() == "open" && pr.GetHead().GetSHA() == sha {
+			open = append(open, pr)
+		}
+	}
+	return open, nil
+}
+
+// syncPRChecklist ticks or unticks the items check names in a PR body
+func (s *Server) syncPRChecklist(ctx context.Context, config *Config, owner, name string, number int, sha, check string, passed bool) error {
+	var changed []string
+	err := s.editPRBody(ctx, config, owner, name, number, sha, func(body string) string {
+		body, changed = syncChecklist(body, config.ChecklistSync, check, passed)
+		return body
+	})
+	if err != nil || len(changed) == 0 {
+		return err
+	}
+	verb := "Unticked"
+	if passed {
+		verb = "Ticked"
+	}
+	s.logger.Success("✅ %s %d checklist item(s) on #%d for %s", verb, len(changed), number, check)
+	return nil
+}
+
+// editPRBody re-reads a PR's body under a lock, so webhooks arriving
+// together don't overwrite each other's edits, and saves edit's result
+// when it changed
+func (s *Server) editPRBody(ctx context.Context, config *Config, owner, name string, number int, sha string, edit func(string) string) error {
+	release, err := s.waitLock(ctx, fmt.Sprintf("pr:%s/%s#%d", owner, name, number), config.Timeouts.github(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to lock PR: %w", err)
+	}
+	defer release()
+
+	// The caller's listing may predate another edit
+	prs, err := s.github.GetPRsForCommit(ctx, owner, name, sha)
+	if err != nil {
+		return err
+	}
+	for _, pr := range prs {
+		if pr.GetNumber() != number {
+			continue
+		}
+		if body := edit(pr.GetBody()); body != pr.GetBody() {
+			return s.github.UpdatePRBody(ctx, owner, name, number, body)
+		}
+	}
+	return nil
+}
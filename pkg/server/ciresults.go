@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// WebhookCheckSuite is sent when a CI suite starts or finishes
+const WebhookCheckSuite = "check_suite"
+
+// Markers around the CI results section, so later runs replace it
+const (
+	ciResultsStart = "<!-- ggquick:ci-results -->"
+	ciResultsEnd   = "<!-- /ggquick:ci-results -->"
+)
+
+// ciCommentTTL stops the same results being commented twice
+const ciCommentTTL = 24 * time.Hour
+
+// CIResultsConfig reports CI results on the PR once every workflow on its
+// head commit has finished
+type CIResultsConfig struct {
+	Enabled bool `json:"enabled"`
+	// Comment posts the results as a comment instead of updating the body
+	Comment bool `json:"comment,omitempty"`
+}
+
+// ciSection renders a pass/fail table of workflow runs, linking failures
+func ciSection(runs []*github.WorkflowRun) string {
+	sort.Slice(runs, func(i, j int) bool { return runs[i].GetName() < runs[j].GetName() })
+
+	var b strings.Builder
+	b.WriteString("## CI Results\n\n| Workflow | Result |\n|----------|--------|\n")
+	failed := 0
+	for _, run := range runs {
+		conclusion := run.GetConclusion()
+		passed, known := checkResult(conclusion)
+		switch {
+		case passed:
+			fmt.Fprintf(&b, "| %s | ✅ %s |\n", run.GetName(), conclusion)
+		case known:
+			failed++
+			fmt.Fprintf(&b, "| [%s](%s) | ❌ %s |\n", run.GetName(), run.GetHTMLURL(), conclusion)
+		default:
+			fmt.Fprintf(&b, "| %s | ⚪ %s |\n", run.GetName(), conclusion)
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(&b, "\n%d of %d workflow(s) failed.\n", failed, len(runs))
+	} else {
+		fmt.Fprintf(&b, "\nAll %d workflow(s) passed.\n", len(runs))
+	}
+	return b.String()
+}
+
+// withCIResults replaces the CI results section of a body, or appends it
+func withCIResults(body, section string) string {
+	return withBlock(body, ciResultsStart, ciResultsEnd, section)
+}
+
+// withBlock replaces the section between the markers of a body, or
+// appends it
+func withBlock(body, startMarker, endMarker, section string) string {
+	block := startMarker + "\n" + strings.TrimSpace(section) + "\n" + endMarker
+	start := strings.Index(body, startMarker)
+	end := strings.Index(body, endMarker)
+	if start >= 0 && end > start {
+		return body[:start] + block + body[end+len(endMarker):]
+	}
+	return strings.TrimRight(body, "\n") + "\n\n" + block
+}
+
+// handleCheckSuiteEvent reports CI results and coverage once the last
+// workflow on a commit finishes
+func (s *Server) handleCheckSuiteEvent(ctx context.Context, e *github.CheckSuiteEvent) {
+	config := s.webhookConfig(WebhookCheckSuite, e.GetRepo())
+	if config == nil || e.GetAction() != "completed" {
+		return
+	}
+	ciResults := config.CIResults != nil && config.CIResults.Enabled
+	coverage := config.Coverage != nil && config.Coverage.Enabled
+	if !ciResults && !coverage {
+		return
+	}
+	sha := e.GetCheckSuite().GetHeadSHA()
+	if sha == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.Timeouts.github())
+	defer cancel()
+	runs, err := s.github.GetWorkflowRuns(ctx, config.Owner, config.Name, sha)
+	if err != nil {
+		s.logger.Warning("CI results skipped for %s: %v", sha, err)
+		return
+	}
+	if len(runs) == 0 {
+		return
+	}
+	for _, run := range runs {
+		if run.GetStatus() != "completed" {
+			s.logger.Debug("CI still running on %s: %s", sha, run.GetName())
+			return
+		}
+	}
+
+	owner, name := config.prTarget()
+	prs, err := s.headPRs(ctx, owner, name, sha)
+	if err != nil {
+		s.logger.Warning("CI results skipped for %s: %v", sha, err)
+		return
+	}
+	section := ciSection(runs)
+	for _, pr := range prs {
+		if coverage {
+			if err := s.reportCoverage(ctx, config, owner, name, pr); err != nil {
+				s.logger.Warning("Failed to report coverage on #%d: %v", pr.GetNumber(), err)
+			} else {
+				s.logger.Success("✅ Reported coverage on #%d", pr.GetNumber())
+			}
+		}
+		if !ciResults {
+			continue
+		}
+		if err := s.postCIResults(ctx, config, owner, name, pr.GetNumber(), sha, section); err != nil {
+			s.logger.Warning("Failed to report CI results on #%d: %v", pr.GetNumber(), err)
+			continue
+		}
+		s.logger.Success("✅ Reported CI results on #%d", pr.GetNumber())
+	}
+}
+
+// postCIResults comments the results once per commit, or writes them
+// into the PR body
+func (s *Server) postCIResults(ctx context.Context, config *Config, owner, name string, number int, sha, section string) error {
+	if !config.CIResults.Comment {
+		return s.editPRBody(ctx, config, owner, name, number, sha, func(body string) string {
+			return withCIResults(body, section)
+		})
+	}
+
+	// Suites finishing together may each see every run completed
+	release, err := s.tryLock(ctx, fmt.Sprintf("ci:%s/%s#%d:%s", owner, name, number, sha), ciCommentTTL)
+	if err != nil {
+		return err
+	}
+	if release == nil {
+		return nil
+	}
+	time.AfterFunc(ciCommentTTL, release)
+	return s.github.CreateComment(ctx, owner, name, number, section)
+}
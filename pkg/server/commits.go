@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/analyze"
+)
+
+// commitReport checks the branch's commit messages and renders a section
+// with suggested rewrites, or "" when every message is fine
+func (s *Server) commitReport(ctx context.Context, job *Job, commits []*github.RepositoryCommit) string {
+	shas := make([]string, 0, len(commits))
+	for _, c := range commits {
+		shas = append(shas, c.GetSHA())
+	}
+	issues := analyze.CheckCommits(shas, commitMessages(commits))
+	if len(issues) == 0 {
+		return ""
+	}
+
+	subjects := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		subjects = append(subjects, issue.Subject)
+	}
+	suggestions, tokens, err := s.generator.SuggestCommitMessages(ctx, subjects)
+	if err != nil {
+		s.logger.Warning("Commit message suggestions skipped: %v", err)
+		suggestions = nil
+	}
+	s.jobs.update(job.ID, func(j *Job) { j.Tokens += tokens })
+
+	return commitSection(issues, suggestions)
+}
+
+// commitSection renders commit message problems with optional rewrites
+func commitSection(issues []analyze.CommitIssue, suggestions []string) string {
+	var b strings.Builder
+	b.WriteString("### Commit messages\n\n")
+	b.WriteString("Some commits don't follow [Conventional Commits](https://www.conventionalcommits.org). ")
+	b.WriteString("Consider these messages when squash-merging:\n\n")
+	for i, issue := range issues {
+		fmt.Fprintf(&b, "- `%s` %s\n", shortSHA(issue.SHA), issue.Subject)
+		for _, p := range issue.Problems {
+			fmt.Fprintf(&b, "  - %s\n", p)
+		}
+		if i < len(suggestions) {
+			fmt.Fprintf(&b, "  - Suggested: `%s`\n", suggestions[i])
+		}
+	}
+	return b.String()
+}
+
+// commitMessages returns the full message of each commit
+func commitMessages(commits []*github.RepositoryCommit) []string {
+	messages := make([]string, 0, len(commits))
+	for _, c := range commits {
+		messages = append(messages, c.GetCommit().GetMessage())
+	}
+	return messages
+}
+
+// trailerSection renders Co-authored-by and Signed-off-by trailers from
+// the branch commits as a trailer block
+func trailerSection(trailers []analyze.Trailer) string {
+	lines := make([]string, 0, len(trailers))
+	for _, t := range trailers {
+		lines = append(lines, t.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// shortSHA abbreviates a commit hash for display
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/ai"
+)
+
+// SectionConfidence warns that a description needs a human rewrite
+const SectionConfidence = "confidence"
+
+// defaultConfidenceLabel marks PRs whose description needs a human
+const defaultConfidenceLabel = "needs-human-description"
+
+// ConfidenceConfig opens PRs whose generated description scores below a
+// threshold as drafts for a human to rewrite
+type ConfidenceConfig struct {
+	Threshold float64 `json:"threshold"`       // from 0 to 1, 0 never drafts
+	Label     string  `json:"label,omitempty"` // default needs-human-description
+}
+
+// validate checks the threshold is a score
+func (c *ConfidenceConfig) validate() error {
+	if c.Threshold < 0 || c.Threshold > 1 {
+		return fmt.Errorf("confidence threshold must be between 0 and 1")
+	}
+	return nil
+}
+
+// label returns the label for low-confidence PRs
+func (c *ConfidenceConfig) label() string {
+	if c.Label == "" {
+		return defaultConfidenceLabel
+	}
+	return c.Label
+}
+
+// confidenceScore averages the model's self-assessment with what the
+// diff says about the description: how much of the change had a visible
+// patch, and how many of the files and symbols it named were in the diff
+func confidenceScore(content *ai.PRContent, files []*github.CommitFile, references, unverified int) float64 {
+	coverage := 1.0
+	changed, visible := 0, 0
+	for _, f := range files {
+		lines := f.GetAdditions() + f.GetDeletions()
+		if lines == 0 {
+			// Binary files still need describing
+			lines = 1
+		}
+		changed += lines
+		if f.GetPatch() != "" {
+			visible += lines
+		}
+	}
+	if changed > 0 {
+		coverage = float64(visible) / float64(changed)
+	}
+	verified := 1.0
+	if references > 0 {
+		verified = float64(references-unverified) / float64(references)
+	}
+	return (content.Confidence + coverage*verified) / 2
+}
+
+// confidenceSection renders the warning atop a low-confidence PR
+func confidenceSection(score, threshold float64) string {
+	var b strings.Builder
+	b.WriteString("> [!WARNING]\n")
+	fmt.Fprintf(&b, "> **This description may be inaccurate** (confidence %.2f, below %.2f) and the PR was opened as a draft.\n", score, threshold)
+	b.WriteString("> Please check it against the diff and rewrite it before marking the PR ready.")
+	return b.String()
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/saint0x/ggquick/pkg/pipeline"
+)
+
+// authorizeConfig checks the caller may store config over existing, the
+// repository's current config or nil, answering the request when not
+func (s *Server) authorizeConfig(w http.ResponseWriter, r *http.Request, body []byte, config, existing *Config) bool {
+	// Once a repository has a push secret, only an admin or someone who
+	// holds the secret may reconfigure it, or anyone could swap in their own
+	// secret and sign forged pushes
+	if existing != nil && existing.PushSecret != "" && !isAdmin(r) && !s.verifyPush(w, r, existing, body) {
+		return false
+	}
+
+	// The first secret decides who can sign pushes, so setting it is as
+	// privileged as holding it
+	if config.PushSecret != "" && (existing == nil || existing.PushSecret == "") && !requireAdmin(w, r) {
+		s.logger.Error("❌ Push secret for %s rejected without the admin token", config.FullName())
+		return false
+	}
+
+	// Settings deciding what runs and who hears about it are the admins'
+	// to change, including dropping them by reapplying without them
+	if !bytes.Equal(adminSettingsOf(config), adminSettingsOf(existing)) && !requireAdmin(w, r) {
+		s.logger.Error("❌ Changes to admin settings of %s rejected", config.FullName())
+		return false
+	}
+
+	// A lint command runs on the server, so only an admin can set one, and
+	// nobody can on a server without an admin token
+	if cmd := lintCommand(config); cmd != "" && cmd != lintCommand(existing) && !isAdmin(r) {
+		s.logger.Error("❌ Lint command for %s rejected without the admin token", config.FullName())
+		http.Error(w, "Setting a lint command needs GGQUICK_ADMIN_TOKEN", http.StatusForbidden)
+		return false
+	}
+
+	// So do benchmarks, which run the branch's tests
+	if runsBenchmarks(config) && !runsBenchmarks(existing) && !isAdmin(r) {
+		s.logger.Error("❌ Benchmarks for %s rejected without the admin token", config.FullName())
+		http.Error(w, "Running benchmarks needs GGQUICK_ADMIN_TOKEN", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// lintCommand returns the lint command config runs, or ""
+func lintCommand(config *Config) string {
+	if config == nil || config.Lint == nil {
+		return ""
+	}
+	return config.Lint.Command
+}
+
+// runsBenchmarks reports whether config runs benchmarks on clones
+func runsBenchmarks(config *Config) bool {
+	return config != nil && config.Performance != nil && config.Performance.Bench
+}
+
+// adminSettings are the parts of a config only an admin may change
+type adminSettings struct {
+	Rules       []*Rule                  `json:"rules,omitempty"`
+	Lint        *LintConfig              `json:"lint,omitempty"`
+	Performance *PerformanceConfig       `json:"performance,omitempty"`
+	Wasm        []string                 `json:"wasm,omitempty"`
+	Extensions  []*pipeline.WebhookStage `json:"extensions,omitempty"`
+	Notify      *NotifyConfig            `json:"notify,omitempty"`
+	Stages      []string                 `json:"stages,omitempty"`
+	AuthorCap   *AuthorCapConfig         `json:"author_cap,omitempty"`
+	StaleSweep  *SweepConfig             `json:"stale_sweep,omitempty"`
+}
+
+// adminSettingsOf encodes config's admin settings for comparison, the
+// same for a nil config as for one without any
+func adminSettingsOf(config *Config) []byte {
+	var settings adminSettings
+	if config != nil {
+		settings = adminSettings{
+			Rules:       config.Rules,
+			Lint:        config.Lint,
+			Performance: config.Performance,
+			Wasm:        config.Wasm,
+			Extensions:  config.Extensions,
+			Notify:      config.Notify,
+			Stages:      config.Stages,
+			AuthorCap:   config.AuthorCap,
+			StaleSweep:  config.StaleSweep,
+		}
+	}
+	data, _ := json.Marshal(settings)
+	return data
+}
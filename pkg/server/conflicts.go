@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// SectionConflicts warns about open PRs changing the same files
+const SectionConflicts = "conflicts"
+
+// defaultConflictPRs is how many open PRs are compared by default
+const defaultConflictPRs = 20
+
+// maxListedOverlap caps the shared files listed per PR
+const maxListedOverlap = 5
+
+// ConflictCheckConfig compares a branch's files with those of other open
+// PRs into the same base and warns when they overlap
+type ConflictCheckConfig struct {
+	Enabled bool `json:"enabled"`
+	// MaxPRs is how many recently updated open PRs are compared, 20 by
+	// default
+	MaxPRs int `json:"max_prs,omitempty"`
+}
+
+// overlap is an open PR changing some of the same files
+type overlap struct {
+	Number int
+	Title  string
+	URL    string
+	Files  []string
+}
+
+// predictConflicts returns the open PRs into base, other than the
+// branch's own, that change files the branch changes, most shared files
+// first
+func (s *Server) predictConflicts(ctx context.Context, config *Config, branch, base string, files []*github.CommitFile) []overlap {
+	changed := make(map[string]bool, len(files))
+	for _, f := range files {
+		changed[f.GetFilename()] = true
+		if prev := f.GetPreviousFilename(); prev != "" {
+			changed[prev] = true
+		}
+	}
+	limit := config.ConflictCheck.MaxPRs
+	if limit <= 0 {
+		limit = defaultConflictPRs
+	}
+
+	owner, name := config.prTarget()
+	fetchCtx, cancel := context.WithTimeout(ctx, config.Timeouts.github())
+	prs, err := s.github.GetOpenPRs(fetchCtx, owner, name, limit)
+	cancel()
+	if err != nil {
+		s.logger.Warning("Conflict check skipped: %v", err)
+		return nil
+	}
+
+	var found []overlap
+	for _, pr := range prs {
+		if pr.GetBase().GetRef() != base || pr.GetHead().GetRef() == branch {
+			continue
+		}
+		fetchCtx, cancel := context.WithTimeout(ctx, config.Timeouts.github())
+		theirs, err := s.github.GetPRFiles(fetchCtx, owner, name, pr.GetNumber())
+		cancel()
+		if err != nil {
+			s.logger.Warning("Conflict check skipped #%d: %v", pr.GetNumber(), err)
+			continue
+		}
+		var shared []string
+		for _, f := range theirs {
+			if changed[f.GetFilename()] || (f.GetPreviousFilename() != "" && changed[f.GetPreviousFilename()]) {
+				shared = append(shared, f.GetFilename())
+			}
+		}
+		if len(shared) > 0 {
+			sort.Strings(shared)
+			found = append(found, overlap{Number: pr.GetNumber(), Title: pr.GetTitle(), URL: pr.GetHTMLURL(), Files: shared})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return len(found[i].Files) > len(found[j].Files) })
+	return found
+}
+
+// conflictSection renders the open PRs that may conflict with this one
+func conflictSection(overlaps []overlap) string {
+	var b strings.Builder
+	b.WriteString("> [!WARNING]\n")
+	b.WriteString("> **Possible merge conflicts** with open PRs changing the same files:\n>\n")
+	for _, o := range overlaps {
+		listed := o.Files
+		if len(listed) > maxListedOverlap {
+			listed = listed[:maxListedOverlap]
+		}
+		fmt.Fprintf(&b, "> - #%d %s: `%s`", o.Number, o.Title, strings.Join(listed, "`, `"))
+		if more := len(o.Files) - len(listed); more > 0 {
+			fmt.Fprintf(&b, " and %d more", more)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// conflictText describes the overlapping PRs for a notification
+func conflictText(branch string, overlaps []overlap) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "The pull request for branch %s changes files that these open pull requests also change:\n", branch)
+	for _, o := range overlaps {
+		fmt.Fprintf(&b, "\n- #%d %s (%d shared file(s)): %s", o.Number, o.Title, len(o.Files), o.URL)
+	}
+	return b.String()
+}
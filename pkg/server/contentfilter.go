@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Content filter actions
+const (
+	FilterRegenerate = "regenerate"
+	FilterReject     = "reject"
+)
+
+// errContentFlagged is returned for generated text the content filter
+// rejects
+var errContentFlagged = errors.New("generated text failed the content filter")
+
+// ContentFilterConfig checks generated titles, bodies and comments before
+// they are posted
+type ContentFilterConfig struct {
+	// Blocklist holds words and phrases matched whole and ignoring case
+	Blocklist []string `json:"blocklist,omitempty"`
+	// Moderation also sends text to the OpenAI moderation API
+	Moderation bool `json:"moderation,omitempty"`
+	// Action for flagged PR descriptions: regenerate once (default), then
+	// reject, or reject straight away
+	Action string `json:"action,omitempty"`
+}
+
+// validate checks the action and blocklist entries
+func (c *ContentFilterConfig) validate() error {
+	if c.Action != "" && c.Action != FilterRegenerate && c.Action != FilterReject {
+		return fmt.Errorf("invalid content_filter action %q", c.Action)
+	}
+	for _, word := range c.Blocklist {
+		if strings.TrimSpace(word) == "" {
+			return fmt.Errorf("content_filter blocklist entries can't be empty")
+		}
+	}
+	return nil
+}
+
+// regenerate reports whether a flagged description gets a second try
+func (c *ContentFilterConfig) regenerate() bool {
+	return c.Action != FilterReject
+}
+
+// blocked returns the blocklist entries text contains
+func (c *ContentFilterConfig) blocked(text string) []string {
+	var hits []string
+	for _, word := range c.Blocklist {
+		word = strings.TrimSpace(word)
+		pattern := `(?i)(^|\W)` + regexp.QuoteMeta(word) + `($|\W)`
+		if regexp.MustCompile(pattern).MatchString(text) {
+			hits = append(hits, word)
+		}
+	}
+	return hits
+}
+
+// checkContent runs generated text through the repository's content
+// filter. Flagged text returns an error wrapping errContentFlagged; a
+// moderation API failure is logged and lets the text through.
+func (s *Server) checkContent(ctx context.Context, config *Config, texts ...string) error {
+	filter := config.ContentFilter
+	if filter == nil {
+		return nil
+	}
+	text := strings.Join(texts, "\n\n")
+
+	if hits := filter.blocked(text); len(hits) > 0 {
+		return fmt.Errorf("%w: blocked terms %s", errContentFlagged, strings.Join(hits, ", "))
+	}
+	if !filter.Moderation {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, config.Timeouts.generate())
+	defer cancel()
+	categories, err := s.generator.Moderate(ctx, text)
+	if err != nil {
+		s.logger.Warning("Moderation skipped: %v", err)
+		return nil
+	}
+	if len(categories) > 0 {
+		return fmt.Errorf("%w: moderation flagged %s", errContentFlagged, strings.Join(categories, ", "))
+	}
+	return nil
+}
+
+// contentPolicy is the extra instruction for a regenerated description
+func contentPolicy(filter *ContentFilterConfig, reason error) string {
+	line := "Your previous description was rejected by the content filter (" + reason.Error() + "). Keep the language strictly professional"
+	if len(filter.Blocklist) > 0 {
+		line += " and don't use these words: " + strings.Join(filter.Blocklist, ", ")
+	}
+	return line + "."
+}
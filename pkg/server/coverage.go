@@ -0,0 +1,187 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/httpclient"
+)
+
+// Markers around the coverage section, so later runs replace it
+const (
+	coverageStart = "<!-- ggquick:coverage -->"
+	coverageEnd   = "<!-- /ggquick:coverage -->"
+)
+
+// defaultCoverageLabel marks PRs losing more coverage than allowed
+const defaultCoverageLabel = "coverage-drop"
+
+// codecovAPI serves commit totals when no report URL is set
+const codecovAPI = "https://api.codecov.io/api/v2/github"
+
+// maxCoverageReport bounds the report read
+const maxCoverageReport = 1 << 20
+
+// coverageClient fetches coverage reports
+var coverageClient = httpclient.New(10 * time.Second)
+
+// CoverageConfig reports how a PR changes test coverage once CI on its
+// head commit finishes, comparing it with the base commit. Reports come
+// from URL, or from Codecov when URL is empty.
+type CoverageConfig struct {
+	Enabled bool `json:"enabled"`
+	// URL is a template for one commit's report, with {{.owner}},
+	// {{.repo}} and {{.sha}}. The report is a percentage, or JSON with a
+	// coverage or totals.coverage number.
+	URL string `json:"url,omitempty"`
+	// MaxDrop, when set, labels PRs losing more percentage points
+	MaxDrop float64 `json:"max_drop,omitempty"`
+	// Label defaults to coverage-drop
+	Label string `json:"label,omitempty"`
+}
+
+// validate checks the URL template parses and the threshold isn't negative
+func (c *CoverageConfig) validate() error {
+	if c.MaxDrop < 0 {
+		return fmt.Errorf("coverage max_drop can't be negative")
+	}
+	if c.URL != "" {
+		if _, err := parseTemplate("coverage URL", c.URL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// label returns the label for PRs dropping too much coverage
+func (c *CoverageConfig) label() string {
+	if c.Label != "" {
+		return c.Label
+	}
+	return defaultCoverageLabel
+}
+
+// reportURL returns where a commit's coverage is fetched from
+func (c *CoverageConfig) reportURL(owner, name, sha string) (string, error) {
+	if c.URL == "" {
+		return fmt.Sprintf("%s/%s/repos/%s/commits/%s", codecovAPI, owner, name, sha), nil
+	}
+	return expandTemplate("coverage URL", c.URL, map[string]string{"owner": owner, "repo": name, "sha": sha})
+}
+
+// fetchCoverage returns a commit's coverage percentage
+func (c *CoverageConfig) fetchCoverage(ctx context.Context, owner, name, sha string) (float64, error) {
+	u, err := c.reportURL(owner, name, sha)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return 0, err
+	}
+	if token := os.Getenv("GGQUICK_CODECOV_TOKEN"); token != "" && c.URL == "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := coverageClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch coverage: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to fetch coverage of %s: %s", shortSHA(sha), resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxCoverageReport))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read coverage: %w", err)
+	}
+	return parseCoverage(data)
+}
+
+// parseCoverage reads a percentage, optionally followed by %, or a JSON
+// report
+func parseCoverage(data []byte) (float64, error) {
+	text := strings.TrimSpace(string(data))
+	if strings.HasPrefix(text, "{") {
+		var report struct {
+			Coverage *float64 `json:"coverage"`
+			Totals   struct {
+				Coverage *float64 `json:"coverage"`
+			} `json:"totals"`
+		}
+		if err := json.Unmarshal(data, &report); err != nil {
+			return 0, fmt.Errorf("failed to parse coverage: %w", err)
+		}
+		switch {
+		case report.Coverage != nil:
+			return *report.Coverage, nil
+		case report.Totals.Coverage != nil:
+			return *report.Totals.Coverage, nil
+		}
+		return 0, fmt.Errorf("coverage report has no coverage field")
+	}
+	pct, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(text, "%")), 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse coverage %q", text)
+	}
+	return pct, nil
+}
+
+// reportCoverage writes the coverage change into a PR's body and labels
+// it when coverage drops too far. A PR whose base has no report gets its
+// head coverage alone.
+func (s *Server) reportCoverage(ctx context.Context, config *Config, owner, name string, pr *github.PullRequest) error {
+	c := config.Coverage
+	head, err := c.fetchCoverage(ctx, owner, name, pr.GetHead().GetSHA())
+	if err != nil {
+		return err
+	}
+	base, err := c.fetchCoverage(ctx, owner, name, pr.GetBase().GetSHA())
+	hasBase := err == nil
+	if err != nil {
+		s.logger.Debug("No base coverage for #%d: %v", pr.GetNumber(), err)
+	}
+	drop := hasBase && c.MaxDrop > 0 && base-head > c.MaxDrop
+
+	section := coverageSection(head, base, hasBase, pr.GetBase().GetRef(), c.MaxDrop, drop)
+	err = s.editPRBody(ctx, config, owner, name, pr.GetNumber(), pr.GetHead().GetSHA(), func(body string) string {
+		return withBlock(body, coverageStart, coverageEnd, section)
+	})
+	if err != nil {
+		return err
+	}
+	if drop {
+		return s.github.AddLabels(ctx, owner, name, pr.GetNumber(), []string{c.label()})
+	}
+	return nil
+}
+
+// coverageSection renders the head coverage and its change from base
+func coverageSection(head, base float64, hasBase bool, baseRef string, maxDrop float64, drop bool) string {
+	var b strings.Builder
+	b.WriteString("## Test Coverage\n\n")
+	if !hasBase {
+		fmt.Fprintf(&b, "Coverage is %.2f%%. No report was found for `%s` to compare with.\n", head, baseRef)
+		return b.String()
+	}
+	delta := head - base
+	icon := "➖"
+	switch {
+	case delta > 0.005:
+		icon = "📈"
+	case delta < -0.005:
+		icon = "📉"
+	}
+	fmt.Fprintf(&b, "%s Coverage is %.2f%% (%+.2f points from %.2f%% on `%s`).\n", icon, head, delta, base, baseRef)
+	if drop {
+		fmt.Fprintf(&b, "\n> [!WARNING]\n> Coverage drops by more than %.2f points.\n", maxDrop)
+	}
+	return b.String()
+}
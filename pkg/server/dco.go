@@ -0,0 +1,34 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/saint0x/ggquick/pkg/analyze"
+)
+
+// defaultDCOLabel marks PRs with commits missing a sign-off
+const defaultDCOLabel = "dco-missing"
+
+// DCOConfig requires a Signed-off-by trailer on every branch commit
+type DCOConfig struct {
+	Label string `json:"label,omitempty"` // default dco-missing
+}
+
+// label returns the label added on violations
+func (c *DCOConfig) label() string {
+	if c.Label != "" {
+		return c.Label
+	}
+	return defaultDCOLabel
+}
+
+// dcoItem renders the checklist item asking for unsigned commits to be
+// signed off against base
+func dcoItem(unsigned []analyze.CommitIssue, base string) string {
+	shas := make([]string, 0, len(unsigned))
+	for _, c := range unsigned {
+		shas = append(shas, "`"+shortSHA(c.SHA)+"`")
+	}
+	return fmt.Sprintf("Sign off every commit (`git rebase --signoff %s`), missing on %s", base, strings.Join(shas, ", "))
+}
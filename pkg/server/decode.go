@@ -0,0 +1,55 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Request body limits
+const (
+	maxBodySize    = 1 << 20  // JSON API requests
+	maxImportSize  = 10 << 20 // configuration imports
+	maxWebhookSize = 25 << 20 // GitHub caps webhook payloads at 25 MB
+)
+
+// validator is implemented by request bodies with constraints beyond
+// their JSON shape
+type validator interface {
+	validate() error
+}
+
+// decodeJSON strictly decodes a request body of at most limit bytes into
+// v: unknown fields and trailing data are rejected, and v is validated
+// when it implements validator. On failure the error response has already
+// been written.
+func decodeJSON(w http.ResponseWriter, r *http.Request, limit int64, v interface{}) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit))
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(v)
+	if err == nil {
+		if _, extra := dec.Token(); extra != io.EOF {
+			err = fmt.Errorf("unexpected data after JSON body")
+		}
+	}
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("Request body exceeds %d bytes", limit), http.StatusRequestEntityTooLarge)
+		} else {
+			http.Error(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		}
+		return err
+	}
+
+	if val, ok := v.(validator); ok {
+		if err := val.validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/analyze"
+	"github.com/saint0x/ggquick/pkg/osv"
+)
+
+// labelVulnerableDependency marks PRs adding dependencies with known
+// vulnerabilities
+const labelVulnerableDependency = "vulnerable-dependency"
+
+// maxAuditedDependencies caps OSV lookups per PR
+const maxAuditedDependencies = 50
+
+// auditedDependency is a dependency change with its known advisories
+type auditedDependency struct {
+	analyze.DependencyChange
+	Vulns []osv.Vuln
+	Err   error
+}
+
+// auditDependencies looks up each changed dependency in OSV. Lookups that
+// fail are kept so the section can say the package wasn't checked.
+func (s *Server) auditDependencies(ctx context.Context, changes []analyze.DependencyChange) []auditedDependency {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	s.logger.Loading("🛡️ Checking %d dependency change(s) against OSV...", len(changes))
+	if len(changes) > maxAuditedDependencies {
+		s.logger.Warning("Only checking the first %d of %d dependency changes", maxAuditedDependencies, len(changes))
+		changes = changes[:maxAuditedDependencies]
+	}
+
+	audited := make([]auditedDependency, 0, len(changes))
+	for _, c := range changes {
+		if s.sandboxed != nil {
+			audited = append(audited, auditedDependency{DependencyChange: c, Err: fmt.Errorf("not checked in sandbox mode")})
+			continue
+		}
+		vulns, err := s.osv.Query(ctx, osv.Package{Ecosystem: c.Ecosystem, Name: c.Name, Version: c.To})
+		if err != nil {
+			s.logger.Warning("OSV lookup failed for %s: %v", c.Name, err)
+		}
+		audited = append(audited, auditedDependency{DependencyChange: c, Vulns: vulns, Err: err})
+	}
+	return audited
+}
+
+// vulnerable reports whether any audited dependency has advisories
+func vulnerable(deps []auditedDependency) bool {
+	for _, d := range deps {
+		if len(d.Vulns) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// dependencySection renders the changed dependencies, calling out known
+// vulnerabilities first
+func dependencySection(deps []auditedDependency) string {
+	var b strings.Builder
+	b.WriteString("## Dependency Changes\n\n")
+
+	if vulnerable(deps) {
+		b.WriteString("> [!CAUTION]\n")
+		b.WriteString("> **Dependencies with known vulnerabilities**\n>\n")
+		for _, d := range deps {
+			for _, v := range d.Vulns {
+				fmt.Fprintf(&b, "> - `%s@%s`: [%s](https://osv.dev/vulnerability/%s)", d.Name, d.To, v.ID, v.ID)
+				if v.Severity != "" {
+					fmt.Fprintf(&b, " (%s)", strings.ToLower(v.Severity))
+				}
+				if v.Summary != "" {
+					fmt.Fprintf(&b, " %s", v.Summary)
+				}
+				if len(v.Fixed) > 0 {
+					fmt.Fprintf(&b, ", fixed in %s", strings.Join(v.Fixed, ", "))
+				}
+				b.WriteString("\n")
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("| Package | Change | Advisories |\n")
+	b.WriteString("|---------|--------|------------|\n")
+	for _, d := range deps {
+		change := "added " + d.To
+		if d.From != "" {
+			change = d.From + " → " + d.To
+		}
+		advisories := "none known"
+		switch {
+		case d.Err != nil:
+			advisories = "not checked"
+		case len(d.Vulns) > 0:
+			advisories = fmt.Sprintf("⚠️ %d", len(d.Vulns))
+		}
+		fmt.Fprintf(&b, "| `%s` (%s) | %s | %s |\n", d.Name, d.Ecosystem, change, advisories)
+	}
+	return b.String()
+}
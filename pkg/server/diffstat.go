@@ -0,0 +1,128 @@
+package server
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/analyze"
+)
+
+// maxDiffStatFiles caps the per-file rows in the diff-stat table
+const maxDiffStatFiles = 50
+
+// maxCollapsedNames caps the files named in a collapsed diff-stat row
+const maxCollapsedNames = 5
+
+// collapsedStat is one diff-stat row for all lockfiles or generated files
+type collapsedStat struct {
+	kind      string
+	names     []string
+	additions int
+	deletions int
+}
+
+// dirStat is the rollup of changes under one directory
+type dirStat struct {
+	dir       string
+	files     int
+	additions int
+	deletions int
+}
+
+// diffStatSection renders a markdown summary of changed files with
+// additions/deletions and per-directory rollups. Lockfiles and generated
+// files share one row per kind.
+func diffStatSection(files []*github.CommitFile) string {
+	if len(files) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	var totalAdd, totalDel int
+	dirs := make(map[string]*dirStat)
+	var collapsed []*collapsedStat
+	rows := 0
+
+	b.WriteString("### Diff stats\n\n")
+	b.WriteString("| File | Status | + | - |\n")
+	b.WriteString("|------|--------|---|---|\n")
+	for _, f := range files {
+		totalAdd += f.GetAdditions()
+		totalDel += f.GetDeletions()
+
+		dir := path.Dir(f.GetFilename())
+		d, ok := dirs[dir]
+		if !ok {
+			d = &dirStat{dir: dir}
+			dirs[dir] = d
+		}
+		d.files++
+		d.additions += f.GetAdditions()
+		d.deletions += f.GetDeletions()
+
+		if kind := analyze.Mechanical(f.GetFilename(), f.GetPatch()); kind != "" {
+			collapsed = addCollapsed(collapsed, kind, f)
+			continue
+		}
+		if rows < maxDiffStatFiles {
+			fmt.Fprintf(&b, "| `%s` | %s | %d | %d |\n",
+				f.GetFilename(), f.GetStatus(), f.GetAdditions(), f.GetDeletions())
+		}
+		rows++
+	}
+	if rows > maxDiffStatFiles {
+		fmt.Fprintf(&b, "| _…and %d more file(s)_ | | | |\n", rows-maxDiffStatFiles)
+	}
+	for _, c := range collapsed {
+		names := c.names
+		if len(names) > maxCollapsedNames {
+			names = names[:maxCollapsedNames]
+		}
+		more := ""
+		if len(c.names) > len(names) {
+			more = fmt.Sprintf(" and %d more", len(c.names)-len(names))
+		}
+		fmt.Fprintf(&b, "| _%d %s file(s): `%s`%s_ | collapsed | %d | %d |\n",
+			len(c.names), c.kind, strings.Join(names, "`, `"), more, c.additions, c.deletions)
+	}
+	fmt.Fprintf(&b, "| **Total (%d file(s))** | | **%d** | **%d** |\n", len(files), totalAdd, totalDel)
+
+	if len(dirs) > 1 {
+		rollup := make([]*dirStat, 0, len(dirs))
+		for _, d := range dirs {
+			rollup = append(rollup, d)
+		}
+		sort.Slice(rollup, func(i, j int) bool {
+			ci := rollup[i].additions + rollup[i].deletions
+			cj := rollup[j].additions + rollup[j].deletions
+			if ci != cj {
+				return ci > cj
+			}
+			return rollup[i].dir < rollup[j].dir
+		})
+
+		b.WriteString("\n| Directory | Files | + | - |\n")
+		b.WriteString("|-----------|-------|---|---|\n")
+		for _, d := range rollup {
+			fmt.Fprintf(&b, "| `%s/` | %d | %d | %d |\n", d.dir, d.files, d.additions, d.deletions)
+		}
+	}
+
+	return b.String()
+}
+
+// addCollapsed adds a lockfile or generated file to its kind's row
+func addCollapsed(collapsed []*collapsedStat, kind string, f *github.CommitFile) []*collapsedStat {
+	for _, c := range collapsed {
+		if c.kind == kind {
+			c.names = append(c.names, f.GetFilename())
+			c.additions += f.GetAdditions()
+			c.deletions += f.GetDeletions()
+			return collapsed
+		}
+	}
+	return append(collapsed, &collapsedStat{kind: kind, names: []string{f.GetFilename()}, additions: f.GetAdditions(), deletions: f.GetDeletions()})
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// defaultDocsLabel marks PRs a doc rule found missing documentation
+const defaultDocsLabel = "needs-docs"
+
+// DocRule asks for documentation to change along with code: when files
+// under When change but none under Docs do, the PR gets a checklist item
+// and a label. Paths are directory prefixes ending in "/" or globs.
+type DocRule struct {
+	When []string `json:"when"`
+	Docs []string `json:"docs"`
+	// Item replaces the generated checklist item
+	Item string `json:"item,omitempty"`
+	// Label defaults to needs-docs
+	Label string `json:"label,omitempty"`
+}
+
+// validate checks both path lists are set and hold valid globs
+func (r *DocRule) validate() error {
+	if len(r.When) == 0 || len(r.Docs) == 0 {
+		return fmt.Errorf("doc rules need when and docs paths")
+	}
+	for _, p := range append(append([]string{}, r.When...), r.Docs...) {
+		if _, err := path.Match(p, ""); err != nil || p == "" {
+			return fmt.Errorf("invalid doc rule path %q", p)
+		}
+	}
+	return nil
+}
+
+// label returns the label for PRs breaking the rule
+func (r *DocRule) label() string {
+	if r.Label != "" {
+		return r.Label
+	}
+	return defaultDocsLabel
+}
+
+// item returns the checklist item for a PR changing file but no docs
+func (r *DocRule) item(file string) string {
+	if r.Item != "" {
+		return r.Item
+	}
+	return fmt.Sprintf("Update the docs in `%s`: `%s` changed without them", strings.Join(r.Docs, "`, `"), file)
+}
+
+// check returns the first changed file the rule is triggered by when no
+// docs changed with it, or ""
+func (r *DocRule) check(files []*github.CommitFile) string {
+	when, docs := ProtectedConfig{Paths: r.When}, ProtectedConfig{Paths: r.Docs}
+	trigger := ""
+	for _, f := range files {
+		if docs.matches(f.GetFilename()) {
+			return ""
+		}
+		if trigger == "" && when.matches(f.GetFilename()) {
+			trigger = f.GetFilename()
+		}
+	}
+	return trigger
+}
+
+// checkDocRules returns the checklist items and labels of the rules the
+// change breaks
+func checkDocRules(rules []DocRule, files []*github.CommitFile) (items, labels []string) {
+	for _, r := range rules {
+		file := r.check(files)
+		if file == "" {
+			continue
+		}
+		items = append(items, r.item(file))
+		if label := r.label(); !contains(labels, label) {
+			labels = append(labels, label)
+		}
+	}
+	return items, labels
+}
@@ -0,0 +1,262 @@
+package server
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/httpclient"
+)
+
+// SectionDocs links documentation pages related to the changed files
+const SectionDocs = "docs"
+
+// maxDocLinks caps the related pages listed
+const maxDocLinks = 8
+
+// maxSitemapSize bounds the sitemap read
+const maxSitemapSize = 5 << 20
+
+// sitemapTTL is how long a fetched sitemap is reused
+const sitemapTTL = time.Hour
+
+// wordSeparators split paths and URLs into words
+var wordSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// genericDocWords appear in too many URLs to link a page by
+var genericDocWords = map[string]bool{
+	"docs": true, "doc": true, "guide": true, "guides": true, "index": true, "html": true,
+	"latest": true, "stable": true, "reference": true, "overview": true, "introduction": true,
+	"main": true, "internal": true, "test": true, "tests": true,
+}
+
+// DocsConfig is the repository's documentation index. Listed pages match
+// changed paths or keywords; sitemap pages match when a word of their
+// URL's last segment names a changed directory or file.
+type DocsConfig struct {
+	Pages []DocPage `json:"pages,omitempty"`
+	// Sitemap is the URL of a sitemap.xml listing the docs pages
+	Sitemap string `json:"sitemap,omitempty"`
+}
+
+// DocPage is a documentation page and what it covers
+type DocPage struct {
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"`
+	// Paths are directory prefixes ending in "/" or globs of files the
+	// page documents
+	Paths []string `json:"paths,omitempty"`
+	// Keywords match changed paths and added lines, ignoring case
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+// validate checks pages and the sitemap are URLs and paths are globs
+func (c *DocsConfig) validate() error {
+	for _, page := range c.Pages {
+		if !webURL(page.URL) {
+			return fmt.Errorf("invalid docs page URL %q", page.URL)
+		}
+		if len(page.Paths) == 0 && len(page.Keywords) == 0 {
+			return fmt.Errorf("docs page %s needs paths or keywords", page.URL)
+		}
+		for _, p := range page.Paths {
+			if _, err := path.Match(p, ""); err != nil || p == "" {
+				return fmt.Errorf("invalid docs path %q", p)
+			}
+		}
+	}
+	if c.Sitemap != "" && !webURL(c.Sitemap) {
+		return fmt.Errorf("invalid sitemap URL %q", c.Sitemap)
+	}
+	return nil
+}
+
+// webURL reports whether raw is an http or https URL
+func webURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// relatedDoc is a page matched by a changed file
+type relatedDoc struct {
+	URL   string
+	Title string
+	File  string // the changed file that matched
+}
+
+// relatedDocs returns the pages covering the changed files, listed pages
+// first
+func (s *Server) relatedDocs(ctx context.Context, c *DocsConfig, files []*github.CommitFile) []relatedDoc {
+	var found []relatedDoc
+	seen := make(map[string]bool)
+	add := func(doc relatedDoc) {
+		if !seen[doc.URL] && len(found) < maxDocLinks {
+			seen[doc.URL] = true
+			found = append(found, doc)
+		}
+	}
+
+	for _, page := range c.Pages {
+		if file := page.match(files); file != "" {
+			add(relatedDoc{URL: page.URL, Title: page.Title, File: file})
+		}
+	}
+	if c.Sitemap == "" {
+		return found
+	}
+	pages, err := s.sitemaps.get(ctx, c.Sitemap)
+	if err != nil {
+		s.logger.Warning("Sitemap skipped: %v", err)
+		return found
+	}
+	words := make(map[string]string)
+	for _, f := range files {
+		for _, w := range pathWords(strings.TrimSuffix(f.GetFilename(), path.Ext(f.GetFilename()))) {
+			if words[w] == "" {
+				words[w] = f.GetFilename()
+			}
+		}
+	}
+	for _, page := range pages {
+		u, err := url.Parse(page)
+		if err != nil {
+			continue
+		}
+		slug := strings.TrimSuffix(path.Base(u.Path), path.Ext(u.Path))
+		for _, w := range pathWords(slug) {
+			if file := words[w]; file != "" {
+				add(relatedDoc{URL: page, File: file})
+				break
+			}
+		}
+	}
+	return found
+}
+
+// match returns the first changed file the page covers, or ""
+func (p DocPage) match(files []*github.CommitFile) string {
+	paths := ProtectedConfig{Paths: p.Paths}
+	for _, f := range files {
+		if len(p.Paths) > 0 && paths.matches(f.GetFilename()) {
+			return f.GetFilename()
+		}
+	}
+	for _, kw := range p.Keywords {
+		kw = strings.ToLower(kw)
+		for _, f := range files {
+			if strings.Contains(strings.ToLower(f.GetFilename()), kw) || addedContains(f.GetPatch(), kw) {
+				return f.GetFilename()
+			}
+		}
+	}
+	return ""
+}
+
+// addedContains reports whether a line the patch adds contains kw,
+// which is lower case
+func addedContains(patch, kw string) bool {
+	for _, line := range strings.Split(patch, "\n") {
+		if strings.HasPrefix(line, "+") && strings.Contains(strings.ToLower(line), kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathWords splits a path or slug into distinctive lower case words
+func pathWords(p string) []string {
+	var words []string
+	for _, w := range wordSeparators.Split(strings.ToLower(p), -1) {
+		if len(w) >= 4 && !genericDocWords[w] {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// docsSection renders the related pages as a reminder to update them
+func docsSection(docs []relatedDoc) string {
+	var b strings.Builder
+	b.WriteString("### Related Docs\n\n")
+	b.WriteString("These pages cover what this PR changes. Check whether they need updating:\n\n")
+	for _, d := range docs {
+		title := d.Title
+		if title == "" {
+			title = d.URL
+		}
+		fmt.Fprintf(&b, "- [%s](%s) (`%s`)\n", title, d.URL, d.File)
+	}
+	return b.String()
+}
+
+// sitemapCache keeps fetched sitemaps for an hour
+type sitemapCache struct {
+	mu      sync.Mutex
+	entries map[string]sitemapEntry
+	client  *http.Client
+}
+
+// sitemapEntry is a fetched sitemap's page URLs
+type sitemapEntry struct {
+	pages []string
+	at    time.Time
+}
+
+// newSitemapCache creates an empty sitemap cache
+func newSitemapCache() *sitemapCache {
+	return &sitemapCache{
+		entries: make(map[string]sitemapEntry),
+		client:  httpclient.New(10 * time.Second),
+	}
+}
+
+// get returns the page URLs of a sitemap, fetching it when not cached
+func (c *sitemapCache) get(ctx context.Context, sitemap string) ([]string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[sitemap]
+	c.mu.Unlock()
+	if ok && time.Since(entry.at) < sitemapTTL {
+		return entry.pages, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sitemap, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sitemap: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch sitemap: %s", resp.Status)
+	}
+	var doc struct {
+		URLs []struct {
+			Loc string `xml:"loc"`
+		} `xml:"url"`
+	}
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, maxSitemapSize)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse sitemap: %w", err)
+	}
+	pages := make([]string, 0, len(doc.URLs))
+	for _, u := range doc.URLs {
+		if loc := strings.TrimSpace(u.Loc); webURL(loc) {
+			pages = append(pages, loc)
+		}
+	}
+
+	c.mu.Lock()
+	c.entries[sitemap] = sitemapEntry{pages: pages, at: time.Now()}
+	c.mu.Unlock()
+	return pages, nil
+}
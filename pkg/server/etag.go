@@ -0,0 +1,112 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// minGzipSize is the smallest response worth compressing
+const minGzipSize = 1024
+
+// bufferedResponse holds a handler's response so it can be tagged and
+// compressed before it is sent
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+// cacheable adds an ETag to successful GET responses, answers a matching
+// If-None-Match with 304 Not Modified, and gzips large bodies for clients
+// that accept it, so polling clients don't re-transfer unchanged payloads.
+// Streaming handlers like /events must not be wrapped.
+func cacheable(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next(w, r)
+			return
+		}
+
+		buf := &bufferedResponse{header: w.Header()}
+		next(buf, r)
+		if buf.status == 0 {
+			buf.status = http.StatusOK
+		}
+		body := buf.body.Bytes()
+
+		if buf.status == http.StatusOK {
+			sum := sha256.Sum256(body)
+			// Weak, since the gzipped and plain bodies share it
+			etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+			w.Header().Set("ETag", etag)
+			w.Header().Add("Vary", "Accept-Encoding")
+			if etagMatches(r.Header.Get("If-None-Match"), etag) {
+				w.Header().Del("Content-Type")
+				w.Header().Del("Content-Length")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+
+		if len(body) >= minGzipSize && acceptsGzip(r) {
+			var gz bytes.Buffer
+			zw := gzip.NewWriter(&gz)
+			zw.Write(body)
+			if zw.Close() == nil {
+				w.Header().Set("Content-Encoding", "gzip")
+				w.Header().Del("Content-Length")
+				body = gz.Bytes()
+			}
+		}
+		w.WriteHeader(buf.status)
+		if r.Method != http.MethodHead {
+			w.Write(body)
+		}
+	}
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, using
+// the weak comparison RFC 9110 requires
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsGzip reports whether the client accepts gzip responses
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(enc), ";")
+		if strings.EqualFold(strings.TrimSpace(name), "gzip") && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Event types broadcast on /events
+const (
+	EventPushReceived       = "push_received"
+	EventGenerationStarted  = "generation_started"
+	EventGenerationFinished = "generation_finished"
+	EventPRCreated          = "pr_created"
+	EventError              = "error"
+	EventStaleBranch        = "stale_branch"
+	EventJobWaiting         = "job_waiting"
+	EventJobCancelled       = "job_cancelled"
+	EventPRMerged           = "pr_merged"
+	EventPRClosed           = "pr_closed"
+	EventPRComment          = "pr_comment"
+	EventRelease            = "release"
+)
+
+// Event is a server event streamed to integrations
+type Event struct {
+	Type    string    `json:"type"`
+	Time    time.Time `json:"time"`
+	JobID   string    `json:"job_id,omitempty"`
+	Owner   string    `json:"owner,omitempty"`
+	Repo    string    `json:"repo,omitempty"`
+	Branch  string    `json:"branch,omitempty"`
+	PRURL   string    `json:"pr_url,omitempty"`
+	Message string    `json:"message,omitempty"`
+}
+
+// broker fans events out to subscribers
+type broker struct {
+	mu   sync.RWMutex
+	subs map[chan Event]struct{}
+}
+
+// newBroker creates an empty event broker
+func newBroker() *broker {
+	return &broker{subs: make(map[chan Event]struct{})}
+}
+
+// subscribe registers a new subscriber and returns its channel
+func (b *broker) subscribe() chan Event {
+	ch := make(chan Event, 32)
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch
+}
+
+// unsubscribe removes a subscriber and closes its channel
+func (b *broker) unsubscribe(ch chan Event) {
+	b.mu.Lock()
+	if _, ok := b.subs[ch]; ok {
+		delete(b.subs, ch)
+		close(ch)
+	}
+	b.mu.Unlock()
+}
+
+// publish sends an event to every subscriber, dropping it for slow ones
+func (b *broker) publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// jobEvent builds an event describing a job
+func jobEvent(typ string, job *Job, msg string) Event {
+	return Event{
+		Type:    typ,
+		JobID:   job.ID,
+		Owner:   job.Owner,
+		Repo:    job.Repo,
+		Branch:  job.Branch,
+		Message: msg,
+	}
+}
+
+// handleEvents streams server events as server-sent events
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ch := s.events.subscribe()
+	defer s.events.unsubscribe(ch)
+	s.logger.Debug("Event subscriber connected: %s", r.RemoteAddr)
+
+	heartbeat := time.NewTicker(15 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			s.logger.Debug("Event subscriber disconnected: %s", r.RemoteAddr)
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case e := <-ch:
+			data, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// prTarget returns the repository PRs are opened against: the upstream
+// for forks, the repository itself otherwise
+func (c *Config) prTarget() (owner, name string) {
+	if parts := strings.SplitN(c.Upstream, "/", 2); len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return c.Owner, c.Name
+}
+
+// prHead returns the head ref for a branch, qualified with the fork
+// owner when the PR crosses repositories
+func (c *Config) prHead(branch string) string {
+	if c.Upstream == "" {
+		return branch
+	}
+	return c.Owner + ":" + branch
+}
+
+// forbidden reports whether a GitHub error means the token lacks
+// permission on the repository, as with triage actions on an upstream
+func forbidden(err error) bool {
+	var ghErr *github.ErrorResponse
+	if !errors.As(err, &ghErr) || ghErr.Response == nil {
+		return false
+	}
+	code := ghErr.Response.StatusCode
+	return code == http.StatusForbidden || code == http.StatusNotFound
+}
+
+// suggestTriage comments the labels and reviewers the token could not
+// apply on an upstream PR, so maintainers can apply them instead
+func (s *Server) suggestTriage(ctx context.Context, owner, name string, number int, labels, reviewers []string) {
+	var b strings.Builder
+	b.WriteString("ggquick could not apply triage on this repository, suggested:\n")
+	if len(labels) > 0 {
+		fmt.Fprintf(&b, "\n- Labels: %s", strings.Join(labels, ", "))
+	}
+	if len(reviewers) > 0 {
+		fmt.Fprintf(&b, "\n- Reviewers: @%s", strings.Join(reviewers, ", @"))
+	}
+	if err := s.github.CreateComment(ctx, owner, name, number, b.String()); err != nil {
+		s.logger.Warning("Failed to comment triage suggestions: %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// hookGrace allows for clock skew between a developer's machine and
+// GitHub before a push without a hook report counts as missed
+const hookGrace = 2 * time.Minute
+
+// hookReport is the last hook fingerprint a user's CLI reported for a
+// repository. Missing is set when GitHub saw a push from them that their
+// hooks never reported, as happens after a fresh clone.
+type hookReport struct {
+	Repo         string     `json:"repo"`
+	User         string     `json:"user"`
+	Fingerprint  string     `json:"fingerprint"`
+	ReportedAt   time.Time  `json:"reported_at"`
+	Missing      bool       `json:"missing"`
+	MissedCommit string     `json:"missed_commit,omitempty"`
+	MissingSince *time.Time `json:"missing_since,omitempty"`
+}
+
+// hookStore holds hook reports by lowercased repo and login
+type hookStore struct {
+	mu      sync.Mutex
+	reports map[string]*hookReport
+}
+
+// hookKey returns the store key for a user's hooks in a repository
+func hookKey(repo, user string) string {
+	return strings.ToLower(repo) + "\x00" + strings.ToLower(user)
+}
+
+// report records the fingerprint sent with a push, clearing any missing
+// mark since the hooks evidently fired
+func (h *hookStore) report(repo, user, fingerprint string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.reports[hookKey(repo, user)] = &hookReport{
+		Repo:        repo,
+		User:        user,
+		Fingerprint: fingerprint,
+		ReportedAt:  time.Now(),
+	}
+}
+
+// missed marks a user's hooks missing when GitHub saw a commit of theirs
+// made after their last report. It returns false for users who never
+// reported, since they may not use hooks at all.
+func (h *hookStore) missed(repo, user, sha string, committed time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	report := h.reports[hookKey(repo, user)]
+	if report == nil || report.Missing || !committed.After(report.ReportedAt.Add(hookGrace)) {
+		return false
+	}
+	now := time.Now()
+	report.Missing = true
+	report.MissedCommit = sha
+	report.MissingSince = &now
+	return true
+}
+
+// list returns copies of the reports for repo, or one user's when user is
+// set, ordered by user
+func (h *hookStore) list(repo, user string) []hookReport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	reports := []hookReport{}
+	for _, report := range h.reports {
+		if !strings.EqualFold(report.Repo, repo) || (user != "" && !strings.EqualFold(report.User, user)) {
+			continue
+		}
+		reports = append(reports, *report)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].User < reports[j].User })
+	return reports
+}
+
+// recordHooks stores the hook fingerprint a push carried, if any
+func (s *Server) recordHooks(repo, user, fingerprint string) {
+	if fingerprint == "" || user == "" {
+		return
+	}
+	s.hookState.report(repo, user, fingerprint)
+}
+
+// checkHooks compares a GitHub push with the pusher's hook reports. A head
+// commit newer than their last report means the hooks didn't fire, so the
+// next ggquick doctor run offers to reinstall them.
+func (s *Server) checkHooks(repo string, e *github.PushEvent) {
+	user := e.GetSender().GetLogin()
+	commit := e.GetHeadCommit()
+	if user == "" || commit.GetTimestamp().IsZero() {
+		return
+	}
+	if s.hookState.missed(repo, user, commit.GetID(), commit.GetTimestamp().Time) {
+		s.logger.Warning("Hooks for %s in %s look missing: %s was pushed without a hook report", user, repo, shortSHA(commit.GetID()))
+	}
+}
+
+// handleHooks lists the hook reports for a repository, optionally for one
+// user, so the CLI can tell when the server stopped hearing from its hooks
+func (s *Server) handleHooks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	config := s.repoConfig(r.URL.Query().Get("repo"))
+	if config == nil {
+		http.Error(w, "Repository not configured", http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.hookState.list(config.FullName(), r.URL.Query().Get("user")))
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/analyze"
+)
+
+// Hotspot comment defaults and the most comments one PR gets
+const (
+	defaultHotspots        = 3
+	maxHotspots            = 10
+	defaultHotspotMinLines = 30
+)
+
+// hotspotPrefix marks each hotspot comment as written by ggquick
+const hotspotPrefix = "🤖 **Automated note from ggquick**, not a review:\n\n"
+
+// HotspotConfig comments on the largest hunks of a new PR, explaining what
+// changed there
+type HotspotConfig struct {
+	Enabled bool `json:"enabled"`
+	// Max comments per PR, 3 by default and at most 10
+	Max int `json:"max,omitempty"`
+	// MinLines a hunk must change to be commented on, 30 by default
+	MinLines int `json:"min_lines,omitempty"`
+}
+
+// validate checks the caps are in range
+func (c *HotspotConfig) validate() error {
+	if c.Max < 0 || c.Max > maxHotspots {
+		return fmt.Errorf("hotspots max must be at most %d", maxHotspots)
+	}
+	if c.MinLines < 0 {
+		return fmt.Errorf("hotspots min_lines can't be negative")
+	}
+	return nil
+}
+
+// limits returns the comment cap and the smallest hunk commented on
+func (c *HotspotConfig) limits() (max, minLines int) {
+	max, minLines = c.Max, c.MinLines
+	if max == 0 {
+		max = defaultHotspots
+	}
+	if minLines == 0 {
+		minLines = defaultHotspotMinLines
+	}
+	return max, minLines
+}
+
+// annotateHotspots posts one review with an explanation on each of the
+// largest hunks of a new PR. Hunks the model can't explain are skipped.
+func (s *Server) annotateHotspots(ctx context.Context, config *Config, job *Job, pr *github.PullRequest, files []*github.CommitFile) error {
+	if config.Hotspots == nil || !config.Hotspots.Enabled {
+		return nil
+	}
+	max, minLines := config.Hotspots.limits()
+	spots := analyze.Hotspots(fileDiffs(files), minLines, max)
+	if len(spots) == 0 {
+		return nil
+	}
+
+	s.logger.Loading("🔥 Explaining %d hotspot(s)...", len(spots))
+	var comments []*github.DraftReviewComment
+	for _, spot := range spots {
+		genCtx, cancel := context.WithTimeout(ctx, config.Timeouts.generate())
+		text, tokens, err := s.generator.ExplainHunk(genCtx, spot.File, spot.Hunk)
+		cancel()
+		s.jobs.update(job.ID, func(j *Job) { j.Tokens += tokens })
+		if err == nil {
+			err = s.checkContent(ctx, config, text)
+		}
+		if err != nil {
+			s.logger.Warning("Hotspot in %s skipped: %v", spot.File, err)
+			continue
+		}
+		comments = append(comments, &github.DraftReviewComment{
+			Path: github.String(spot.File),
+			Line: github.Int(spot.Line),
+			Side: github.String("RIGHT"),
+			Body: github.String(hotspotPrefix + sanitize(config, text, "")),
+		})
+	}
+	if len(comments) == 0 {
+		return nil
+	}
+
+	owner, name := config.prTarget()
+	postCtx, cancel := context.WithTimeout(ctx, config.Timeouts.github())
+	defer cancel()
+	err := s.github.CreateReview(postCtx, owner, name, pr.GetNumber(), &github.PullRequestReviewRequest{
+		CommitID: github.String(pr.GetHead().GetSHA()),
+		Event:    github.String("COMMENT"),
+		Comments: comments,
+	})
+	if err != nil {
+		return err
+	}
+	s.logger.Success("✅ Explained %d hotspot(s) on #%d", len(comments), pr.GetNumber())
+	return nil
+}
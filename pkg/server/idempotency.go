@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// idempotencyHeader carries a key the client picks per submission and
+// repeats on retries, so a retried push returns the job the first created
+const idempotencyHeader = "Idempotency-Key"
+
+// deliveryHeader identifies a webhook delivery; GitHub keeps it when it
+// redelivers
+const deliveryHeader = "X-GitHub-Delivery"
+
+// idempotencyTTL is how long a key is remembered
+const idempotencyTTL = 24 * time.Hour
+
+var idempotencyKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_.:-]{1,128}$`)
+
+// idempotencyStore remembers the job the first request with each key
+// created, "" while it is handled or when it created none
+type idempotencyStore struct {
+	mu   sync.Mutex
+	keys map[string]*idempotentRequest
+}
+
+// idempotentRequest is the first request seen with a key
+type idempotentRequest struct {
+	jobID   string
+	at      time.Time
+	release func() // frees the backend claim, if any
+}
+
+// claimIdempotent claims key for this request. When an earlier request,
+// on this or another replica, already has it, claimed is false and jobID
+// names its job if it has made one.
+func (s *Server) claimIdempotent(ctx context.Context, key string) (jobID string, claimed bool) {
+	now := time.Now()
+	st := s.dedup
+	st.mu.Lock()
+	for k, req := range st.keys {
+		if now.Sub(req.at) > idempotencyTTL {
+			delete(st.keys, k)
+		}
+	}
+	if req, ok := st.keys[key]; ok {
+		st.mu.Unlock()
+		return req.jobID, false
+	}
+	req := &idempotentRequest{at: now}
+	st.keys[key] = req
+	st.mu.Unlock()
+
+	if s.backend == nil {
+		return "", true
+	}
+	release, err := s.tryLock(ctx, "idempotency:"+key, idempotencyTTL)
+	if err != nil {
+		// Better a rare duplicate than a dropped push
+		s.logger.Warning("Failed to claim idempotency key: %v", err)
+		return "", true
+	}
+	if release == nil {
+		jobID = s.loadIdempotent(ctx, key)
+		st.mu.Lock()
+		req.jobID = jobID
+		st.mu.Unlock()
+		return jobID, false
+	}
+	st.mu.Lock()
+	req.release = release
+	st.mu.Unlock()
+	return "", true
+}
+
+// finishIdempotent records the job created for key, with the job
+func (s *Server) finishIdempotent(ctx context.Context, key string, job *Job) {
+	s.dedup.mu.Lock()
+	if req, ok := s.dedup.keys[key]; ok {
+		req.jobID = job.ID
+	}
+	s.dedup.mu.Unlock()
+	s.jobs.update(job.ID, func(j *Job) { j.IdempotencyKey = key })
+
+	if s.backend != nil {
+		ctx, cancel := context.WithTimeout(ctx, backendOpTimeout)
+		defer cancel()
+		if err := s.backend.SaveJob(ctx, "idempotency:"+key, []byte(job.ID)); err != nil {
+			s.logger.Warning("Failed to share idempotency key: %v", err)
+		}
+	}
+}
+
+// forgetIdempotent drops key after its request failed, so a retry runs
+// again
+func (s *Server) forgetIdempotent(key string) {
+	s.dedup.mu.Lock()
+	req, ok := s.dedup.keys[key]
+	delete(s.dedup.keys, key)
+	s.dedup.mu.Unlock()
+	if ok && req.release != nil {
+		req.release()
+	}
+}
+
+// loadIdempotent returns the job another replica recorded for key
+func (s *Server) loadIdempotent(ctx context.Context, key string) string {
+	ctx, cancel := context.WithTimeout(ctx, backendOpTimeout)
+	defer cancel()
+	data, err := s.backend.LoadJob(ctx, "idempotency:"+key)
+	if err != nil {
+		s.logger.Warning("Failed to read idempotency key: %v", err)
+	}
+	return string(data)
+}
+
+// writeIdempotent answers a repeated request with the job the first one
+// created, or a conflict while that one is still being handled
+func (s *Server) writeIdempotent(w http.ResponseWriter, jobID string) {
+	if jobID == "" {
+		http.Error(w, "A request with this Idempotency-Key is still being handled", http.StatusConflict)
+		return
+	}
+	job, ok := s.findJob(jobID)
+	if !ok {
+		job = Job{ID: jobID}
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	writeJSON(w, http.StatusOK, job)
+}
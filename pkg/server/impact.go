@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/analyze"
+)
+
+// touchesGo reports whether any changed file is Go source or go.mod
+func touchesGo(files []*github.CommitFile) bool {
+	for _, f := range files {
+		name := f.GetFilename()
+		if strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "go.mod") {
+			return true
+		}
+	}
+	return false
+}
+
+// impactSection renders a Mermaid diagram of the packages importing the
+// changed Go packages
+func (s *Server) impactSection(ctx context.Context, jw *jobWorkspace, files []*github.CommitFile) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Minute)
+	defer cancel()
+
+	s.logger.Loading("🕸️ Analyzing package impact...")
+	ws, err := jw.get(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	changed := make([]string, 0, len(files))
+	for _, f := range files {
+		changed = append(changed, f.GetFilename())
+	}
+
+	impact, err := analyze.GoImpact(ctx, ws, changed)
+	if err != nil {
+		return "", err
+	}
+	graph := impact.Mermaid()
+	if graph == "" {
+		return "", nil
+	}
+
+	importers := 0
+	for _, pkgs := range impact.Importers {
+		importers += len(pkgs)
+	}
+	return fmt.Sprintf("### Impact\n\n%d changed package(s), imported by %d other package(s).\n\n```mermaid\n%s```\n",
+		len(impact.Changed), importers, graph), nil
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/saint0x/ggquick/pkg/analyze"
+)
+
+// labelDestructiveInfra marks PRs that may delete infrastructure
+const labelDestructiveInfra = "destructive-infra"
+
+// infraCounts tallies changes by action
+func infraCounts(changes []analyze.InfraChange) (add, change, destroy int) {
+	for _, c := range changes {
+		switch c.Action {
+		case analyze.InfraAdded:
+			add++
+		case analyze.InfraChanged:
+			change++
+		case analyze.InfraDestroyed:
+			destroy++
+		}
+	}
+	return add, change, destroy
+}
+
+// infraNote summarizes the changes for the model
+func infraNote(changes []analyze.InfraChange) string {
+	add, change, destroy := infraCounts(changes)
+	return fmt.Sprintf("Infrastructure changes: %d to add, %d to change, %d to destroy", add, change, destroy)
+}
+
+// infraSection renders a plan-style summary of infrastructure changes,
+// with destructive ones called out first
+func infraSection(changes []analyze.InfraChange) string {
+	add, change, destroy := infraCounts(changes)
+
+	var b strings.Builder
+	b.WriteString("## Infrastructure Impact\n\n")
+	var destructive []analyze.InfraChange
+	for _, c := range changes {
+		if c.Destructive() {
+			destructive = append(destructive, c)
+		}
+	}
+	if len(destructive) > 0 {
+		b.WriteString("> [!CAUTION]\n")
+		b.WriteString("> **Destructive infrastructure changes**\n>\n")
+		for _, c := range destructive {
+			if c.Action == analyze.InfraDestroyed {
+				fmt.Fprintf(&b, "> - `%s` is destroyed (`%s`)\n", c.Resource, c.File)
+			} else {
+				fmt.Fprintf(&b, "> - `%s`: %s (`%s`)\n", c.Resource, c.Note, c.File)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "**Estimated plan:** %d to add, %d to change, %d to destroy\n\n", add, change, destroy)
+	symbols := map[string]string{analyze.InfraAdded: "+", analyze.InfraChanged: "~", analyze.InfraDestroyed: "-"}
+	b.WriteString("```diff\n")
+	for _, c := range changes {
+		fmt.Fprintf(&b, "%s %s\n", symbols[c.Action], c.Resource)
+	}
+	b.WriteString("```\n\n")
+	b.WriteString("_Estimated from the diff, not from `terraform plan` or a cluster. Review the real plan before applying._\n")
+	return b.String()
+}
@@ -0,0 +1,194 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/httpclient"
+)
+
+const (
+	githubMetaURL  = "https://api.github.com/meta"
+	githubMetaTTL  = time.Hour
+	githubMetaWait = 10 * time.Second
+)
+
+// rangeSource returns the networks allowed to reach an endpoint
+type rangeSource func(ctx context.Context) ([]*net.IPNet, error)
+
+// hookRanges caches GitHub's published webhook source ranges
+type hookRanges struct {
+	mu      sync.Mutex
+	nets    []*net.IPNet
+	fetched time.Time
+	// refresh is closed when the fetch in flight, if any, finishes
+	refresh chan struct{}
+	err     error // of the last fetch
+}
+
+// get returns the cached ranges, refreshing them from the meta API when
+// stale. One fetch runs at a time, outside the lock, and callers are
+// served the previous ranges meanwhile or when it fails. Only callers
+// without any ranges yet wait for it.
+func (h *hookRanges) get(ctx context.Context) ([]*net.IPNet, error) {
+	h.mu.Lock()
+	if h.nets != nil && time.Since(h.fetched) < githubMetaTTL {
+		defer h.mu.Unlock()
+		return h.nets, nil
+	}
+	if h.refresh == nil {
+		h.refresh = make(chan struct{})
+		go h.fetch(h.refresh)
+	}
+	done, nets := h.refresh, h.nets
+	h.mu.Unlock()
+	if nets != nil {
+		return nets, nil
+	}
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.nets != nil {
+		return h.nets, nil
+	}
+	return nil, h.err
+}
+
+// fetch refreshes the ranges and closes done. It isn't bound to the
+// request that started it, which may end first.
+func (h *hookRanges) fetch(done chan struct{}) {
+	nets, err := fetchHookRanges(context.Background())
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err == nil {
+		h.nets, h.fetched = nets, time.Now()
+	}
+	h.err = err
+	h.refresh = nil
+	close(done)
+}
+
+// fetchHookRanges reads the "hooks" CIDRs from GitHub's meta API
+func fetchHookRanges(ctx context.Context) ([]*net.IPNet, error) {
+	ctx, cancel := context.WithTimeout(ctx, githubMetaWait)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, githubMetaURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := httpclient.New(0).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch GitHub meta: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub meta returned status %d", resp.StatusCode)
+	}
+
+	var meta struct {
+		Hooks []string `json:"hooks"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&meta); err != nil {
+		return nil, fmt.Errorf("failed to decode GitHub meta: %w", err)
+	}
+	if len(meta.Hooks) == 0 {
+		return nil, fmt.Errorf("GitHub meta lists no hook ranges")
+	}
+	return parseCIDRs(meta.Hooks)
+}
+
+// parseCIDRs parses networks, treating bare addresses as single hosts
+func parseCIDRs(list []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, s := range list {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		if !strings.Contains(s, "/") {
+			if ip := net.ParseIP(s); ip != nil && ip.To4() != nil {
+				s += "/32"
+			} else {
+				s += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q: %w", s, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// clientIP returns the caller's address. On fly.io the proxy's
+// Fly-Client-IP header is trusted; elsewhere the connection address is used.
+func clientIP(r *http.Request) net.IP {
+	if os.Getenv("FLY_APP_NAME") != "" {
+		if ip := net.ParseIP(r.Header.Get("Fly-Client-IP")); ip != nil {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return net.ParseIP(host)
+}
+
+// allowIPs rejects requests from outside the networks returned by source
+// with 403. A nil source allows everyone.
+func (s *Server) allowIPs(source rangeSource, next http.HandlerFunc) http.HandlerFunc {
+	if source == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		nets, err := source(r.Context())
+		if err != nil {
+			s.logger.Error("❌ Failed to load allowed networks: %v", err)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		ip := clientIP(r)
+		for _, n := range nets {
+			if ip != nil && n.Contains(ip) {
+				next(w, r)
+				return
+			}
+		}
+		s.logger.Error("❌ Rejected %s from %s", r.URL.Path, ip)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	}
+}
+
+// ipFiltersFromEnv returns the range sources for /webhook and /push.
+// GGQUICK_WEBHOOK_IP_CHECK=true limits /webhook to GitHub's hook ranges;
+// GGQUICK_PUSH_ALLOWLIST limits /push to comma separated networks.
+func ipFiltersFromEnv() (webhook, push rangeSource, err error) {
+	if os.Getenv("GGQUICK_WEBHOOK_IP_CHECK") == "true" {
+		webhook = (&hookRanges{}).get
+	}
+	if list := os.Getenv("GGQUICK_PUSH_ALLOWLIST"); list != "" {
+		nets, err := parseCIDRs(strings.Split(list, ","))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid GGQUICK_PUSH_ALLOWLIST: %w", err)
+		}
+		push = func(context.Context) ([]*net.IPNet, error) { return nets, nil }
+	}
+	return webhook, push, nil
+}
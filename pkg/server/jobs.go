@@ -0,0 +1,171 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Job statuses
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobWaiting   = "waiting" // branch not pushed to GitHub yet
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// Job tracks a single PR generation request
+type Job struct {
+	ID        string    `json:"id"`
+	Owner     string    `json:"owner"`
+	Repo      string    `json:"repo"`
+	Branch    string    `json:"branch"`
+	SHA       string    `json:"sha,omitempty"`
+	Status    string    `json:"status"`
+	PRNumber  int       `json:"pr_number,omitempty"`
+	PRURL     string    `json:"pr_url,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Tokens    int       `json:"tokens,omitempty"`
+	Author    string    `json:"author,omitempty"`
+	Priority  string    `json:"priority,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Model and PromptVersion wrote the description; References counts the
+	// files and symbols it named and Unverified those not in the diff
+	Model         string `json:"model,omitempty"`
+	Provider      string `json:"provider,omitempty"` // the AI provider that answered
+	PromptVersion string `json:"prompt_version,omitempty"`
+	References    int    `json:"references,omitempty"`
+	Unverified    int    `json:"unverified,omitempty"`
+	// Confidence scores the description from 0 to 1
+	Confidence float64 `json:"confidence,omitempty"`
+
+	// IdempotencyKey is the key of the push that created the job, if any
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+
+	// authorVerified is set when the push carried the author's user key
+	authorVerified bool
+	// capIdentity is who the job counts against in author caps: the
+	// sender of a signed webhook or the owner of a user key
+	capIdentity string
+	// local is what the pusher's clone reported about the branch, if any
+	local *localChanges
+}
+
+// jobStoreSize is how many recent jobs each replica keeps in memory
+const jobStoreSize = 100
+
+// jobStore keeps recent jobs in memory, oldest evicted first
+type jobStore struct {
+	mu    sync.RWMutex
+	jobs  map[string]*Job
+	order []string
+	max   int
+
+	// onChange, when set, receives a copy of each created or updated job
+	onChange func(Job)
+}
+
+// newJobStore creates a job store holding at most max jobs
+func newJobStore(max int) *jobStore {
+	return &jobStore{
+		jobs: make(map[string]*Job),
+		max:  max,
+	}
+}
+
+// create registers a new queued job
+func (s *jobStore) create(owner, repo, branch, sha string) *Job {
+	now := time.Now().UTC()
+	job := &Job{
+		ID:        newJobID(),
+		Owner:     owner,
+		Repo:      repo,
+		Branch:    branch,
+		SHA:       sha,
+		Status:    JobQueued,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	s.add(job)
+	return job
+}
+
+// adopt registers a job created by another replica
+func (s *jobStore) adopt(job Job, authorVerified bool) *Job {
+	job.authorVerified = authorVerified
+	s.add(&job)
+	return &job
+}
+
+// add stores a job, evicting the oldest past max
+func (s *jobStore) add(job *Job) {
+	s.mu.Lock()
+	s.jobs[job.ID] = job
+	s.order = append(s.order, job.ID)
+	for len(s.order) > s.max {
+		delete(s.jobs, s.order[0])
+		s.order = s.order[1:]
+	}
+	copied := *job
+	s.mu.Unlock()
+	s.changed(copied)
+}
+
+// update applies fn to the job under lock
+func (s *jobStore) update(id string, fn func(*Job)) {
+	s.mu.Lock()
+	job, ok := s.jobs[id]
+	if !ok {
+		s.mu.Unlock()
+		return
+	}
+	fn(job)
+	job.UpdatedAt = time.Now().UTC()
+	copied := *job
+	s.mu.Unlock()
+	s.changed(copied)
+}
+
+// changed passes a job to onChange, if set
+func (s *jobStore) changed(job Job) {
+	if s.onChange != nil {
+		s.onChange(job)
+	}
+}
+
+// get returns a copy of the job with the given ID
+func (s *jobStore) get(id string) (Job, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// list returns copies of all jobs, newest first
+func (s *jobStore) list() []Job {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	jobs := make([]Job, 0, len(s.order))
+	for i := len(s.order) - 1; i >= 0; i-- {
+		jobs = append(jobs, *s.jobs[s.order[i]])
+	}
+	return jobs
+}
+
+// newJobID returns a random hex job identifier
+func newJobID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().UTC().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
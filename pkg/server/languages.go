@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// languageCommand sets the commenter's language, e.g. "/ggquick lang ja"
+const languageCommand = "/ggquick lang"
+
+// languagePattern matches language tags like ja or pt-BR
+var languagePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})?$`)
+
+// LocalizationConfig writes PR descriptions and comments for a user's PRs
+// in their preferred language
+type LocalizationConfig struct {
+	Enabled bool `json:"enabled"`
+	// Users maps GitHub logins to language tags. Preferences set with
+	// /ggquick lang take precedence.
+	Users map[string]string `json:"users,omitempty"`
+}
+
+// validate checks every user has a language tag
+func (c *LocalizationConfig) validate() error {
+	for login, lang := range c.Users {
+		if !languagePattern.MatchString(lang) {
+			return fmt.Errorf("invalid language %q for %s", lang, login)
+		}
+	}
+	return nil
+}
+
+// languageStore holds languages set with /ggquick lang by lowercased login
+type languageStore struct {
+	mu    sync.RWMutex
+	prefs map[string]string
+}
+
+// get returns login's language, or ""
+func (l *languageStore) get(login string) string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.prefs[strings.ToLower(login)]
+}
+
+// set stores login's language, clearing it when lang is ""
+func (l *languageStore) set(login, lang string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if lang == "" {
+		delete(l.prefs, strings.ToLower(login))
+		return
+	}
+	l.prefs[strings.ToLower(login)] = lang
+}
+
+// all returns a copy of the stored languages
+func (l *languageStore) all() map[string]string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if len(l.prefs) == 0 {
+		return nil
+	}
+	prefs := make(map[string]string, len(l.prefs))
+	for login, lang := range l.prefs {
+		prefs[login] = lang
+	}
+	return prefs
+}
+
+// userLanguage returns the language login's PRs are written in, or "" for
+// the default
+func (s *Server) userLanguage(config *Config, login string) string {
+	if config.Localization == nil || !config.Localization.Enabled || login == "" {
+		return ""
+	}
+	if lang := s.languages.get(login); lang != "" {
+		return lang
+	}
+	// Sorted so a login configured twice in different cases is stable
+	logins := make([]string, 0, len(config.Localization.Users))
+	for configured := range config.Localization.Users {
+		logins = append(logins, configured)
+	}
+	sort.Strings(logins)
+	for _, configured := range logins {
+		if strings.EqualFold(configured, login) {
+			return config.Localization.Users[configured]
+		}
+	}
+	return ""
+}
+
+// withLanguage adds a language instruction to model instructions. Headings
+// stay in English because the body layout is parsed by them.
+func withLanguage(instructions, lang string) string {
+	if lang == "" {
+		return instructions
+	}
+	line := fmt.Sprintf("Write all prose in the language with tag %q. Keep Markdown headings exactly as given, in English, and leave code, identifiers and file paths untranslated.", lang)
+	if instructions == "" {
+		return line
+	}
+	return instructions + "\n\n" + line
+}
+
+// handleLanguageCommand stores the language a /ggquick lang comment asks
+// for. It reports whether the comment was a language command.
+func (s *Server) handleLanguageCommand(config *Config, e *github.IssueCommentEvent) bool {
+	line, _, _ := strings.Cut(strings.TrimSpace(e.GetComment().GetBody()), "\n")
+	arg, ok := strings.CutPrefix(strings.TrimSpace(line), languageCommand)
+	if !ok || config.Localization == nil || !config.Localization.Enabled {
+		return false
+	}
+	user := e.GetComment().GetUser()
+	if user.GetType() == "Bot" {
+		return true
+	}
+
+	login := user.GetLogin()
+	lang := strings.TrimSpace(arg)
+	var reply string
+	switch {
+	case lang == "off" || lang == "default":
+		s.languages.set(login, "")
+		reply = fmt.Sprintf("🌐 @%s, ggquick will write your PRs in the default language.", login)
+	case languagePattern.MatchString(lang):
+		s.languages.set(login, lang)
+		reply = fmt.Sprintf("🌐 @%s, ggquick will write your PR descriptions in `%s`.", login, lang)
+	default:
+		reply = fmt.Sprintf("🌐 @%s, `%s` isn't a language tag. Try `%s ja`, `%s pt-BR` or `%s off`.", login, lang, languageCommand, languageCommand, languageCommand)
+		lang = ""
+	}
+	if lang != "" {
+		s.logger.Info("🌐 %s set their language to %s", login, lang)
+		s.persist()
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), config.Timeouts.github())
+		defer cancel()
+		repo := e.GetRepo()
+		if err := s.github.CreateComment(ctx, repo.GetOwner().GetLogin(), repo.GetName(), e.GetIssue().GetNumber(), reply); err != nil {
+			s.logger.Warning("Failed to confirm language for %s: %v", login, err)
+		}
+	}()
+	return true
+}
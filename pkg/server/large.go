@@ -0,0 +1,52 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/saint0x/ggquick/pkg/analyze"
+)
+
+// defaultLargeDiffLabel marks PRs with binary or oversized files
+const defaultLargeDiffLabel = "large-diff"
+
+// LargeFileConfig tunes the binary and large file warnings
+type LargeFileConfig struct {
+	MaxLines int    `json:"max_lines,omitempty"` // changed lines before a file counts as large, default 1000
+	Label    bool   `json:"label,omitempty"`     // also label the PR
+	LabelAs  string `json:"label_as,omitempty"`  // default large-diff
+}
+
+// maxLines returns the configured threshold, zero meaning the default
+func (c *LargeFileConfig) maxLines() int {
+	if c == nil {
+		return 0
+	}
+	return c.MaxLines
+}
+
+// label returns the label to add, or "" when labelling is off
+func (c *LargeFileConfig) label() string {
+	if c == nil || !c.Label {
+		return ""
+	}
+	if c.LabelAs != "" {
+		return c.LabelAs
+	}
+	return defaultLargeDiffLabel
+}
+
+// largeFileSection renders a warning for files the summary can't cover
+func largeFileSection(warnings []analyze.FileWarning) string {
+	var b strings.Builder
+	b.WriteString("> [!WARNING]\n")
+	b.WriteString("> **Binary or large files changed** and are not covered by the summary above. Please review them directly.\n>\n")
+	for i, w := range warnings {
+		if i == 20 {
+			fmt.Fprintf(&b, "> - ...and %d more\n", len(warnings)-i)
+			break
+		}
+		fmt.Fprintf(&b, "> - `%s`: %s\n", w.File, w.Detail)
+	}
+	return b.String()
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// leaderTTL is how long scheduler leadership lasts without renewal. The
+// leader renews it every scheduler tick.
+const leaderTTL = 3 * time.Minute
+
+// leader tracks whether this replica runs the scheduled tasks
+type leader struct {
+	id      string
+	leading bool
+}
+
+// replicaID names this replica for leader election
+func replicaID() string {
+	// Fly sets FLY_MACHINE_ID on each machine
+	if id := os.Getenv("FLY_MACHINE_ID"); id != "" {
+		return id + "-" + newJobID()
+	}
+	host, _ := os.Hostname()
+	return host + "-" + newJobID()
+}
+
+// leadScheduler reports whether this replica should run stale sweeps and
+// digests. Without a queue backend it always does; with one, a single
+// replica holds the lease and another takes over within leaderTTL of it
+// stopping.
+func (s *Server) leadScheduler(ctx context.Context) bool {
+	if s.backend == nil {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, backendOpTimeout)
+	defer cancel()
+	leading, err := s.backend.Lead(ctx, "scheduler", s.leader.id, leaderTTL)
+	if err != nil {
+		// Unsure whether the lease was renewed, so stand down
+		s.logger.Warning("Scheduler leader election failed: %v", err)
+		leading = false
+	}
+
+	if leading != s.leader.leading {
+		if leading {
+			s.logger.Success("✅ Running scheduled tasks as leader %s", s.leader.id)
+		} else {
+			s.logger.Info("ℹ️ Another replica is running scheduled tasks")
+		}
+		s.leader.leading = leading
+	}
+	return leading
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// defaultLicenseLabel marks PRs adding files without a license header
+const defaultLicenseLabel = "license-header"
+
+// LicenseConfig requires a license header in newly added files
+type LicenseConfig struct {
+	Header string   `json:"header"`          // regexp the top of each file must match, e.g. "SPDX-License-Identifier"
+	Files  []string `json:"files,omitempty"` // globs to check, common source files by default
+	Label  string   `json:"label,omitempty"` // default license-header
+}
+
+// validate checks the header pattern compiles
+func (c *LicenseConfig) validate() error {
+	if c.Header == "" {
+		return fmt.Errorf("license header pattern is required")
+	}
+	if _, err := regexp.Compile(c.Header); err != nil {
+		return fmt.Errorf("invalid license header pattern: %w", err)
+	}
+	return nil
+}
+
+// label returns the label added on violations
+func (c *LicenseConfig) label() string {
+	if c.Label != "" {
+		return c.Label
+	}
+	return defaultLicenseLabel
+}
+
+// licenseSection renders a checklist of files missing a license header
+func licenseSection(files []string) string {
+	var b strings.Builder
+	b.WriteString("## License Headers\n\n")
+	b.WriteString("New files without the required license header:\n\n")
+	for _, f := range files {
+		fmt.Fprintf(&b, "- [ ] Add a license header to `%s`\n", f)
+	}
+	return b.String()
+}
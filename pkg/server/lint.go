@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/analyze"
+	"github.com/saint0x/ggquick/pkg/sandbox"
+)
+
+// LintConfig configures the lint step for a repository
+type LintConfig struct {
+	Command string `json:"command"`           // e.g. "golangci-lint run ./..."
+	Timeout string `json:"timeout,omitempty"` // default 5m
+}
+
+// timeout returns the parsed lint timeout
+func (c *LintConfig) timeout() time.Duration {
+	if d, err := time.ParseDuration(c.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return 5 * time.Minute
+}
+
+// maxLintIssues caps the issues listed in the PR body
+const maxLintIssues = 50
+
+// runLint runs the configured lint command on the clone and returns the
+// issues on lines the branch added
+func (s *Server) runLint(ctx context.Context, jw *jobWorkspace, lint *LintConfig, diffs []analyze.FileDiff) ([]analyze.LintIssue, error) {
+	s.logger.Loading("🧹 Running linter...")
+	ws, err := jw.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := ws.Run(ctx, lint.timeout(), sandbox.GoEnv, "sh", "-c", lint.Command)
+	if err != nil {
+		return nil, err
+	}
+	if res.TimedOut {
+		return nil, fmt.Errorf("lint timed out after %s", lint.timeout())
+	}
+
+	issues := analyze.NewIssues(analyze.ParseLintOutput(res.Output), diffs)
+	s.logger.Info("🧹 %d new lint issue(s)", len(issues))
+	return issues, nil
+}
+
+// lintSection renders new lint issues as a collapsible section
+func lintSection(command string, issues []analyze.LintIssue) string {
+	var b strings.Builder
+	if len(issues) == 0 {
+		fmt.Fprintf(&b, "### Lint\n\n✅ No new issues from `%s`.\n", command)
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "### Lint\n\n<details>\n<summary>⚠️ %d new issue(s) from <code>%s</code></summary>\n\n", len(issues), command)
+	for i, issue := range issues {
+		if i == maxLintIssues {
+			fmt.Fprintf(&b, "- …and %d more\n", len(issues)-maxLintIssues)
+			break
+		}
+		fmt.Fprintf(&b, "- `%s:%d`: %s\n", issue.File, issue.Line, issue.Message)
+	}
+	b.WriteString("\n</details>\n")
+	return b.String()
+}
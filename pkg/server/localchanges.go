@@ -0,0 +1,66 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/saint0x/ggquick/pkg/ai"
+)
+
+// maxLocalFiles and maxLocalCommits bound what a push may report about
+// the pusher's clone, matching the client's limits
+const (
+	maxLocalFiles   = 1000
+	maxLocalCommits = 100
+)
+
+// localChanges is what the git hooks computed in the pusher's clone: the
+// branch's changed files and commit subjects, newest first. It stands in
+// for the GitHub compare when that fails.
+type localChanges struct {
+	Files   []string `json:"files,omitempty"`
+	Commits []string `json:"commits,omitempty"`
+}
+
+// newLocalChanges returns the changes a push reported, or nil for none
+func newLocalChanges(files, commits []string) *localChanges {
+	if len(files) == 0 && len(commits) == 0 {
+		return nil
+	}
+	return &localChanges{Files: files, Commits: commits}
+}
+
+// validateLocalChanges rejects file lists and subjects too long for the
+// prompt
+func validateLocalChanges(files, commits []string) error {
+	if len(files) > maxLocalFiles {
+		return fmt.Errorf("too many files, at most %d", maxLocalFiles)
+	}
+	if len(commits) > maxLocalCommits {
+		return fmt.Errorf("too many commits, at most %d", maxLocalCommits)
+	}
+	for _, f := range files {
+		if f == "" || strings.ContainsAny(f, "\r\n") {
+			return fmt.Errorf("invalid file %q", f)
+		}
+	}
+	for _, c := range commits {
+		if strings.ContainsAny(c, "\r\n") {
+			return fmt.Errorf("invalid commit subject %q", c)
+		}
+	}
+	return nil
+}
+
+// localContext adds what the pusher's clone reported to a prompt that
+// has no compare to work from: the languages of the changed files and
+// notes listing them and the commits
+func localContext(info *ai.RepoInfo, local *localChanges) {
+	if len(local.Files) > 0 {
+		info.Languages = ai.DetectLanguages(local.Files)
+		info.Notes = append(info.Notes, "Changed files, as reported by the pusher's clone: "+strings.Join(local.Files, ", "))
+	}
+	if len(local.Commits) > 0 {
+		info.Notes = append(info.Notes, "Commits on the branch, newest first:\n- "+strings.Join(local.Commits, "\n- "))
+	}
+}
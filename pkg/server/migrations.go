@@ -0,0 +1,67 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/saint0x/ggquick/pkg/analyze"
+)
+
+// labelMigration marks PRs that change the database schema
+const labelMigration = "migration"
+
+// migrationNote summarizes the migrations for the model
+func migrationNote(migrations []analyze.Migration) string {
+	files := make([]string, 0, len(migrations))
+	for _, m := range migrations {
+		files = append(files, m.File)
+	}
+	return "Database migrations in this branch: " + strings.Join(files, ", ")
+}
+
+// migrationSection renders the order migrations apply in, whether each
+// can be rolled back, and a rollout checklist
+func migrationSection(migrations []analyze.Migration) string {
+	var b strings.Builder
+	b.WriteString("## Migration Plan\n\n")
+
+	var destructive, irreversible []string
+	for _, m := range migrations {
+		if len(m.Destructive) > 0 {
+			destructive = append(destructive, "`"+m.File+"`")
+		}
+		if !m.Reversible {
+			irreversible = append(irreversible, "`"+m.File+"`")
+		}
+	}
+	if len(destructive) > 0 {
+		b.WriteString("> [!CAUTION]\n")
+		fmt.Fprintf(&b, "> Data may be lost or old code may break: %s\n\n", strings.Join(destructive, ", "))
+	}
+
+	b.WriteString("Applied in this order:\n\n")
+	b.WriteString("| # | Migration | Tool | Reversible | Notes |\n")
+	b.WriteString("|---|-----------|------|------------|-------|\n")
+	for i, m := range migrations {
+		reversible := "✅"
+		if !m.Reversible {
+			reversible = "❌ no down migration"
+		}
+		notes := strings.Join(m.Destructive, ", ")
+		fmt.Fprintf(&b, "| %d | `%s` | %s | %s | %s |\n", i+1, m.File, m.Tool, reversible, notes)
+	}
+
+	b.WriteString("\n### Rollout checklist\n\n")
+	b.WriteString("- [ ] Migrations are compatible with the code currently deployed, so they can run before the release\n")
+	if len(destructive) > 0 {
+		b.WriteString("- [ ] Affected tables are backed up, and nothing still reads dropped or renamed columns\n")
+	}
+	if len(irreversible) > 0 {
+		fmt.Fprintf(&b, "- [ ] A manual rollback plan exists for %s\n", strings.Join(irreversible, ", "))
+	}
+	if len(irreversible) < len(migrations) {
+		b.WriteString("- [ ] Down migrations were run against a copy of production data\n")
+	}
+	b.WriteString("- [ ] Long-running locks were checked on large tables\n")
+	return b.String()
+}
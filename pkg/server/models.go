@@ -0,0 +1,80 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/analyze"
+)
+
+// ModelRule routes matching jobs to a list of models. Conditions left
+// empty match every job.
+type ModelRule struct {
+	MinLines int      `json:"min_lines,omitempty"` // changes of at least this many lines
+	MaxLines int      `json:"max_lines,omitempty"` // changes of at most this many lines
+	Tier     string   `json:"tier,omitempty"`      // repositories of this tier, like critical
+	Models   []string `json:"models"`              // tried in order, the next on provider errors
+}
+
+// matches reports whether the rule covers a change to a repository
+func (r ModelRule) matches(tier string, lines int) bool {
+	if r.Tier != "" && !strings.EqualFold(r.Tier, tier) {
+		return false
+	}
+	if r.MinLines > 0 && lines < r.MinLines {
+		return false
+	}
+	return r.MaxLines <= 0 || lines <= r.MaxLines
+}
+
+// validateModelRules checks every rule names a model
+func validateModelRules(rules []ModelRule) error {
+	for i, rule := range rules {
+		if len(rule.Models) == 0 {
+			return fmt.Errorf("model rule %d has no models", i+1)
+		}
+		for _, model := range rule.Models {
+			if strings.TrimSpace(model) == "" {
+				return fmt.Errorf("model rule %d has an empty model", i+1)
+			}
+		}
+		if rule.MaxLines > 0 && rule.MinLines > rule.MaxLines {
+			return fmt.Errorf("model rule %d has min_lines above max_lines", i+1)
+		}
+	}
+	return nil
+}
+
+// routeModels returns the models for a change from the first matching
+// rule of the repository, then of its org, or nil for the default
+func (s *Server) routeModels(config *Config, lines int) []string {
+	rules := config.ModelRules
+	if org := s.org(config.Owner); org != nil {
+		rules = append(rules[:len(rules):len(rules)], org.ModelRules...)
+	}
+	return routeRules(rules, config.Tier, lines)
+}
+
+// routeRules returns the models of the first rule matching a change, or
+// nil when none does
+func routeRules(rules []ModelRule, tier string, lines int) []string {
+	for _, rule := range rules {
+		if rule.matches(tier, lines) {
+			return rule.Models
+		}
+	}
+	return nil
+}
+
+// changedLines counts the lines a change touches, leaving out lockfiles
+// and generated files so they don't make a small change look large
+func changedLines(files []*github.CommitFile) int {
+	lines := 0
+	for _, f := range files {
+		if analyze.Mechanical(f.GetFilename(), f.GetPatch()) == "" {
+			lines += f.GetAdditions() + f.GetDeletions()
+		}
+	}
+	return lines
+}
@@ -0,0 +1,304 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/nats"
+)
+
+// JetStream streams the NATS backend keeps its data in
+const (
+	natsQueueStream = "GGQUICK_QUEUE" // one subject per lane
+	natsStateStream = "GGQUICK_STATE" // locks and leases, latest value per key
+	natsJobStream   = "GGQUICK_JOBS"  // job records and cancel flags, for jobRecordTTL
+)
+
+// natsMaxTakes bounds how often one Pop retries after another replica
+// took the task it picked
+const natsMaxTakes = 5
+
+// natsBackend keeps the queue in a JetStream stream, one subject per
+// lane, and takes a task by deleting it: of replicas deleting the same
+// message only one succeeds. Locks, leases and job records are the last
+// message on their subject, changed with compare-and-set publishes, and
+// expire by the replicas' clocks.
+type natsBackend struct {
+	client *nats.Client
+
+	mu    sync.Mutex
+	ready bool
+
+	wakeMu sync.Mutex
+	// wake is closed and replaced when a task is queued on any replica
+	wake chan struct{}
+}
+
+// newNATSBackend connects to the server at rawURL
+func newNATSBackend(rawURL string) (*natsBackend, error) {
+	client, err := nats.New(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return &natsBackend{client: client, wake: make(chan struct{})}, nil
+}
+
+// setup creates the streams and watches for queued tasks, once
+func (b *natsBackend) setup(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.ready {
+		return nil
+	}
+	streams := []nats.StreamConfig{
+		{Name: natsQueueStream, Subjects: []string{"ggquick.queue.*"}},
+		{Name: natsStateStream, Subjects: []string{"ggquick.lock.*", "ggquick.leader.*"}, MaxMsgsPerSubject: 1},
+		{Name: natsJobStream, Subjects: []string{"ggquick.job.*", "ggquick.cancel.*"}, MaxMsgsPerSubject: 1, MaxAge: jobRecordTTL},
+	}
+	for _, stream := range streams {
+		if err := b.client.AddStream(ctx, stream); err != nil {
+			return err
+		}
+	}
+	err := b.client.Subscribe("ggquick.queue.*", func([]byte) {
+		b.wakeMu.Lock()
+		close(b.wake)
+		b.wake = make(chan struct{})
+		b.wakeMu.Unlock()
+	})
+	if err != nil {
+		return err
+	}
+	b.ready = true
+	return nil
+}
+
+// waker returns the channel closed when the next task is queued
+func (b *natsBackend) waker() <-chan struct{} {
+	b.wakeMu.Lock()
+	defer b.wakeMu.Unlock()
+	return b.wake
+}
+
+// natsToken makes key usable as one token of a subject
+func natsToken(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+func (b *natsBackend) laneSubject(priority string) string {
+	return "ggquick.queue." + priority
+}
+
+func (b *natsBackend) Push(ctx context.Context, priority string, task []byte) error {
+	if err := b.setup(ctx); err != nil {
+		return fmt.Errorf("failed to queue job: %w", err)
+	}
+	if _, err := b.client.Publish(ctx, b.laneSubject(priority), task, -1); err != nil {
+		return fmt.Errorf("failed to queue job: %w", err)
+	}
+	return nil
+}
+
+func (b *natsBackend) Pop(ctx context.Context, lanes []string, wait, maxAge time.Duration) ([]byte, string, error) {
+	if len(lanes) == 0 {
+		time.Sleep(wait)
+		return nil, "", nil
+	}
+	if err := b.setup(ctx); err != nil {
+		return nil, "", fmt.Errorf("failed to pop queue: %w", err)
+	}
+
+	deadline := time.After(wait)
+	for {
+		// Taken before looking so a task queued meanwhile still wakes us
+		wake := b.waker()
+		task, lane, err := b.take(ctx, lanes, maxAge)
+		if err != nil || task != nil {
+			return task, lane, err
+		}
+		select {
+		case <-wake:
+		case <-deadline:
+			return nil, "", nil
+		case <-ctx.Done():
+			return nil, "", ctx.Err()
+		}
+	}
+}
+
+// take claims the task Pop should run next, or returns nil when the lanes
+// are empty. Starved lanes go first, oldest head wins, then lanes in
+// order.
+func (b *natsBackend) take(ctx context.Context, lanes []string, maxAge time.Duration) ([]byte, string, error) {
+	for attempt := 0; attempt < natsMaxTakes; attempt++ {
+		var next *nats.Msg
+		lane, starved := "", false
+		oldest := time.Now().Add(-maxAge)
+		for _, l := range lanes {
+			head, err := b.client.FirstMsg(ctx, natsQueueStream, b.laneSubject(l))
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to read queue: %w", err)
+			}
+			if head == nil {
+				continue
+			}
+			if head.Time.Before(oldest) {
+				next, lane, starved, oldest = head, l, true, head.Time
+			} else if next == nil && !starved {
+				next, lane = head, l
+			}
+		}
+		if next == nil {
+			return nil, "", nil
+		}
+		err := b.client.DeleteMsg(ctx, natsQueueStream, next.Seq)
+		if err == nil {
+			return next.Data, lane, nil
+		}
+		if _, lost := err.(*nats.APIError); !lost {
+			return nil, "", fmt.Errorf("failed to pop queue: %w", err)
+		}
+		// Another replica took it first
+	}
+	return nil, "", nil
+}
+
+// natsLease is the value of a lock or leader subject: who holds it and
+// until when. An empty value is a released lock.
+type natsLease struct {
+	holder string
+	until  time.Time
+}
+
+// readLease returns the lease on subject and the sequence to replace it
+// at, 0 when the subject has never been set
+func (b *natsBackend) readLease(ctx context.Context, subject string) (natsLease, int64, error) {
+	msg, err := b.client.LastMsg(ctx, natsStateStream, subject)
+	if err != nil || msg == nil {
+		return natsLease{}, 0, err
+	}
+	holder, until, _ := strings.Cut(string(msg.Data), " ")
+	nanos, _ := strconv.ParseInt(until, 10, 64)
+	return natsLease{holder: holder, until: time.Unix(0, nanos)}, int64(msg.Seq), nil
+}
+
+// writeLease replaces the lease on subject if it is still at seq,
+// reporting false when another replica changed it first
+func (b *natsBackend) writeLease(ctx context.Context, subject string, lease natsLease, seq int64) (uint64, bool, error) {
+	value := ""
+	if lease.holder != "" {
+		value = lease.holder + " " + strconv.FormatInt(lease.until.UnixNano(), 10)
+	}
+	newSeq, err := b.client.Publish(ctx, subject, []byte(value), seq)
+	if nats.IsAPIError(err, nats.ErrCodeWrongLastSequence) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return newSeq, true, nil
+}
+
+func (b *natsBackend) Lock(ctx context.Context, key string, ttl time.Duration) (func(), error) {
+	if err := b.setup(ctx); err != nil {
+		return nil, fmt.Errorf("failed to take lock: %w", err)
+	}
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+	subject := "ggquick.lock." + natsToken(key)
+
+	current, seq, err := b.readLease(ctx, subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take lock: %w", err)
+	}
+	if current.holder != "" && time.Now().Before(current.until) {
+		return nil, nil
+	}
+	held, ok, err := b.writeLease(ctx, subject, natsLease{holder: token, until: time.Now().Add(ttl)}, seq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take lock: %w", err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		// Only releases the lock if nobody took it over after it expired
+		b.writeLease(ctx, subject, natsLease{}, int64(held))
+	}, nil
+}
+
+func (b *natsBackend) Lead(ctx context.Context, key, id string, ttl time.Duration) (bool, error) {
+	if err := b.setup(ctx); err != nil {
+		return false, fmt.Errorf("failed to claim leadership: %w", err)
+	}
+	subject := "ggquick.leader." + natsToken(key)
+	current, seq, err := b.readLease(ctx, subject)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim leadership: %w", err)
+	}
+	if current.holder != "" && current.holder != id && time.Now().Before(current.until) {
+		return false, nil
+	}
+	_, ok, err := b.writeLease(ctx, subject, natsLease{holder: id, until: time.Now().Add(ttl)}, seq)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim leadership: %w", err)
+	}
+	return ok, nil
+}
+
+func (b *natsBackend) SaveJob(ctx context.Context, id string, data []byte) error {
+	if err := b.setup(ctx); err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+	if _, err := b.client.Publish(ctx, "ggquick.job."+natsToken(id), data, -1); err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+	return nil
+}
+
+func (b *natsBackend) LoadJob(ctx context.Context, id string) ([]byte, error) {
+	if err := b.setup(ctx); err != nil {
+		return nil, fmt.Errorf("failed to load job: %w", err)
+	}
+	msg, err := b.client.LastMsg(ctx, natsJobStream, "ggquick.job."+natsToken(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job: %w", err)
+	}
+	if msg == nil || len(msg.Data) == 0 {
+		return nil, nil
+	}
+	return msg.Data, nil
+}
+
+func (b *natsBackend) Cancel(ctx context.Context, id string) error {
+	if err := b.setup(ctx); err != nil {
+		return fmt.Errorf("failed to cancel job: %w", err)
+	}
+	if _, err := b.client.Publish(ctx, "ggquick.cancel."+natsToken(id), []byte("1"), -1); err != nil {
+		return fmt.Errorf("failed to cancel job: %w", err)
+	}
+	return nil
+}
+
+func (b *natsBackend) Cancelled(ctx context.Context, id string) (bool, error) {
+	if err := b.setup(ctx); err != nil {
+		return false, fmt.Errorf("failed to check cancellation: %w", err)
+	}
+	msg, err := b.client.LastMsg(ctx, natsJobStream, "ggquick.cancel."+natsToken(id))
+	if err != nil {
+		return false, fmt.Errorf("failed to check cancellation: %w", err)
+	}
+	return msg != nil, nil
+}
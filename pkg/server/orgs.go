@@ -0,0 +1,105 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// OrgConfig holds settings shared by every repository of an owner
+type OrgConfig struct {
+	Name string `json:"name"`
+	// Checklist items are appended to every generated PR body
+	Checklist []string `json:"checklist,omitempty"`
+
+	// Variables such as team, oncall_url or runbook_url are available to
+	// prompt, footer and checklist templates as {{.name}}
+	Variables map[string]string `json:"variables,omitempty"`
+	// Prompt is extra guidance for the model on every repository
+	Prompt string `json:"prompt,omitempty"`
+	// Footer is appended to PR bodies of repositories without their own
+	Footer string `json:"footer,omitempty"`
+	// ModelRules pick models for jobs after the repository's own rules
+	ModelRules []ModelRule `json:"model_rules,omitempty"`
+}
+
+// validate checks the org config is well formed
+func (o *OrgConfig) validate() error {
+	if o.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	for _, item := range o.Checklist {
+		if strings.TrimSpace(item) == "" {
+			return fmt.Errorf("checklist items must not be empty")
+		}
+	}
+	if err := validateVariables(o.Variables); err != nil {
+		return err
+	}
+	if err := validateModelRules(o.ModelRules); err != nil {
+		return err
+	}
+	return validateTemplates(o.Prompt, o.Footer, o.Checklist)
+}
+
+// checklist returns the checklist for a repository: its own override,
+// nothing when skipped, or its org's
+func (s *Server) checklist(config *Config) []string {
+	if config.SkipChecklist {
+		return nil
+	}
+	if len(config.Checklist) > 0 {
+		return config.Checklist
+	}
+	if org := s.org(config.Owner); org != nil {
+		return org.Checklist
+	}
+	return nil
+}
+
+// checklistSection renders checklist items as unchecked tasks
+func checklistSection(items []string) string {
+	var b strings.Builder
+	b.WriteString("## Checklist\n\n")
+	for _, item := range items {
+		fmt.Fprintf(&b, "- [ ] %s\n", strings.TrimSpace(item))
+	}
+	return b.String()
+}
+
+// handleOrgs lists (GET) or sets (POST) org-level configuration
+func (s *Server) handleOrgs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.mu.RLock()
+		orgs := make([]*OrgConfig, 0, len(s.orgs))
+		for _, org := range s.orgs {
+			orgs = append(orgs, org)
+		}
+		s.mu.RUnlock()
+		sort.Slice(orgs, func(i, j int) bool { return orgs[i].Name < orgs[j].Name })
+		writeJSON(w, http.StatusOK, orgs)
+
+	case http.MethodPost:
+		if !requireAdmin(w, r) {
+			return
+		}
+		var org OrgConfig
+		if err := decodeJSON(w, r, maxBodySize, &org); err != nil {
+			s.logger.Error("❌ Failed to decode org config: %v", err)
+			return
+		}
+
+		s.mu.Lock()
+		s.orgs[org.Name] = &org
+		s.mu.Unlock()
+		s.persist()
+
+		s.logger.Success("✅ Stored org config for %s", org.Name)
+		writeJSON(w, http.StatusOK, org)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/analyze"
+)
+
+// SectionPeople lists people who know the changed files
+const SectionPeople = "people"
+
+// Ownership limits: files whose history is read, commits read per file
+// and people listed
+const (
+	maxOwnershipFiles   = 10
+	maxOwnershipCommits = 30
+	maxOwnershipPeople  = 5
+)
+
+// contributor is someone who committed to the changed files before
+type contributor struct {
+	Login   string
+	Commits int
+	Files   []string
+}
+
+// peopleWithContext returns who committed most to the branch's largest
+// changed source files on base, leaving out the author and bots
+func (s *Server) peopleWithContext(ctx context.Context, config *Config, job *Job, base string, files []*github.CommitFile) []contributor {
+	var paths []*github.CommitFile
+	for _, f := range files {
+		if f.GetStatus() != "added" && analyze.Relevance(f.GetFilename(), f.GetPatch()) == analyze.TierSource {
+			paths = append(paths, f)
+		}
+	}
+	sort.SliceStable(paths, func(i, j int) bool { return paths[i].GetChanges() > paths[j].GetChanges() })
+	if len(paths) > maxOwnershipFiles {
+		paths = paths[:maxOwnershipFiles]
+	}
+
+	owner, name := config.prTarget()
+	people := make(map[string]*contributor)
+	for _, f := range paths {
+		path := f.GetFilename()
+		if prev := f.GetPreviousFilename(); prev != "" {
+			path = prev
+		}
+		fetchCtx, cancel := context.WithTimeout(ctx, config.Timeouts.github())
+		commits, err := s.github.GetFileCommits(fetchCtx, owner, name, path, base, maxOwnershipCommits)
+		cancel()
+		if err != nil {
+			s.logger.Warning("Skipped history of %s: %v", path, err)
+			continue
+		}
+		for _, c := range commits {
+			login := c.GetAuthor().GetLogin()
+			if login == "" || strings.EqualFold(login, job.Author) || c.GetAuthor().GetType() == "Bot" || strings.HasSuffix(login, "[bot]") {
+				continue
+			}
+			p := people[login]
+			if p == nil {
+				p = &contributor{Login: login}
+				people[login] = p
+			}
+			p.Commits++
+			if !contains(p.Files, f.GetFilename()) {
+				p.Files = append(p.Files, f.GetFilename())
+			}
+		}
+	}
+
+	ranked := make([]contributor, 0, len(people))
+	for _, p := range people {
+		ranked = append(ranked, *p)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if len(ranked[i].Files) != len(ranked[j].Files) {
+			return len(ranked[i].Files) > len(ranked[j].Files)
+		}
+		if ranked[i].Commits != ranked[j].Commits {
+			return ranked[i].Commits > ranked[j].Commits
+		}
+		return ranked[i].Login < ranked[j].Login
+	})
+	if len(ranked) > maxOwnershipPeople {
+		ranked = ranked[:maxOwnershipPeople]
+	}
+	return ranked
+}
+
+// peopleSection renders the people with context. Profiles are linked
+// rather than mentioned so nobody is notified.
+func peopleSection(people []contributor) string {
+	var b strings.Builder
+	b.WriteString("### People with Context\n\n")
+	b.WriteString("Frequent committers to the changed files, who may make good reviewers:\n\n")
+	for _, p := range people {
+		listed := p.Files
+		if len(listed) > 3 {
+			listed = listed[:3]
+		}
+		fmt.Fprintf(&b, "- [%s](https://github.com/%s): %d commit(s) to `%s`", p.Login, p.Login, p.Commits, strings.Join(listed, "`, `"))
+		if more := len(p.Files) - len(listed); more > 0 {
+			fmt.Fprintf(&b, " and %d more", more)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	ghclient "github.com/saint0x/ggquick/pkg/github"
+)
+
+const (
+	pendingRecheck = 5 * time.Minute // how often waiting branches are looked up
+	pendingExpiry  = time.Hour       // when a branch that never shows up fails
+)
+
+// pendingJob is a job waiting for its branch to be pushed
+type pendingJob struct {
+	config    *Config
+	job       *Job
+	commitMsg string
+	since     time.Time
+	checked   time.Time
+}
+
+// pendingJobs holds waiting jobs by repository and branch
+type pendingJobs struct {
+	mu   sync.Mutex
+	jobs map[string]*pendingJob
+}
+
+// pendingKey identifies a branch across repositories
+func pendingKey(owner, repo, branch string) string {
+	return owner + "/" + repo + ":" + branch
+}
+
+// branchMissing reports whether a GitHub error means the head branch
+// does not exist on the remote yet. Other 404s, such as for a repository
+// the token can't see, are real errors.
+func branchMissing(err error) bool {
+	if errors.Is(err, ghclient.ErrBranchNotFound) {
+		return true
+	}
+	var ghErr *github.ErrorResponse
+	if !errors.As(err, &ghErr) || ghErr.Response == nil || ghErr.Response.StatusCode != http.StatusUnprocessableEntity {
+		return false
+	}
+	for _, e := range ghErr.Errors {
+		if e.Field == "head" && e.Code == "invalid" {
+			return true
+		}
+	}
+	return false
+}
+
+// parkJob marks a job as waiting for its branch to reach GitHub. It is
+// resumed by the next push to the branch or a periodic recheck.
+func (s *Server) parkJob(config *Config, job *Job, commitMsg string) {
+	now := time.Now()
+	s.pending.mu.Lock()
+	key := pendingKey(config.Owner, config.Name, job.Branch)
+	if p, ok := s.pending.jobs[key]; ok && p.job.ID == job.ID {
+		p.checked = now
+	} else {
+		s.pending.jobs[key] = &pendingJob{config: config, job: job, commitMsg: commitMsg, since: now, checked: now}
+	}
+	s.pending.mu.Unlock()
+
+	s.jobs.update(job.ID, func(j *Job) { j.Status = JobWaiting })
+	s.events.publish(jobEvent(EventJobWaiting, job, "branch not on GitHub yet, waiting for push"))
+	s.logger.Info("⏳ Branch %s not pushed yet, job %s will resume on push", job.Branch, job.ID)
+}
+
+// jobFor returns the waiting job for a branch, updated to sha, or a new
+// job if none is waiting, so repeated hooks don't duplicate work
+func (s *Server) jobFor(config *Config, branch, sha string) (*Job, bool) {
+	s.pending.mu.Lock()
+	key := pendingKey(config.Owner, config.Name, branch)
+	p, ok := s.pending.jobs[key]
+	delete(s.pending.jobs, key)
+	s.pending.mu.Unlock()
+
+	if !ok {
+		return s.jobs.create(config.Owner, config.Name, branch, sha), false
+	}
+	s.jobs.update(p.job.ID, func(j *Job) {
+		j.Status = JobQueued
+		if sha != "" {
+			j.SHA = sha
+		}
+	})
+	return p.job, true
+}
+
+// recheckPending retries waiting jobs whose branch may have been pushed
+// without a webhook reaching us, and fails those that expired
+func (s *Server) recheckPending(ctx context.Context) {
+	now := time.Now()
+	var due []*pendingJob
+	s.pending.mu.Lock()
+	for key, p := range s.pending.jobs {
+		if now.Sub(p.checked) < pendingRecheck {
+			continue
+		}
+		delete(s.pending.jobs, key)
+		due = append(due, p)
+	}
+	s.pending.mu.Unlock()
+
+	for _, p := range due {
+		if now.Sub(p.since) >= pendingExpiry {
+			s.failJob(p.job, fmt.Errorf("branch %s was not pushed within %s", p.job.Branch, pendingExpiry))
+			continue
+		}
+
+		_, err := s.github.CompareBranches(ctx, p.config.Owner, p.config.Name, p.config.baseBranch(p.job.Branch), p.job.Branch)
+		if branchMissing(err) {
+			s.pending.mu.Lock()
+			p.checked = now
+			key := pendingKey(p.config.Owner, p.config.Name, p.job.Branch)
+			if _, taken := s.pending.jobs[key]; !taken {
+				s.pending.jobs[key] = p
+			}
+			s.pending.mu.Unlock()
+			continue
+		}
+
+		s.logger.Branch("🌿 Branch %s is now on GitHub, resuming job %s", p.job.Branch, p.job.ID)
+		done := s.enqueueJob(ctx, p.config, p.job, p.commitMsg, PriorityNormal)
+		go func(id string) {
+			if err := <-done; err != nil {
+				s.logger.Error("❌ Failed to resume job %s: %v", id, err)
+			}
+		}(p.job.ID)
+	}
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/sandbox"
+)
+
+// SectionPerformance asks for benchmarks when performance-critical code
+// changes
+const SectionPerformance = "performance"
+
+// maxPerformanceFiles caps the changed files listed
+const maxPerformanceFiles = 20
+
+// PerformanceConfig marks performance-critical paths. PRs changing them
+// get a section asking for benchmark results.
+type PerformanceConfig struct {
+	// Paths are directory prefixes ending in "/" or globs
+	Paths []string `json:"paths"`
+	// Bench runs go test -bench on the changed Go packages in a clone
+	// and pastes the results. It runs the branch's code, so only an admin
+	// can turn it on.
+	Bench bool `json:"bench,omitempty"`
+	// Timeout bounds the benchmark run, 5m by default
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// validate checks the paths are globs and the timeout parses
+func (c *PerformanceConfig) validate() error {
+	if len(c.Paths) == 0 {
+		return fmt.Errorf("performance needs paths")
+	}
+	for _, p := range c.Paths {
+		if _, err := path.Match(p, ""); err != nil || p == "" {
+			return fmt.Errorf("invalid performance path %q", p)
+		}
+	}
+	if c.Timeout != "" {
+		if d, err := time.ParseDuration(c.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid performance timeout %q", c.Timeout)
+		}
+	}
+	return nil
+}
+
+// timeout returns the benchmark time limit
+func (c *PerformanceConfig) timeout() time.Duration {
+	if d, err := time.ParseDuration(c.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return 5 * time.Minute
+}
+
+// files returns the changed files under performance-critical paths
+func (c *PerformanceConfig) files(files []*github.CommitFile) []*github.CommitFile {
+	paths := ProtectedConfig{Paths: c.Paths}
+	var touched []*github.CommitFile
+	for _, f := range files {
+		if paths.matches(f.GetFilename()) {
+			touched = append(touched, f)
+		}
+	}
+	return touched
+}
+
+// benchPackages returns the packages of the changed Go files that still
+// exist, as go test arguments
+func benchPackages(files []*github.CommitFile) []string {
+	var pkgs []string
+	for _, f := range files {
+		if f.GetStatus() == "removed" || !strings.HasSuffix(f.GetFilename(), ".go") {
+			continue
+		}
+		pkg := "./" + path.Dir(f.GetFilename())
+		if pkg == "./." {
+			pkg = "."
+		}
+		if !contains(pkgs, pkg) {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	sort.Strings(pkgs)
+	return pkgs
+}
+
+// performanceReport returns the performance section when the branch
+// changes performance-critical paths, or ""
+func (s *Server) performanceReport(ctx context.Context, jw *jobWorkspace, c *PerformanceConfig, files []*github.CommitFile) string {
+	touched := c.files(files)
+	if len(touched) == 0 {
+		return ""
+	}
+	var bench *sandbox.Result
+	if c.Bench {
+		var err error
+		if bench, err = s.runBenchmarks(ctx, jw, c, touched); err != nil {
+			s.logger.Warning("Benchmarks skipped: %v", err)
+		}
+	}
+	return performanceSection(touched, bench)
+}
+
+// runBenchmarks runs the benchmarks of the changed packages on the clone
+func (s *Server) runBenchmarks(ctx context.Context, jw *jobWorkspace, c *PerformanceConfig, files []*github.CommitFile) (*sandbox.Result, error) {
+	pkgs := benchPackages(files)
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("no Go packages changed")
+	}
+	s.logger.Loading("⏱️ Running benchmarks in %d package(s)...", len(pkgs))
+	ws, err := jw.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !ws.Exists("go.mod") {
+		return nil, fmt.Errorf("not a Go module")
+	}
+	args := append([]string{"test", "-run", "^$", "-bench", ".", "-benchmem"}, pkgs...)
+	return ws.Run(ctx, c.timeout(), sandbox.GoEnv, "go", args...)
+}
+
+// performanceSection lists the performance-critical files changed and
+// asks for benchmarks, with the sandbox run's results when there are any
+func performanceSection(files []*github.CommitFile, bench *sandbox.Result) string {
+	var b strings.Builder
+	b.WriteString("### Performance Considerations\n\n")
+	b.WriteString("This PR changes performance-critical code:\n\n")
+	for i, f := range files {
+		if i == maxPerformanceFiles {
+			fmt.Fprintf(&b, "- ...and %d more\n", len(files)-i)
+			break
+		}
+		fmt.Fprintf(&b, "- `%s`\n", f.GetFilename())
+	}
+	if bench == nil {
+		b.WriteString("\nPlease add benchmark results from before and after the change, e.g. `go test -bench . -benchmem` with `benchstat`.\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "\nBenchmarks on this branch (%s, %s). Please compare them with the base branch:\n", checkStatus(bench), bench.Duration.Round(time.Second))
+	fmt.Fprintf(&b, "\n<details>\n<summary><code>%s</code> output</summary>\n\n```\n%s\n```\n</details>\n",
+		bench.Command, truncateOutput(bench.Output))
+	return b.String()
+}
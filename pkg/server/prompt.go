@@ -0,0 +1,219 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/ai"
+	"github.com/saint0x/ggquick/pkg/analyze"
+	"github.com/saint0x/ggquick/pkg/openai"
+	"github.com/saint0x/ggquick/pkg/pipeline"
+)
+
+// branchAnalysis is what preparing a job learned about its branch
+type branchAnalysis struct {
+	base       string
+	comp       *github.CommitsComparison // nil when the compare failed
+	infra      []analyze.InfraChange
+	migrations []analyze.Migration
+	apiSpecs   []apiSpecChanges
+}
+
+// prepareRepoInfo builds the model's input for a job: templated
+// instructions, the branch's diff against its base fitted to the prompt,
+// languages and analysis notes. A branch that isn't pushed yet returns an
+// error for which branchMissing is true.
+func (s *Server) prepareRepoInfo(ctx context.Context, config *Config, job *Job, commitMsg string) (ai.RepoInfo, *branchAnalysis, error) {
+	repoInfo := ai.RepoInfo{
+		Repo:          config.FullName(),
+		BranchName:    job.Branch,
+		CommitMessage: commitMsg,
+		Changes:       make(map[string]ai.Change),
+	}
+	if prompt := s.promptTemplate(config); prompt != "" {
+		instructions, err := expandTemplate("prompt", prompt, s.templateVars(config, job))
+		if err != nil {
+			s.logger.Warning("Prompt template skipped: %v", err)
+		}
+		repoInfo.Instructions = instructions
+	}
+	repoInfo.Instructions = withLanguage(repoInfo.Instructions, s.userLanguage(config, job.Author))
+
+	// Compare against the base branch for deterministic body sections
+	base := config.baseBranch(job.Branch)
+	fetchCtx, cancel := context.WithTimeout(ctx, config.Timeouts.github())
+	comp, err := s.github.CompareBranches(fetchCtx, config.Owner, config.Name, base, job.Branch)
+	cancel()
+	if branchMissing(err) {
+		return repoInfo, nil, err
+	}
+	if err != nil {
+		s.logger.Warning("Failed to compare branch: %v", err)
+	}
+
+	analysis := &branchAnalysis{base: base, comp: comp}
+	if comp != nil {
+		analysis.infra, analysis.migrations = fileContext(config, &repoInfo, comp.Files)
+		if len(repoInfo.Omitted) > 0 {
+			s.logger.Info("ℹ️ %d file(s) left out of the prompt diff", len(repoInfo.Omitted))
+		}
+	} else if job.local != nil {
+		s.logger.Info("ℹ️ Using the %d file(s) and %d commit(s) reported by the push", len(job.local.Files), len(job.local.Commits))
+		localContext(&repoInfo, job.local)
+	}
+	// Prompt for the languages the branch touches, else the repo's
+	if len(repoInfo.Languages) == 0 {
+		fetchCtx, cancel := context.WithTimeout(ctx, config.Timeouts.github())
+		langs, err := s.github.GetLanguages(fetchCtx, config.Owner, config.Name)
+		cancel()
+		if err != nil {
+			s.logger.Debug("Failed to get repository languages: %v", err)
+		} else {
+			repoInfo.Languages = ai.RepoLanguages(langs)
+		}
+	}
+	if len(repoInfo.Languages) > 0 {
+		s.logger.Info("🗣️ Languages: %s", strings.Join(repoInfo.Languages, ", "))
+	}
+	if comp != nil {
+		// Compare specs from the merge base so base branch changes don't show
+		specBase := base
+		if sha := comp.GetMergeBaseCommit().GetSHA(); sha != "" {
+			specBase = sha
+		}
+		fetchCtx, cancel := context.WithTimeout(ctx, config.Timeouts.github())
+		analysis.apiSpecs = s.apiChanges(fetchCtx, config, specBase, job.Branch, comp.Files)
+		cancel()
+		if len(analysis.apiSpecs) > 0 {
+			repoInfo.Notes = append(repoInfo.Notes, apiNote(analysis.apiSpecs))
+		}
+	}
+	return repoInfo, analysis, nil
+
+}
+
+// fileContext adds what the changed files alone tell the model: their
+// languages, the diff fitted to the budget, and notes on infrastructure
+// and migration changes, which it returns
+func fileContext(config *Config, info *ai.RepoInfo, files []*github.CommitFile) ([]analyze.InfraChange, []analyze.Migration) {
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		paths = append(paths, f.GetFilename())
+	}
+	info.Languages = ai.DetectLanguages(paths)
+	info.Diff, info.Omitted = promptDiff(config, files)
+
+	infra := analyze.InfraChanges(fileDiffs(files))
+	if len(infra) > 0 {
+		info.Notes = append(info.Notes, infraNote(infra))
+	}
+	migrations := analyze.Migrations(fileDiffs(files))
+	if len(migrations) > 0 {
+		info.Notes = append(info.Notes, migrationNote(migrations))
+	}
+	return infra, migrations
+}
+
+// promptRequest asks for the prompt a branch would be sent with
+type promptRequest struct {
+	Repo    string `json:"repo"`
+	Branch  string `json:"branch"`
+	Message string `json:"message"`
+	Author  string `json:"author,omitempty"`
+}
+
+// promptResult is the prompt with its estimated size and cost
+type promptResult struct {
+	Messages         []openai.ChatCompletionMessage `json:"messages"`
+	Models           []string                       `json:"models"`
+	PromptTokens     int                            `json:"prompt_tokens"`
+	CompletionTokens int                            `json:"completion_tokens"` // expected
+	Cost             float64                        `json:"cost,omitempty"`    // US dollars, for the first model
+	Omitted          []string                       `json:"omitted,omitempty"`
+	Notes            []string                       `json:"notes,omitempty"`
+}
+
+// handlePrompt builds the prompt a push of a branch would send, without
+// calling the model
+func (s *Server) handlePrompt(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req promptRequest
+	if err := decodeJSON(w, r, maxBodySize, &req); err != nil {
+		s.logger.Error("❌ Failed to decode prompt request: %v", err)
+		return
+	}
+	if req.Branch == "" {
+		http.Error(w, "branch is required", http.StatusBadRequest)
+		return
+	}
+	config := s.repoConfig(req.Repo)
+	if config == nil {
+		http.Error(w, "Repository not configured", http.StatusBadRequest)
+		return
+	}
+	if !s.allowRepo(w, config.FullName()) {
+		return
+	}
+
+	result, err := s.dryRunPrompt(r.Context(), config, req)
+	if branchMissing(err) {
+		http.Error(w, fmt.Sprintf("branch %s is not pushed", req.Branch), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.logger.Error("❌ Prompt dry run failed: %v", err)
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// dryRunPrompt prepares a branch like a job would, up to the model call.
+// Pre-generation hooks and per-file summaries are skipped since they run
+// commands or call the model.
+func (s *Server) dryRunPrompt(ctx context.Context, config *Config, req promptRequest) (*promptResult, error) {
+	job := &Job{Repo: config.FullName(), Branch: req.Branch, Author: req.Author}
+	repoInfo, analysis, err := s.prepareRepoInfo(ctx, config, job, strings.TrimSpace(req.Message))
+	if err != nil {
+		return nil, err
+	}
+	stages, err := s.jobStages(config)
+	if err != nil {
+		return nil, err
+	}
+	if err := pipeline.RunAnalyze(ctx, stages, &repoInfo); err != nil {
+		return nil, err
+	}
+
+	lines := 0
+	if analysis.comp != nil {
+		lines = changedLines(analysis.comp.Files)
+	}
+	models := s.routeModels(config, lines)
+	if len(models) == 0 {
+		models = []string{openai.GPT4}
+	}
+	messages := ai.PRMessages(repoInfo)
+	result := &promptResult{
+		Messages:         messages,
+		Models:           models,
+		PromptTokens:     ai.EstimateTokens(messages),
+		CompletionTokens: ai.ExpectedCompletionTokens,
+		Omitted:          repoInfo.Omitted,
+	}
+	result.Cost, _ = ai.EstimateCost(models[0], result.PromptTokens, result.CompletionTokens)
+	if config.Summaries != nil && config.Summaries.Enabled {
+		result.Notes = append(result.Notes, "per-file summaries are skipped in a dry run")
+	}
+	if s.execHooks != nil && s.execHooks.Commands[pipeline.PhasePreGeneration] != "" {
+		result.Notes = append(result.Notes, "pre-generation hooks are skipped in a dry run")
+	}
+	return result, nil
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// ProtectedConfig lists sensitive paths whose PRs need extra scrutiny
+type ProtectedConfig struct {
+	// Paths are directory prefixes ending in "/" (infra/) or globs
+	// (db/migrations/*.sql)
+	Paths []string `json:"paths"`
+	// Reviewers (user or org/team) are always requested on such PRs
+	Reviewers []string `json:"reviewers,omitempty"`
+}
+
+// matches reports whether file falls under a protected path
+func (c *ProtectedConfig) matches(file string) bool {
+	for _, p := range c.Paths {
+		if strings.HasSuffix(p, "/") {
+			if strings.HasPrefix(file, p) {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(p, file); ok || p == file {
+			return true
+		}
+	}
+	return false
+}
+
+// protectedFiles returns the changed files under protected paths
+func protectedFiles(c *ProtectedConfig, files []*github.CommitFile) []string {
+	if c == nil {
+		return nil
+	}
+	var touched []string
+	for _, f := range files {
+		if c.matches(f.GetFilename()) {
+			touched = append(touched, f.GetFilename())
+		}
+	}
+	return touched
+}
+
+// protectedSection renders the banner for PRs touching protected paths
+func protectedSection(files []string, reviewers []string) string {
+	var b strings.Builder
+	b.WriteString("> [!CAUTION]\n")
+	b.WriteString("> **This PR touches protected paths** and was opened as a draft.\n")
+	if len(reviewers) > 0 {
+		fmt.Fprintf(&b, "> Approval required from @%s.\n", strings.Join(reviewers, ", @"))
+	}
+	b.WriteString(">\n")
+	for i, f := range files {
+		if i == 20 {
+			fmt.Fprintf(&b, "> - ...and %d more\n", len(files)-i)
+			break
+		}
+		fmt.Fprintf(&b, "> - `%s`\n", f)
+	}
+	return b.String()
+}
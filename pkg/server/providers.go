@@ -0,0 +1,12 @@
+package server
+
+import "net/http"
+
+// handleProviders reports the health of each AI provider in failover order
+func (s *Server) handleProviders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.generator.Health())
+}
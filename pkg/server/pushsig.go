@@ -0,0 +1,70 @@
+package server
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// pushSignatureHeader and pushTimestampHeader carry the HMAC of a push
+// body made with the repository's push secret, and when it was made
+const (
+	pushSignatureHeader = "X-Ggquick-Signature"
+	pushTimestampHeader = "X-Ggquick-Timestamp"
+)
+
+// pushSignatureAge bounds the clock difference a signed push may show, so
+// a captured request can't be replayed later
+const pushSignatureAge = 5 * time.Minute
+
+// peekBody reads up to limit bytes of the request body and puts them
+// back, so the handler can both verify and decode it
+func peekBody(r *http.Request, limit int64) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+	return body, nil
+}
+
+// pushSignature returns the signature of body at timestamp
+func pushSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyPush checks a push to a repository with a push secret was signed
+// with it recently. It writes the error response and returns false when
+// the signature is missing, stale or wrong.
+func (s *Server) verifyPush(w http.ResponseWriter, r *http.Request, config *Config, body []byte) bool {
+	if config.PushSecret == "" {
+		return true
+	}
+	signature := r.Header.Get(pushSignatureHeader)
+	timestamp := r.Header.Get(pushTimestampHeader)
+	if signature == "" || timestamp == "" {
+		s.logger.Error("❌ Rejected unsigned push for %s", config.FullName())
+		http.Error(w, "Push signature required", http.StatusUnauthorized)
+		return false
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if age := time.Since(time.Unix(unix, 0)); err != nil || age > pushSignatureAge || age < -pushSignatureAge {
+		s.logger.Error("❌ Rejected push for %s with a stale signature", config.FullName())
+		http.Error(w, "Push signature expired", http.StatusUnauthorized)
+		return false
+	}
+	if !hmac.Equal([]byte(signature), []byte(pushSignature(config.PushSecret, timestamp, body))) {
+		s.logger.Error("❌ Rejected push for %s with a bad signature", config.FullName())
+		http.Error(w, "Invalid push signature", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
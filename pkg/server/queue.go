@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Job priorities, highest first
+const (
+	PriorityInteractive = "interactive" // a developer is waiting, e.g. ggquick notify
+	PriorityNormal      = "normal"      // webhooks and resumed jobs
+	PriorityBackground  = "background"  // backfill and stale branch sweeps
+)
+
+// priorityLanes orders the lanes workers take jobs from
+var priorityLanes = []string{PriorityInteractive, PriorityNormal, PriorityBackground}
+
+const (
+	// defaultWorkers is how many jobs run at once unless GGQUICK_WORKERS
+	// says otherwise
+	defaultWorkers = 4
+	// starvationAge is how long a job waits before it is taken ahead of
+	// higher lanes
+	starvationAge = 2 * time.Minute
+)
+
+// queuedJob is work waiting for a worker
+type queuedJob struct {
+	priority string
+	enqueued time.Time
+	run      func() error
+	done     chan error
+}
+
+// jobQueue runs jobs on a fixed pool of workers, highest priority first.
+// Background jobs never take the last free worker, so an interactive job
+// waits at most for one running job to finish.
+type jobQueue struct {
+	mu         sync.Mutex
+	cond       *sync.Cond
+	lanes      map[string][]*queuedJob
+	workers    int
+	background int // background jobs running
+}
+
+// newJobQueue starts a queue with n workers
+func newJobQueue(n int) *jobQueue {
+	q := &jobQueue{lanes: make(map[string][]*queuedJob), workers: n}
+	q.cond = sync.NewCond(&q.mu)
+	for i := 0; i < n; i++ {
+		go q.work()
+	}
+	return q
+}
+
+// queueWorkers reads GGQUICK_WORKERS, falling back to defaultWorkers
+func queueWorkers() int {
+	if n, err := strconv.Atoi(os.Getenv("GGQUICK_WORKERS")); err == nil && n > 0 {
+		return n
+	}
+	return defaultWorkers
+}
+
+// submit queues run at priority. The returned channel receives its error
+// once it has run.
+func (q *jobQueue) submit(priority string, run func() error) <-chan error {
+	if !contains(priorityLanes, priority) {
+		priority = PriorityNormal
+	}
+	job := &queuedJob{priority: priority, enqueued: time.Now(), run: run, done: make(chan error, 1)}
+	q.mu.Lock()
+	q.lanes[priority] = append(q.lanes[priority], job)
+	q.mu.Unlock()
+	q.cond.Signal()
+	return job.done
+}
+
+// enqueueJob runs a job on the worker pool at priority. With a queue
+// backend the job is handed to whichever replica is free and the returned
+// channel receives once it is queued.
+func (s *Server) enqueueJob(ctx context.Context, config *Config, job *Job, commitMsg, priority string) <-chan error {
+	s.jobs.update(job.ID, func(j *Job) { j.Priority = priority })
+	if s.backend != nil {
+		err := s.pushRemote(ctx, config, job, commitMsg, priority)
+		if err == nil {
+			done := make(chan error, 1)
+			done <- nil
+			return done
+		}
+		s.logger.Warning("Running job %s locally: %v", job.ID, err)
+	}
+	return s.queue.submit(priority, func() error {
+		return s.runJob(ctx, config, job, commitMsg)
+	})
+}
+
+// work runs queued jobs until the process exits
+func (q *jobQueue) work() {
+	for {
+		q.mu.Lock()
+		job := q.next()
+		for job == nil {
+			q.cond.Wait()
+			job = q.next()
+		}
+		background := job.priority == PriorityBackground
+		if background {
+			q.background++
+		}
+		q.mu.Unlock()
+
+		job.done <- job.run()
+
+		if background {
+			q.mu.Lock()
+			q.background--
+			q.mu.Unlock()
+			// A waiting background job may now fit
+			q.cond.Signal()
+		}
+	}
+}
+
+// next removes and returns the job to run, or nil when nothing may run.
+// The oldest job that has waited past starvationAge goes first; otherwise
+// lanes are taken in priority order. Called with mu held.
+func (q *jobQueue) next() *queuedJob {
+	pick := ""
+	var oldest time.Time
+	for _, lane := range priorityLanes {
+		if len(q.lanes[lane]) == 0 || !q.canRun(lane) {
+			continue
+		}
+		head := q.lanes[lane][0]
+		if time.Since(head.enqueued) >= starvationAge && (pick == "" || head.enqueued.Before(oldest)) {
+			pick, oldest = lane, head.enqueued
+		}
+	}
+	if pick == "" {
+		for _, lane := range priorityLanes {
+			if len(q.lanes[lane]) > 0 && q.canRun(lane) {
+				pick = lane
+				break
+			}
+		}
+	}
+	if pick == "" {
+		return nil
+	}
+	job := q.lanes[pick][0]
+	q.lanes[pick] = q.lanes[pick][1:]
+	return job
+}
+
+// canRun reports whether a job from lane may start now
+func (q *jobQueue) canRun(lane string) bool {
+	return lane != PriorityBackground || q.workers == 1 || q.background < q.workers-1
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// idleQueue returns a queue with no workers running, so tests can take
+// jobs with next themselves
+func idleQueue(workers int) *jobQueue {
+	q := &jobQueue{lanes: make(map[string][]*queuedJob), workers: workers}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// add queues a job at priority, enqueued age ago
+func (q *jobQueue) add(priority string, age time.Duration) *queuedJob {
+	job := &queuedJob{priority: priority, enqueued: time.Now().Add(-age)}
+	q.lanes[priority] = append(q.lanes[priority], job)
+	return job
+}
+
+func TestQueueTakesHigherLanesFirst(t *testing.T) {
+	q := idleQueue(4)
+	background := q.add(PriorityBackground, 0)
+	normal1 := q.add(PriorityNormal, 0)
+	interactive := q.add(PriorityInteractive, 0)
+	normal2 := q.add(PriorityNormal, 0)
+
+	for i, want := range []*queuedJob{interactive, normal1, normal2, background} {
+		if got := q.next(); got != want {
+			t.Fatalf("job %d: got %s job, want %s", i, lane(got), lane(want))
+		}
+	}
+	if got := q.next(); got != nil {
+		t.Errorf("empty queue returned a %s job", lane(got))
+	}
+}
+
+func TestQueueTakesStarvedJobsFirst(t *testing.T) {
+	q := idleQueue(4)
+	interactive := q.add(PriorityInteractive, 0)
+	normal := q.add(PriorityNormal, starvationAge/2)
+	background := q.add(PriorityBackground, starvationAge+time.Second)
+
+	for i, want := range []*queuedJob{background, interactive, normal} {
+		if got := q.next(); got != want {
+			t.Fatalf("job %d: got %s job, want %s", i, lane(got), lane(want))
+		}
+	}
+}
+
+func TestQueueKeepsAWorkerFromBackgroundJobs(t *testing.T) {
+	q := idleQueue(2)
+	q.background = 1
+	q.add(PriorityBackground, starvationAge+time.Second)
+	if got := q.next(); got != nil {
+		t.Fatal("background job took the last free worker")
+	}
+	normal := q.add(PriorityNormal, 0)
+	if got := q.next(); got != normal {
+		t.Fatalf("got %s job, want the normal one", lane(got))
+	}
+
+	// A single worker has nothing to keep free
+	single := idleQueue(1)
+	background := single.add(PriorityBackground, 0)
+	if got := single.next(); got != background {
+		t.Fatalf("single worker: got %s job, want the background one", lane(got))
+	}
+}
+
+func TestQueueRunsJobsInPriorityOrder(t *testing.T) {
+	q := newJobQueue(1)
+	release := make(chan struct{})
+	blocker := q.submit(PriorityNormal, func() error {
+		<-release
+		return nil
+	})
+
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) func() error {
+		return func() error {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+			return nil
+		}
+	}
+	// Queued while the only worker is busy
+	done := []<-chan error{
+		q.submit(PriorityBackground, record(PriorityBackground)),
+		q.submit(PriorityNormal, record(PriorityNormal)),
+		q.submit("unknown", record("unknown")),
+		q.submit(PriorityInteractive, record(PriorityInteractive)),
+	}
+	close(release)
+	<-blocker
+	for _, ch := range done {
+		if err := <-ch; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{PriorityInteractive, PriorityNormal, "unknown", PriorityBackground}
+	mu.Lock()
+	defer mu.Unlock()
+	for i := range want {
+		if i >= len(order) || order[i] != want[i] {
+			t.Fatalf("ran %v, want %v", order, want)
+		}
+	}
+}
+
+// lane names a job's lane for failure messages
+func lane(job *queuedJob) string {
+	if job == nil {
+		return "no"
+	}
+	return job.priority
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// defaultReconcileInterval is how often failed webhook deliveries are
+// looked for
+const defaultReconcileInterval = 15 * time.Minute
+
+// reconcileLookback is how far back the first check after a start looks.
+// GitHub keeps deliveries for three days.
+const reconcileLookback = 24 * time.Hour
+
+// redeliveryGrace leaves deliveries this recent alone, since GitHub may
+// still be retrying them
+const redeliveryGrace = time.Minute
+
+// maxRedeliveries bounds how often one delivery is sent again
+const maxRedeliveries = 3
+
+// reconcileInterval returns how often to look for failed webhook
+// deliveries, from GGQUICK_RECONCILE_INTERVAL. "off" or 0 disables it.
+func reconcileInterval() time.Duration {
+	v := os.Getenv("GGQUICK_RECONCILE_INTERVAL")
+	if v == "off" {
+		return 0
+	}
+	if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+		return d
+	}
+	return defaultReconcileInterval
+}
+
+// redelivery counts the times a delivery was sent again
+type redelivery struct {
+	count int
+	last  time.Time
+}
+
+// runDueReconciles checks every repository's webhook deliveries once the
+// interval has elapsed, so pushes GitHub couldn't deliver while the
+// server was down or failing still get processed
+func (s *Server) runDueReconciles(ctx context.Context) {
+	interval := reconcileInterval()
+	if interval == 0 || s.sandboxed != nil {
+		return
+	}
+	s.mu.RLock()
+	var due []*Config
+	for _, config := range s.configs {
+		if s.repos.allowed(config.FullName()) {
+			due = append(due, config)
+		}
+	}
+	s.mu.RUnlock()
+
+	now := time.Now()
+	for _, config := range due {
+		s.scheduler.mu.Lock()
+		last := s.scheduler.lastReconcile[config.FullName()]
+		ready := now.Sub(last) >= interval
+		if ready {
+			s.scheduler.lastReconcile[config.FullName()] = now
+		}
+		s.scheduler.mu.Unlock()
+
+		if !ready || !s.claimPeriodic(ctx, "reconcile:"+config.FullName(), interval) {
+			continue
+		}
+		// Overlap the last check so deliveries that failed during it count
+		since := now.Add(-reconcileLookback)
+		if !last.IsZero() {
+			since = last.Add(-interval)
+		}
+		s.reconcileDeliveries(ctx, config, since)
+	}
+}
+
+// reconcileDeliveries asks GitHub to redeliver the webhook deliveries for
+// config since the given time that never succeeded. Redeliveries keep
+// their delivery ID, so ones that were handled after all are ignored.
+func (s *Server) reconcileDeliveries(ctx context.Context, config *Config, since time.Time) int {
+	fetchCtx, cancel := context.WithTimeout(ctx, config.Timeouts.github())
+	failed, err := s.hooks.FailedDeliveries(fetchCtx, config.Owner, config.Name, since)
+	cancel()
+	if err != nil {
+		s.logger.Warning("Failed to check webhook deliveries for %s: %v", config.FullName(), err)
+		return 0
+	}
+
+	now := time.Now()
+	s.scheduler.mu.Lock()
+	for guid, r := range s.scheduler.redelivered {
+		if now.Sub(r.last) > reconcileLookback {
+			delete(s.scheduler.redelivered, guid)
+		}
+	}
+	s.scheduler.mu.Unlock()
+
+	sent := 0
+	for _, d := range failed {
+		if now.Sub(d.GetDeliveredAt().Time) < redeliveryGrace {
+			continue
+		}
+		guid := d.GetGUID()
+		s.scheduler.mu.Lock()
+		r := s.scheduler.redelivered[guid]
+		if r.count >= maxRedeliveries {
+			s.scheduler.mu.Unlock()
+			continue
+		}
+		s.scheduler.redelivered[guid] = redelivery{count: r.count + 1, last: now}
+		s.scheduler.mu.Unlock()
+
+		fetchCtx, cancel := context.WithTimeout(ctx, config.Timeouts.github())
+		err := s.hooks.Redeliver(fetchCtx, config.Owner, config.Name, d.GetID())
+		cancel()
+		if err != nil {
+			s.logger.Warning("Failed to redeliver %s event %s for %s: %v", d.GetEvent(), guid, config.FullName(), err)
+			continue
+		}
+		sent++
+	}
+	if sent > 0 {
+		s.logger.Info("🔁 Asked GitHub to redeliver %d failed webhook delivery(ies) for %s", sent, config.FullName())
+	}
+	return sent
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// recordedHeaders are the request headers a recording keeps. Credentials,
+// like the user key and admin token, are left out.
+var recordedHeaders = []string{
+	"Content-Type", "User-Agent",
+	"X-GitHub-Event", "X-GitHub-Delivery", "X-GitHub-Hook-ID",
+}
+
+// recording is one request as written to the record file, a JSON line
+type recording struct {
+	Time   time.Time         `json:"time"`
+	Method string            `json:"method"`
+	Path   string            `json:"path"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   string            `json:"body"`
+}
+
+// recorder appends requests to a file
+type recorder struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// RecordTo appends every /push and /webhook request to path, one JSON line
+// each, for "ggquick replay" to send again later
+func (s *Server) RecordTo(path string) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open record file: %w", err)
+	}
+	s.recorder = &recorder{file: file}
+	s.logger.Warning("Recording /push and /webhook payloads to %s, they may hold private code", path)
+	return nil
+}
+
+// write appends a request to the record file
+func (rec *recorder) write(r *http.Request, body []byte) error {
+	entry := recording{
+		Time:   time.Now().UTC(),
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Header: make(map[string]string),
+		Body:   string(body),
+	}
+	for _, name := range recordedHeaders {
+		if v := r.Header.Get(name); v != "" {
+			entry.Header[name] = v
+		}
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	_, err = rec.file.Write(append(line, '\n'))
+	return err
+}
+
+// recorded records requests of at most limit bytes before handling them.
+// Larger ones are passed on unrecorded for the handler to reject.
+func (s *Server) recorded(limit int64, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.recorder == nil || r.Method != http.MethodPost {
+			next(w, r)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+		if err != nil {
+			s.logger.Error("❌ Failed to read request body: %v", err)
+			http.Error(w, "Failed to read request", http.StatusBadRequest)
+			return
+		}
+		if int64(len(body)) <= limit {
+			if err := s.recorder.write(r, body); err != nil {
+				s.logger.Warning("Failed to record request: %v", err)
+			}
+		}
+		r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+		next(w, r)
+	}
+}
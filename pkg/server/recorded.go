@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/ai"
+	"github.com/saint0x/ggquick/pkg/analyze"
+)
+
+// DescribeRecorded generates a description for a recorded change the way a
+// job would, without GitHub: the same prompt diff, notes, reference check
+// and sanitizing. It is for evaluating prompts and models offline; a nil
+// config uses the defaults.
+func DescribeRecorded(ctx context.Context, gen *ai.Generator, config *Config, branch, message string, files []*github.CommitFile) (*ai.PRContent, error) {
+	if config == nil {
+		config = &Config{}
+	}
+	info := ai.RepoInfo{
+		Repo:          config.FullName(),
+		BranchName:    branch,
+		CommitMessage: message,
+		Changes:       make(map[string]ai.Change),
+	}
+	fileContext(config, &info, files)
+	info.Models = routeRules(config.ModelRules, config.Tier, changedLines(files))
+
+	content, err := gen.GeneratePR(ctx, info)
+	if err != nil {
+		return nil, err
+	}
+	if config.ReferenceCheck != ReferencesOff && len(files) > 0 {
+		mode := config.ReferenceCheck
+		if mode == "" {
+			mode = analyze.ReferencesStrike
+		}
+		content.Description, _ = analyze.CheckReferences(content.Description, fileDiffs(files), mode)
+	}
+	content.Description = sanitize(config, content.Description, message)
+	return content, nil
+}
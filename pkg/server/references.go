@@ -0,0 +1,75 @@
+package server
+
+import (
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/ai"
+	"github.com/saint0x/ggquick/pkg/analyze"
+)
+
+// ReferencesOff turns the reference check off
+const ReferencesOff = "off"
+
+// ReferenceStats counts how often descriptions from one model and prompt
+// version named files or symbols that weren't in the diff
+type ReferenceStats struct {
+	Model         string  `json:"model"`
+	PromptVersion string  `json:"prompt_version"`
+	Descriptions  int     `json:"descriptions"`
+	References    int     `json:"references"`
+	Unverified    int     `json:"unverified"`
+	Rate          float64 `json:"unverified_rate"` // unverified per reference
+}
+
+// checkReferences strikes or removes the files and symbols a generated
+// description names that the diff doesn't contain, and records the counts
+// on the job for the reference metric. It returns the counts.
+func (s *Server) checkReferences(config *Config, job *Job, content *ai.PRContent, files []*github.CommitFile) (references, unverified int) {
+	mode := config.ReferenceCheck
+	if mode == ReferencesOff || content.Model == "" || len(files) == 0 {
+		return 0, 0
+	}
+	if mode == "" {
+		mode = analyze.ReferencesStrike
+	}
+
+	description, refs := analyze.CheckReferences(content.Description, fileDiffs(files), mode)
+	for _, ref := range refs {
+		if !ref.Verified {
+			unverified++
+		}
+	}
+	content.Description = description
+	s.jobs.update(job.ID, func(j *Job) {
+		j.Model, j.PromptVersion = content.Model, content.PromptVersion
+		j.References, j.Unverified = len(refs), unverified
+	})
+	if unverified > 0 {
+		s.logger.Warning("%d of %d reference(s) not in the diff (%s, prompt %s)", unverified, len(refs), content.Model, content.PromptVersion)
+	} else {
+		s.logger.Debug("All %d reference(s) verified (%s, prompt %s)", len(refs), content.Model, content.PromptVersion)
+	}
+	return len(refs), unverified
+}
+
+// addReferenceStats adds a job's reference check to the stats for its
+// model and prompt version
+func addReferenceStats(stats []ReferenceStats, job Job) []ReferenceStats {
+	if job.PromptVersion == "" {
+		// Jobs without a reference check
+		return stats
+	}
+	i := 0
+	for i < len(stats) && (stats[i].Model != job.Model || stats[i].PromptVersion != job.PromptVersion) {
+		i++
+	}
+	if i == len(stats) {
+		stats = append(stats, ReferenceStats{Model: job.Model, PromptVersion: job.PromptVersion})
+	}
+	stats[i].Descriptions++
+	stats[i].References += job.References
+	stats[i].Unverified += job.Unverified
+	if stats[i].References > 0 {
+		stats[i].Rate = float64(stats[i].Unverified) / float64(stats[i].References)
+	}
+	return stats
+}
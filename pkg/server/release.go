@@ -0,0 +1,526 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/ai"
+)
+
+// Version bumps for a release
+const (
+	BumpMajor = "major"
+	BumpMinor = "minor"
+	BumpPatch = "patch"
+)
+
+// Errors that skip a scheduled release quietly
+var (
+	errNothingToRelease = errors.New("nothing to release")
+	errReleaseOpen      = errors.New("release PR already open")
+)
+
+// Ways to publish a merged release PR
+const (
+	PublishTag     = "tag"
+	PublishRelease = "release"
+)
+
+// releaseTTL stops a redelivered merge webhook publishing twice
+const releaseTTL = 24 * time.Hour
+
+// defaultBumpLabels compute the bump from PR labels unless configured
+var defaultBumpLabels = map[string]string{
+	"breaking":        BumpMajor,
+	"breaking-change": BumpMajor,
+	"feature":         BumpMinor,
+	"enhancement":     BumpMinor,
+	"bug":             BumpPatch,
+	"fix":             BumpPatch,
+}
+
+// ReleaseConfig opens "release vX.Y.Z" PRs merging a development branch
+// into the release branch, on demand or on a schedule
+type ReleaseConfig struct {
+	Enabled  bool   `json:"enabled"`            // open release PRs on a schedule
+	Interval string `json:"interval,omitempty"` // how often, default 168h
+	From     string `json:"from,omitempty"`     // branch released, default develop
+	To       string `json:"to,omitempty"`       // branch released to, default the default branch
+	Bump     string `json:"bump,omitempty"`     // when no PR label decides, default minor
+	// BumpLabels map PR labels to major, minor or patch. The largest bump
+	// among the included PRs wins.
+	BumpLabels map[string]string `json:"bump_labels,omitempty"`
+	// VersionFiles have the previous version replaced with the new one
+	VersionFiles []string `json:"version_files,omitempty"`
+	// Publish creates a tag, or a tag and a GitHub Release with the
+	// release notes, when the release PR merges
+	Publish string `json:"publish,omitempty"`
+}
+
+// validate checks the bumps, interval and publish mode
+func (c *ReleaseConfig) validate() error {
+	bumps := []string{BumpMajor, BumpMinor, BumpPatch}
+	if c.Bump != "" && !contains(bumps, c.Bump) {
+		return fmt.Errorf("invalid release bump %q", c.Bump)
+	}
+	for label, bump := range c.BumpLabels {
+		if !contains(bumps, bump) {
+			return fmt.Errorf("invalid bump %q for label %q", bump, label)
+		}
+	}
+	if c.Publish != "" && c.Publish != PublishTag && c.Publish != PublishRelease {
+		return fmt.Errorf("invalid release publish %q", c.Publish)
+	}
+	if c.Interval != "" {
+		if d, err := time.ParseDuration(c.Interval); err != nil || d <= 0 {
+			return fmt.Errorf("invalid release interval %q", c.Interval)
+		}
+	}
+	return nil
+}
+
+// interval returns the parsed schedule interval
+func (c *ReleaseConfig) interval() time.Duration {
+	if d, err := time.ParseDuration(c.Interval); err == nil && d > 0 {
+		return d
+	}
+	return 7 * 24 * time.Hour
+}
+
+// bump returns the largest bump the PRs' labels call for, falling back to
+// the configured bump
+func (c *ReleaseConfig) bump(prs []ai.ReleasePR) string {
+	labels := c.BumpLabels
+	if labels == nil {
+		labels = defaultBumpLabels
+	}
+	rank := map[string]int{BumpPatch: 1, BumpMinor: 2, BumpMajor: 3}
+	bump := ""
+	for _, pr := range prs {
+		for _, label := range pr.Labels {
+			for name, b := range labels {
+				if strings.EqualFold(name, label) && rank[b] > rank[bump] {
+					bump = b
+				}
+			}
+		}
+	}
+	if bump == "" {
+		bump = c.Bump
+	}
+	return bump
+}
+
+// from returns the branch being released
+func (c *ReleaseConfig) from() string {
+	if c == nil || c.From == "" {
+		return "develop"
+	}
+	return c.From
+}
+
+// semver is a parsed vMAJOR.MINOR.PATCH version
+type semver struct {
+	prefix string // "v" or ""
+	parts  [3]int
+}
+
+// parseSemver parses versions like v1.2.3 or 1.2.3. Pre-release and build
+// suffixes aren't releases and don't parse.
+func parseSemver(s string) (semver, bool) {
+	v := semver{}
+	if strings.HasPrefix(s, "v") {
+		v.prefix, s = "v", s[1:]
+	}
+	fields := strings.Split(s, ".")
+	if len(fields) != 3 {
+		return v, false
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v.parts[i] = n
+	}
+	return v, true
+}
+
+// String formats the version with its prefix
+func (v semver) String() string {
+	return fmt.Sprintf("%s%d.%d.%d", v.prefix, v.parts[0], v.parts[1], v.parts[2])
+}
+
+// bare formats the version without a prefix, as version files have it
+func (v semver) bare() string {
+	return strings.TrimPrefix(v.String(), v.prefix)
+}
+
+// less orders versions
+func (v semver) less(o semver) bool {
+	for i := range v.parts {
+		if v.parts[i] != o.parts[i] {
+			return v.parts[i] < o.parts[i]
+		}
+	}
+	return false
+}
+
+// bump returns the next version for a bump level
+func (v semver) bump(level string) semver {
+	switch level {
+	case BumpMajor:
+		v.parts = [3]int{v.parts[0] + 1, 0, 0}
+	case BumpPatch:
+		v.parts[2]++
+	default:
+		v.parts = [3]int{v.parts[0], v.parts[1] + 1, 0}
+	}
+	return v
+}
+
+// latestSemver returns the highest version among tags
+func latestSemver(tags []string) (semver, bool) {
+	var latest semver
+	found := false
+	for _, tag := range tags {
+		if v, ok := parseSemver(tag); ok && (!found || latest.less(v)) {
+			latest, found = v, true
+		}
+	}
+	return latest, found
+}
+
+// releaseRequest asks for a release PR. Version overrides the bump.
+type releaseRequest struct {
+	Repo    string `json:"repo"`
+	Version string `json:"version,omitempty"`
+	Bump    string `json:"bump,omitempty"`
+}
+
+// releaseResult is the release PR that was opened
+type releaseResult struct {
+	Number  int    `json:"number"`
+	URL     string `json:"url"`
+	Version string `json:"version"`
+	Branch  string `json:"branch"`
+	PRs     int    `json:"prs"` // PRs included
+}
+
+// handleRelease opens a release PR
+func (s *Server) handleRelease(w http.ResponseWriter, r *http.Request) {
+	s.logger.Loading("📥 Receiving release request...")
+
+	if r.Method != http.MethodPost {
+		s.logger.Error("❌ Invalid method: %s", r.Method)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	var req releaseRequest
+	if err := decodeJSON(w, r, maxBodySize, &req); err != nil {
+		s.logger.Error("❌ Failed to decode release request: %v", err)
+		return
+	}
+	if _, ok := parseSemver(req.Version); req.Version != "" && !ok {
+		http.Error(w, "Version must look like v1.2.3", http.StatusBadRequest)
+		return
+	}
+	if req.Bump != "" && !contains([]string{BumpMajor, BumpMinor, BumpPatch}, req.Bump) {
+		http.Error(w, "Bump must be major, minor or patch", http.StatusBadRequest)
+		return
+	}
+
+	config := s.repoConfig(req.Repo)
+	if config == nil {
+		s.logger.Error("❌ Repository not configured: %s", req.Repo)
+		http.Error(w, "Repository not configured", http.StatusBadRequest)
+		return
+	}
+	if !s.allowRepo(w, config.FullName()) {
+		return
+	}
+
+	result, err := s.openRelease(r.Context(), config, req.Version, req.Bump)
+	if err != nil {
+		s.logger.Error("❌ Release failed: %v", err)
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// runDueReleases opens release PRs for repositories whose interval has
+// elapsed
+func (s *Server) runDueReleases(ctx context.Context) {
+	s.mu.RLock()
+	var due []*Config
+	for _, config := range s.configs {
+		if config.Release != nil && config.Release.Enabled && s.repos.allowed(config.FullName()) {
+			due = append(due, config)
+		}
+	}
+	s.mu.RUnlock()
+
+	now := time.Now()
+	for _, config := range due {
+		s.scheduler.mu.Lock()
+		last := s.scheduler.lastRelease[config.FullName()]
+		ready := now.Sub(last) >= config.Release.interval()
+		if ready {
+			s.scheduler.lastRelease[config.FullName()] = now
+		}
+		s.scheduler.mu.Unlock()
+
+		if !ready || !s.claimPeriodic(ctx, "release:"+config.FullName(), config.Release.interval()) {
+			continue
+		}
+		if _, err := s.openRelease(ctx, config, "", ""); errors.Is(err, errNothingToRelease) || errors.Is(err, errReleaseOpen) {
+			s.logger.Debug("Scheduled release skipped for %s: %v", config.FullName(), err)
+		} else if err != nil {
+			s.logger.Error("❌ Scheduled release failed for %s: %v", config.FullName(), err)
+		}
+	}
+}
+
+// openRelease opens a PR from the release config's from branch to its to
+// branch, titled with the next version and described with release notes
+// generated from the merged PRs it includes. Version files are bumped on a
+// release branch first.
+func (s *Server) openRelease(ctx context.Context, config *Config, version, bump string) (*releaseResult, error) {
+	owner, name := config.prTarget()
+	release := config.Release
+	if release == nil {
+		release = &ReleaseConfig{}
+	}
+	fetchCtx, cancel := context.WithTimeout(ctx, config.Timeouts.github())
+	defer cancel()
+
+	from, to := release.from(), release.To
+	if to == "" {
+		var err error
+		if to, err = s.github.GetDefaultBranch(fetchCtx, owner, name); err != nil {
+			return nil, err
+		}
+	}
+	comp, err := s.github.CompareBranches(fetchCtx, owner, name, to, from)
+	if err != nil {
+		return nil, err
+	}
+	if comp.GetAheadBy() == 0 {
+		return nil, fmt.Errorf("%s has no commits missing from %s: %w", from, to, errNothingToRelease)
+	}
+
+	tags, err := s.github.GetTags(fetchCtx, owner, name)
+	if err != nil {
+		return nil, err
+	}
+	// One lookup per commit, so not on the shared fetch deadline
+	lookupCtx, cancelLookup := context.WithTimeout(ctx, config.Timeouts.github())
+	defer cancelLookup()
+	prs := s.releasePRs(lookupCtx, owner, name, comp.Commits)
+
+	previous, hasPrevious := latestSemver(tags)
+	next, _ := parseSemver(version)
+	if version == "" {
+		if bump == "" {
+			bump = release.bump(prs)
+		}
+		next = semver{prefix: "v"}.bump(bump)
+		if hasPrevious {
+			next = previous.bump(bump)
+		}
+	}
+
+	branch := "release-" + next.String()
+	head := from
+	if len(release.VersionFiles) > 0 {
+		head = branch
+	}
+	if open, err := s.github.HasOpenPR(fetchCtx, owner, name, head); err != nil {
+		return nil, err
+	} else if open {
+		return nil, fmt.Errorf("%s: %w", head, errReleaseOpen)
+	}
+
+	info := ai.ReleaseInfo{Repo: owner + "/" + name, Version: next.String(), PRs: prs}
+	if hasPrevious {
+		info.Previous = previous.String()
+	}
+	if prompt := s.promptTemplate(config); prompt != "" {
+		info.Instructions, _ = expandTemplate("prompt", prompt, s.templateVars(config, &Job{Branch: head}))
+	}
+
+	createCtx, cancelCreate := context.WithTimeout(ctx, config.Timeouts.createPR())
+	defer cancelCreate()
+	if head == branch {
+		files := s.bumpVersionFiles(fetchCtx, owner, name, from, release.VersionFiles, previous, next, hasPrevious)
+		if len(files) == 0 {
+			head = from
+		} else {
+			s.logger.Branch("🌿 Creating %s bumping %d version file(s)", branch, len(files))
+			if err := s.github.CommitFiles(createCtx, owner, name, from, branch, "Release "+next.String(), files); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	genCtx, cancelGen := context.WithTimeout(ctx, config.Timeouts.generate())
+	defer cancelGen()
+	content, err := s.generator.GenerateReleaseNotes(genCtx, info)
+	if err == nil {
+		err = s.checkContent(ctx, config, content.Title, content.Description)
+	}
+	if err != nil {
+		// The included PRs are still worth a release PR
+		s.logger.Warning("Release notes not generated: %v", err)
+		content = &ai.PRContent{Title: "release " + next.String()}
+	}
+
+	pr, err := s.github.CreatePullRequest(createCtx, owner, name, &github.NewPullRequest{
+		Title: github.String(content.Title),
+		Head:  github.String(head),
+		Base:  github.String(to),
+		Body:  github.String(releaseBody(sanitize(config, content.Description, ""), prs)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.events.publish(Event{Type: EventPRCreated, Owner: owner, Repo: name, Branch: head, PRURL: pr.GetHTMLURL(), Message: content.Title})
+	s.logger.Success("✅ Opened release PR #%d for %s", pr.GetNumber(), next)
+	return &releaseResult{Number: pr.GetNumber(), URL: pr.GetHTMLURL(), Version: next.String(), Branch: head, PRs: len(prs)}, nil
+}
+
+// releasePRs returns the merged PRs the commits came from, oldest first
+func (s *Server) releasePRs(ctx context.Context, owner, name string, commits []*github.RepositoryCommit) []ai.ReleasePR {
+	seen := make(map[int]bool)
+	var prs []ai.ReleasePR
+	for _, commit := range commits {
+		found, err := s.github.GetPRsForCommit(ctx, owner, name, commit.GetSHA())
+		if err != nil {
+			s.logger.Warning("Failed to find PRs for %s: %v", commit.GetSHA(), err)
+			continue
+		}
+		for _, pr := range found {
+			if pr.GetMergedAt().IsZero() || seen[pr.GetNumber()] {
+				continue
+			}
+			seen[pr.GetNumber()] = true
+			var labels []string
+			for _, label := range pr.Labels {
+				labels = append(labels, label.GetName())
+			}
+			prs = append(prs, ai.ReleasePR{Number: pr.GetNumber(), Title: pr.GetTitle(), Author: pr.GetUser().GetLogin(), Labels: labels})
+		}
+	}
+	sort.Slice(prs, func(i, j int) bool { return prs[i].Number < prs[j].Number })
+	return prs
+}
+
+// bumpVersionFiles returns the version files with the previous version
+// replaced by next. Files without the previous version are left alone.
+func (s *Server) bumpVersionFiles(ctx context.Context, owner, name, ref string, paths []string, previous, next semver, hasPrevious bool) map[string]string {
+	if !hasPrevious {
+		s.logger.Warning("No previous version tag, version files not bumped")
+		return nil
+	}
+	files := make(map[string]string)
+	for _, path := range paths {
+		content, err := s.github.GetFileContent(ctx, owner, name, path, ref)
+		if err != nil {
+			s.logger.Warning("Version file not bumped: %v", err)
+			continue
+		}
+		if !strings.Contains(content, previous.bare()) {
+			s.logger.Warning("Version file %s doesn't contain %s", path, previous.bare())
+			continue
+		}
+		files[path] = strings.ReplaceAll(content, previous.bare(), next.bare())
+	}
+	return files
+}
+
+// releaseBody appends links to the included PRs to the release notes
+func releaseBody(notes string, prs []ai.ReleasePR) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(notes))
+	b.WriteString("\n\n## Included PRs\n\n")
+	for _, pr := range prs {
+		fmt.Fprintf(&b, "- #%d %s (@%s)\n", pr.Number, pr.Title, pr.Author)
+	}
+	if len(prs) == 0 {
+		b.WriteString("No merged PRs found, only direct commits.\n")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// releaseVersion returns the version a merged release PR publishes
+func releaseVersion(release *ReleaseConfig, pr *github.PullRequest) (semver, bool) {
+	title, ok := strings.CutPrefix(pr.GetTitle(), "release ")
+	version, valid := parseSemver(strings.TrimSpace(title))
+	head := pr.GetHead().GetRef()
+	if !ok || !valid || (head != release.from() && head != "release-"+version.String()) {
+		return semver{}, false
+	}
+	return version, true
+}
+
+// publishRelease tags a merged release PR's merge commit and, when
+// configured, creates a GitHub Release with the PR's release notes. The
+// webhook payload only picks candidates: the PR is fetched from GitHub
+// again, and what gets tagged comes from there.
+func (s *Server) publishRelease(config *Config, event *github.PullRequest) {
+	release := config.Release
+	if release == nil || release.Publish == "" || event.GetMergedAt().IsZero() {
+		return
+	}
+	if _, ok := releaseVersion(release, event); !ok {
+		return
+	}
+
+	go func() {
+		owner, name := config.prTarget()
+		ctx, cancel := context.WithTimeout(context.Background(), config.Timeouts.github())
+		defer cancel()
+
+		pr, err := s.github.GetPullRequest(ctx, owner, name, event.GetNumber())
+		if err != nil {
+			s.logger.Error("❌ Failed to check release PR #%d of %s: %v", event.GetNumber(), config.FullName(), err)
+			return
+		}
+		version, ok := releaseVersion(release, pr)
+		if !pr.GetMerged() || !ok {
+			s.logger.Warning("Release PR #%d of %s isn't a merged release, not publishing", pr.GetNumber(), config.FullName())
+			return
+		}
+
+		unlock, err := s.tryLock(ctx, fmt.Sprintf("publish:%s/%s:%s", owner, name, version), releaseTTL)
+		if err != nil || unlock == nil {
+			return
+		}
+		time.AfterFunc(releaseTTL, unlock)
+
+		sha := pr.GetMergeCommitSHA()
+		if release.Publish == PublishTag {
+			err = s.github.CreateTag(ctx, owner, name, version.String(), sha)
+		} else {
+			err = s.github.CreateRelease(ctx, owner, name, version.String(), sha, pr.GetBody())
+		}
+		if err != nil {
+			s.logger.Error("❌ Failed to publish %s for %s: %v", version, config.FullName(), err)
+			return
+		}
+		s.logger.Success("✅ Published %s of %s from #%d", version, config.FullName(), pr.GetNumber())
+	}()
+}
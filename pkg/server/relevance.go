@@ -0,0 +1,30 @@
+package server
+
+import (
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/ai"
+	"github.com/saint0x/ggquick/pkg/analyze"
+)
+
+// defaultDiffBudget is the bytes of patch sent to the model, about 6k
+// tokens
+const defaultDiffBudget = 24000
+
+// promptDiff fits the most relevant hunks of the change into the diff
+// budget: source first, then tests, lockfiles and generated files. It
+// returns nothing when the budget is negative.
+func promptDiff(config *Config, files []*github.CommitFile) ([]ai.DiffChunk, []string) {
+	budget := config.DiffBudget
+	if budget < 0 {
+		return nil, nil
+	}
+	if budget == 0 {
+		budget = defaultDiffBudget
+	}
+	chunks, omitted := analyze.FitChunks(fileDiffs(files), budget)
+	diff := make([]ai.DiffChunk, 0, len(chunks))
+	for _, c := range chunks {
+		diff = append(diff, ai.DiffChunk{File: c.File, Patch: c.Patch, Partial: c.Partial, Summary: c.Summary})
+	}
+	return diff, omitted
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+// repoFilter limits which repositories the server acts on. Patterns are
+// owner/name globs such as "acme/*", matched case-insensitively.
+type repoFilter struct {
+	allow []string
+	deny  []string
+}
+
+// allowed reports whether the server may act on fullName. A denied
+// match always wins; with no allow patterns every other repo is allowed.
+func (f *repoFilter) allowed(fullName string) bool {
+	if f == nil {
+		return true
+	}
+	name := strings.ToLower(fullName)
+	if matchRepo(f.deny, name) {
+		return false
+	}
+	return len(f.allow) == 0 || matchRepo(f.allow, name)
+}
+
+// matchRepo reports whether name matches one of the patterns
+func matchRepo(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRepoPatterns splits a comma separated list of owner/name globs
+func parseRepoPatterns(list string) ([]string, error) {
+	var patterns []string
+	for _, p := range strings.Split(list, ",") {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "" {
+			continue
+		}
+		if strings.Count(p, "/") != 1 {
+			return nil, fmt.Errorf("pattern %q must be owner/name", p)
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+// repoFilterFromEnv builds the filter from GGQUICK_ALLOWED_REPOS and
+// GGQUICK_DENIED_REPOS, returning nil when neither is set
+func repoFilterFromEnv() (*repoFilter, error) {
+	allow, err := parseRepoPatterns(os.Getenv("GGQUICK_ALLOWED_REPOS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid GGQUICK_ALLOWED_REPOS: %w", err)
+	}
+	deny, err := parseRepoPatterns(os.Getenv("GGQUICK_DENIED_REPOS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid GGQUICK_DENIED_REPOS: %w", err)
+	}
+	if len(allow) == 0 && len(deny) == 0 {
+		return nil, nil
+	}
+	return &repoFilter{allow: allow, deny: deny}, nil
+}
+
+// allowRepo rejects requests for repositories outside the server's
+// allowlist with a 403
+func (s *Server) allowRepo(w http.ResponseWriter, fullName string) bool {
+	if s.repos.allowed(fullName) {
+		return true
+	}
+	s.logger.Warning("Rejected request for %s, repository not allowed on this server", fullName)
+	http.Error(w, "Repository not allowed", http.StatusForbidden)
+	return false
+}
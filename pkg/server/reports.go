@@ -0,0 +1,225 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/notify"
+)
+
+// DigestConfig controls the weekly digest for a repository
+type DigestConfig struct {
+	Enabled bool   `json:"enabled"`
+	Weekday string `json:"weekday,omitempty"` // day to send on, default monday
+}
+
+// weekday returns the configured delivery day
+func (c *DigestConfig) weekday() time.Weekday {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(d.String(), c.Weekday) {
+			return d
+		}
+	}
+	return time.Monday
+}
+
+// Contributor is an author ranked in a report
+type Contributor struct {
+	Login string `json:"login"`
+	PRs   int    `json:"prs"`
+}
+
+// Report summarizes ggquick activity for a repository over a window.
+// Activity is counted by UTC day, for at most activityRetention, and
+// contributors are the pushers of the generated PRs merged.
+type Report struct {
+	Repo            string        `json:"repo"`
+	Since           time.Time     `json:"since"`
+	Until           time.Time     `json:"until"`
+	PRsGenerated    int           `json:"prs_generated"`
+	PRsMerged       int           `json:"prs_merged"`
+	FailedJobs      int           `json:"failed_jobs"`
+	AvgTimeToMerge  string        `json:"avg_time_to_merge,omitempty"`
+	TokensUsed      int           `json:"tokens_used"`
+	TopContributors []Contributor `json:"top_contributors"`
+	// References are reference check results per model and prompt version
+	References []ReferenceStats `json:"references,omitempty"`
+}
+
+// buildReport compiles a report for config covering the given window
+func (s *Server) buildReport(ctx context.Context, config *Config, window time.Duration) (*Report, error) {
+	until := time.Now().UTC()
+	since := until.Add(-window)
+	report := &Report{
+		Repo:            config.FullName(),
+		Since:           since,
+		Until:           until,
+		TopContributors: []Contributor{},
+	}
+
+	// Counted from the daily activity, which outlives the job store
+	generated := make(map[int]string) // PR number -> pusher
+	for _, day := range s.activity.since(config.FullName(), since) {
+		report.PRsGenerated += day.Generated
+		report.FailedJobs += day.Failed
+		report.TokensUsed += day.Tokens
+		report.References = mergeReferenceStats(report.References, day.References)
+		for _, pr := range day.PRs {
+			generated[pr.Number] = pr.Author
+		}
+	}
+
+	targetOwner, targetName := config.prTarget()
+	prs, err := s.github.GetMergedPRs(ctx, targetOwner, targetName, since)
+	if err != nil {
+		return nil, err
+	}
+
+	var mergeTime time.Duration
+	authors := make(map[string]int)
+	for _, pr := range prs {
+		author, ok := generated[pr.GetNumber()]
+		if !ok {
+			continue
+		}
+		report.PRsMerged++
+		mergeTime += pr.GetMergedAt().Sub(pr.GetCreatedAt().Time)
+		// The PR is opened by ggquick's account, so credit the pusher
+		if author != "" {
+			authors[author]++
+		}
+	}
+	if report.PRsMerged > 0 {
+		avg := mergeTime / time.Duration(report.PRsMerged)
+		report.AvgTimeToMerge = avg.Round(time.Minute).String()
+	}
+
+	for login, count := range authors {
+		report.TopContributors = append(report.TopContributors, Contributor{Login: login, PRs: count})
+	}
+	sort.Slice(report.TopContributors, func(i, j int) bool {
+		a, b := report.TopContributors[i], report.TopContributors[j]
+		if a.PRs != b.PRs {
+			return a.PRs > b.PRs
+		}
+		return a.Login < b.Login
+	})
+	if len(report.TopContributors) > 5 {
+		report.TopContributors = report.TopContributors[:5]
+	}
+
+	return report, nil
+}
+
+// formatReport renders a report as a markdown digest
+func formatReport(r *Report) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s to %s\n\n", r.Since.Format("Jan 2"), r.Until.Format("Jan 2, 2006"))
+	fmt.Fprintf(&b, "• PRs generated: %d\n", r.PRsGenerated)
+	fmt.Fprintf(&b, "• PRs merged: %d\n", r.PRsMerged)
+	if r.AvgTimeToMerge != "" {
+		fmt.Fprintf(&b, "• Avg time to merge: %s\n", r.AvgTimeToMerge)
+	}
+	if r.FailedJobs > 0 {
+		fmt.Fprintf(&b, "• Failed generations: %d\n", r.FailedJobs)
+	}
+	fmt.Fprintf(&b, "• Tokens used: %d\n", r.TokensUsed)
+	if len(r.TopContributors) > 0 {
+		b.WriteString("\nTop contributors:\n")
+		for _, c := range r.TopContributors {
+			fmt.Fprintf(&b, "• @%s (%d merged)\n", c.Login, c.PRs)
+		}
+	}
+	return b.String()
+}
+
+// sendDigest builds the weekly report for config and delivers it
+func (s *Server) sendDigest(ctx context.Context, config *Config) error {
+	report, err := s.buildReport(ctx, config, 7*24*time.Hour)
+	if err != nil {
+		return fmt.Errorf("failed to build report: %w", err)
+	}
+
+	s.logger.Loading("📊 Sending weekly digest for %s...", config.FullName())
+	return s.notify(ctx, notify.Message{
+		Repo:    config.FullName(),
+		Subject: fmt.Sprintf("ggquick weekly digest: %s", config.FullName()),
+		Text:    formatReport(report),
+	})
+}
+
+// runDueDigests sends digests for repositories on their configured day,
+// at most once per week
+func (s *Server) runDueDigests(ctx context.Context) {
+	s.mu.RLock()
+	var due []*Config
+	for _, config := range s.configs {
+		if config.Digest != nil && config.Digest.Enabled {
+			due = append(due, config)
+		}
+	}
+	s.mu.RUnlock()
+
+	now := time.Now()
+	for _, config := range due {
+		if now.Weekday() != config.Digest.weekday() {
+			continue
+		}
+
+		s.scheduler.mu.Lock()
+		last := s.scheduler.lastDigest[config.FullName()]
+		ready := now.Sub(last) >= 6*24*time.Hour
+		if ready {
+			s.scheduler.lastDigest[config.FullName()] = now
+		}
+		s.scheduler.mu.Unlock()
+
+		if !ready || !s.claimPeriodic(ctx, "digest:"+config.FullName(), 6*24*time.Hour) {
+			continue
+		}
+		if err := s.sendDigest(ctx, config); err != nil {
+			s.logger.Error("❌ Weekly digest failed for %s: %v", config.FullName(), err)
+		}
+	}
+}
+
+// handleReports returns the activity report for a repository
+func (s *Server) handleReports(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	config := s.repoConfig(r.URL.Query().Get("repo"))
+	if config == nil {
+		http.Error(w, "Repository not configured", http.StatusBadRequest)
+		return
+	}
+
+	days := 7
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "Invalid days value", http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+	if maxDays := int(activityRetention / (24 * time.Hour)); days > maxDays {
+		http.Error(w, fmt.Sprintf("Reports cover at most %d days", maxDays), http.StatusBadRequest)
+		return
+	}
+
+	report, err := s.buildReport(r.Context(), config, time.Duration(days)*24*time.Hour)
+	if err != nil {
+		s.logger.Error("❌ Failed to build report: %v", err)
+		http.Error(w, "Failed to build report", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/ai"
+)
+
+// commitSHAPattern matches abbreviated or full commit SHAs
+var commitSHAPattern = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// revertRequest asks for a PR reverting a merged PR or a commit
+type revertRequest struct {
+	Repo   string `json:"repo"`
+	PR     int    `json:"pr,omitempty"`
+	SHA    string `json:"sha,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// revertResult is the revert PR that was opened
+type revertResult struct {
+	Number  int    `json:"number"`
+	URL     string `json:"url"`
+	Branch  string `json:"branch"`
+	Reverts int    `json:"reverts,omitempty"` // the original PR, when known
+}
+
+// handleRevert opens a PR reverting a merged PR or commit
+func (s *Server) handleRevert(w http.ResponseWriter, r *http.Request) {
+	s.logger.Loading("📥 Receiving revert request...")
+
+	if r.Method != http.MethodPost {
+		s.logger.Error("❌ Invalid method: %s", r.Method)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	var req revertRequest
+	if err := decodeJSON(w, r, maxBodySize, &req); err != nil {
+		s.logger.Error("❌ Failed to decode revert request: %v", err)
+		return
+	}
+	req.SHA = strings.ToLower(strings.TrimSpace(req.SHA))
+	if (req.PR == 0) == (req.SHA == "") {
+		http.Error(w, "Exactly one of pr or sha is required", http.StatusBadRequest)
+		return
+	}
+	if req.SHA != "" && !commitSHAPattern.MatchString(req.SHA) {
+		http.Error(w, "Invalid commit SHA", http.StatusBadRequest)
+		return
+	}
+
+	config := s.repoConfig(req.Repo)
+	if config == nil {
+		s.logger.Error("❌ Repository not configured: %s", req.Repo)
+		http.Error(w, "Repository not configured", http.StatusBadRequest)
+		return
+	}
+	if !s.allowRepo(w, config.FullName()) {
+		return
+	}
+
+	result, err := s.revert(r.Context(), config, req)
+	if err != nil {
+		s.logger.Error("❌ Revert failed: %v", err)
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// revert creates a revert branch, generates its description from the
+// original PR and opens the revert PR, linking the two
+func (s *Server) revert(ctx context.Context, config *Config, req revertRequest) (*revertResult, error) {
+	owner, name := config.prTarget()
+	fetchCtx, cancel := context.WithTimeout(ctx, config.Timeouts.github())
+	defer cancel()
+
+	original, sha, err := s.revertTarget(fetchCtx, config, req)
+	if err != nil {
+		return nil, err
+	}
+	info := ai.RevertInfo{Repo: owner + "/" + name, SHA: sha, Reason: req.Reason}
+	base := ""
+	if original != nil {
+		info.Title, info.Body, base = original.GetTitle(), original.GetBody(), original.GetBase().GetRef()
+	} else {
+		msg, err := s.github.GetCommitMessage(fetchCtx, owner, name, sha)
+		if err != nil {
+			return nil, err
+		}
+		info.Title, _, _ = strings.Cut(strings.TrimSpace(msg), "\n")
+		if base, err = s.github.GetDefaultBranch(fetchCtx, owner, name); err != nil {
+			return nil, err
+		}
+	}
+
+	branch := "revert-" + sha[:7]
+	if original != nil {
+		branch = fmt.Sprintf("revert-%d-%s", original.GetNumber(), sha[:7])
+	}
+	s.logger.Branch("🌿 Creating %s reverting %s on %s", branch, sha[:7], base)
+	createCtx, cancelCreate := context.WithTimeout(ctx, config.Timeouts.createPR())
+	defer cancelCreate()
+	message := fmt.Sprintf("Revert %q\n\nThis reverts commit %s.", info.Title, sha)
+	if err := s.github.CreateRevertBranch(createCtx, owner, name, base, sha, branch, message); err != nil {
+		return nil, err
+	}
+
+	if prompt := s.promptTemplate(config); prompt != "" {
+		info.Instructions, _ = expandTemplate("prompt", prompt, s.templateVars(config, &Job{Branch: branch}))
+	}
+	genCtx, cancelGen := context.WithTimeout(ctx, config.Timeouts.generate())
+	defer cancelGen()
+	content, err := s.generator.GenerateRevert(genCtx, info)
+	if err == nil {
+		err = s.checkContent(ctx, config, content.Title, content.Description)
+	}
+	if err != nil {
+		// The branch exists, so still open the PR
+		s.logger.Warning("Revert description not generated: %v", err)
+		content = &ai.PRContent{Title: fmt.Sprintf("Revert %q", info.Title)}
+		if req.Reason != "" {
+			content.Description = "## Summary\n\n" + req.Reason
+		}
+	}
+
+	body := strings.TrimSpace(sanitize(config, content.Description, "")) + "\n\nThis reverts commit " + sha + "."
+	if original != nil {
+		body += fmt.Sprintf("\n\nReverts #%d", original.GetNumber())
+	}
+	pr, err := s.github.CreatePullRequest(createCtx, owner, name, &github.NewPullRequest{
+		Title: github.String(content.Title),
+		Head:  github.String(branch),
+		Base:  github.String(base),
+		Body:  github.String(strings.TrimSpace(body)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &revertResult{Number: pr.GetNumber(), URL: pr.GetHTMLURL(), Branch: branch}
+	if original != nil {
+		result.Reverts = original.GetNumber()
+		comment := fmt.Sprintf("Reverted in #%d.", pr.GetNumber())
+		if err := s.github.CreateComment(createCtx, owner, name, original.GetNumber(), comment); err != nil {
+			s.logger.Warning("Failed to link #%d to its revert: %v", original.GetNumber(), err)
+		}
+	}
+	s.events.publish(Event{Type: EventPRCreated, Owner: owner, Repo: name, Branch: branch, PRURL: pr.GetHTMLURL(), Message: content.Title})
+	s.logger.Success("✅ Opened revert PR #%d", pr.GetNumber())
+	return result, nil
+}
+
+// revertTarget resolves the request to the commit to revert and, when
+// there is one, the merged PR it came from
+func (s *Server) revertTarget(ctx context.Context, config *Config, req revertRequest) (*github.PullRequest, string, error) {
+	owner, name := config.prTarget()
+	if req.PR != 0 {
+		pr, err := s.github.GetPullRequest(ctx, owner, name, req.PR)
+		if err != nil {
+			return nil, "", err
+		}
+		if pr.GetMergedAt().IsZero() || pr.GetMergeCommitSHA() == "" {
+			return nil, "", fmt.Errorf("PR #%d is not merged", req.PR)
+		}
+		return pr, pr.GetMergeCommitSHA(), nil
+	}
+
+	prs, err := s.github.GetPRsForCommit(ctx, owner, name, req.SHA)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, pr := range prs {
+		// Listed PRs leave merged unset, merged_at is reliable
+		if !pr.GetMergedAt().IsZero() && strings.HasPrefix(pr.GetMergeCommitSHA(), req.SHA) {
+			return pr, pr.GetMergeCommitSHA(), nil
+		}
+	}
+	if len(req.SHA) < 40 {
+		return nil, "", fmt.Errorf("no merged PR for %s, give the full commit SHA", req.SHA)
+	}
+	return nil, req.SHA, nil
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/ai"
+)
+
+// SectionReviewOrder suggests the order to review the changed files in
+const SectionReviewOrder = "review_order"
+
+// maxReviewOrderFiles skips the review order for larger changes, where a
+// list of every file stops helping
+const maxReviewOrderFiles = 40
+
+// reviewOrder asks the model for the order to review the branch's files
+// in and renders it, or "" for changes too small or too large to need one
+func (s *Server) reviewOrder(ctx context.Context, job *Job, files []*github.CommitFile, summaries []ai.FileSummary) string {
+	if len(files) < 3 || len(files) > maxReviewOrderFiles {
+		return ""
+	}
+	summary := make(map[string]string, len(summaries))
+	for _, fs := range summaries {
+		summary[fs.File] = fs.Summary
+	}
+	review := make([]ai.ReviewFile, 0, len(files))
+	for _, f := range files {
+		review = append(review, ai.ReviewFile{
+			Path:      f.GetFilename(),
+			Status:    f.GetStatus(),
+			Additions: f.GetAdditions(),
+			Deletions: f.GetDeletions(),
+			Summary:   summary[f.GetFilename()],
+			Patch:     f.GetPatch(),
+		})
+	}
+
+	s.logger.Loading("🧭 Ordering %d file(s) for review...", len(files))
+	steps, tokens, err := s.generator.ReviewOrder(ctx, review)
+	s.jobs.update(job.ID, func(j *Job) { j.Tokens += tokens })
+	if err != nil {
+		s.logger.Warning("Review order skipped: %v", err)
+		return ""
+	}
+	return reviewOrderSection(steps)
+}
+
+// reviewOrderSection renders the review order as a collapsed numbered list
+func reviewOrderSection(steps []ai.ReviewStep) string {
+	var b strings.Builder
+	b.WriteString("<details>\n<summary>Suggested review order</summary>\n\n")
+	for i, step := range steps {
+		fmt.Fprintf(&b, "%d. `%s`", i+1, step.Path)
+		if step.Rationale != "" {
+			fmt.Fprintf(&b, " — %s", step.Rationale)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n</details>\n")
+	return b.String()
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Rule kinds
+const (
+	RuleBranch   = "branch"   // only branches matching a branch rule get PRs
+	RuleIgnore   = "ignore"   // branches matching an ignore rule never get PRs
+	RuleBase     = "base"     // PRs from matching branches target Value
+	RuleLabel    = "label"    // PRs from matching branches get label Value
+	RuleReviewer = "reviewer" // Value (user or org/team) reviews matching PRs
+)
+
+var reviewerPattern = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?(?:/[A-Za-z0-9_.-]+)?$`)
+
+// Rule customizes PR generation for branches matching Pattern, a glob
+// such as "feature/*" ("*" does not cross "/")
+type Rule struct {
+	ID      string `json:"id"`
+	Kind    string `json:"kind"`
+	Pattern string `json:"pattern"`
+	Value   string `json:"value,omitempty"`
+}
+
+// validate checks the rule is well formed
+func (r *Rule) validate() error {
+	if r.Pattern == "" {
+		return fmt.Errorf("pattern is required")
+	}
+	if _, err := path.Match(r.Pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", r.Pattern, err)
+	}
+
+	switch r.Kind {
+	case RuleBranch, RuleIgnore:
+		if r.Value != "" {
+			return fmt.Errorf("%s rules take no value", r.Kind)
+		}
+	case RuleBase, RuleLabel:
+		if strings.TrimSpace(r.Value) == "" {
+			return fmt.Errorf("%s rules require a value", r.Kind)
+		}
+	case RuleReviewer:
+		if !reviewerPattern.MatchString(r.Value) {
+			return fmt.Errorf("invalid reviewer %q, want a login or org/team", r.Value)
+		}
+	default:
+		return fmt.Errorf("unknown rule kind %q", r.Kind)
+	}
+	return nil
+}
+
+// matches reports whether branch matches the rule pattern
+func (r *Rule) matches(branch string) bool {
+	ok, _ := path.Match(r.Pattern, branch)
+	return ok
+}
+
+// branchAllowed reports whether the rules let branch get a PR. Bot
+// branches are skipped unless configured otherwise.
+func (c *Config) branchAllowed(branch string) bool {
+	if c.botMode(branch) == BotSkip {
+		return false
+	}
+	filtered, allowed := false, false
+	for _, r := range c.Rules {
+		switch r.Kind {
+		case RuleIgnore:
+			if r.matches(branch) {
+				return false
+			}
+		case RuleBranch:
+			filtered = true
+			allowed = allowed || r.matches(branch)
+		}
+	}
+	return !filtered || allowed
+}
+
+// baseBranch returns the branch PRs from branch should target
+func (c *Config) baseBranch(branch string) string {
+	for _, r := range c.Rules {
+		if r.Kind == RuleBase && r.matches(branch) {
+			return r.Value
+		}
+	}
+	return c.DefaultBranch
+}
+
+// ruleValues returns the values of every rule of kind matching branch
+func (c *Config) ruleValues(kind, branch string) []string {
+	var values []string
+	for _, r := range c.Rules {
+		if r.Kind == kind && r.matches(branch) && !contains(values, r.Value) {
+			values = append(values, r.Value)
+		}
+	}
+	return values
+}
+
+// newRuleID returns a short identifier unused by rules
+func newRuleID(rules []*Rule) string {
+	for {
+		id := newJobID()[:6]
+		taken := false
+		for _, r := range rules {
+			taken = taken || r.ID == id
+		}
+		if !taken {
+			return id
+		}
+	}
+}
+
+// handleRules lists (GET), adds (POST) and removes (DELETE) repo rules.
+// Configs are replaced rather than edited so running jobs keep a
+// consistent view. Changing rules takes the admin token, like changing
+// them through /config.
+func (s *Server) handleRules(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+	changes := r.Method == http.MethodPost || r.Method == http.MethodDelete
+	if changes && !requireAdmin(w, r) {
+		return
+	}
+
+	var rule Rule
+	if r.Method == http.MethodPost {
+		var req struct {
+			Repo string `json:"repo"`
+			Rule
+		}
+		// The embedded Rule makes decodeJSON validate it
+		if err := decodeJSON(w, r, maxBodySize, &req); err != nil {
+			return
+		}
+		repo, rule = req.Repo, req.Rule
+	}
+
+	config := s.repoConfig(repo)
+	if config == nil {
+		http.Error(w, "Repository not configured", http.StatusBadRequest)
+		return
+	}
+	if changes && !s.allowRepo(w, config.FullName()) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		rules := config.Rules
+		if rules == nil {
+			rules = []*Rule{}
+		}
+		writeJSON(w, http.StatusOK, rules)
+
+	case http.MethodPost:
+		rule.Value = strings.TrimSpace(rule.Value)
+		s.mu.Lock()
+		updated := *s.configs[config.FullName()]
+		rule.ID = newRuleID(updated.Rules)
+		updated.Rules = append(append([]*Rule(nil), updated.Rules...), &rule)
+		s.configs[config.FullName()] = &updated
+		s.mu.Unlock()
+		s.persist()
+
+		s.logger.Success("✅ Added %s rule %s for %s", rule.Kind, rule.ID, config.FullName())
+		writeJSON(w, http.StatusCreated, rule)
+
+	case http.MethodDelete:
+		id := r.URL.Query().Get("id")
+
+		s.mu.Lock()
+		current := s.configs[config.FullName()]
+		updated := *current
+		updated.Rules = nil
+		for _, existing := range current.Rules {
+			if existing.ID != id {
+				updated.Rules = append(updated.Rules, existing)
+			}
+		}
+		found := len(updated.Rules) < len(current.Rules)
+		if found {
+			s.configs[config.FullName()] = &updated
+		}
+		s.mu.Unlock()
+
+		if !found {
+			http.Error(w, "Rule not found", http.StatusNotFound)
+			return
+		}
+		s.persist()
+		s.logger.Success("✅ Removed rule %s from %s", id, config.FullName())
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
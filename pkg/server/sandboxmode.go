@@ -0,0 +1,121 @@
+package server
+
+import (
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/fake"
+)
+
+// UseSandbox marks the server as running against the sandbox GitHub.
+// Credentials aren't required, nothing is cloned or looked up in OSV, and
+// the pull requests it creates are listed at /sandbox.
+func (s *Server) UseSandbox(gh *fake.GitHub) {
+	s.sandboxed = gh
+}
+
+// sandboxPR is a pull request created in sandbox mode
+type sandboxPR struct {
+	Repo      string         `json:"repo"`
+	Number    int            `json:"number"`
+	Title     string         `json:"title"`
+	Body      string         `json:"body"`
+	State     string         `json:"state"`
+	Draft     bool           `json:"draft,omitempty"`
+	Head      string         `json:"head"`
+	Base      string         `json:"base"`
+	Labels    []string       `json:"labels,omitempty"`
+	Reviewers []string       `json:"reviewers,omitempty"`
+	Comments  []fake.Comment `json:"comments,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// sandboxPRs returns the sandbox pull requests, newest first
+func (s *Server) sandboxPRs() []sandboxPR {
+	prs := s.sandboxed.PullRequests()
+	out := make([]sandboxPR, 0, len(prs))
+	for _, pr := range prs {
+		p := sandboxPR{
+			Repo:      pr.GetBase().GetRepo().GetFullName(),
+			Number:    pr.GetNumber(),
+			Title:     pr.GetTitle(),
+			Body:      pr.GetBody(),
+			State:     pr.GetState(),
+			Draft:     pr.GetDraft(),
+			Head:      pr.GetHead().GetRef(),
+			Base:      pr.GetBase().GetRef(),
+			CreatedAt: pr.GetCreatedAt().Time,
+		}
+		for _, label := range pr.Labels {
+			p.Labels = append(p.Labels, label.GetName())
+		}
+		for _, user := range pr.RequestedReviewers {
+			p.Reviewers = append(p.Reviewers, user.GetLogin())
+		}
+		p.Comments = s.sandboxed.Comments(p.Repo, p.Number)
+		out = append(out, p)
+	}
+	return out
+}
+
+// handleSandboxPRs lists the sandbox pull requests as JSON
+func (s *Server) handleSandboxPRs(w http.ResponseWriter, r *http.Request) {
+	if s.sandboxed == nil {
+		http.Error(w, "Not running in sandbox mode", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.sandboxPRs())
+}
+
+// sandboxPage shows the sandbox pull requests, newest first
+var sandboxPage = template.Must(template.New("sandbox").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>ggquick sandbox</title>
+<style>
+body { font-family: sans-serif; max-width: 56rem; margin: 2rem auto; padding: 0 1rem; }
+article { border: 1px solid #ccc; border-radius: 6px; padding: 1rem; margin-bottom: 1.5rem; }
+pre { white-space: pre-wrap; background: #f6f8fa; padding: 1rem; }
+.meta { color: #555; font-size: 0.9rem; }
+</style>
+</head>
+<body>
+<h1>ggquick sandbox</h1>
+<p>Pull requests created in sandbox mode. Nothing here was sent to GitHub.</p>
+{{range .}}
+<article id="{{.Repo}}/{{.Number}}">
+<h2>{{.Title}}</h2>
+<p class="meta">{{.Repo}} #{{.Number}}, {{.Head}} into {{.Base}}, {{.State}}{{if .Draft}} draft{{end}}, {{.CreatedAt.Format "2006-01-02 15:04:05"}}
+{{if .Labels}}<br>Labels: {{range $i, $l := .Labels}}{{if $i}}, {{end}}{{$l}}{{end}}{{end}}
+{{if .Reviewers}}<br>Reviewers: {{range $i, $r := .Reviewers}}{{if $i}}, {{end}}{{$r}}{{end}}{{end}}</p>
+<pre>{{.Body}}</pre>
+{{range .Comments}}<p class="meta">Comment, {{.Time.Format "15:04:05"}}</p><pre>{{.Body}}</pre>{{end}}
+</article>
+{{else}}
+<p>No pull requests yet. Push a branch or replay a recording to create one.</p>
+{{end}}
+</body>
+</html>
+`))
+
+// handleSandbox renders the sandbox pull requests as a page
+func (s *Server) handleSandbox(w http.ResponseWriter, r *http.Request) {
+	if s.sandboxed == nil {
+		http.Error(w, "Not running in sandbox mode", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := sandboxPage.Execute(w, s.sandboxPRs()); err != nil {
+		s.logger.Error("❌ Failed to render sandbox page: %v", err)
+	}
+}
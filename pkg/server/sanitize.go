@@ -0,0 +1,51 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/saint0x/ggquick/pkg/analyze"
+)
+
+// defaultMaxLinks caps the links in generated text
+const defaultMaxLinks = 10
+
+// SanitizeConfig relaxes how generated text is sanitized. By default
+// mentions are wrapped in code, closing keywords the commits don't use
+// become Refs, HTML is escaped, images are dropped and links are capped.
+type SanitizeConfig struct {
+	AllowMentions bool `json:"allow_mentions,omitempty"` // let mentions ping
+	AllowClosing  bool `json:"allow_closing,omitempty"`  // keep every closing keyword
+	AllowHTML     bool `json:"allow_html,omitempty"`
+	MaxImages     int  `json:"max_images,omitempty"` // default 0, -1 for no limit
+	MaxLinks      int  `json:"max_links,omitempty"`  // default 10, -1 for no limit
+}
+
+// validate checks the caps
+func (c *SanitizeConfig) validate() error {
+	if c.MaxImages < -1 || c.MaxLinks < -1 {
+		return fmt.Errorf("sanitize caps must be -1 or more")
+	}
+	return nil
+}
+
+// sanitize neutralizes mentions, closing keywords, HTML and extra images
+// and links in generated text. Closing keywords in intended, usually the
+// commit messages, are kept.
+func sanitize(config *Config, text, intended string) string {
+	c := config.Sanitize
+	if c == nil {
+		c = &SanitizeConfig{}
+	}
+	maxLinks := c.MaxLinks
+	if maxLinks == 0 {
+		maxLinks = defaultMaxLinks
+	}
+	return analyze.Sanitize(text, analyze.SanitizeOptions{
+		Mentions:  !c.AllowMentions,
+		Closing:   !c.AllowClosing,
+		HTML:      !c.AllowHTML,
+		Intended:  intended,
+		MaxImages: c.MaxImages,
+		MaxLinks:  maxLinks,
+	})
+}
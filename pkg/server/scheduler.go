@@ -0,0 +1,287 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/notify"
+)
+
+// Stale sweep actions
+const (
+	SweepNotify   = "notify"
+	SweepGenerate = "generate"
+)
+
+// SweepConfig controls the periodic stale-branch sweep for a repository
+type SweepConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Interval string `json:"interval,omitempty"` // how often to sweep, default 24h
+	MinAge   string `json:"min_age,omitempty"`  // skip branches with newer commits, default 24h
+	Action   string `json:"action,omitempty"`   // notify or generate, default notify
+}
+
+// interval returns the parsed sweep interval
+func (c *SweepConfig) interval() time.Duration {
+	if d, err := time.ParseDuration(c.Interval); err == nil && d > 0 {
+		return d
+	}
+	return 24 * time.Hour
+}
+
+// minAge returns the parsed minimum branch age
+func (c *SweepConfig) minAge() time.Duration {
+	if d, err := time.ParseDuration(c.MinAge); err == nil && d >= 0 {
+		return d
+	}
+	return 24 * time.Hour
+}
+
+// staleBranch is a branch ahead of the default branch with no open PR
+type staleBranch struct {
+	Name       string    `json:"name"`
+	AheadBy    int       `json:"ahead_by"`
+	Author     string    `json:"author"`
+	LastCommit time.Time `json:"last_commit"`
+	Message    string    `json:"message"`
+}
+
+// scheduler tracks when periodic tasks last ran for each repository
+type scheduler struct {
+	mu          sync.Mutex
+	lastSweep   map[string]time.Time
+	lastDigest  map[string]time.Time
+	lastRelease map[string]time.Time
+
+	// lastReconcile and redelivered track failed webhook deliveries
+	lastReconcile map[string]time.Time
+	redelivered   map[string]redelivery
+}
+
+// runScheduler periodically runs due per-repo tasks until ctx is done
+func (s *Server) runScheduler(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if s.leadScheduler(ctx) {
+				s.runDueSweeps(ctx)
+				s.runDueDigests(ctx)
+				s.runDueReleases(ctx)
+				s.runDueReconciles(ctx)
+			}
+			// Waiting jobs live on the replica that received them
+			s.recheckPending(ctx)
+		}
+	}
+}
+
+// runDueSweeps sweeps every repository whose interval has elapsed
+func (s *Server) runDueSweeps(ctx context.Context) {
+	s.mu.RLock()
+	var due []*Config
+	for _, config := range s.configs {
+		if config.StaleSweep != nil && config.StaleSweep.Enabled && s.repos.allowed(config.FullName()) {
+			due = append(due, config)
+		}
+	}
+	s.mu.RUnlock()
+
+	now := time.Now()
+	for _, config := range due {
+		s.scheduler.mu.Lock()
+		last := s.scheduler.lastSweep[config.FullName()]
+		ready := now.Sub(last) >= config.StaleSweep.interval()
+		if ready {
+			s.scheduler.lastSweep[config.FullName()] = now
+		}
+		s.scheduler.mu.Unlock()
+
+		if !ready || !s.claimPeriodic(ctx, "sweep:"+config.FullName(), config.StaleSweep.interval()) {
+			continue
+		}
+		if err := s.sweepStaleBranches(ctx, config); err != nil {
+			s.logger.Error("❌ Stale branch sweep failed for %s: %v", config.FullName(), err)
+		}
+	}
+}
+
+// sweepStaleBranches finds stale branches and notifies or generates PRs
+func (s *Server) sweepStaleBranches(ctx context.Context, config *Config) error {
+	s.logger.Loading("🧹 Sweeping stale branches in %s...", config.FullName())
+
+	stale, err := s.findStaleBranches(ctx, config)
+	if err != nil {
+		return err
+	}
+	if len(stale) == 0 {
+		s.logger.Debug("No stale branches in %s", config.FullName())
+		return nil
+	}
+
+	for _, b := range stale {
+		if config.StaleSweep.Action == SweepGenerate {
+			s.logger.Branch("🌿 Generating PR for stale branch %s", b.Name)
+			// The repository opted in to these, so author caps don't hold them
+			job := s.jobs.create(config.Owner, config.Name, b.Name, "")
+			s.events.publish(jobEvent(EventPushReceived, job, b.Message))
+			// Jobs finish long after the sweep, which mustn't hold up the
+			// scheduler
+			done := s.enqueueJob(ctx, config, job, b.Message, PriorityBackground)
+			go func(branch string) {
+				if err := <-done; err != nil {
+					s.logger.Error("❌ Failed to generate PR for %s: %v", branch, err)
+				}
+			}(b.Name)
+			continue
+		}
+
+		msg := fmt.Sprintf("%d commit(s) ahead of %s with no open PR, last commit by %s on %s",
+			b.AheadBy, config.baseBranch(b.Name), b.Author, b.LastCommit.Format("2006-01-02"))
+		s.logger.Warning("Stale branch %s: %s", b.Name, msg)
+		s.events.publish(Event{
+			Type:    EventStaleBranch,
+			Owner:   config.Owner,
+			Repo:    config.Name,
+			Branch:  b.Name,
+			Message: msg,
+		})
+		s.notifyStale(ctx, config, b, msg)
+	}
+
+	s.logger.Success("✅ Found %d stale branch(es) in %s", len(stale), config.FullName())
+	return nil
+}
+
+// notifyStale tells a stale branch's author through the notifier, by
+// email when the repository maps their login to an address
+func (s *Server) notifyStale(ctx context.Context, config *Config, b staleBranch, text string) {
+	msg := notify.Message{
+		Repo:       config.FullName(),
+		Subject:    fmt.Sprintf("Stale branch %s in %s", b.Name, config.FullName()),
+		Text:       fmt.Sprintf("@%s, your branch %s is %s. Open a PR for it or delete it.", b.Author, b.Name, text),
+		Link:       fmt.Sprintf("https://github.com/%s/tree/%s", config.FullName(), b.Name),
+		Recipients: config.Notify.recipients(b.Author),
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if err := s.notify(ctx, msg); err != nil {
+		s.logger.Error("❌ Failed to notify %s about %s: %v", b.Author, b.Name, err)
+	}
+}
+
+// findStaleBranches lists unmerged branches with no commits newer than
+// the configured min age
+func (s *Server) findStaleBranches(ctx context.Context, config *Config) ([]staleBranch, error) {
+	cutoff := time.Now().Add(-config.StaleSweep.minAge())
+	return s.unmergedBranches(ctx, config, "", 0, func(last time.Time) bool {
+		return !last.After(cutoff)
+	})
+}
+
+// unmergedBranches lists branches with commits ahead of the default
+// branch and no open PR whose last commit time satisfies keep, in name
+// order. Branches named up to after are skipped, and at most limit are
+// returned when it is positive, so callers can page through them.
+func (s *Server) unmergedBranches(ctx context.Context, config *Config, after string, limit int, keep func(time.Time) bool) ([]staleBranch, error) {
+	branches, err := s.github.GetBranches(ctx, config.Owner, config.Name)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(branches, func(i, j int) bool { return branches[i].GetName() < branches[j].GetName() })
+
+	var found []staleBranch
+	for _, branch := range branches {
+		if limit > 0 && len(found) >= limit {
+			break
+		}
+		name := branch.GetName()
+		base := config.baseBranch(name)
+		if name <= after || name == config.DefaultBranch || name == base || !config.branchAllowed(name) {
+			continue
+		}
+
+		comp, err := s.github.CompareBranches(ctx, config.Owner, config.Name, base, name)
+		if err != nil {
+			s.logger.Debug("Skipping %s: %v", name, err)
+			continue
+		}
+		if comp.GetAheadBy() == 0 || len(comp.Commits) == 0 {
+			continue
+		}
+
+		last := comp.Commits[len(comp.Commits)-1]
+		when := last.GetCommit().GetAuthor().GetDate().Time
+		if !keep(when) {
+			continue
+		}
+
+		targetOwner, targetName := config.prTarget()
+		open, err := s.github.HasOpenPR(ctx, targetOwner, targetName, config.prHead(name))
+		if err != nil {
+			s.logger.Debug("Skipping %s: %v", name, err)
+			continue
+		}
+		if open {
+			continue
+		}
+
+		author := last.GetAuthor().GetLogin()
+		if author == "" {
+			author = last.GetCommit().GetAuthor().GetName()
+		}
+		found = append(found, staleBranch{
+			Name:       name,
+			AheadBy:    comp.GetAheadBy(),
+			Author:     author,
+			LastCommit: when,
+			Message:    strings.TrimSpace(last.GetCommit().GetMessage()),
+		})
+	}
+
+	return found, nil
+}
+
+// handleStale lists stale branches for a repository on demand, so they
+// can be reviewed before generating PRs for them via /push
+func (s *Server) handleStale(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	config := s.repoConfig(r.URL.Query().Get("repo"))
+	if config == nil {
+		http.Error(w, "Repository not configured", http.StatusBadRequest)
+		return
+	}
+
+	// Use the repo's sweep settings when present, defaults otherwise
+	sweep := config.StaleSweep
+	if sweep == nil {
+		sweep = &SweepConfig{}
+	}
+	lookup := *config
+	lookup.StaleSweep = sweep
+
+	stale, err := s.findStaleBranches(r.Context(), &lookup)
+	if err != nil {
+		s.logger.Error("❌ Failed to find stale branches: %v", err)
+		http.Error(w, "Failed to list branches", http.StatusInternalServerError)
+		return
+	}
+
+	if stale == nil {
+		stale = []staleBranch{}
+	}
+	writeJSON(w, http.StatusOK, stale)
+}
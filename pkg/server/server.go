@@ -7,13 +7,20 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/go-github/v57/github"
 	"github.com/saint0x/ggquick/pkg/ai"
+	"github.com/saint0x/ggquick/pkg/analyze"
+	"github.com/saint0x/ggquick/pkg/fake"
 	"github.com/saint0x/ggquick/pkg/log"
+	"github.com/saint0x/ggquick/pkg/notify"
+	"github.com/saint0x/ggquick/pkg/osv"
+	"github.com/saint0x/ggquick/pkg/pipeline"
 	"golang.org/x/time/rate"
 )
 
@@ -23,18 +30,453 @@ type Config struct {
 	Owner         string `json:"owner"`
 	Name          string `json:"name"`
 	DefaultBranch string `json:"default_branch"`
+
+	// Upstream is the parent repository (owner/name) PRs are opened
+	// against when this repository is a fork
+	Upstream string `json:"upstream,omitempty"`
+
+	StaleSweep *SweepConfig  `json:"stale_sweep,omitempty"`
+	Digest     *DigestConfig `json:"digest,omitempty"`
+	Notify     *NotifyConfig `json:"notify,omitempty"`
+
+	// ImpactDiagram adds a Mermaid graph of importing packages for Go repos
+	ImpactDiagram bool `json:"impact_diagram,omitempty"`
+
+	// AffectedTargets lists the packages or Bazel targets a branch affects
+	AffectedTargets bool `json:"affected_targets,omitempty"`
+
+	// BreakingLanguages limits breaking-change detectors, all when empty
+	BreakingLanguages []string `json:"breaking_languages,omitempty"`
+
+	// SmokeCheck runs go build and go vet on a clone before opening the PR
+	SmokeCheck bool `json:"smoke_check,omitempty"`
+
+	// Lint runs a lint command on a clone and lists new issues
+	Lint *LintConfig `json:"lint,omitempty"`
+
+	// CommitTopics gives each unrelated topic of a branch's commits its
+	// own subsection
+	CommitTopics *CommitTopicsConfig `json:"commit_topics,omitempty"`
+
+	// AuthorCap holds jobs of authors over a daily cap until approved
+	AuthorCap *AuthorCapConfig `json:"author_cap,omitempty"`
+
+	// Size labels PRs over the size limits and suggests a split into
+	// smaller PRs
+	Size *SizeConfig `json:"size,omitempty"`
+
+	// Performance asks for benchmarks when performance-critical paths
+	// change, optionally running them on a clone
+	Performance *PerformanceConfig `json:"performance,omitempty"`
+
+	// Stages selects registered pipeline stages by name, all when empty
+	Stages []string `json:"stages,omitempty"`
+	// Wasm lists WASI modules on the server run as pipeline stages for
+	// this repository, each named after its file
+	Wasm []string `json:"wasm,omitempty"`
+	// Extensions are webhook-based pipeline stages for this repository
+	Extensions []*pipeline.WebhookStage `json:"extensions,omitempty"`
+
+	// Rules filter branches and pick base branches, labels and reviewers
+	Rules []*Rule `json:"rules,omitempty"`
+
+	// Protected paths make PRs touching them drafts with required reviewers
+	Protected *ProtectedConfig `json:"protected,omitempty"`
+
+	// License requires a header in files the branch adds
+	License *LicenseConfig `json:"license,omitempty"`
+
+	// DCO flags commits missing a Signed-off-by trailer
+	DCO *DCOConfig `json:"dco,omitempty"`
+
+	// LargeFiles tunes the warning for binary and oversized files
+	LargeFiles *LargeFileConfig `json:"large_files,omitempty"`
+
+	// DependencyAudit lists changed dependencies with known
+	// vulnerabilities from the OSV database
+	DependencyAudit bool `json:"dependency_audit,omitempty"`
+
+	// CommitReport adds a section grading commit messages against
+	// Conventional Commits with suggested rewrites
+	CommitReport bool `json:"commit_report,omitempty"`
+
+	// ConflictCheck warns when open PRs change the same files
+	ConflictCheck *ConflictCheckConfig `json:"conflict_check,omitempty"`
+
+	// Hotspots explains the largest hunks of new PRs in review comments
+	Hotspots *HotspotConfig `json:"hotspots,omitempty"`
+
+	// Docs links documentation pages covering the changed files
+	Docs *DocsConfig `json:"docs,omitempty"`
+
+	// DocRules add a checklist item and label when code changes without
+	// its docs
+	DocRules []DocRule `json:"doc_rules,omitempty"`
+
+	// Ownership lists people who committed most to the changed files
+	Ownership bool `json:"ownership,omitempty"`
+
+	// ReviewOrder adds a collapsed section suggesting the order to review
+	// the changed files in, with a line on each
+	ReviewOrder bool `json:"review_order,omitempty"`
+
+	// SquashMessage makes squash merges use the generated PR title and
+	// body as the commit message on the default branch
+	SquashMessage bool `json:"squash_message,omitempty"`
+
+	// ReferenceCheck handles file paths and symbols a generated
+	// description names that aren't in the diff: strike (default),
+	// remove or off
+	ReferenceCheck string `json:"reference_check,omitempty"`
+
+	// Confidence drafts PRs whose description scores below a threshold
+	Confidence *ConfidenceConfig `json:"confidence,omitempty"`
+
+	// DiffBudget is the bytes of patch sent to the model, most relevant
+	// files first. Defaults to 24000, -1 sends no diff.
+	DiffBudget int `json:"diff_budget,omitempty"`
+
+	// Tier classifies the repository for model rules, like critical
+	Tier string `json:"tier,omitempty"`
+	// ModelRules pick the models for each job, first match wins; the
+	// org's rules apply after these
+	ModelRules []ModelRule `json:"model_rules,omitempty"`
+
+	// Summaries summarizes each file of large changes in parallel
+	Summaries *SummaryConfig `json:"summaries,omitempty"`
+
+	// Sanitize relaxes how generated text is sanitized before posting
+	Sanitize *SanitizeConfig `json:"sanitize,omitempty"`
+
+	// ContentFilter checks generated text against a blocklist and the
+	// moderation API before it's posted
+	ContentFilter *ContentFilterConfig `json:"content_filter,omitempty"`
+
+	// Welcome greets authors of their first PR to the repository with the
+	// parts of the contributing guide that apply to their changes
+	Welcome bool `json:"welcome,omitempty"`
+
+	// Events subscribes the webhook to extra events beyond push and
+	// pull_request: issue_comment, release
+	Events []string `json:"events,omitempty"`
+
+	// ChecklistSync ticks checklist items as the CI checks they name pass
+	ChecklistSync *ChecklistSyncConfig `json:"checklist_sync,omitempty"`
+
+	// CIResults reports pass/fail per workflow once CI finishes
+	CIResults *CIResultsConfig `json:"ci_results,omitempty"`
+
+	// Coverage reports the change in test coverage once CI finishes
+	Coverage *CoverageConfig `json:"coverage,omitempty"`
+
+	// ActionItems keeps a checklist of what reviews ask for in a comment
+	ActionItems *ActionItemsConfig `json:"action_items,omitempty"`
+
+	// StatusCheck sets a commit status on the pushed commit as its job runs
+	StatusCheck *StatusCheckConfig `json:"status_check,omitempty"`
+
+	// Backport opens backport PRs for merged PRs with backport labels
+	Backport *BackportConfig `json:"backport,omitempty"`
+
+	// Release opens release PRs with generated notes, also on a schedule
+	Release *ReleaseConfig `json:"release,omitempty"`
+
+	// Triage labels and summarizes newly opened issues
+	Triage *TriageConfig `json:"triage,omitempty"`
+
+	// Localization writes each user's PRs in their preferred language
+	Localization *LocalizationConfig `json:"localization,omitempty"`
+
+	// Bots controls dependabot and renovate branches, skipped by default
+	Bots *BotConfig `json:"bots,omitempty"`
+
+	// TitlePrefix puts a ticket key or team tag at the start of PR titles
+	TitlePrefix *TitlePrefixConfig `json:"title_prefix,omitempty"`
+
+	// Attribution credits the pusher or opens PRs with their own token
+	Attribution *AttributionConfig `json:"attribution,omitempty"`
+
+	// PushSecret, when set, must sign every push for the repository. It is
+	// generated by ggquick init and sent by ggquick apply.
+	PushSecret string `json:"push_secret,omitempty"`
+
+	// Timeouts bound GitHub fetches, model calls and PR creation
+	Timeouts *TimeoutConfig `json:"timeouts,omitempty"`
+
+	// Body reorders or disables PR body sections
+	Body *BodyConfig `json:"body,omitempty"`
+
+	// Variables override org template variables for this repository
+	Variables map[string]string `json:"variables,omitempty"`
+	// Prompt is extra guidance for the model, after the org's
+	Prompt string `json:"prompt,omitempty"`
+	// Footer replaces the org footer appended to PR bodies
+	Footer string `json:"footer,omitempty"`
+
+	// Checklist replaces the org checklist appended to PR bodies
+	Checklist []string `json:"checklist,omitempty"`
+	// SkipChecklist leaves the org checklist out for this repository
+	SkipChecklist bool `json:"skip_checklist,omitempty"`
+}
+
+// NotifyConfig controls per-repo notifications
+type NotifyConfig struct {
+	Emails    []string `json:"emails,omitempty"`     // recipients for this repository
+	PRCreated bool     `json:"pr_created,omitempty"` // notify when a PR is opened
+	Failures  bool     `json:"failures,omitempty"`   // notify when generation fails
+	Conflicts bool     `json:"conflicts,omitempty"`  // notify when open PRs change the same files
+	// Authors maps GitHub logins to email addresses, for messages about
+	// one person's work such as their stale branches
+	Authors map[string]string `json:"authors,omitempty"`
+}
+
+// validate checks the author addresses are usable
+func (c *NotifyConfig) validate() error {
+	for login, email := range c.Authors {
+		if !strings.Contains(email, "@") || strings.ContainsAny(email, "\r\n,") {
+			return fmt.Errorf("notify author %s: invalid email %q", login, email)
+		}
+	}
+	return nil
+}
+
+// recipients returns where a message about login's work goes: their own
+// address when mapped, otherwise the repository's recipients
+func (c *NotifyConfig) recipients(login string) []string {
+	if c == nil {
+		return nil
+	}
+	for l, email := range c.Authors {
+		if strings.EqualFold(l, login) {
+			return []string{email}
+		}
+	}
+	return c.Emails
+}
+
+// FullName returns the owner/name form of the repository
+func (c *Config) FullName() string {
+	return c.Owner + "/" + c.Name
+}
+
+// validate checks a submitted config is usable
+func (c *Config) validate() error {
+	if c.RepoURL == "" && (c.Owner == "" || c.Name == "") {
+		return fmt.Errorf("repo_url is required")
+	}
+	if c.Upstream != "" && !repoNamePattern.MatchString(c.Upstream) {
+		return fmt.Errorf("invalid upstream %q, want owner/name", c.Upstream)
+	}
+	if c.StaleSweep != nil && c.StaleSweep.Action != "" &&
+		c.StaleSweep.Action != SweepNotify && c.StaleSweep.Action != SweepGenerate {
+		return fmt.Errorf("invalid stale_sweep action %q", c.StaleSweep.Action)
+	}
+	if err := validateWasm(c.Wasm); err != nil {
+		return err
+	}
+	for _, ext := range c.Extensions {
+		if ext.StageName == "" {
+			return fmt.Errorf("extension name is required")
+		}
+		if !strings.HasPrefix(ext.URL, "https://") && !strings.HasPrefix(ext.URL, "http://") {
+			return fmt.Errorf("extension %s: invalid url %q", ext.StageName, ext.URL)
+		}
+	}
+	for _, rule := range c.Rules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("rule %s: %w", rule.ID, err)
+		}
+	}
+	if err := validateVariables(c.Variables); err != nil {
+		return err
+	}
+	if err := validateTemplates(c.Prompt, c.Footer, c.Checklist); err != nil {
+		return err
+	}
+	if c.Timeouts != nil {
+		if err := c.Timeouts.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Body != nil {
+		if err := c.Body.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Confidence != nil {
+		if err := c.Confidence.validate(); err != nil {
+			return err
+		}
+	}
+	if c.ContentFilter != nil {
+		if err := c.ContentFilter.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Sanitize != nil {
+		if err := c.Sanitize.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Notify != nil {
+		if err := c.Notify.validate(); err != nil {
+			return err
+		}
+	}
+	if err := validateModelRules(c.ModelRules); err != nil {
+		return err
+	}
+	if c.DiffBudget < -1 {
+		return fmt.Errorf("diff_budget must be -1 or more")
+	}
+	if c.ReferenceCheck != "" && !contains([]string{analyze.ReferencesStrike, analyze.ReferencesRemove, ReferencesOff}, c.ReferenceCheck) {
+		return fmt.Errorf("invalid reference_check %q", c.ReferenceCheck)
+	}
+	for _, event := range c.Events {
+		if !contains(optionalWebhookEvents, event) {
+			return fmt.Errorf("unsupported webhook event %q", event)
+		}
+	}
+	if c.ChecklistSync != nil {
+		if err := c.ChecklistSync.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Backport != nil {
+		if err := c.Backport.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Release != nil {
+		if err := c.Release.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Triage != nil {
+		if err := c.Triage.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Hotspots != nil {
+		if err := c.Hotspots.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Docs != nil {
+		if err := c.Docs.validate(); err != nil {
+			return err
+		}
+	}
+	if c.AuthorCap != nil {
+		if err := c.AuthorCap.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Size != nil {
+		if err := c.Size.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Performance != nil {
+		if err := c.Performance.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Coverage != nil {
+		if err := c.Coverage.validate(); err != nil {
+			return err
+		}
+	}
+	for i := range c.DocRules {
+		if err := c.DocRules[i].validate(); err != nil {
+			return err
+		}
+	}
+	if c.Localization != nil {
+		if err := c.Localization.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Bots != nil {
+		if err := c.Bots.validate(); err != nil {
+			return err
+		}
+	}
+	if c.TitlePrefix != nil {
+		if err := c.TitlePrefix.validate(); err != nil {
+			return err
+		}
+	}
+	if c.License != nil {
+		if err := c.License.validate(); err != nil {
+			return err
+		}
+	}
+	if c.LargeFiles != nil && c.LargeFiles.MaxLines < 0 {
+		return fmt.Errorf("large_files max_lines must not be negative")
+	}
+	if c.Protected != nil {
+		for _, p := range c.Protected.Paths {
+			if _, err := path.Match(p, ""); err != nil || p == "" {
+				return fmt.Errorf("invalid protected path %q", p)
+			}
+		}
+		for _, r := range c.Protected.Reviewers {
+			if !reviewerPattern.MatchString(r) {
+				return fmt.Errorf("invalid protected reviewer %q", r)
+			}
+		}
+	}
+	return nil
 }
 
 // GitHubClient interface for GitHub operations
 type GitHubClient interface {
 	CreatePullRequest(ctx context.Context, owner, repo string, pr *github.NewPullRequest) (*github.PullRequest, error)
 	GetDefaultBranch(ctx context.Context, owner, repo string) (string, error)
+	GetCommitMessage(ctx context.Context, owner, repo, sha string) (string, error)
+	GetBranches(ctx context.Context, owner, repo string) ([]*github.Branch, error)
+	CompareBranches(ctx context.Context, owner, repo, base, head string) (*github.CommitsComparison, error)
+	HasOpenPR(ctx context.Context, owner, repo, head string) (bool, error)
+	GetMergedPRs(ctx context.Context, owner, repo string, since time.Time) ([]*github.PullRequest, error)
+	GetOpenPRTitles(ctx context.Context, owner, repo string) ([]string, error)
+	GetOpenPRs(ctx context.Context, owner, repo string, limit int) ([]*github.PullRequest, error)
+	GetPRFiles(ctx context.Context, owner, repo string, number int) ([]*github.CommitFile, error)
+	GetFileCommits(ctx context.Context, owner, repo, path, ref string, limit int) ([]*github.RepositoryCommit, error)
+	AddLabels(ctx context.Context, owner, repo string, number int, labels []string) error
+	RequestReviewers(ctx context.Context, owner, repo string, number int, reviewers []string) error
+	CreateComment(ctx context.Context, owner, repo string, number int, body string) error
+	CreateReview(ctx context.Context, owner, repo string, number int, review *github.PullRequestReviewRequest) error
+	UpsertComment(ctx context.Context, owner, repo string, number int, marker, body string) error
+	GetReviews(ctx context.Context, owner, repo string, number int) ([]*github.PullRequestReview, error)
+	GetReviewComments(ctx context.Context, owner, repo string, number int) ([]*github.PullRequestComment, error)
+	GetPRsForCommit(ctx context.Context, owner, repo, sha string) ([]*github.PullRequest, error)
+	UpdatePRBody(ctx context.Context, owner, repo string, number int, body string) error
+	GetWorkflowRuns(ctx context.Context, owner, repo, sha string) ([]*github.WorkflowRun, error)
+	CreateStatus(ctx context.Context, owner, repo, sha string, status *github.RepoStatus) error
+	GetPullRequest(ctx context.Context, owner, repo string, number int) (*github.PullRequest, error)
+	CreateRevertBranch(ctx context.Context, owner, repo, base, sha, branch, message string) error
+	CreateCherryPickBranch(ctx context.Context, owner, repo, base, sha, branch string) error
+	GetTags(ctx context.Context, owner, repo string) ([]string, error)
+	CreateTag(ctx context.Context, owner, repo, tag, sha string) error
+	CreateRelease(ctx context.Context, owner, repo, tag, sha, notes string) error
+	CommitFiles(ctx context.Context, owner, repo, base, branch, message string, files map[string]string) error
+	GetUpstream(ctx context.Context, owner, repo string) (string, error)
+	GetContributingGuide(ctx context.Context, owner, repo string) (string, error)
+	CountMergedPRs(ctx context.Context, owner, repo, author string) (int, error)
+	GetLanguages(ctx context.Context, owner, repo string) (map[string]int, error)
+	GetFileContent(ctx context.Context, owner, repo, path, ref string) (string, error)
+	UsePRContentForSquash(ctx context.Context, owner, repo string) error
 }
 
 // HooksManager interface for webhook management
 type HooksManager interface {
-	CreateHook(ctx context.Context, owner, repo, url string) error
+	CreateHook(ctx context.Context, owner, repo, url, secret string, events []string) error
 	DeleteHook(ctx context.Context, owner, repo string) error
+	// FailedDeliveries lists webhook deliveries since a time that never
+	// succeeded, and Redeliver asks GitHub to send one again
+	FailedDeliveries(ctx context.Context, owner, repo string, since time.Time) ([]*github.HookDelivery, error)
+	Redeliver(ctx context.Context, owner, repo string, deliveryID int64) error
 }
 
 // RateLimiter wraps rate.Limiter with a mutex for concurrent access
@@ -46,13 +488,40 @@ type RateLimiter struct {
 // Server handles HTTP requests for the ggquick service
 type Server struct {
 	logger    *log.Logger
-	config    *Config
+	configs   map[string]*Config
+	orgs      map[string]*OrgConfig
 	generator *ai.Generator
 	limiter   *RateLimiter
 	mu        sync.RWMutex
 	github    GitHubClient
 	hooks     HooksManager
+	jobs      *jobStore
+	events    *broker
+	scheduler *scheduler
+	notifier  notify.Notifier
+	execHooks *pipeline.ExecHooks
+	state     *stateFile
+	recorder  *recorder
+	sandboxed *fake.GitHub
+	pending   *pendingJobs
+	cancels   *jobCancels
+	queue     *jobQueue
+	backend   QueueBackend
+	locks     *localLocks
+	leader    *leader
+	repos     *repoFilter
+	users     *userStore
+	hookState *hookStore
+	languages *languageStore
+	activity  *activityStore
+	dedup     *idempotencyStore
+	sitemaps  *sitemapCache
+	caps      *authorCaps
+	wasm      *wasmModules
+	osv       *osv.Client
 	srv       *http.Server
+
+	userClients func(token string) UserClient
 }
 
 // New creates a new server instance
@@ -82,10 +551,77 @@ func New(logger *log.Logger, generator *ai.Generator, github GitHubClient, hooks
 		github:    github,
 		hooks:     hooks,
 		limiter:   limiter,
+		configs:   make(map[string]*Config),
+		orgs:      make(map[string]*OrgConfig),
+		jobs:      newJobStore(jobStoreSize),
+		events:    newBroker(),
+		pending:   &pendingJobs{jobs: make(map[string]*pendingJob)},
+		cancels:   &jobCancels{funcs: make(map[string]context.CancelFunc)},
+		queue:     newJobQueue(queueWorkers()),
+		locks:     &localLocks{held: make(map[string]bool)},
+		leader:    &leader{id: replicaID()},
+		users:     &userStore{accounts: make(map[string]*userAccount)},
+		hookState: &hookStore{reports: make(map[string]*hookReport)},
+		languages: &languageStore{prefs: make(map[string]string)},
+		activity:  &activityStore{days: make(map[string][]*activityDay)},
+		dedup:     &idempotencyStore{keys: make(map[string]*idempotentRequest)},
+		sitemaps:  newSitemapCache(),
+		caps:      newAuthorCaps(),
+		wasm:      &wasmModules{stages: make(map[string]pipeline.Stage)},
+		osv:       osv.New(""),
 		mu:        sync.RWMutex{},
+		scheduler: &scheduler{
+			lastSweep:     make(map[string]time.Time),
+			lastDigest:    make(map[string]time.Time),
+			lastRelease:   make(map[string]time.Time),
+			lastReconcile: make(map[string]time.Time),
+			redelivered:   make(map[string]redelivery),
+		},
 	}, nil
 }
 
+// SetNotifier sets where digests and alerts are delivered
+func (s *Server) SetNotifier(n notify.Notifier) {
+	s.notifier = n
+}
+
+// SetExecHooks sets the shell commands run around each job
+func (s *Server) SetExecHooks(h *pipeline.ExecHooks) {
+	s.execHooks = h
+}
+
+// notify delivers a message if a notifier is configured
+func (s *Server) notify(ctx context.Context, msg notify.Message) error {
+	if s.notifier == nil {
+		s.logger.Debug("No notifier configured, skipping: %s", msg.Subject)
+		return nil
+	}
+	return s.notifier.Notify(ctx, msg)
+}
+
+// notifyJob sends a per-repo job notification when enabled for the repo
+func (s *Server) notifyJob(job *Job, subject, text, link string, enabled func(*NotifyConfig) bool) {
+	config := s.repoConfig(job.Owner + "/" + job.Repo)
+	if config == nil || config.Notify == nil || !enabled(config.Notify) {
+		return
+	}
+
+	msg := notify.Message{
+		Repo:       config.FullName(),
+		Subject:    subject,
+		Text:       text,
+		Link:       link,
+		Recipients: config.Notify.Emails,
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := s.notify(ctx, msg); err != nil {
+			s.logger.Error("❌ Failed to send notification: %v", err)
+		}
+	}()
+}
+
 // Start starts the HTTP server
 func (s *Server) Start(ctx context.Context) error {
 	// Validate server state
@@ -93,10 +629,40 @@ func (s *Server) Start(ctx context.Context) error {
 		return fmt.Errorf("invalid server state: %w", err)
 	}
 
+	webhookIPs, pushIPs, err := ipFiltersFromEnv()
+	if err != nil {
+		return err
+	}
+	if s.repos, err = repoFilterFromEnv(); err != nil {
+		return err
+	}
+
 	mux := http.NewServeMux()
-	mux.HandleFunc("/webhook", s.handleWebhook)
+	mux.HandleFunc("/webhook", s.allowIPs(webhookIPs, s.recorded(maxWebhookSize, s.handleWebhook)))
 	mux.HandleFunc("/health", s.handleHealth)
 	mux.HandleFunc("/config", s.handleConfig)
+	mux.HandleFunc("/push", s.allowIPs(pushIPs, s.recorded(maxBodySize, s.handlePush)))
+	mux.HandleFunc("/jobs", cacheable(s.handleJobs))
+	mux.HandleFunc("/jobs/", cacheable(s.handleJob))
+	mux.HandleFunc("/events", s.handleEvents)
+	mux.HandleFunc("/stale", cacheable(s.handleStale))
+	mux.HandleFunc("/backfill", s.handleBackfill)
+	mux.HandleFunc("/revert", s.handleRevert)
+	mux.HandleFunc("/backport", s.handleBackport)
+	mux.HandleFunc("/translate", s.handleTranslate)
+	mux.HandleFunc("/action-items", s.handleActionItems)
+	mux.HandleFunc("/release", s.handleRelease)
+	mux.HandleFunc("/reports", cacheable(s.handleReports))
+	mux.HandleFunc("/providers", s.handleProviders)
+	mux.HandleFunc("/prompt", s.handlePrompt)
+	mux.HandleFunc("/rules", s.handleRules)
+	mux.HandleFunc("/orgs", s.handleOrgs)
+	mux.HandleFunc("/users", s.handleUsers)
+	mux.HandleFunc("/hooks", s.handleHooks)
+	mux.HandleFunc("/admin/export", s.handleExport)
+	mux.HandleFunc("/admin/import", s.handleImport)
+	mux.HandleFunc("/sandbox", s.handleSandbox)
+	mux.HandleFunc("/sandbox/prs", s.handleSandboxPRs)
 
 	// Get server address from environment
 	addr := ":8080" // Default port
@@ -108,7 +674,17 @@ func (s *Server) Start(ctx context.Context) error {
 
 	s.srv = &http.Server{
 		Addr:    addr,
-		Handler: mux,
+		Handler: s.requireClientCerts(mux),
+	}
+
+	// Serve TLS directly when a certificate is configured, e.g. for mTLS
+	tlsCert, tlsKey := os.Getenv("GGQUICK_TLS_CERT"), os.Getenv("GGQUICK_TLS_KEY")
+	if tlsCert != "" {
+		tlsConfig, err := tlsConfigFromEnv()
+		if err != nil {
+			return fmt.Errorf("invalid TLS config: %w", err)
+		}
+		s.srv.TLSConfig = tlsConfig
 	}
 
 	// Single, clear startup sequence
@@ -116,17 +692,10 @@ func (s *Server) Start(ctx context.Context) error {
 	s.logger.Info("🔧 Debug mode: %v", s.logger.IsDebug())
 
 	// Check environment
-	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
-		s.logger.Success("✅ GITHUB_TOKEN configured")
-	} else {
-		s.logger.Error("❌ GITHUB_TOKEN not configured")
-		return fmt.Errorf("GITHUB_TOKEN not configured")
-	}
-	if key := os.Getenv("OPENAI_API_KEY"); key != "" {
-		s.logger.Success("✅ OPENAI_API_KEY configured")
-	} else {
-		s.logger.Error("❌ OPENAI_API_KEY not configured")
-		return fmt.Errorf("OPENAI_API_KEY not configured")
+	if s.sandboxed != nil {
+		s.logger.Warning("Sandbox mode: GitHub and the AI provider are fakes, pull requests are listed at /sandbox")
+	} else if err := checkCredentials(s.logger); err != nil {
+		return err
 	}
 
 	// Initialize components
@@ -135,6 +704,18 @@ func (s *Server) Start(ctx context.Context) error {
 	s.logger.Success("✅ GitHub client ready")
 	s.logger.Success("✅ Git hooks ready")
 	s.logger.Success("✅ Server initialized")
+	if s.srv.TLSConfig != nil && s.srv.TLSConfig.ClientCAs != nil {
+		s.logger.Success("✅ Client certificates required for changes")
+	}
+	if webhookIPs != nil {
+		s.logger.Success("✅ /webhook limited to GitHub hook IP ranges")
+	}
+	if webhookSecret() == "" && s.sandboxed == nil {
+		s.logger.Warning("GGQUICK_WEBHOOK_SECRET isn't set, GitHub webhooks will be refused")
+	}
+	if pushIPs != nil {
+		s.logger.Success("✅ /push limited to allowlisted networks")
+	}
 
 	// Start HTTP server
 	s.logger.Loading("🌐 Starting HTTP server on %s...", addr)
@@ -142,11 +723,31 @@ func (s *Server) Start(ctx context.Context) error {
 	s.logger.Info("   • /health - Server health check")
 	s.logger.Info("   • /config - Repository configuration")
 	s.logger.Info("   • /webhook - GitHub event handling")
+	s.logger.Info("   • /push - Git hook event handling")
+	s.logger.Info("   • /jobs - PR generation jobs")
+	s.logger.Info("   • /events - Server event stream")
+	s.logger.Info("   • /stale - Stale branches without PRs")
+	s.logger.Info("   • /backfill - Generate PRs for recent branches")
+	s.logger.Info("   • /revert - Open PRs reverting merged changes")
+	s.logger.Info("   • /backport - Open PRs backporting merged changes")
+	s.logger.Info("   • /release - Open release PRs with generated notes")
+	s.logger.Info("   • /reports - Repository activity reports")
+	s.logger.Info("   • /providers - AI provider health")
+	s.logger.Info("   • /prompt - Prompt dry runs")
+	s.logger.Info("   • /rules - Branch, label and reviewer rules")
+	s.logger.Info("   • /orgs - Org-level settings")
+	s.logger.Info("   • /admin/export, /admin/import - Configuration backup")
 
 	errCh := make(chan error, 1)
 	go func() {
 		s.logger.Debug("Starting server on %s", addr)
-		if err := s.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if tlsCert != "" {
+			err = s.srv.ListenAndServeTLS(tlsCert, tlsKey)
+		} else {
+			err = s.srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			s.logger.Error("❌ Server error: %v", err)
 			errCh <- fmt.Errorf("server error: %w", err)
 		}
@@ -155,6 +756,12 @@ func (s *Server) Start(ctx context.Context) error {
 
 	s.logger.Success("✅ Server is ready to accept connections")
 
+	go s.runScheduler(ctx)
+	if s.backend != nil {
+		s.runFeeders(ctx)
+		s.logger.Success("✅ Sharing the job queue with other replicas")
+	}
+
 	// Wait for either context cancellation or server error
 	select {
 	case err := <-errCh:
@@ -187,10 +794,15 @@ func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	body, err := peekBody(r, maxBodySize)
+	if err != nil {
+		s.logger.Error("❌ Failed to read configuration: %v", err)
+		http.Error(w, "Failed to read request", http.StatusBadRequest)
+		return
+	}
 	var config Config
-	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+	if err := decodeJSON(w, r, maxBodySize, &config); err != nil {
 		s.logger.Error("❌ Failed to decode configuration: %v", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
@@ -205,14 +817,43 @@ func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
 		config.Owner = parts[len(parts)-2]
 		config.Name = parts[len(parts)-1]
 	}
+	if !s.allowRepo(w, config.FullName()) {
+		return
+	}
+	s.mu.RLock()
+	existing := s.configs[config.FullName()]
+	s.mu.RUnlock()
+	if !s.authorizeConfig(w, r, body, &config, existing) {
+		return
+	}
+	for _, path := range config.Wasm {
+		if _, err := s.wasm.load(path); err != nil {
+			s.logger.Error("❌ %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
 
 	s.logger.Success("✅ Parsed repository details:")
 	s.logger.Info("   📦 Repository: %s", config.RepoURL)
 	s.logger.Info("   👤 Owner: %s", config.Owner)
 	s.logger.Info("   📝 Name: %s", config.Name)
 
+	// Forks open PRs against their parent
+	if config.Upstream == "" {
+		upstream, err := s.github.GetUpstream(r.Context(), config.Owner, config.Name)
+		if err != nil {
+			s.logger.Warning("Failed to check for upstream: %v", err)
+		}
+		config.Upstream = upstream
+	}
+	if config.Upstream != "" {
+		s.logger.Info("   🍴 Fork of: %s", config.Upstream)
+	}
+
 	// Get default branch
-	defaultBranch, err := s.github.GetDefaultBranch(r.Context(), config.Owner, config.Name)
+	targetOwner, targetName := config.prTarget()
+	defaultBranch, err := s.github.GetDefaultBranch(r.Context(), targetOwner, targetName)
 	if err != nil {
 		s.logger.Error("❌ Failed to get default branch: %v", err)
 		http.Error(w, "Failed to get repository details", http.StatusInternalServerError)
@@ -221,30 +862,42 @@ func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
 	config.DefaultBranch = defaultBranch
 	s.logger.Info("   🌿 Default branch: %s", defaultBranch)
 
+	// A merged commit can't be rewritten, so set the squash default up front
+	if config.SquashMessage {
+		if err := s.github.UsePRContentForSquash(r.Context(), targetOwner, targetName); err != nil {
+			s.logger.Warning("Failed to set squash commit message default (needs admin access): %v", err)
+		} else {
+			s.logger.Info("   🔀 Squash merges use the PR title and body")
+		}
+	}
+
 	// Store config in memory
 	s.logger.Loading("💾 Storing configuration...")
 	s.mu.Lock()
-	s.config = &config
+	// The checks above were against existing, so a config stored meanwhile
+	// would skip them
+	if s.configs[config.FullName()] != existing {
+		s.mu.Unlock()
+		s.logger.Error("❌ Configuration of %s changed meanwhile", config.FullName())
+		http.Error(w, "Configuration changed meanwhile, retry", http.StatusConflict)
+		return
+	}
+	if existing != nil && config.PushSecret == "" {
+		// Reapplying without the secret mustn't turn signing off
+		config.PushSecret = existing.PushSecret
+	}
+	s.configs[config.FullName()] = &config
 	s.mu.Unlock()
+	s.persist()
 	s.logger.Success("✨ Configuration stored successfully")
 
 	// Create webhook
 	s.logger.Loading("🔗 Setting up GitHub webhook...")
-	// Use fly.io domain for production, fallback to local address for development
-	webhookURL := "https://ggquick.fly.dev/webhook"
-	if os.Getenv("FLY_APP_NAME") == "" {
-		// For local development, use the actual server port
-		port := os.Getenv("PORT")
-		if port == "" {
-			port = "8080"
-		}
-		webhookURL = fmt.Sprintf("http://localhost:%s/webhook", port)
-	}
-	s.logger.Debug("Webhook URL: %s", webhookURL)
+	s.logger.Debug("Webhook URL: %s", webhookURL())
 
 	// Check webhook status
 	s.logger.Loading("🔍 Checking webhook status...")
-	if err := s.hooks.CreateHook(r.Context(), config.Owner, config.Name, webhookURL); err != nil {
+	if err := s.hooks.CreateHook(r.Context(), config.Owner, config.Name, webhookURL(), webhookSecret(), config.webhookEvents()); err != nil {
 		s.logger.Error("❌ Failed to manage webhook: %v", err)
 		http.Error(w, "Failed to manage webhook", http.StatusInternalServerError)
 		return
@@ -262,6 +915,49 @@ func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
 	s.logger.Success("🔄 Ready to process Git events for %s/%s", config.Owner, config.Name)
 }
 
+// webhookURL returns the address GitHub should deliver events to
+func webhookURL() string {
+	return BaseURL() + "/webhook"
+}
+
+// webhookSecret returns the secret GitHub signs webhook deliveries with
+func webhookSecret() string {
+	return os.Getenv("GGQUICK_WEBHOOK_SECRET")
+}
+
+// verifyWebhook reports whether a delivery may be handled: signed by
+// GitHub with the webhook secret, replayed with the admin token, or sent
+// to a sandbox, whose deliveries are made up by hand
+func (s *Server) verifyWebhook(r *http.Request, payload []byte) bool {
+	if s.sandboxed != nil || isAdmin(r) {
+		return true
+	}
+	secret := webhookSecret()
+	if secret == "" {
+		s.logger.Warning("Refused webhook delivery: GGQUICK_WEBHOOK_SECRET isn't set")
+		return false
+	}
+	if err := github.ValidateSignature(r.Header.Get(github.SHA256SignatureHeader), payload, []byte(secret)); err != nil {
+		s.logger.Warning("Refused webhook delivery: %v", err)
+		return false
+	}
+	return true
+}
+
+// BaseURL returns the server's public address
+func BaseURL() string {
+	// Use fly.io domain for production, fallback to local address for development
+	if os.Getenv("FLY_APP_NAME") != "" {
+		return "https://ggquick.fly.dev"
+	}
+	// For local development, use the actual server port
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	return fmt.Sprintf("http://localhost:%s", port)
+}
+
 // handleWebhook handles incoming GitHub webhook events
 func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 	s.logger.Loading("📥 Processing incoming webhook...")
@@ -281,14 +977,20 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse webhook event
-	payload, err := io.ReadAll(r.Body)
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookSize))
 	if err != nil {
 		s.logger.Error("❌ Failed to read request body: %v", err)
-		http.Error(w, "Failed to read request", http.StatusBadRequest)
+		http.Error(w, "Failed to read request", http.StatusRequestEntityTooLarge)
 		return
 	}
 	defer r.Body.Close()
 
+	// Deliveries change repository state, so only GitHub's are handled
+	if !s.verifyWebhook(r, payload) {
+		http.Error(w, "Invalid webhook signature", http.StatusUnauthorized)
+		return
+	}
+
 	event, err := github.ParseWebHook(github.WebHookType(r), payload)
 	if err != nil {
 		s.logger.Error("❌ Failed to parse webhook: %v", err)
@@ -296,18 +998,37 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Redeliveries keep their delivery ID, so each is handled once
+	delivery := r.Header.Get(deliveryHeader)
+	if delivery != "" && idempotencyKeyPattern.MatchString(delivery) {
+		delivery = "delivery:" + delivery
+		if _, claimed := s.claimIdempotent(r.Context(), delivery); !claimed {
+			s.logger.Info("ℹ️ Ignoring repeated delivery %s", r.Header.Get(deliveryHeader))
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	} else {
+		delivery = ""
+	}
+
 	// Handle push event
 	switch e := event.(type) {
 	case *github.PushEvent:
+		// Branch deletions and tag pushes carry no head commit to describe
+		if e.GetRepo().GetFullName() == "" || !strings.HasPrefix(e.GetRef(), "refs/heads/") ||
+			e.GetDeleted() || e.GetHeadCommit().GetID() == "" {
+			s.logger.Info("ℹ️ Ignoring push without a branch head commit")
+			break
+		}
 		s.logger.Success("✅ Received push event")
 		s.logger.Info("📝 Repository: %s", *e.Repo.FullName)
 		s.logger.Info("📝 Branch: %s", strings.TrimPrefix(*e.Ref, "refs/heads/"))
+		if !s.allowRepo(w, e.GetRepo().GetFullName()) {
+			return
+		}
 
 		// Get stored config
-		s.mu.RLock()
-		config := s.config
-		s.mu.RUnlock()
-
+		config := s.repoConfig(e.GetRepo().GetFullName())
 		if config == nil {
 			s.logger.Error("❌ No repository configuration found")
 			http.Error(w, "Repository not configured", http.StatusBadRequest)
@@ -315,15 +1036,48 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 		}
 
 		s.logger.Info("📝 Using stored config for %s/%s", config.Owner, config.Name)
+		s.checkHooks(config.FullName(), e)
 
 		// Process push event
-		if err := s.processPushEvent(r.Context(), e); err != nil {
+		if err := s.processPushEvent(r.Context(), config, e, delivery); err != nil {
 			s.logger.Error("❌ Failed to process push event: %v", err)
+			if delivery != "" {
+				// Let a redelivery try again
+				s.forgetIdempotent(delivery)
+			}
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		s.logger.Success("✨ Push event processed successfully")
+		// GitHub gives up on deliveries after 10 seconds, so the job's
+		// outcome is reported through GET /jobs and events instead
+		s.logger.Success("✨ Push event queued")
+		w.WriteHeader(http.StatusAccepted)
+		return
+
+	case *github.PullRequestEvent:
+		s.handlePullRequestEvent(e)
+
+	case *github.IssueCommentEvent:
+		s.handleIssueCommentEvent(e)
+
+	case *github.PullRequestReviewEvent:
+		s.handlePullRequestReviewEvent(e)
+
+	case *github.IssuesEvent:
+		s.handleIssuesEvent(e)
+
+	case *github.ReleaseEvent:
+		s.handleReleaseEvent(e)
+
+	case *github.CheckRunEvent:
+		s.handleCheckRunEvent(r.Context(), e)
+
+	case *github.StatusEvent:
+		s.handleStatusEvent(r.Context(), e)
+
+	case *github.CheckSuiteEvent:
+		s.handleCheckSuiteEvent(r.Context(), e)
 
 	default:
 		s.logger.Info("ℹ️ Ignoring unsupported event type: %s", github.WebHookType(r))
@@ -343,8 +1097,10 @@ func (s *Server) checkRateLimit(ctx context.Context) error {
 	return nil
 }
 
-// processPushEvent processes a GitHub push event and creates a PR if needed
-func (s *Server) processPushEvent(ctx context.Context, event *github.PushEvent) error {
+// processPushEvent queues a job for a GitHub push event. The job outlives
+// the request; when it fails, delivery is forgotten so a redelivery
+// runs it again.
+func (s *Server) processPushEvent(ctx context.Context, config *Config, event *github.PushEvent, delivery string) error {
 	// Check rate limit before processing
 	if err := s.checkRateLimit(ctx); err != nil {
 		s.logger.Error("❌ Rate limit check failed: %v", err)
@@ -353,51 +1109,792 @@ func (s *Server) processPushEvent(ctx context.Context, event *github.PushEvent)
 
 	s.logger.Loading("🔄 Processing push event...")
 
-	// Get stored config
-	s.mu.RLock()
-	config := s.config
-	s.mu.RUnlock()
-
 	// Get commit info
 	branch := strings.TrimPrefix(*event.Ref, "refs/heads/")
 	commitMsg := *event.HeadCommit.Message
 	commitSHA := *event.HeadCommit.ID
 
-	s.logger.Info("📝 Processing commit: %s", commitSHA)
+	if !config.branchAllowed(branch) {
+		s.logger.Info("ℹ️ Skipping %s, excluded by branch rules", branch)
+		return nil
+	}
+
+	job, resumed := s.jobFor(config, branch, commitSHA)
+	author := event.GetSender().GetLogin()
+	if author == "" {
+		author = event.GetPusher().GetName()
+	}
+	s.setAuthor(job, author, false)
+	// The delivery is signed by GitHub, so its sender can be counted
+	s.setCapAuthor(job, event.GetSender().GetLogin())
+	if resumed {
+		s.logger.Info("▶️ Resuming job %s now that %s is pushed", job.ID, branch)
+	}
+	s.events.publish(jobEvent(EventPushReceived, job, commitMsg))
+	if s.holdOverCap(config, job, commitMsg, PriorityNormal) {
+		return nil
+	}
+	done := s.enqueueJob(context.WithoutCancel(ctx), config, job, commitMsg, PriorityNormal)
+	go func() {
+		if err := <-done; err != nil {
+			s.logger.Error("❌ Failed to process push event: %v", err)
+			if delivery != "" {
+				s.forgetIdempotent(delivery)
+			}
+		}
+	}()
+	return nil
+}
+
+// runJob generates PR content for a job's branch and opens the PR
+func (s *Server) runJob(ctx context.Context, config *Config, job *Job, commitMsg string) error {
+	if s.cancelled(job.ID) {
+		s.logger.Info("ℹ️ Skipping cancelled job %s", job.ID)
+		return nil
+	}
+	unlock, err := s.lockBranch(ctx, config, job)
+	if err != nil {
+		return s.failJob(job, fmt.Errorf("failed to lock branch: %w", err))
+	}
+	defer unlock()
+	ctx, done := s.startJob(ctx, job, config.Timeouts.job())
+	defer done()
+	go s.watchCancel(ctx, job.ID)
+
+	s.jobs.update(job.ID, func(j *Job) { j.Status = JobRunning })
+	s.events.publish(jobEvent(EventGenerationStarted, job, ""))
+	s.publishStatus(job, statusPending, "Generating description")
+
+	s.logger.Info("📝 Processing commit: %s", job.SHA)
 	s.logger.Info("📝 Message: %s", commitMsg)
 
-	// Get repository info
-	repoInfo := ai.RepoInfo{
-		BranchName:    branch,
-		CommitMessage: commitMsg,
-		Changes:       make(map[string]ai.Change),
+	repoInfo, analysis, err := s.prepareRepoInfo(ctx, config, job, commitMsg)
+	if branchMissing(err) {
+		// Committed locally but not pushed yet
+		s.parkJob(config, job, commitMsg)
+		return nil
 	}
+	base, comp := analysis.base, analysis.comp
+	infra, migrations, apiSpecs := analysis.infra, analysis.migrations, analysis.apiSpecs
+	vars := s.templateVars(config, job)
 
-	// Generate PR content
-	s.logger.Loading("🤖 Generating PR content...")
-	prContent, err := s.generator.GeneratePR(ctx, repoInfo)
+	jw := s.newJobWorkspace(config, job.Branch)
+	defer jw.cleanup()
+
+	// Don't spend tokens on a branch that doesn't compile
+	var smoke *smokeResult
+	if config.SmokeCheck {
+		smoke, err = s.smokeCheck(ctx, jw)
+		if err != nil {
+			s.logger.Warning("Smoke check skipped: %v", err)
+		} else if !smoke.build.OK() {
+			s.logger.Error("❌ Branch does not compile")
+			return s.failJob(job, fmt.Errorf("branch %s does not compile, skipping PR creation: %s",
+				job.Branch, truncateOutput(smoke.build.Output)))
+		}
+	}
+
+	stages, err := s.jobStages(config)
 	if err != nil {
-		s.logger.Error("❌ Failed to generate PR: %v", err)
-		return fmt.Errorf("failed to generate PR: %w", err)
+		s.logger.Error("❌ Failed to load pipeline stages: %v", err)
+		return s.failJob(job, err)
+	}
+	if err := pipeline.RunAnalyze(ctx, stages, &repoInfo); err != nil {
+		s.logger.Error("❌ Pipeline stage failed: %v", err)
+		return s.failJob(job, err)
+	}
+
+	hookPayload := &pipeline.ExecPayload{
+		JobID:         job.ID,
+		Repo:          config.FullName(),
+		Branch:        job.Branch,
+		SHA:           job.SHA,
+		CommitMessage: repoInfo.CommitMessage,
+		Notes:         repoInfo.Notes,
+	}
+	if err := s.execHooks.Run(ctx, pipeline.PhasePreGeneration, hookPayload); err != nil {
+		s.logger.Error("❌ %v", err)
+		return s.failJob(job, err)
+	}
+	repoInfo.CommitMessage = hookPayload.CommitMessage
+	repoInfo.Notes = hookPayload.Notes
+
+	// Generate PR content
+	var prContent *ai.PRContent
+	summaryTokens := 0
+	if comp != nil && config.botMode(job.Branch) != BotTemplate {
+		repoInfo.Summaries, summaryTokens = s.summarizeFiles(ctx, config, comp.Files)
+	}
+	if comp != nil && config.CommitTopics != nil && config.CommitTopics.Enabled && config.botMode(job.Branch) != BotTemplate {
+		genCtx, cancel := context.WithTimeout(ctx, config.Timeouts.generate())
+		repoInfo.Topics = s.commitTopics(genCtx, job, comp.Commits)
+		cancel()
+	}
+	lines := 0
+	if comp != nil {
+		lines = changedLines(comp.Files)
+	}
+	if repoInfo.Models = s.routeModels(config, lines); len(repoInfo.Models) > 0 {
+		s.logger.Info("🤖 Models: %s", strings.Join(repoInfo.Models, ", "))
+	}
+	if config.botMode(job.Branch) == BotTemplate {
+		s.logger.Info("🤖 Bot branch, building PR from commit messages")
+		var commits []*github.RepositoryCommit
+		if comp != nil {
+			commits = comp.Commits
+		}
+		prContent = templatePR(job.Branch, repoInfo.CommitMessage, commits)
+	} else {
+		s.logger.Loading("🤖 Generating PR content...")
+		genCtx, cancel := context.WithTimeout(ctx, config.Timeouts.generate())
+		prContent, err = s.generator.GeneratePR(genCtx, repoInfo)
+		cancel()
+		if err != nil {
+			s.logger.Error("❌ Failed to generate PR: %v", err)
+			return s.failJob(job, fmt.Errorf("failed to generate PR: %w", err))
+		}
+		if err := s.checkContent(ctx, config, prContent.Title, prContent.Description); err != nil {
+			if !config.ContentFilter.regenerate() {
+				s.logger.Error("❌ %v", err)
+				return s.failJob(job, err)
+			}
+			s.logger.Warning("%v, regenerating", err)
+			retryInfo := repoInfo
+			retryInfo.Instructions = strings.TrimSpace(repoInfo.Instructions + "\n\n" + contentPolicy(config.ContentFilter, err))
+			genCtx, cancel := context.WithTimeout(ctx, config.Timeouts.generate())
+			retry, genErr := s.generator.GeneratePR(genCtx, retryInfo)
+			cancel()
+			if genErr != nil {
+				s.logger.Error("❌ Failed to regenerate PR: %v", genErr)
+				return s.failJob(job, fmt.Errorf("failed to regenerate PR: %w", genErr))
+			}
+			retry.TokensUsed += prContent.TokensUsed
+			prContent = retry
+			if err := s.checkContent(ctx, config, prContent.Title, prContent.Description); err != nil {
+				s.logger.Error("❌ %v", err)
+				s.jobs.update(job.ID, func(j *Job) { j.Tokens = prContent.TokensUsed })
+				return s.failJob(job, err)
+			}
+		}
+	}
+	prContent.TokensUsed += summaryTokens
+	s.jobs.update(job.ID, func(j *Job) {
+		j.Tokens, j.Model, j.Provider = prContent.TokensUsed, prContent.Model, prContent.Provider
+	})
+	var references, unverified int
+	if comp != nil {
+		references, unverified = s.checkReferences(config, job, prContent, comp.Files)
+	}
+	if prContent.Model != "" {
+		// Closing keywords the commits use are meant to close issues
+		intended := repoInfo.CommitMessage
+		if comp != nil {
+			for _, c := range comp.Commits {
+				intended += "\n" + c.GetCommit().GetMessage()
+			}
+		}
+		prContent.Description = sanitize(config, prContent.Description, intended)
+	}
+	s.events.publish(jobEvent(EventGenerationFinished, job, prContent.Title))
+
+	sections := splitGenerated(prContent.Description)
+	labels := config.ruleValues(RuleLabel, job.Branch)
+	reviewers := config.ruleValues(RuleReviewer, job.Branch)
+	draft := false
+	if prContent.Model != "" && comp != nil {
+		score := confidenceScore(prContent, comp.Files, references, unverified)
+		s.jobs.update(job.ID, func(j *Job) { j.Confidence = score })
+		s.logger.Info("🎯 Description confidence: %.2f", score)
+		if config.Confidence != nil && score < config.Confidence.Threshold {
+			s.logger.Warning("Confidence below %.2f, opening as draft for a human description", config.Confidence.Threshold)
+			sections[SectionConfidence] = confidenceSection(score, config.Confidence.Threshold)
+			labels = append(labels, config.Confidence.label())
+			draft = true
+		}
+	}
+	var trailers []analyze.Trailer
+	var dcoMissing string
+	var docItems []string
+	var conflicts []overlap
+	if comp != nil {
+		trailers = analyze.CollectTrailers(commitMessages(comp.Commits))
+		breaking := analyze.DetectBreaking(fileDiffs(comp.Files), config.BreakingLanguages...)
+		if len(breaking) > 0 {
+			s.logger.Warning("%d potential breaking change(s) detected", len(breaking))
+			sections[SectionBreaking] = breakingSection(breaking)
+			labels = append(labels, labelBreakingChange)
+		}
+		if config.ConflictCheck != nil && config.ConflictCheck.Enabled {
+			if conflicts = s.predictConflicts(ctx, config, job.Branch, base, comp.Files); len(conflicts) > 0 {
+				s.logger.Warning("%d open PR(s) change the same files", len(conflicts))
+				sections[SectionConflicts] = conflictSection(conflicts)
+			}
+		}
+		if len(infra) > 0 {
+			for _, c := range infra {
+				if c.Destructive() {
+					s.logger.Warning("Branch may destroy infrastructure: %s", c.Resource)
+					labels = append(labels, labelDestructiveInfra)
+					break
+				}
+			}
+			sections[SectionInfra] = infraSection(infra)
+		}
+		if len(migrations) > 0 {
+			s.logger.Info("🗄️ Branch includes %d migration(s)", len(migrations))
+			labels = append(labels, labelMigration)
+			sections[SectionMigrations] = migrationSection(migrations)
+		}
+		if len(apiSpecs) > 0 {
+			if apiBreaking(apiSpecs) && !contains(labels, labelBreakingChange) {
+				s.logger.Warning("Branch removes or changes API elements")
+				labels = append(labels, labelBreakingChange)
+			}
+			sections[SectionAPI] = apiSection(apiSpecs)
+		}
+		if touched := protectedFiles(config.Protected, comp.Files); len(touched) > 0 {
+			s.logger.Warning("PR touches %d protected file(s), opening as draft", len(touched))
+			sections[SectionProtected] = protectedSection(touched, config.Protected.Reviewers)
+			draft = true
+			for _, r := range config.Protected.Reviewers {
+				if !contains(reviewers, r) {
+					reviewers = append(reviewers, r)
+				}
+			}
+		}
+		if large := analyze.LargeFiles(fileDiffs(comp.Files), config.LargeFiles.maxLines()); len(large) > 0 {
+			s.logger.Warning("%d binary or large file(s) in diff", len(large))
+			sections[SectionLargeFiles] = largeFileSection(large)
+			if label := config.LargeFiles.label(); label != "" {
+				labels = append(labels, label)
+			}
+		}
+		sections[SectionDiffstat] = diffStatSection(comp.Files)
+		if config.DependencyAudit {
+			if changes := analyze.DependencyChanges(fileDiffs(comp.Files)); len(changes) > 0 {
+				deps := s.auditDependencies(ctx, changes)
+				if vulnerable(deps) {
+					s.logger.Warning("Branch adds dependencies with known vulnerabilities")
+					labels = append(labels, labelVulnerableDependency)
+				}
+				sections[SectionDependencies] = dependencySection(deps)
+			}
+		}
+		if config.License != nil {
+			header, err := regexp.Compile(config.License.Header)
+			if err != nil {
+				s.logger.Warning("License check skipped, invalid header pattern: %v", err)
+			} else if missing := analyze.MissingHeaders(fileDiffs(comp.Files), header, config.License.Files); len(missing) > 0 {
+				s.logger.Warning("%d new file(s) missing a license header", len(missing))
+				sections[SectionLicense] = licenseSection(missing)
+				labels = append(labels, config.License.label())
+			}
+		}
+		if config.DCO != nil {
+			shas := make([]string, 0, len(comp.Commits))
+			for _, c := range comp.Commits {
+				shas = append(shas, c.GetSHA())
+			}
+			if unsigned := analyze.UnsignedCommits(shas, commitMessages(comp.Commits)); len(unsigned) > 0 {
+				s.logger.Warning("%d commit(s) missing a sign-off", len(unsigned))
+				dcoMissing = dcoItem(unsigned, base)
+				labels = append(labels, config.DCO.label())
+			}
+		}
+		if len(config.DocRules) > 0 {
+			var docLabels []string
+			docItems, docLabels = checkDocRules(config.DocRules, comp.Files)
+			if len(docItems) > 0 {
+				s.logger.Warning("%d doc rule(s) not met", len(docItems))
+				labels = append(labels, docLabels...)
+			}
+		}
+		if config.Lint != nil && config.Lint.Command != "" {
+			issues, err := s.runLint(ctx, jw, config.Lint, fileDiffs(comp.Files))
+			if err != nil {
+				s.logger.Warning("Lint skipped: %v", err)
+			} else {
+				sections[SectionLint] = lintSection(config.Lint.Command, issues)
+			}
+		}
+		if config.Performance != nil {
+			sections[SectionPerformance] = s.performanceReport(ctx, jw, config.Performance, comp.Files)
+		}
+		if config.CommitReport {
+			genCtx, cancel := context.WithTimeout(ctx, config.Timeouts.generate())
+			sections[SectionCommits] = s.commitReport(genCtx, job, comp.Commits)
+			cancel()
+		}
+		if len(repoInfo.Topics) > 1 && config.CommitTopics.Titles {
+			sections[SectionTopics] = topicsSection(repoInfo.Topics)
+		}
+		if config.Size != nil {
+			genCtx, cancel := context.WithTimeout(ctx, config.Timeouts.generate())
+			section, large := s.sizeGuidance(genCtx, config, job, comp.Files, repoInfo.Summaries)
+			cancel()
+			if large {
+				sections[SectionSize] = section
+				labels = append(labels, config.Size.label())
+			}
+		}
+		if config.ReviewOrder && config.botMode(job.Branch) != BotTemplate {
+			genCtx, cancel := context.WithTimeout(ctx, config.Timeouts.generate())
+			sections[SectionReviewOrder] = s.reviewOrder(genCtx, job, comp.Files, repoInfo.Summaries)
+			cancel()
+		}
+		if config.Docs != nil {
+			if docs := s.relatedDocs(ctx, config.Docs, comp.Files); len(docs) > 0 {
+				sections[SectionDocs] = docsSection(docs)
+			}
+		}
+		if config.Ownership {
+			if people := s.peopleWithContext(ctx, config, job, base, comp.Files); len(people) > 0 {
+				sections[SectionPeople] = peopleSection(people)
+			}
+		}
+		if config.ImpactDiagram && touchesGo(comp.Files) {
+			section, err := s.impactSection(ctx, jw, comp.Files)
+			if err != nil {
+				s.logger.Warning("Impact analysis skipped: %v", err)
+			} else {
+				sections[SectionImpact] = section
+			}
+		}
+		if config.AffectedTargets {
+			section, err := s.targetsSection(ctx, jw, comp.Files)
+			if err != nil {
+				s.logger.Warning("Affected targets skipped: %v", err)
+			} else {
+				sections[SectionTargets] = section
+			}
+		}
+	}
+	if smoke != nil {
+		sections[SectionBuild] = smokeSection(smoke)
+	}
+	if footer := s.footerTemplate(config); footer != "" {
+		if sections[SectionFooter], err = expandTemplate("footer", footer, vars); err != nil {
+			s.logger.Warning("Footer template skipped: %v", err)
+		}
+	}
+
+	prContent.Description = config.Body.render(sections)
+	if err := pipeline.RunDecorate(ctx, stages, prContent); err != nil {
+		s.logger.Error("❌ Pipeline stage failed: %v", err)
+		return s.failJob(job, err)
+	}
+
+	hookPayload.Title = prContent.Title
+	hookPayload.Description = prContent.Description
+	if err := s.execHooks.Run(ctx, pipeline.PhasePostGeneration, hookPayload); err != nil {
+		s.logger.Error("❌ %v", err)
+		return s.failJob(job, err)
+	}
+	prContent.Title = hookPayload.Title
+	prContent.Description = hookPayload.Description
+
+	// Added last so stages and hooks can't drop mandatory items
+	if config.TitlePrefix != nil {
+		prefix := config.TitlePrefix.prefix(job.Branch, commitMsg)
+		prContent.Title = applyTitlePrefix(prContent.Title, prefix)
+	}
+	var items []string
+	for _, item := range s.checklist(config) {
+		expanded, err := expandTemplate("checklist", item, vars)
+		if err != nil {
+			s.logger.Warning("Checklist item left unexpanded: %v", err)
+			expanded = item
+		}
+		items = append(items, expanded)
+	}
+	if dcoMissing != "" {
+		items = append(items, dcoMissing)
+	}
+	items = append(items, docItems...)
+	if len(items) > 0 && config.Body.enabled(SectionChecklist) {
+		prContent.Description += "\n\n" + checklistSection(items)
+	}
+	if config.Attribution != nil && config.Attribution.Line && job.Author != "" {
+		prContent.Description += "\n\n" + attributionSection(job.Author)
+	}
+	// Trailers end the body so squash merges using it keep them
+	if len(trailers) > 0 {
+		prContent.Description += "\n\n" + trailerSection(trailers)
 	}
 
 	// Create PR
 	s.logger.Loading("📝 Creating PR...")
+	createCtx, cancelCreate := context.WithTimeout(ctx, config.Timeouts.createPR())
+	defer cancelCreate()
+	targetOwner, targetName := config.prTarget()
+	if titles, err := s.github.GetOpenPRTitles(createCtx, targetOwner, targetName); err != nil {
+		s.logger.Warning("Failed to check for duplicate titles: %v", err)
+	} else if title := uniqueTitle(prContent.Title, job, titles); title != prContent.Title {
+		s.logger.Info("ℹ️ An open PR already has this title, using %q", title)
+		prContent.Title = title
+	}
 	pr := &github.NewPullRequest{
 		Title:               github.String(prContent.Title),
 		Body:                github.String(prContent.Description),
-		Head:                github.String(branch),
-		Base:                github.String(config.DefaultBranch),
+		Head:                github.String(config.prHead(job.Branch)),
+		Base:                github.String(base),
+		Draft:               github.Bool(draft),
 		MaintainerCanModify: github.Bool(true),
 	}
 
-	_, err = s.github.CreatePullRequest(ctx, config.Owner, config.Name, pr)
+	created, err := s.createPR(createCtx, config, job, targetOwner, targetName, pr)
+	if branchMissing(err) {
+		s.parkJob(config, job, commitMsg)
+		return nil
+	}
 	if err != nil {
 		s.logger.Error("❌ Failed to create PR: %v", err)
-		return fmt.Errorf("failed to create PR: %w", err)
+		return s.failJob(job, fmt.Errorf("failed to create PR: %w", err))
+	}
+
+	// Fork tokens usually can't triage upstream, so fall back to suggesting
+	var skippedLabels, skippedReviewers []string
+	if len(labels) > 0 {
+		err := s.github.AddLabels(createCtx, targetOwner, targetName, created.GetNumber(), labels)
+		if forbidden(err) {
+			skippedLabels = labels
+		} else if err != nil {
+			s.logger.Warning("Failed to add labels: %v", err)
+		}
+	}
+	if len(reviewers) > 0 {
+		err := s.github.RequestReviewers(createCtx, targetOwner, targetName, created.GetNumber(), reviewers)
+		if forbidden(err) {
+			skippedReviewers = reviewers
+		} else if err != nil {
+			s.logger.Warning("Failed to request reviewers: %v", err)
+		}
+	}
+	if len(skippedLabels) > 0 || len(skippedReviewers) > 0 {
+		s.logger.Warning("No triage access to %s/%s, suggesting labels and reviewers instead", targetOwner, targetName)
+		s.suggestTriage(createCtx, targetOwner, targetName, created.GetNumber(), skippedLabels, skippedReviewers)
 	}
 
+	s.jobs.update(job.ID, func(j *Job) {
+		j.Status = JobSucceeded
+		j.PRNumber = created.GetNumber()
+		j.PRURL = created.GetHTMLURL()
+	})
+	s.recordActivity(job.ID)
+	event := jobEvent(EventPRCreated, job, prContent.Title)
+	event.PRURL = created.GetHTMLURL()
+	s.events.publish(event)
+	s.publishStatus(job, statusSuccess, "Description generated")
+	s.notifyJob(job, fmt.Sprintf("PR created: %s", prContent.Title),
+		fmt.Sprintf("ggquick opened a pull request for branch %s.", job.Branch),
+		created.GetHTMLURL(), func(n *NotifyConfig) bool { return n.PRCreated })
 	s.logger.Success("✨ PR created successfully")
+	if len(conflicts) > 0 {
+		s.notifyJob(job, fmt.Sprintf("Possible conflicts: %s", prContent.Title), conflictText(job.Branch, conflicts),
+			created.GetHTMLURL(), func(n *NotifyConfig) bool { return n.Conflicts })
+	}
+
+	// The pusher is the contributor, not the account opening the PR
+	if err := s.welcomeContributor(ctx, config, created, job.Author); err != nil {
+		s.logger.Warning("Failed to welcome %s: %v", job.Author, err)
+	}
+
+	if comp != nil {
+		if err := s.annotateHotspots(ctx, config, job, created, comp.Files); err != nil {
+			s.logger.Warning("Failed to comment on hotspots: %v", err)
+		}
+	}
+
+	hookPayload.PRNumber = created.GetNumber()
+	hookPayload.PRURL = created.GetHTMLURL()
+	if err := s.execHooks.Run(ctx, pipeline.PhasePostPR, hookPayload); err != nil {
+		s.logger.Warning("Post-PR hook failed: %v", err)
+	}
+	return nil
+}
+
+// contains reports whether list holds s
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// failJob marks a job as failed and returns the error
+func (s *Server) failJob(job *Job, err error) error {
+	// A cancelled job keeps its status and doesn't notify
+	if s.cancelled(job.ID) {
+		return err
+	}
+	s.jobs.update(job.ID, func(j *Job) {
+		j.Status = JobFailed
+		j.Error = err.Error()
+	})
+	s.recordActivity(job.ID)
+	s.events.publish(jobEvent(EventError, job, err.Error()))
+	s.publishStatus(job, statusFailure, err.Error())
+	s.notifyJob(job, fmt.Sprintf("PR generation failed: %s", job.Branch),
+		fmt.Sprintf("ggquick could not create a pull request for branch %s.\n\n%v", job.Branch, err),
+		"", func(n *NotifyConfig) bool { return n.Failures })
+	return err
+}
+
+// pushRequest is the body posted by the git hooks
+type pushRequest struct {
+	Ref  string `json:"ref"`
+	SHA  string `json:"sha"`
+	Repo string `json:"repo,omitempty"`
+	// Author is the pusher's GitHub login as reported by the hook
+	Author string `json:"author,omitempty"`
+	// Hooks fingerprints the hooks installed in the pusher's clone
+	Hooks string `json:"hooks,omitempty"`
+	// Files and Commits describe the branch as the pusher's clone sees it
+	Files   []string `json:"files,omitempty"`
+	Commits []string `json:"commits,omitempty"`
+}
+
+var (
+	shaPattern      = regexp.MustCompile(`^[0-9a-f]{7,64}$`)
+	repoNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+)
+
+// validate checks the ref, commit and repository are well formed
+func (p *pushRequest) validate() error {
+	branch := strings.TrimPrefix(p.Ref, "refs/heads/")
+	if branch == "" || branch == "HEAD" || strings.ContainsAny(branch, " ~^:?*[\\") {
+		return fmt.Errorf("invalid ref %q", p.Ref)
+	}
+	if p.SHA != "" && !shaPattern.MatchString(p.SHA) {
+		return fmt.Errorf("invalid sha %q", p.SHA)
+	}
+	if p.Repo != "" && !repoNamePattern.MatchString(p.Repo) {
+		return fmt.Errorf("invalid repo %q, want owner/name", p.Repo)
+	}
+	if p.Author != "" && (!reviewerPattern.MatchString(p.Author) || strings.Contains(p.Author, "/")) {
+		return fmt.Errorf("invalid author %q", p.Author)
+	}
+	return validateLocalChanges(p.Files, p.Commits)
+}
+
+// handlePush handles events posted by the local git hooks
+func (s *Server) handlePush(w http.ResponseWriter, r *http.Request) {
+	s.logger.Loading("📥 Processing incoming push...")
+	s.logger.Debug("Request from: %s", r.RemoteAddr)
+
+	if r.Method != http.MethodPost {
+		s.logger.Error("❌ Invalid method: %s", r.Method)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := s.checkRateLimit(r.Context()); err != nil {
+		s.logger.Error("❌ Rate limit exceeded: %v", err)
+		http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+
+	idempotencyKey := r.Header.Get(idempotencyHeader)
+	if idempotencyKey != "" && !idempotencyKeyPattern.MatchString(idempotencyKey) {
+		http.Error(w, "Invalid Idempotency-Key", http.StatusBadRequest)
+		return
+	}
+	body, err := peekBody(r, maxBodySize)
+	if err != nil {
+		s.logger.Error("❌ Failed to read push: %v", err)
+		http.Error(w, "Failed to read request", http.StatusBadRequest)
+		return
+	}
+	var push pushRequest
+	if err := decodeJSON(w, r, maxBodySize, &push); err != nil {
+		s.logger.Error("❌ Failed to decode push: %v", err)
+		return
+	}
+	author, ok := s.pushAuthor(r)
+	if !ok {
+		s.logger.Error("❌ Rejected push with unknown user key")
+		http.Error(w, "Unknown user key", http.StatusUnauthorized)
+		return
+	}
+	branch := strings.TrimPrefix(push.Ref, "refs/heads/")
+
+	config := s.repoConfig(push.Repo)
+	if config == nil {
+		s.logger.Error("❌ No repository configuration found")
+		http.Error(w, "Repository not configured", http.StatusBadRequest)
+		return
+	}
+	if !s.allowRepo(w, config.FullName()) {
+		return
+	}
+	if !s.verifyPush(w, r, config, body) {
+		return
+	}
+	if author != "" {
+		s.recordHooks(config.FullName(), author, push.Hooks)
+	} else {
+		s.recordHooks(config.FullName(), push.Author, push.Hooks)
+	}
+
+	if !config.branchAllowed(branch) {
+		s.logger.Info("ℹ️ Skipping %s, excluded by branch rules", branch)
+		writeJSON(w, http.StatusOK, map[string]string{"status": "skipped", "branch": branch})
+		return
+	}
+
+	if idempotencyKey != "" {
+		// Keys are scoped by repository so clients can't see others' jobs
+		idempotencyKey = "push:" + config.FullName() + ":" + idempotencyKey
+		if jobID, claimed := s.claimIdempotent(r.Context(), idempotencyKey); !claimed {
+			s.logger.Info("ℹ️ Repeated push for %s, returning job %s", branch, jobID)
+			s.writeIdempotent(w, jobID)
+			return
+		}
+	}
+
+	s.logger.Branch("🌿 Branch: %s", branch)
+	job, _ := s.jobFor(config, branch, push.SHA)
+	if idempotencyKey != "" {
+		s.finishIdempotent(r.Context(), idempotencyKey, job)
+	}
+	if author != "" {
+		s.setAuthor(job, author, true)
+		s.setCapAuthor(job, author)
+	} else {
+		s.setAuthor(job, push.Author, false)
+	}
+	if local := newLocalChanges(push.Files, push.Commits); local != nil {
+		s.jobs.update(job.ID, func(j *Job) { j.local = local })
+	}
+	s.events.publish(jobEvent(EventPushReceived, job, ""))
+
+	// Hooks fire and forget, so generation continues after the response
+	go func() {
+		ctx := context.Background()
+		commitMsg := branch
+		if push.SHA != "" {
+			fetchCtx, cancel := context.WithTimeout(ctx, config.Timeouts.github())
+			msg, err := s.github.GetCommitMessage(fetchCtx, config.Owner, config.Name, push.SHA)
+			cancel()
+			if err != nil {
+				s.logger.Debug("Failed to get commit message: %v", err)
+			} else {
+				commitMsg = msg
+			}
+		}
+		if commitMsg == branch && len(push.Commits) > 0 {
+			commitMsg = push.Commits[0]
+		}
+		if s.holdOverCap(config, job, commitMsg, PriorityInteractive) {
+			return
+		}
+		if err := <-s.enqueueJob(ctx, config, job, commitMsg, PriorityInteractive); err != nil {
+			s.logger.Error("❌ Failed to process push: %v", err)
+		}
+	}()
+
+	queued, _ := s.jobs.get(job.ID)
+	writeJSON(w, http.StatusAccepted, queued)
+}
+
+// handleJobs lists recent jobs
+func (s *Server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	jobs := s.jobs.list()
+	if s.backend != nil {
+		// Another replica may have run the job since
+		for i, job := range jobs {
+			if job.Status == JobQueued || job.Status == JobRunning || job.Status == JobWaiting {
+				if shared, ok := s.loadJob(job.ID); ok {
+					jobs[i] = shared
+				}
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+// handleJob returns (GET) or cancels (DELETE) a single job by ID, or
+// approves a held one
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/jobs/")
+	if held, ok := strings.CutSuffix(id, "/approve"); ok {
+		s.handleApprove(w, r, held)
+		return
+	}
+	job, ok := s.findJob(id)
+	if !ok {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, job)
+
+	case http.MethodDelete:
+		if !s.canCancel(w, r, job) {
+			return
+		}
+		cancel := s.cancelJob
+		if _, local := s.jobs.get(job.ID); !local {
+			cancel = func(string) (Job, error) { return s.cancelRemote(job) }
+		}
+		cancelled, err := cancel(job.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		writeJSON(w, http.StatusOK, cancelled)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// writeJSON writes v as a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// repoConfig returns the stored config for owner/name. An empty name
+// resolves to the only configured repository, if there is exactly one.
+func (s *Server) repoConfig(fullName string) *Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if fullName == "" {
+		if len(s.configs) != 1 {
+			return nil
+		}
+		for _, config := range s.configs {
+			return config
+		}
+	}
+	return s.configs[fullName]
+}
+
+// checkCredentials makes sure the GitHub token and OpenAI key are set
+func checkCredentials(logger *log.Logger) error {
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		logger.Success("✅ GITHUB_TOKEN configured")
+	} else {
+		logger.Error("❌ GITHUB_TOKEN not configured")
+		return fmt.Errorf("GITHUB_TOKEN not configured")
+	}
+	if key := os.Getenv("OPENAI_API_KEY"); key != "" {
+		logger.Success("✅ OPENAI_API_KEY configured")
+	} else {
+		logger.Error("❌ OPENAI_API_KEY not configured")
+		return fmt.Errorf("OPENAI_API_KEY not configured")
+	}
 	return nil
 }
 
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/ai"
+	"github.com/saint0x/ggquick/pkg/analyze"
+)
+
+// SectionSize suggests splitting PRs over the size limits
+const SectionSize = "size"
+
+// PR size defaults, and the most files sent to plan a split
+const (
+	defaultSizeMaxLines = 800
+	defaultSizeMaxFiles = 40
+	defaultSizeLabel    = "large-pr"
+	maxSplitFiles       = 200
+)
+
+// SizeConfig sets when a PR counts as large. Large PRs get the label and
+// a suggested split into smaller PRs. Tests count, lockfiles and
+// generated files don't.
+type SizeConfig struct {
+	// MaxLines changed, 800 by default
+	MaxLines int `json:"max_lines,omitempty"`
+	// MaxFiles changed, 40 by default
+	MaxFiles int `json:"max_files,omitempty"`
+	// Label defaults to large-pr
+	Label string `json:"label,omitempty"`
+}
+
+// validate checks the limits aren't negative
+func (c *SizeConfig) validate() error {
+	if c.MaxLines < 0 || c.MaxFiles < 0 {
+		return fmt.Errorf("size limits can't be negative")
+	}
+	return nil
+}
+
+// label returns the label for large PRs
+func (c *SizeConfig) label() string {
+	if c.Label != "" {
+		return c.Label
+	}
+	return defaultSizeLabel
+}
+
+// limits returns the line and file limits
+func (c *SizeConfig) limits() (maxLines, maxFiles int) {
+	maxLines, maxFiles = c.MaxLines, c.MaxFiles
+	if maxLines == 0 {
+		maxLines = defaultSizeMaxLines
+	}
+	if maxFiles == 0 {
+		maxFiles = defaultSizeMaxFiles
+	}
+	return maxLines, maxFiles
+}
+
+// reviewedFiles returns the changed files a reviewer reads, leaving out
+// lockfiles and generated files, and the lines they change
+func reviewedFiles(files []*github.CommitFile) ([]*github.CommitFile, int) {
+	var reviewed []*github.CommitFile
+	lines := 0
+	for _, f := range files {
+		if analyze.Relevance(f.GetFilename(), f.GetPatch()) <= analyze.TierTest {
+			reviewed = append(reviewed, f)
+			lines += f.GetChanges()
+		}
+	}
+	return reviewed, lines
+}
+
+// sizeGuidance returns the size section and true when the branch is over
+// the limits. The split is left out when the model can't plan one.
+func (s *Server) sizeGuidance(ctx context.Context, config *Config, job *Job, files []*github.CommitFile, summaries []ai.FileSummary) (string, bool) {
+	reviewed, lines := reviewedFiles(files)
+	maxLines, maxFiles := config.Size.limits()
+	count := len(reviewed)
+	if lines <= maxLines && count <= maxFiles {
+		return "", false
+	}
+	s.logger.Warning("Large PR: %d line(s) in %d file(s)", lines, count)
+
+	var groups []ai.SplitGroup
+	if config.botMode(job.Branch) != BotTemplate && count >= 2 {
+		if count > maxSplitFiles {
+			reviewed = reviewed[:maxSplitFiles]
+		}
+		summary := make(map[string]string, len(summaries))
+		for _, fs := range summaries {
+			summary[fs.File] = fs.Summary
+		}
+		split := make([]ai.ReviewFile, 0, len(reviewed))
+		for _, f := range reviewed {
+			split = append(split, ai.ReviewFile{
+				Path:      f.GetFilename(),
+				Status:    f.GetStatus(),
+				Additions: f.GetAdditions(),
+				Deletions: f.GetDeletions(),
+				Summary:   summary[f.GetFilename()],
+			})
+		}
+		s.logger.Loading("✂️ Planning a split of %d file(s)...", len(split))
+		var tokens int
+		var err error
+		groups, tokens, err = s.generator.SplitPlan(ctx, split)
+		s.jobs.update(job.ID, func(j *Job) { j.Tokens += tokens })
+		if err != nil {
+			s.logger.Warning("Split suggestion skipped: %v", err)
+		}
+	}
+	return sizeSection(lines, count, maxLines, maxFiles, groups), true
+}
+
+// sizeSection renders the size warning and the suggested split
+func sizeSection(lines, files, maxLines, maxFiles int, groups []ai.SplitGroup) string {
+	var b strings.Builder
+	b.WriteString("### PR Size\n\n")
+	fmt.Fprintf(&b, "This PR changes %d line(s) in %d file(s), over the suggested %d lines or %d files. Smaller PRs are reviewed faster and more carefully.\n", lines, files, maxLines, maxFiles)
+	if len(groups) == 0 {
+		return b.String()
+	}
+	b.WriteString("\n<details>\n<summary>Suggested split</summary>\n\n")
+	for i, group := range groups {
+		title := group.Title
+		if title == "" {
+			title = "Everything else"
+		}
+		fmt.Fprintf(&b, "%d. **%s** (%d file(s))\n", i+1, title, len(group.Files))
+		for _, f := range group.Files {
+			fmt.Fprintf(&b, "   - `%s`\n", f)
+		}
+	}
+	b.WriteString("\n</details>\n")
+	return b.String()
+}
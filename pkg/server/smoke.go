@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/sandbox"
+)
+
+// smokeTimeout limits each smoke check command
+const smokeTimeout = 3 * time.Minute
+
+// maxCheckOutput caps command output pasted into PR bodies
+const maxCheckOutput = 3000
+
+// smokeResult holds the build and vet outcomes for a branch
+type smokeResult struct {
+	build *sandbox.Result
+	vet   *sandbox.Result
+}
+
+// smokeCheck runs go build and go vet against the branch. Vet is skipped
+// when the build fails.
+func (s *Server) smokeCheck(ctx context.Context, jw *jobWorkspace) (*smokeResult, error) {
+	s.logger.Loading("🔨 Running build smoke check...")
+	ws, err := jw.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !ws.Exists("go.mod") {
+		return nil, fmt.Errorf("not a Go module")
+	}
+
+	result := &smokeResult{}
+	if result.build, err = ws.Run(ctx, smokeTimeout, sandbox.GoEnv, "go", "build", "./..."); err != nil {
+		return nil, err
+	}
+	if !result.build.OK() {
+		return result, nil
+	}
+	if result.vet, err = ws.Run(ctx, smokeTimeout, sandbox.GoEnv, "go", "vet", "./..."); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// smokeSection renders smoke check results for the PR body
+func smokeSection(r *smokeResult) string {
+	var b strings.Builder
+	b.WriteString("### Build check\n\n")
+	b.WriteString("| Check | Result | Time |\n")
+	b.WriteString("|-------|--------|------|\n")
+	for _, res := range []*sandbox.Result{r.build, r.vet} {
+		if res != nil {
+			fmt.Fprintf(&b, "| `%s` | %s | %s |\n", res.Command, checkStatus(res), res.Duration.Round(100*time.Millisecond))
+		}
+	}
+	for _, res := range []*sandbox.Result{r.build, r.vet} {
+		if res != nil && !res.OK() && strings.TrimSpace(res.Output) != "" {
+			fmt.Fprintf(&b, "\n<details>\n<summary><code>%s</code> output</summary>\n\n```\n%s\n```\n</details>\n",
+				res.Command, truncateOutput(res.Output))
+		}
+	}
+	return b.String()
+}
+
+// checkStatus describes a command result
+func checkStatus(r *sandbox.Result) string {
+	switch {
+	case r.TimedOut:
+		return "⏱️ timed out"
+	case r.OK():
+		return "✅ passed"
+	default:
+		return fmt.Sprintf("❌ failed (exit %d)", r.ExitCode)
+	}
+}
+
+// truncateOutput trims command output to fit in a PR body
+func truncateOutput(out string) string {
+	out = strings.TrimSpace(out)
+	if len(out) <= maxCheckOutput {
+		return out
+	}
+	return out[:maxCheckOutput] + "\n… (truncated)"
+}
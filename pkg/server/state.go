@@ -0,0 +1,212 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/saint0x/ggquick/pkg/secrets"
+)
+
+// stateFile persists repository configs across restarts, with secrets
+// encrypted under the master keyring
+type stateFile struct {
+	mu   sync.Mutex
+	path string
+	keys *secrets.Keyring
+}
+
+// stateDoc is the on-disk layout of the state file
+type stateDoc struct {
+	Version int          `json:"version"`
+	Repos   []*Config    `json:"repos"`
+	Orgs    []*OrgConfig `json:"orgs,omitempty"`
+	// Users are registered user tokens, encrypted like other secrets
+	Users []*userAccount `json:"users,omitempty"`
+	// Languages are preferences set with /ggquick lang, by login
+	Languages map[string]string `json:"languages,omitempty"`
+	// Activity is daily report activity, by repository
+	Activity map[string][]*activityDay `json:"activity,omitempty"`
+}
+
+// UseStateFile loads configs from path and saves them there on every
+// change. Secrets are encrypted with keys when it is non-nil; values
+// sealed with a retired key are re-encrypted with the current one.
+func (s *Server) UseStateFile(path string, keys *secrets.Keyring) error {
+	s.state = &stateFile{path: path, keys: keys}
+	if keys == nil {
+		s.logger.Warning("No master key configured, secrets in %s are stored unencrypted", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read state: %w", err)
+	}
+
+	var doc stateDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to decode state: %w", err)
+	}
+	// Hand-edited or older state gets the checks /config and imports make
+	for _, config := range doc.Repos {
+		if err := config.validate(); err != nil {
+			return fmt.Errorf("invalid config for %s in state: %w", config.FullName(), err)
+		}
+	}
+	for _, org := range doc.Orgs {
+		if err := org.validate(); err != nil {
+			return fmt.Errorf("invalid config for org %s in state: %w", org.Name, err)
+		}
+	}
+
+	rotate := false
+	err = mapSecrets(doc.Repos, func(secret string) (string, error) {
+		if !secrets.IsEncrypted(secret) {
+			// Written before a master key was configured
+			rotate = rotate || keys != nil
+			return secret, nil
+		}
+		if keys == nil {
+			return "", fmt.Errorf("state has encrypted secrets but no master key is configured")
+		}
+		plaintext, stale, err := keys.Decrypt(secret)
+		rotate = rotate || stale
+		return plaintext, err
+	})
+	if err != nil {
+		return err
+	}
+	for _, account := range doc.Users {
+		if !secrets.IsEncrypted(account.Token) {
+			rotate = rotate || keys != nil
+			continue
+		}
+		if keys == nil {
+			return fmt.Errorf("state has encrypted user tokens but no master key is configured")
+		}
+		token, stale, err := keys.Decrypt(account.Token)
+		if err != nil {
+			return fmt.Errorf("@%s: %w", account.Login, err)
+		}
+		account.Token = token
+		rotate = rotate || stale
+	}
+	for _, account := range doc.Users {
+		s.users.put(account)
+	}
+	for login, lang := range doc.Languages {
+		s.languages.set(login, lang)
+	}
+	s.activity.load(doc.Activity)
+
+	s.mu.Lock()
+	for _, config := range doc.Repos {
+		s.configs[config.FullName()] = config
+	}
+	for _, org := range doc.Orgs {
+		s.orgs[org.Name] = org
+	}
+	s.mu.Unlock()
+	s.logger.Success("✅ Loaded %d repository config(s) from %s", len(doc.Repos), path)
+
+	if rotate {
+		s.logger.Loading("🔑 Re-encrypting secrets with the current master key...")
+		return s.saveState()
+	}
+	return nil
+}
+
+// saveState writes all configs to the state file, if one is in use
+func (s *Server) saveState() error {
+	if s.state == nil {
+		return nil
+	}
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	doc := stateDoc{Version: 1, Repos: []*Config{}}
+	s.mu.RLock()
+	for _, config := range s.configs {
+		doc.Repos = append(doc.Repos, cloneConfig(config))
+	}
+	for _, org := range s.orgs {
+		doc.Orgs = append(doc.Orgs, org)
+	}
+	s.mu.RUnlock()
+	doc.Users = s.users.list()
+	doc.Languages = s.languages.all()
+	doc.Activity = s.activity.all()
+
+	if s.state.keys != nil {
+		if err := mapSecrets(doc.Repos, s.state.keys.Encrypt); err != nil {
+			return fmt.Errorf("failed to encrypt secrets: %w", err)
+		}
+		for _, account := range doc.Users {
+			token, err := s.state.keys.Encrypt(account.Token)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt secrets: %w", err)
+			}
+			account.Token = token
+		}
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	// Write then rename so a crash never leaves a truncated file
+	tmp, err := os.CreateTemp(filepath.Dir(s.state.path), ".ggquick-state-*")
+	if err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.state.path); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	return nil
+}
+
+// persist saves state after a config change, logging failures
+func (s *Server) persist() {
+	if err := s.saveState(); err != nil {
+		s.logger.Error("❌ Failed to save state: %v", err)
+	}
+}
+
+// mapSecrets replaces every non-empty secret in configs with fn's result
+func mapSecrets(configs []*Config, fn func(string) (string, error)) error {
+	for _, config := range configs {
+		if config.PushSecret != "" {
+			secret, err := fn(config.PushSecret)
+			if err != nil {
+				return fmt.Errorf("%s: %w", config.FullName(), err)
+			}
+			config.PushSecret = secret
+		}
+		for _, ext := range config.Extensions {
+			if ext.Secret == "" {
+				continue
+			}
+			secret, err := fn(ext.Secret)
+			if err != nil {
+				return fmt.Errorf("%s: %w", config.FullName(), err)
+			}
+			ext.Secret = secret
+		}
+	}
+	return nil
+}
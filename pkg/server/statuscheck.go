@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// Commit status states
+const (
+	statusPending = "pending"
+	statusSuccess = "success"
+	statusFailure = "failure"
+)
+
+// defaultStatusContext names the commit status when the config doesn't
+const defaultStatusContext = "ggquick"
+
+// maxStatusDescription is the longest description GitHub accepts
+const maxStatusDescription = 140
+
+// StatusCheckConfig sets a commit status on the pushed commit, pending
+// while the description is generated and success or failure after, so it
+// shows in the PR checks and branch protection can require it
+type StatusCheckConfig struct {
+	Enabled bool `json:"enabled"`
+	// Context names the status, ggquick by default
+	Context string `json:"context,omitempty"`
+}
+
+// context returns the status name
+func (c *StatusCheckConfig) context() string {
+	if c.Context == "" {
+		return defaultStatusContext
+	}
+	return c.Context
+}
+
+// publishStatus sets the job's commit status in the background, linking
+// to the job
+func (s *Server) publishStatus(job *Job, state, description string) {
+	config := s.repoConfig(job.Owner + "/" + job.Repo)
+	if config == nil || config.StatusCheck == nil || !config.StatusCheck.Enabled || job.SHA == "" {
+		return
+	}
+	if len(description) > maxStatusDescription {
+		description = description[:maxStatusDescription-3] + "..."
+	}
+	status := &github.RepoStatus{
+		State:       github.String(state),
+		Description: github.String(description),
+		Context:     github.String(config.StatusCheck.context()),
+		TargetURL:   github.String(BaseURL() + "/jobs/" + job.ID),
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), config.Timeouts.github())
+		defer cancel()
+		if err := s.github.CreateStatus(ctx, job.Owner, job.Repo, job.SHA, status); err != nil {
+			s.logger.Warning("Failed to set commit status on %s: %v", job.SHA, err)
+		}
+	}()
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/ai"
+	"github.com/saint0x/ggquick/pkg/analyze"
+)
+
+// Defaults for per-file summaries
+const (
+	defaultSummaryWorkers  = 8
+	defaultSummaryMinFiles = 20
+	maxSummaryFiles        = 200
+	// minSummaryTime is the least time left worth starting a summary with
+	minSummaryTime = 5 * time.Second
+)
+
+// SummaryConfig summarizes each file of a large change in parallel so the
+// description model sees every file, not only the diff that fits
+type SummaryConfig struct {
+	Enabled  bool `json:"enabled"`
+	MinFiles int  `json:"min_files,omitempty"` // files before summarizing, default 20
+	Workers  int  `json:"workers,omitempty"`   // concurrent model calls, default 8
+}
+
+// summarizeFiles fans per-file summaries out over a bounded worker pool.
+// The whole fan-out shares one generate timeout; files not started in
+// time and files whose summary fails are left out. It returns the
+// summaries in rank order and the tokens used.
+func (s *Server) summarizeFiles(ctx context.Context, config *Config, files []*github.CommitFile) ([]ai.FileSummary, int) {
+	c := config.Summaries
+	if c == nil || !c.Enabled {
+		return nil, 0
+	}
+	minFiles, workers := c.MinFiles, c.Workers
+	if minFiles <= 0 {
+		minFiles = defaultSummaryMinFiles
+	}
+	if workers <= 0 {
+		workers = defaultSummaryWorkers
+	}
+
+	// Lockfiles and generated files are already collapsed
+	var todo []*github.CommitFile
+	for _, f := range files {
+		if f.GetPatch() != "" && analyze.Mechanical(f.GetFilename(), f.GetPatch()) == "" {
+			todo = append(todo, f)
+		}
+	}
+	if len(todo) < minFiles {
+		return nil, 0
+	}
+	sort.SliceStable(todo, func(i, j int) bool {
+		return analyze.Relevance(todo[i].GetFilename(), todo[i].GetPatch()) < analyze.Relevance(todo[j].GetFilename(), todo[j].GetPatch())
+	})
+	if len(todo) > maxSummaryFiles {
+		todo = todo[:maxSummaryFiles]
+	}
+
+	s.logger.Loading("🤖 Summarizing %d files with %d workers...", len(todo), workers)
+	ctx, cancel := context.WithTimeout(ctx, config.Timeouts.generate())
+	defer cancel()
+
+	summaries := make([]string, len(todo))
+	tokens := make([]int, len(todo))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(todo); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				f := todo[i]
+				summary, used, err := s.generator.SummarizeFile(ctx, f.GetFilename(), f.GetPatch())
+				if err != nil {
+					s.logger.Debug("Summary of %s skipped: %v", f.GetFilename(), err)
+					continue
+				}
+				summaries[i], tokens[i] = summary, used
+			}
+		}()
+	}
+	skipped := 0
+	for i := range todo {
+		if deadline, _ := ctx.Deadline(); time.Until(deadline) < minSummaryTime {
+			skipped = len(todo) - i
+			break
+		}
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	var out []ai.FileSummary
+	total := 0
+	for i, summary := range summaries {
+		total += tokens[i]
+		if summary != "" {
+			out = append(out, ai.FileSummary{File: todo[i].GetFilename(), Summary: summary})
+		}
+	}
+	if len(out) < len(todo) {
+		s.logger.Warning("Summarized %d of %d files (%d out of time)", len(out), len(todo), skipped)
+	} else {
+		s.logger.Success("✅ Summarized %d files", len(out))
+	}
+	return out, total
+}
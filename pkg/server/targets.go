@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/analyze"
+)
+
+// maxListedTargets caps the targets listed in the PR body
+const maxListedTargets = 50
+
+// targetsSection renders the build targets and tests a branch affects,
+// with a command CI can run to test only those
+func (s *Server) targetsSection(ctx context.Context, jw *jobWorkspace, files []*github.CommitFile) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 6*time.Minute)
+	defer cancel()
+
+	s.logger.Loading("🎯 Computing affected targets...")
+	ws, err := jw.get(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	changed := make([]string, 0, len(files))
+	for _, f := range files {
+		changed = append(changed, f.GetFilename())
+	}
+	targets, err := analyze.AffectedTargets(ctx, ws, changed)
+	if err != nil {
+		return "", err
+	}
+	if len(targets.Affected) == 0 && len(targets.Tests) == 0 {
+		return "", nil
+	}
+
+	var b strings.Builder
+	b.WriteString("### Affected Targets\n\n")
+	if targets.All {
+		b.WriteString("Module dependencies changed, so every package is affected.\n\n")
+	}
+	fmt.Fprintf(&b, "%d target(s) affected, %d with tests.\n", len(targets.Affected), len(targets.Tests))
+	if len(targets.Tests) > 0 && len(targets.Tests) <= maxListedTargets {
+		command := "go test " + strings.Join(targets.Tests, " ")
+		if targets.Tool == "bazel" {
+			command = "bazel test " + strings.Join(targets.Tests, " ")
+		}
+		fmt.Fprintf(&b, "\n```sh\n%s\n```\n", command)
+	}
+
+	listed := targets.Affected
+	if len(listed) > maxListedTargets {
+		listed = listed[:maxListedTargets]
+	}
+	b.WriteString("\n<details>\n<summary>Targets</summary>\n\n")
+	for _, t := range listed {
+		fmt.Fprintf(&b, "- `%s`\n", t)
+	}
+	if more := len(targets.Affected) - len(listed); more > 0 {
+		fmt.Fprintf(&b, "- …and %d more\n", more)
+	}
+	b.WriteString("\n</details>\n")
+	return b.String(), nil
+}
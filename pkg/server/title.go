@@ -0,0 +1,85 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// defaultTicketPattern matches Jira-style keys such as ABC-123
+const defaultTicketPattern = `[A-Z][A-Z0-9]+-\d+`
+
+// TitlePrefixConfig prefixes PR titles with a ticket key or team tag
+type TitlePrefixConfig struct {
+	TicketPattern string `json:"ticket_pattern,omitempty"` // regexp for ticket keys, default ABC-123 style
+	Tag           string `json:"tag,omitempty"`            // used when no ticket key is found
+}
+
+// validate checks the ticket pattern compiles
+func (c *TitlePrefixConfig) validate() error {
+	if _, err := regexp.Compile(c.pattern()); err != nil {
+		return fmt.Errorf("invalid ticket pattern: %w", err)
+	}
+	return nil
+}
+
+// pattern returns the ticket key pattern
+func (c *TitlePrefixConfig) pattern() string {
+	if c.TicketPattern != "" {
+		return c.TicketPattern
+	}
+	return defaultTicketPattern
+}
+
+// prefix returns the bracketed prefix for a branch and commit message,
+// preferring a ticket key in the branch name, or "" when nothing applies
+func (c *TitlePrefixConfig) prefix(branch, commitMsg string) string {
+	re := regexp.MustCompile(c.pattern())
+	if key := re.FindString(branch); key != "" {
+		return "[" + key + "]"
+	}
+	if key := re.FindString(commitMsg); key != "" {
+		return "[" + key + "]"
+	}
+	if c.Tag != "" {
+		return "[" + c.Tag + "]"
+	}
+	return ""
+}
+
+// applyTitlePrefix puts prefix at the start of title, dropping any copy of
+// it the model already wrote so the result is the same either way
+func applyTitlePrefix(title, prefix string) string {
+	if prefix == "" {
+		return title
+	}
+	key := strings.Trim(prefix, "[]")
+	rest := strings.TrimSpace(title)
+	for _, p := range []string{prefix, key + ":", key + " "} {
+		if strings.HasPrefix(rest, p) {
+			rest = strings.TrimSpace(strings.TrimPrefix(rest, p))
+			break
+		}
+	}
+	return prefix + " " + rest
+}
+
+// uniqueTitle returns title, or title with the branch (and then the commit)
+// appended when an open PR already uses it
+func uniqueTitle(title string, job *Job, existing []string) string {
+	taken := func(t string) bool {
+		for _, e := range existing {
+			if strings.EqualFold(strings.TrimSpace(e), strings.TrimSpace(t)) {
+				return true
+			}
+		}
+		return false
+	}
+	if !taken(title) {
+		return title
+	}
+	if t := fmt.Sprintf("%s (%s)", title, job.Branch); !taken(t) {
+		return t
+	}
+	return fmt.Sprintf("%s (%s, %s)", title, job.Branch, shortSHA(job.SHA))
+}
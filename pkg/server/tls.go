@@ -0,0 +1,65 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// tlsConfigFromEnv builds the listener TLS config. With GGQUICK_CLIENT_CA
+// set, client certificates signed by that CA are verified when presented.
+// They are optional at the handshake so GitHub webhooks and read-only
+// clients still connect; requireClientCerts enforces them on changes.
+func tlsConfigFromEnv() (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	caFile := os.Getenv("GGQUICK_CLIENT_CA")
+	if caFile == "" {
+		return config, nil
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+	config.ClientCAs = pool
+	config.ClientAuth = tls.VerifyClientCertIfGiven
+	return config, nil
+}
+
+// requireClientCerts rejects requests that change state without a
+// verified client certificate when mTLS is enabled. Reads stay open, and
+// /webhook is left to its signature check since GitHub can't present a
+// certificate.
+func (s *Server) requireClientCerts(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.URL.Path == "/webhook" || s.requireClientCert(w, r) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// requireClientCert rejects requests without a verified client
+// certificate when mTLS is enabled
+func (s *Server) requireClientCert(w http.ResponseWriter, r *http.Request) bool {
+	if s.srv == nil || s.srv.TLSConfig == nil || s.srv.TLSConfig.ClientCAs == nil {
+		return true
+	}
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+		s.logger.Error("❌ Rejected %s %s without client certificate from %s", r.Method, r.URL.Path, r.RemoteAddr)
+		http.Error(w, "Client certificate required", http.StatusUnauthorized)
+		return false
+	}
+	s.logger.Debug("%s %s from enrolled client %s", r.Method, r.URL.Path, r.TLS.VerifiedChains[0][0].Subject.CommonName)
+	return true
+}
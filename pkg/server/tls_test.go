@@ -0,0 +1,55 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/saint0x/ggquick/pkg/log"
+)
+
+func TestChangesNeedClientCert(t *testing.T) {
+	s := &Server{
+		logger: log.New(false),
+		srv:    &http.Server{TLSConfig: &tls.Config{ClientCAs: x509.NewCertPool()}},
+	}
+	handler := s.requireClientCerts(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	enrolled := &tls.ConnectionState{
+		VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "laptop"}}}},
+	}
+
+	tests := []struct {
+		method, path string
+		state        *tls.ConnectionState
+		want         int
+	}{
+		{http.MethodPost, "/push", nil, http.StatusUnauthorized},
+		{http.MethodPost, "/config", &tls.ConnectionState{}, http.StatusUnauthorized},
+		{http.MethodDelete, "/rules", nil, http.StatusUnauthorized},
+		{http.MethodPost, "/admin/import", nil, http.StatusUnauthorized},
+		{http.MethodPost, "/config", enrolled, http.StatusOK},
+		{http.MethodGet, "/jobs", nil, http.StatusOK},
+		// GitHub can't present a certificate; the signature check covers it
+		{http.MethodPost, "/webhook", nil, http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.TLS = tt.state
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s %s: got %d, want %d", tt.method, tt.path, rec.Code, tt.want)
+		}
+	}
+
+	// Without a client CA nothing is checked
+	s.srv.TLSConfig = &tls.Config{}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/push", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("POST /push without mTLS: got %d, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/ai"
+)
+
+// SectionTopics suggests a PR title for each topic of a multi-topic branch
+const SectionTopics = "topics"
+
+// maxTopicCommits skips grouping on branches with more commits
+const maxTopicCommits = 100
+
+// CommitTopicsConfig groups a branch's commits by topic before generating,
+// so a branch covering unrelated work gets a subsection per topic
+type CommitTopicsConfig struct {
+	Enabled bool `json:"enabled"`
+	// Titles adds a section suggesting a separate PR title per topic
+	Titles bool `json:"titles,omitempty"`
+}
+
+// commitTopics returns the unrelated topics the branch's commits cover,
+// or nil when there's one
+func (s *Server) commitTopics(ctx context.Context, job *Job, commits []*github.RepositoryCommit) []ai.Topic {
+	if len(commits) < 2 || len(commits) > maxTopicCommits {
+		return nil
+	}
+	s.logger.Loading("🧩 Grouping %d commit(s) by topic...", len(commits))
+	topics, tokens, err := s.generator.CommitTopics(ctx, commitMessages(commits))
+	s.jobs.update(job.ID, func(j *Job) { j.Tokens += tokens })
+	if err != nil {
+		s.logger.Warning("Commit topics skipped: %v", err)
+		return nil
+	}
+	if len(topics) > 1 {
+		s.logger.Info("🧩 Commits cover %d topics", len(topics))
+	}
+	return topics
+}
+
+// topicsSection renders a suggested PR per topic
+func topicsSection(topics []ai.Topic) string {
+	var b strings.Builder
+	b.WriteString("### Topics\n\n")
+	fmt.Fprintf(&b, "This branch covers %d unrelated topics. Consider a separate PR for each:\n\n", len(topics))
+	for i, t := range topics {
+		fmt.Fprintf(&b, "%d. **%s**\n", i+1, t.Title)
+		for _, subject := range t.Subjects {
+			fmt.Fprintf(&b, "   - %s\n", subject)
+		}
+	}
+	return b.String()
+}
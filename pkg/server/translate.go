@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// translationPattern matches the translation sections of a body, so they
+// aren't translated again and a later run replaces its language's
+var translationPattern = regexp.MustCompile(`(?s)\n*<!-- ggquick:translation:([a-zA-Z0-9-]+) -->.*?<!-- /ggquick:translation:[a-zA-Z0-9-]+ -->`)
+
+// translateRequest asks for a PR description in another language
+type translateRequest struct {
+	Repo string `json:"repo"`
+	PR   int    `json:"pr"`
+	Lang string `json:"lang"`
+}
+
+// translateResult is the PR whose description got a translation
+type translateResult struct {
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+	Lang   string `json:"lang"`
+	Tokens int    `json:"tokens,omitempty"`
+}
+
+// handleTranslate appends a translation of a PR's description to it
+func (s *Server) handleTranslate(w http.ResponseWriter, r *http.Request) {
+	s.logger.Loading("📥 Receiving translate request...")
+
+	if r.Method != http.MethodPost {
+		s.logger.Error("❌ Invalid method: %s", r.Method)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	var req translateRequest
+	if err := decodeJSON(w, r, maxBodySize, &req); err != nil {
+		s.logger.Error("❌ Failed to decode translate request: %v", err)
+		return
+	}
+	req.Lang = strings.TrimSpace(req.Lang)
+	if req.PR <= 0 || req.Lang == "" {
+		http.Error(w, "pr and lang are required", http.StatusBadRequest)
+		return
+	}
+	if !languagePattern.MatchString(req.Lang) {
+		http.Error(w, "Invalid language tag", http.StatusBadRequest)
+		return
+	}
+
+	config := s.repoConfig(req.Repo)
+	if config == nil {
+		s.logger.Error("❌ Repository not configured: %s", req.Repo)
+		http.Error(w, "Repository not configured", http.StatusBadRequest)
+		return
+	}
+	if !s.allowRepo(w, config.FullName()) {
+		return
+	}
+
+	result, err := s.translate(r.Context(), config, req)
+	if err != nil {
+		s.logger.Error("❌ Translation failed: %v", err)
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// translate translates a PR's description, leaving out earlier
+// translations, and adds it to the body in a collapsed section
+func (s *Server) translate(ctx context.Context, config *Config, req translateRequest) (*translateResult, error) {
+	owner, name := config.prTarget()
+	fetchCtx, cancel := context.WithTimeout(ctx, config.Timeouts.github())
+	defer cancel()
+	pr, err := s.github.GetPullRequest(fetchCtx, owner, name, req.PR)
+	if err != nil {
+		return nil, err
+	}
+	original := strings.TrimSpace(translationPattern.ReplaceAllString(pr.GetBody(), ""))
+	if original == "" {
+		return nil, fmt.Errorf("PR #%d has no description to translate", req.PR)
+	}
+
+	s.logger.Loading("🌐 Translating #%d to %s...", req.PR, req.Lang)
+	genCtx, cancelGen := context.WithTimeout(ctx, config.Timeouts.generate())
+	defer cancelGen()
+	translated, tokens, err := s.generator.TranslateBody(genCtx, original, req.Lang)
+	if err == nil {
+		err = s.checkContent(ctx, config, translated)
+	}
+	if err != nil {
+		return nil, err
+	}
+	section := translationSection(req.Lang, sanitize(config, translated, ""))
+
+	editCtx, cancelEdit := context.WithTimeout(ctx, config.Timeouts.github())
+	defer cancelEdit()
+	err = s.editPRBody(editCtx, config, owner, name, req.PR, pr.GetHead().GetSHA(), func(body string) string {
+		return withTranslation(body, req.Lang, section)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Success("✅ Added %s translation to #%d", req.Lang, req.PR)
+	return &translateResult{Number: req.PR, URL: pr.GetHTMLURL(), Lang: req.Lang, Tokens: tokens}, nil
+}
+
+// translationSection wraps a translation in a collapsed section between
+// markers for its language
+func translationSection(lang, text string) string {
+	return fmt.Sprintf("<!-- ggquick:translation:%s -->\n<details>\n<summary>🌐 Translation (%s)</summary>\n\n%s\n\n</details>\n<!-- /ggquick:translation:%s -->",
+		lang, lang, strings.TrimSpace(text), lang)
+}
+
+// withTranslation replaces the body's translation into lang, or appends it
+func withTranslation(body, lang, section string) string {
+	replaced := false
+	body = translationPattern.ReplaceAllStringFunc(body, func(old string) string {
+		if translationPattern.FindStringSubmatch(old)[1] != lang || replaced {
+			return old
+		}
+		replaced = true
+		return "\n\n" + section
+	})
+	if replaced {
+		return body
+	}
+	return strings.TrimRight(body, "\n") + "\n\n" + section
+}
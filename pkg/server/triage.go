@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/saint0x/ggquick/pkg/ai"
+)
+
+// WebhookIssues is sent when issues are opened or edited
+const WebhookIssues = "issues"
+
+// TriageConfig labels and summarizes newly opened issues
+type TriageConfig struct {
+	Enabled bool `json:"enabled"`
+	// Components the model picks the affected one from, labelled
+	// component:<name>
+	Components []string `json:"components,omitempty"`
+	// Labels the model may add as it sees fit
+	Labels []string `json:"labels,omitempty"`
+}
+
+// validate checks components and labels are named
+func (c *TriageConfig) validate() error {
+	for _, name := range append(append([]string{}, c.Components...), c.Labels...) {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("triage components and labels can't be empty")
+		}
+	}
+	return nil
+}
+
+// handleIssuesEvent triages newly opened issues in the background, so
+// the webhook returns before the model does
+func (s *Server) handleIssuesEvent(e *github.IssuesEvent) {
+	config := s.webhookConfig(WebhookIssues, e.GetRepo())
+	if config == nil || config.Triage == nil || !config.Triage.Enabled || e.GetAction() != "opened" {
+		return
+	}
+	issue := e.GetIssue()
+	if issue.GetUser().GetType() == "Bot" {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), config.Timeouts.generate()+config.Timeouts.github())
+		defer cancel()
+		if err := s.triageIssue(ctx, config, issue); err != nil {
+			s.logger.Warning("Triage failed for issue #%d in %s: %v", issue.GetNumber(), config.FullName(), err)
+		}
+	}()
+}
+
+// triageIssue labels an issue with its guessed severity, component and
+// suggested labels, and comments a summary
+func (s *Server) triageIssue(ctx context.Context, config *Config, issue *github.Issue) error {
+	s.logger.Loading("🏷️ Triaging issue #%d in %s...", issue.GetNumber(), config.FullName())
+	info := ai.IssueInfo{
+		Repo:       config.FullName(),
+		Title:      issue.GetTitle(),
+		Body:       issue.GetBody(),
+		Components: config.Triage.Components,
+		Labels:     config.Triage.Labels,
+	}
+	if prompt := s.promptTemplate(config); prompt != "" {
+		info.Instructions, _ = expandTemplate("prompt", prompt, s.templateVars(config, &Job{}))
+	}
+
+	genCtx, cancelGen := context.WithTimeout(ctx, config.Timeouts.generate())
+	defer cancelGen()
+	triage, err := s.generator.TriageIssue(genCtx, info)
+	if err != nil {
+		return err
+	}
+	if err := s.checkContent(ctx, config, triage.Summary); err != nil {
+		// Labels come from the configured lists, only the summary is free text
+		s.logger.Warning("Triage summary for #%d dropped: %v", issue.GetNumber(), err)
+		triage.Summary = ""
+	}
+	triage.Summary = sanitize(config, triage.Summary, "")
+
+	var labels []string
+	if triage.Severity != "" {
+		labels = append(labels, "severity:"+triage.Severity)
+	}
+	if triage.Component != "" {
+		labels = append(labels, "component:"+triage.Component)
+	}
+	labels = append(labels, triage.Labels...)
+
+	ghCtx, cancelGH := context.WithTimeout(ctx, config.Timeouts.github())
+	defer cancelGH()
+	owner, name := config.Owner, config.Name
+	if len(labels) > 0 {
+		if err := s.github.AddLabels(ghCtx, owner, name, issue.GetNumber(), labels); err != nil {
+			return err
+		}
+	}
+	if triage.Summary != "" {
+		if err := s.github.CreateComment(ghCtx, owner, name, issue.GetNumber(), triageComment(triage)); err != nil {
+			return err
+		}
+	}
+	s.logger.Success("✅ Triaged issue #%d as %s", issue.GetNumber(), strings.Join(labels, ", "))
+	return nil
+}
+
+// triageComment renders the summary comment on a triaged issue
+func triageComment(triage *ai.Triage) string {
+	var b strings.Builder
+	b.WriteString("**Triage summary**\n\n")
+	b.WriteString(triage.Summary)
+	b.WriteString("\n\n")
+	if triage.Severity != "" {
+		fmt.Fprintf(&b, "- Severity (guessed): %s\n", triage.Severity)
+	}
+	if triage.Component != "" {
+		fmt.Fprintf(&b, "- Affected component: %s\n", triage.Component)
+	}
+	b.WriteString("\n<sub>Generated by ggquick from the issue text, maintainers may relabel.</sub>")
+	return b.String()
+}
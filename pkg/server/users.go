@@ -0,0 +1,226 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// userKeyHeader carries the key returned when a user registers their token,
+// proving the push came from them
+const userKeyHeader = "X-Ggquick-User-Key"
+
+// UserClient is a GitHub client authenticated with a user's own token
+type UserClient interface {
+	Login(ctx context.Context) (string, error)
+	CreatePullRequest(ctx context.Context, owner, repo string, pr *github.NewPullRequest) (*github.PullRequest, error)
+}
+
+// AttributionConfig credits the pusher on generated PRs
+type AttributionConfig struct {
+	// Line adds "Generated for @user" to the PR body
+	Line bool `json:"line,omitempty"`
+	// OnBehalf opens the PR with the pusher's registered token. Only
+	// pushes sent with the user's key qualify; others use the server token.
+	OnBehalf bool `json:"on_behalf,omitempty"`
+}
+
+// userAccount is a registered user token
+type userAccount struct {
+	Login   string `json:"login"`
+	Token   string `json:"token"`
+	KeyHash string `json:"key_hash"`
+}
+
+// userStore holds registered user tokens by lowercased login
+type userStore struct {
+	mu       sync.RWMutex
+	accounts map[string]*userAccount
+}
+
+// put stores or replaces the account for its login
+func (u *userStore) put(account *userAccount) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.accounts[strings.ToLower(account.Login)] = account
+}
+
+// remove forgets the account for login
+func (u *userStore) remove(login string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	delete(u.accounts, strings.ToLower(login))
+}
+
+// byKey returns the account whose key hashes to the stored hash
+func (u *userStore) byKey(key string) *userAccount {
+	hash := hashUserKey(key)
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	for _, account := range u.accounts {
+		if subtle.ConstantTimeCompare([]byte(account.KeyHash), []byte(hash)) == 1 {
+			return account
+		}
+	}
+	return nil
+}
+
+// token returns the registered token for login, if any
+func (u *userStore) token(login string) string {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	if account := u.accounts[strings.ToLower(login)]; account != nil {
+		return account.Token
+	}
+	return ""
+}
+
+// list returns copies of every account so tokens can be rewritten
+func (u *userStore) list() []*userAccount {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	accounts := make([]*userAccount, 0, len(u.accounts))
+	for _, account := range u.accounts {
+		copied := *account
+		accounts = append(accounts, &copied)
+	}
+	return accounts
+}
+
+// hashUserKey returns the stored form of a user key
+func hashUserKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// newUserKey returns a random key for a registered user
+func newUserKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// SetUserClients enables opening PRs on behalf of users, building a
+// client for each registered token with fn
+func (s *Server) SetUserClients(fn func(token string) UserClient) {
+	s.userClients = fn
+}
+
+// pushAuthor resolves the user key sent with a push. It returns "" when
+// no key was sent and false when the key is unknown.
+func (s *Server) pushAuthor(r *http.Request) (string, bool) {
+	key := r.Header.Get(userKeyHeader)
+	if key == "" {
+		return "", true
+	}
+	account := s.users.byKey(key)
+	if account == nil {
+		return "", false
+	}
+	return account.Login, true
+}
+
+// setAuthor records who triggered a job. Verified authors sent a
+// registered user key and may have PRs opened with their token.
+func (s *Server) setAuthor(job *Job, author string, verified bool) {
+	if author == "" {
+		return
+	}
+	s.jobs.update(job.ID, func(j *Job) {
+		j.Author = author
+		j.authorVerified = verified
+	})
+}
+
+// createPR opens the pull request, with the author's own token when the
+// repository asks for it and one is registered. PRs the user can't open
+// fall back to the server token.
+func (s *Server) createPR(ctx context.Context, config *Config, job *Job, owner, name string, pr *github.NewPullRequest) (*github.PullRequest, error) {
+	if config.Attribution != nil && config.Attribution.OnBehalf && job.authorVerified && s.userClients != nil {
+		if token := s.users.token(job.Author); token != "" {
+			created, err := s.userClients(token).CreatePullRequest(ctx, owner, name, pr)
+			if err == nil {
+				s.logger.Info("👤 Opened PR as @%s", job.Author)
+				return created, nil
+			}
+			if !forbidden(err) {
+				return nil, err
+			}
+			s.logger.Warning("@%s can't open PRs on %s/%s, using the server token", job.Author, owner, name)
+		}
+	}
+	return s.github.CreatePullRequest(ctx, owner, name, pr)
+}
+
+// attributionSection credits the user a PR was generated for
+func attributionSection(author string) string {
+	return fmt.Sprintf("_Generated for @%s_", author)
+}
+
+// userRequest registers a GitHub token with the server
+type userRequest struct {
+	Token string `json:"token"`
+}
+
+// handleUsers registers a user's token (POST) or removes it (DELETE). The
+// token's owner is looked up on GitHub, so only the user can register it,
+// and the returned key identifies their pushes.
+func (s *Server) handleUsers(w http.ResponseWriter, r *http.Request) {
+	if s.userClients == nil {
+		http.Error(w, "User tokens are not enabled", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		var req userRequest
+		if err := decodeJSON(w, r, maxBodySize, &req); err != nil {
+			s.logger.Error("❌ Failed to decode user request: %v", err)
+			return
+		}
+		if req.Token == "" {
+			http.Error(w, "token is required", http.StatusBadRequest)
+			return
+		}
+		login, err := s.userClients(req.Token).Login(r.Context())
+		if err != nil {
+			s.logger.Error("❌ Failed to verify user token: %v", err)
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+		key, err := newUserKey()
+		if err != nil {
+			s.logger.Error("❌ %v", err)
+			http.Error(w, "Failed to register user", http.StatusInternalServerError)
+			return
+		}
+		s.users.put(&userAccount{Login: login, Token: req.Token, KeyHash: hashUserKey(key)})
+		s.persist()
+		s.logger.Success("👤 Registered token for @%s", login)
+		writeJSON(w, http.StatusOK, map[string]string{"login": login, "key": key})
+
+	case http.MethodDelete:
+		login, ok := s.pushAuthor(r)
+		if !ok || login == "" {
+			http.Error(w, "Unknown user key", http.StatusUnauthorized)
+			return
+		}
+		s.users.remove(login)
+		s.persist()
+		s.logger.Success("👤 Removed token for @%s", login)
+		writeJSON(w, http.StatusOK, map[string]string{"status": "removed", "login": login})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
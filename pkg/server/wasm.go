@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/saint0x/ggquick/pkg/pipeline"
+)
+
+// wasmModules compiles the WASM modules repositories list on first use
+// and keeps them by path. A module changed on disk is picked up on
+// restart.
+type wasmModules struct {
+	mu     sync.Mutex
+	stages map[string]pipeline.Stage
+}
+
+// load returns the stage for the module at path, compiling it when it
+// isn't loaded yet
+func (m *wasmModules) load(path string) (pipeline.Stage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if stage, ok := m.stages[path]; ok {
+		return stage, nil
+	}
+	// Compiled modules outlive the request that loaded them
+	stage, err := pipeline.LoadWasm(context.Background(), path)
+	if err != nil {
+		return nil, err
+	}
+	m.stages[path] = stage
+	return stage, nil
+}
+
+// validateWasm checks that wasm module paths are absolute .wasm files
+func validateWasm(paths []string) error {
+	for _, path := range paths {
+		if !filepath.IsAbs(path) || filepath.Ext(path) != ".wasm" {
+			return fmt.Errorf("invalid wasm module %q, want an absolute path to a .wasm file", path)
+		}
+	}
+	return nil
+}
+
+// jobStages returns the registered stages selected for a repository,
+// followed by its WASM modules and webhook extensions
+func (s *Server) jobStages(config *Config) ([]pipeline.Stage, error) {
+	var stages []pipeline.Stage
+	for _, stage := range pipeline.Stages() {
+		if len(config.Stages) == 0 || contains(config.Stages, stage.Name()) {
+			stages = append(stages, stage)
+		}
+	}
+	for _, path := range config.Wasm {
+		stage, err := s.wasm.load(path)
+		if err != nil {
+			return nil, err
+		}
+		stages = append(stages, stage)
+	}
+	for _, ext := range config.Extensions {
+		stages = append(stages, ext)
+	}
+	return stages, nil
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// Webhook events a repository's webhook can send
+const (
+	WebhookPush         = "push"
+	WebhookPullRequest  = "pull_request"
+	WebhookIssueComment = "issue_comment"
+	WebhookRelease      = "release"
+)
+
+// optionalWebhookEvents can be added with the events config field
+var optionalWebhookEvents = []string{WebhookIssueComment, WebhookRelease}
+
+// webhookEvents lists the events the repository's webhook subscribes to.
+// push starts jobs and pull_request follows the PRs they open; the rest
+// are opted into per repository.
+func (c *Config) webhookEvents() []string {
+	events := []string{WebhookPush, WebhookPullRequest}
+	if c.ChecklistSync != nil && c.ChecklistSync.Enabled {
+		events = append(events, WebhookCheckRun, WebhookStatus)
+	}
+	if (c.CIResults != nil && c.CIResults.Enabled) || (c.Coverage != nil && c.Coverage.Enabled) {
+		events = append(events, WebhookCheckSuite)
+	}
+	if c.Triage != nil && c.Triage.Enabled {
+		events = append(events, WebhookIssues)
+	}
+	if c.ActionItems != nil && c.ActionItems.Enabled {
+		events = append(events, WebhookPullRequestReview)
+	}
+	if c.Localization != nil && c.Localization.Enabled {
+		events = append(events, WebhookIssueComment)
+	}
+	for _, event := range c.Events {
+		if !contains(events, event) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// webhookConfig returns the config for a webhook's repository, or nil when
+// the repository isn't configured or allowed here
+func (s *Server) webhookConfig(kind string, repo *github.Repository) *Config {
+	name := repo.GetFullName()
+	if name == "" || !s.repos.allowed(name) {
+		s.logger.Info("ℹ️ Ignoring %s event for %s", kind, name)
+		return nil
+	}
+	config := s.repoConfig(name)
+	if config == nil {
+		s.logger.Info("ℹ️ Ignoring %s event for unconfigured %s", kind, name)
+	}
+	return config
+}
+
+// handlePullRequestEvent welcomes first-time contributors, publishes
+// merged and closed PRs, starts label-triggered backports and publishes
+// merged release PRs
+func (s *Server) handlePullRequestEvent(e *github.PullRequestEvent) {
+	config := s.webhookConfig(WebhookPullRequest, e.GetRepo())
+	if config == nil {
+		return
+	}
+	pr := e.GetPullRequest()
+	switch e.GetAction() {
+	case "opened":
+		s.welcomeOpened(config, pr)
+		return
+	case "labeled":
+		s.backportLabels(config, pr, []*github.Label{e.GetLabel()})
+		return
+	case "closed":
+		s.backportLabels(config, pr, pr.Labels)
+		s.publishRelease(config, pr)
+	default:
+		return
+	}
+
+	typ := EventPRClosed
+	if pr.GetMerged() {
+		typ = EventPRMerged
+	}
+	s.logger.Info("📝 PR #%d %s in %s", pr.GetNumber(), strings.TrimPrefix(typ, "pr_"), config.FullName())
+	s.events.publish(Event{
+		Type:    typ,
+		Owner:   config.Owner,
+		Repo:    config.Name,
+		Branch:  pr.GetHead().GetRef(),
+		PRURL:   pr.GetHTMLURL(),
+		Message: pr.GetTitle(),
+	})
+}
+
+// handleIssueCommentEvent runs /ggquick commands and publishes new
+// comments on pull requests
+func (s *Server) handleIssueCommentEvent(e *github.IssueCommentEvent) {
+	config := s.webhookConfig(WebhookIssueComment, e.GetRepo())
+	if config == nil || e.GetAction() != "created" {
+		return
+	}
+	if s.handleLanguageCommand(config, e) || !e.GetIssue().IsPullRequest() {
+		return
+	}
+
+	body, _, _ := strings.Cut(strings.TrimSpace(e.GetComment().GetBody()), "\n")
+	s.events.publish(Event{
+		Type:    EventPRComment,
+		Owner:   config.Owner,
+		Repo:    config.Name,
+		PRURL:   e.GetIssue().GetHTMLURL(),
+		Message: fmt.Sprintf("%s: %s", e.GetComment().GetUser().GetLogin(), body),
+	})
+}
+
+// handleReleaseEvent publishes published releases
+func (s *Server) handleReleaseEvent(e *github.ReleaseEvent) {
+	config := s.webhookConfig(WebhookRelease, e.GetRepo())
+	if config == nil || e.GetAction() != "published" {
+		return
+	}
+
+	release := e.GetRelease()
+	s.logger.Info("📝 Release %s published in %s", release.GetTagName(), config.FullName())
+	s.events.publish(Event{
+		Type:    EventRelease,
+		Owner:   config.Owner,
+		Repo:    config.Name,
+		Branch:  release.GetTargetCommitish(),
+		PRURL:   release.GetHTMLURL(),
+		Message: release.GetTagName(),
+	})
+}
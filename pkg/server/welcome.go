@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// welcomeTTL stops the pull_request webhook welcoming a PR ggquick
+// already welcomed when it opened it
+const welcomeTTL = 24 * time.Hour
+
+// maxWelcomeFiles bounds the files listed in the guide prompt
+const maxWelcomeFiles = 50
+
+// welcomeContributor posts a welcome comment on a PR whose author has no
+// merged PRs in the repository yet, with the parts of CONTRIBUTING.md that
+// matter for the files they touched
+func (s *Server) welcomeContributor(ctx context.Context, config *Config, pr *github.PullRequest, author string) error {
+	if !config.Welcome || author == "" {
+		return nil
+	}
+	owner, name := config.prTarget()
+	ghCtx, cancel := context.WithTimeout(ctx, config.Timeouts.github())
+	defer cancel()
+
+	merged, err := s.github.CountMergedPRs(ghCtx, owner, name, author)
+	if err != nil || merged > 0 {
+		return err
+	}
+	release, err := s.tryLock(ghCtx, fmt.Sprintf("welcome:%s/%s#%d", owner, name, pr.GetNumber()), welcomeTTL)
+	if err != nil || release == nil {
+		return err
+	}
+	time.AfterFunc(welcomeTTL, release)
+
+	comment := fmt.Sprintf("👋 Welcome, @%s, and thanks for your first PR to %s/%s!", author, owner, name)
+	if excerpt := s.guideExcerpt(ctx, config, pr, author); excerpt != "" {
+		comment += "\n\nA few things from the contributing guide that apply to your changes:\n\n" + excerpt
+	}
+	if err := s.github.CreateComment(ghCtx, owner, name, pr.GetNumber(), comment); err != nil {
+		return err
+	}
+	s.logger.Success("✅ Welcomed first-time contributor %s on #%d", author, pr.GetNumber())
+	return nil
+}
+
+// guideExcerpt condenses the contributing guide for the files a PR
+// touches, in the author's language. It returns "" without a guide or
+// when condensing fails.
+func (s *Server) guideExcerpt(ctx context.Context, config *Config, pr *github.PullRequest, author string) string {
+	owner, name := config.prTarget()
+	ghCtx, cancel := context.WithTimeout(ctx, config.Timeouts.github())
+	defer cancel()
+
+	guide, err := s.github.GetContributingGuide(ghCtx, owner, name)
+	if err != nil {
+		s.logger.Debug("No contributing guide in %s/%s: %v", owner, name, err)
+		return ""
+	}
+	var files []string
+	comp, err := s.github.CompareBranches(ghCtx, owner, name, pr.GetBase().GetSHA(), pr.GetHead().GetSHA())
+	if err != nil {
+		s.logger.Warning("Failed to list files of #%d: %v", pr.GetNumber(), err)
+	} else {
+		for _, f := range comp.Files {
+			if len(files) == maxWelcomeFiles {
+				break
+			}
+			files = append(files, f.GetFilename())
+		}
+	}
+
+	var instructions string
+	if prompt := s.promptTemplate(config); prompt != "" {
+		instructions, _ = expandTemplate("prompt", prompt, s.templateVars(config, &Job{Branch: pr.GetHead().GetRef()}))
+	}
+	genCtx, cancelGen := context.WithTimeout(ctx, config.Timeouts.generate())
+	defer cancelGen()
+	instructions = withLanguage(instructions, s.userLanguage(config, author))
+	excerpt, _, err := s.generator.CondenseGuide(genCtx, guide, files, instructions)
+	if err == nil {
+		err = s.checkContent(ctx, config, excerpt)
+	}
+	if err != nil {
+		s.logger.Warning("Contributing guide not condensed: %v", err)
+		return ""
+	}
+	return strings.TrimSpace(sanitize(config, excerpt, ""))
+}
+
+// welcomeOpened welcomes the author of a PR opened outside ggquick
+func (s *Server) welcomeOpened(config *Config, pr *github.PullRequest) {
+	if !config.Welcome || pr.GetUser().GetType() == "Bot" {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*config.Timeouts.github()+config.Timeouts.generate())
+		defer cancel()
+		if err := s.welcomeContributor(ctx, config, pr, pr.GetUser().GetLogin()); err != nil {
+			s.logger.Warning("Failed to welcome %s on #%d: %v", pr.GetUser().GetLogin(), pr.GetNumber(), err)
+		}
+	}()
+}
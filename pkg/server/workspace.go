@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/saint0x/ggquick/pkg/sandbox"
+)
+
+// jobWorkspace lazily clones a job's branch once, shared by every
+// sandbox stage that needs the source tree
+type jobWorkspace struct {
+	url    string
+	branch string
+
+	once sync.Once
+	ws   *sandbox.Workspace
+	err  error
+}
+
+// newJobWorkspace prepares a lazy clone of branch
+func (s *Server) newJobWorkspace(config *Config, branch string) *jobWorkspace {
+	if s.sandboxed != nil {
+		return &jobWorkspace{branch: branch}
+	}
+	return &jobWorkspace{
+		url:    sandbox.CloneURL(config.Owner, config.Name, os.Getenv("GITHUB_TOKEN")),
+		branch: branch,
+	}
+}
+
+// get clones the branch on first use
+func (w *jobWorkspace) get(ctx context.Context) (*sandbox.Workspace, error) {
+	w.once.Do(func() {
+		if w.url == "" {
+			w.err = fmt.Errorf("no clones in sandbox mode")
+			return
+		}
+		ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+		defer cancel()
+		w.ws, w.err = sandbox.Clone(ctx, w.url, w.branch)
+	})
+	return w.ws, w.err
+}
+
+// cleanup removes the clone if one was made
+func (w *jobWorkspace) cleanup() {
+	if w.ws != nil {
+		w.ws.Cleanup()
+	}
+}